@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Server administration commands",
+	Long:  "Commands for server administrators. These require an account with the admin role.",
+}
+
+var adminStaleOlderThan string
+
+var adminStaleProfilesCmd = &cobra.Command{
+	Use:   "stale-profiles",
+	Short: "List profiles that have not been updated recently",
+	Long:  "List profiles on the server whose last update is older than --older-than (e.g. 90d, 12h), oldest first.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load config
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		client := newAuthenticatedClient(cfg.Server.URL)
+
+		stale, err := client.ListStaleProfiles(adminStaleOlderThan)
+		if err != nil {
+			return err
+		}
+
+		if len(stale) == 0 {
+			cmd.Printf("No profiles older than %s.\n", adminStaleOlderThan)
+			return nil
+		}
+
+		cmd.Printf("%-20s %-38s %-12s %-25s\n", "NAME", "OWNER", "EXTENSIONS", "LAST UPDATED")
+		cmd.Printf("%s\n", strings.Repeat("-", 96))
+		for _, p := range stale {
+			cmd.Printf("%-20s %-38s %-12d %-25s\n",
+				p.Name,
+				p.UserID,
+				p.Extensions,
+				p.UpdatedAt.Format("2006-01-02 15:04:05"),
+			)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	adminStaleProfilesCmd.Flags().StringVar(&adminStaleOlderThan, "older-than", "90d", "Report profiles not updated within this period (e.g. 90d, 12h)")
+
+	adminCmd.AddCommand(adminStaleProfilesCmd)
+	rootCmd.AddCommand(adminCmd)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestAdminStaleProfilesCommand(t *testing.T) {
+	setupMockKeychain(t)
+
+	var gotOlderThan string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/profiles/stale" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		gotOlderThan = r.URL.Query().Get("older_than")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"older_than": gotOlderThan,
+			"profiles": []map[string]interface{}{
+				{"name": "legacy", "user_id": "7d1c5c1e-0000-4000-8000-000000000001", "updated_at": time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), "extensions": 4},
+			},
+		})
+	}))
+	defer server.Close()
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	setupTestConfig(t, tempHome, server.URL, filepath.Join(tempHome, ".devtools-sync", "profiles"))
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(adminCmd)
+
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"admin", "stale-profiles", "--older-than", "180d"})
+	t.Cleanup(func() { adminStaleOlderThan = "90d" })
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("admin stale-profiles failed: %v", err)
+	}
+
+	if gotOlderThan != "180d" {
+		t.Errorf("expected older_than=180d, got %q", gotOlderThan)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "legacy") || !strings.Contains(got, "2025-01-02 03:04:05") {
+		t.Errorf("expected stale profile row in output, got: %s", got)
+	}
+}
+
+func TestAdminStaleProfilesCommand_Forbidden(t *testing.T) {
+	setupMockKeychain(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	setupTestConfig(t, tempHome, server.URL, filepath.Join(tempHome, ".devtools-sync", "profiles"))
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(adminCmd)
+
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"admin", "stale-profiles"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "admin role required") {
+		t.Errorf("expected admin role error, got: %v", err)
+	}
+}
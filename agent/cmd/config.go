@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -14,6 +15,11 @@ var configCmd = &cobra.Command{
 	Long:  "View and update DevTools Sync Agent configuration settings",
 }
 
+var (
+	configShowJSON   bool
+	configShowRedact bool
+)
+
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Display current configuration",
@@ -24,6 +30,14 @@ var configShowCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		if configShowRedact {
+			cfg = cfg.Redacted()
+		}
+
+		if configShowJSON {
+			return printConfigJSON(cmd, cfg)
+		}
+
 		cmd.Printf("Server:\n")
 		cmd.Printf("  URL: %s\n", cfg.Server.URL)
 		cmd.Printf("\n")
@@ -32,6 +46,9 @@ var configShowCmd = &cobra.Command{
 		cmd.Printf("\n")
 		cmd.Printf("Logging:\n")
 		cmd.Printf("  Level: %s\n", cfg.Logging.Level)
+		cmd.Printf("\n")
+		cmd.Printf("VS Code:\n")
+		cmd.Printf("  Blocked extensions: %s\n", formatBlockedExtensions(cfg.VSCode.BlockedExtensions))
 
 		return nil
 	},
@@ -84,6 +101,13 @@ var configSetCmd = &cobra.Command{
 			default:
 				return fmt.Errorf("unknown logging field: %s", field)
 			}
+		case "vscode":
+			switch field {
+			case "blocked_extensions":
+				cfg.VSCode.BlockedExtensions = parseBlockedExtensions(value)
+			default:
+				return fmt.Errorf("unknown vscode field: %s", field)
+			}
 		default:
 			return fmt.Errorf("unknown config section: %s", section)
 		}
@@ -103,7 +127,28 @@ var configSetCmd = &cobra.Command{
 	},
 }
 
+// printConfigJSON writes the effective config as indented JSON
+func printConfigJSON(cmd *cobra.Command, cfg *config.Config) error {
+	// Emit an empty list rather than null so the shape is stable for tooling
+	out := *cfg
+	if out.VSCode.BlockedExtensions == nil {
+		out.VSCode.BlockedExtensions = []string{}
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := dataOutput(cmd).Write(data); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
 func init() {
+	configShowCmd.Flags().BoolVar(&configShowJSON, "json", false, "Print the effective configuration as JSON")
+	configShowCmd.Flags().BoolVar(&configShowRedact, "redact", false, "Mask sensitive values such as credentials in the server URL")
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configSetCmd)
 	rootCmd.AddCommand(configCmd)
@@ -120,8 +165,28 @@ func configKeyCompletion(cmd *cobra.Command, args []string, toComplete string) (
 	validKeys := []string{
 		"server.url\tServer URL for syncing profiles",
 		"profiles.directory\tDirectory for storing local profiles",
-		"logging.level\tLogging level (info, debug, error)",
+		"logging.level\tLogging level (" + strings.Join(config.LogLevels, ", ") + ")",
+		"vscode.blocked_extensions\tComma-separated extension IDs or publisher.* never installed by profile load",
 	}
 
 	return validKeys, cobra.ShellCompDirectiveNoFileComp
 }
+
+// parseBlockedExtensions splits a comma-separated blocklist; an empty value
+// clears it
+func parseBlockedExtensions(value string) []string {
+	var blocked []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			blocked = append(blocked, pattern)
+		}
+	}
+	return blocked
+}
+
+func formatBlockedExtensions(blocked []string) string {
+	if len(blocked) == 0 {
+		return "(none)"
+	}
+	return strings.Join(blocked, ", ")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
+	"github.com/spf13/cobra"
+)
+
+// Process exit codes, so scripts can tell failure causes apart.
+// Documented in docs/error-messages.md.
+const (
+	exitCodeError             = 1
+	exitCodeUsage             = 2
+	exitCodeNotAuthenticated  = 3
+	exitCodeServerUnreachable = 4
+	exitCodeVSCodeNotFound    = 5
+	exitCodeExtensionInstall  = 6
+)
+
+// usageError marks an error caused by invalid arguments or flags
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// markUsageErrors makes argument and flag validation errors of root and all
+// its subcommands usageErrors, so they exit with exitCodeUsage
+func markUsageErrors(root *cobra.Command) {
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+
+	var wrap func(cmd *cobra.Command)
+	wrap = func(cmd *cobra.Command) {
+		if args := cmd.Args; args != nil {
+			cmd.Args = func(cmd *cobra.Command, a []string) error {
+				if err := args(cmd, a); err != nil {
+					var usage *usageError
+					if errors.As(err, &usage) {
+						return err
+					}
+					return &usageError{err: err}
+				}
+				return nil
+			}
+		}
+		for _, sub := range cmd.Commands() {
+			wrap(sub)
+		}
+	}
+	wrap(root)
+}
+
+// silentExitError ends the process with code without printing an error,
+// for commands whose exit status is the result (e.g. diff --exit-code)
+type silentExitError struct {
+	code int
+}
+
+func (e *silentExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// silentExit returns an error that makes the command exit with code. It
+// silences cobra's error and usage output for cmd.
+func silentExit(cmd *cobra.Command, code int) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &silentExitError{code: code}
+}
+
+// exitCode returns the process exit code for an error returned by a command
+func exitCode(err error) int {
+	var silent *silentExitError
+	var usage *usageError
+	switch {
+	case errors.As(err, &silent):
+		return silent.code
+	case errors.As(err, &usage), strings.HasPrefix(err.Error(), "unknown command "):
+		return exitCodeUsage
+	case errors.Is(err, api.ErrNotAuthenticated), errors.Is(err, api.ErrSessionExpired), errors.Is(err, api.ErrAPIKeyRejected):
+		return exitCodeNotAuthenticated
+	case errors.Is(err, api.ErrServerUnreachable):
+		return exitCodeServerUnreachable
+	case errors.Is(err, vscode.ErrVSCodeNotFound):
+		return exitCodeVSCodeNotFound
+	case errors.Is(err, vscode.ErrExtensionInstallFailed):
+		return exitCodeExtensionInstall
+	default:
+		return exitCodeError
+	}
+}
@@ -12,10 +12,10 @@ import (
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize configuration",
-	Long:  "Create the configuration file and required directories for DevTools Sync Agent",
+	Long:  "Create the configuration file and required directories for DevTools Sync Agent. The profiles directory is created next to the configuration file, so --config places both.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configDir := config.GetConfigDir()
 		configPath := config.GetConfigPath()
+		configDir := filepath.Dir(configPath)
 
 		// Check if config already exists
 		if _, err := os.Stat(configPath); err == nil {
@@ -37,13 +37,17 @@ var initCmd = &cobra.Command{
 		cfg := &config.Config{}
 		cfg.Server.URL = "http://localhost:8080"
 		cfg.Profiles.Directory = profilesDir
-		cfg.Logging.Level = "info"
+		cfg.Logging.Level = config.DefaultLogLevel
+
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
 
 		if err := cfg.Save(); err != nil {
 			return fmt.Errorf("failed to save config: %w", err)
 		}
 
-		cmd.Printf("Configuration initialized at %s\n", configDir)
+		cmd.Printf("Configuration initialized at %s\n", configPath)
 		return nil
 	},
 }
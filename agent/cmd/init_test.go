@@ -41,13 +41,13 @@ func TestInitCommand(t *testing.T) {
 	// Verify output
 	got := output.String()
 	configDir := filepath.Join(tempHome, ".devtools-sync")
-	want := "Configuration initialized at " + configDir + "\n"
+	configPath := filepath.Join(configDir, "config.yaml")
+	want := "Configuration initialized at " + configPath + "\n"
 	if got != want {
 		t.Errorf("init command output:\ngot:  %q\nwant: %q", got, want)
 	}
 
 	// Verify config file exists
-	configPath := filepath.Join(configDir, "config.yaml")
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		t.Errorf("config file was not created at %s", configPath)
 	}
@@ -91,6 +91,51 @@ func TestInitCommand(t *testing.T) {
 
 }
 
+func TestInitCommand_CustomConfigPath(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	configDir := filepath.Join(t.TempDir(), "team")
+	configPath := filepath.Join(configDir, "devtools.yaml")
+
+	root := newTestRoot(t, initCmd)
+	output := &bytes.Buffer{}
+	root.SetOut(output)
+	root.SetErr(output)
+	root.SetArgs([]string{"init", "--config", configPath})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("init --config failed: %v", err)
+	}
+
+	if want := "Configuration initialized at " + configPath + "\n"; output.String() != want {
+		t.Errorf("init command output:\ngot:  %q\nwant: %q", output.String(), want)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read config file: %v", err)
+	}
+	var cfg struct {
+		Profiles struct {
+			Directory string `yaml:"directory"`
+		} `yaml:"profiles"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config file: %v", err)
+	}
+	profilesDir := filepath.Join(configDir, "profiles")
+	if cfg.Profiles.Directory != profilesDir {
+		t.Errorf("expected profiles.directory %s, got %s", profilesDir, cfg.Profiles.Directory)
+	}
+	if info, err := os.Stat(profilesDir); err != nil || !info.IsDir() {
+		t.Errorf("profiles directory was not created at %s", profilesDir)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempHome, ".devtools-sync")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be created under the home directory, got: %v", err)
+	}
+}
+
 func TestInitCommand_AlreadyExists(t *testing.T) {
 	// Create temporary home directory
 	tempHome := t.TempDir()
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"syscall"
 
 	"github.com/mark-chris/devtools-sync/agent/internal/api"
@@ -16,6 +17,30 @@ var keychainFactory func() keychain.Keychain = func() keychain.Keychain {
 	return keychain.NewSystemKeychain()
 }
 
+// newAuthenticatedClient creates an API client for serverURL that identifies
+// itself with this agent's version. It authenticates with the API key in
+// DEVTOOLS_SYNC_API_KEY if set, otherwise with the token in the keychain.
+func newAuthenticatedClient(serverURL string) *api.AuthenticatedClient {
+	if apiKey := os.Getenv(api.APIKeyEnvVar); apiKey != "" {
+		return api.NewAPIKeyClient(serverURL, apiKey, clientOptions()...)
+	}
+	return newKeychainClient(serverURL)
+}
+
+// clientOptions returns the API client options shared by every command
+func clientOptions() []api.ClientOption {
+	return []api.ClientOption{
+		api.WithUserAgent(api.UserAgent(version)),
+		api.WithRetryStatuses(retryStatuses...),
+	}
+}
+
+// newKeychainClient creates an API client for serverURL that stores
+// credentials in the keychain, ignoring DEVTOOLS_SYNC_API_KEY
+func newKeychainClient(serverURL string) *api.AuthenticatedClient {
+	return api.NewAuthenticatedClient(serverURL, keychainFactory(), clientOptions()...)
+}
+
 var (
 	loginEmail    string
 	loginPassword string
@@ -41,9 +66,10 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Prompt for email if not provided
+	// Prompt for email if not provided. Prompts go to stderr so that they are
+	// still shown with --quiet.
 	if loginEmail == "" {
-		_, _ = fmt.Fprint(cmd.OutOrStdout(), "Email: ")
+		_, _ = fmt.Fprint(cmd.ErrOrStderr(), "Email: ")
 		_, err := fmt.Fscanln(cmd.InOrStdin(), &loginEmail)
 		if err != nil {
 			return fmt.Errorf("failed to read email: %w", err)
@@ -52,18 +78,17 @@ func runLogin(cmd *cobra.Command, args []string) error {
 
 	// Prompt for password if not provided
 	if loginPassword == "" {
-		_, _ = fmt.Fprint(cmd.OutOrStdout(), "Password: ")
+		_, _ = fmt.Fprint(cmd.ErrOrStderr(), "Password: ")
 		passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
-		_, _ = fmt.Fprintln(cmd.OutOrStdout()) // newline after password
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr()) // newline after password
 		if err != nil {
 			return fmt.Errorf("failed to read password: %w", err)
 		}
 		loginPassword = string(passwordBytes)
 	}
 
-	// Create authenticated client
-	kc := keychainFactory()
-	client := api.NewAuthenticatedClient(cfg.Server.URL, kc)
+	// Login always stores credentials in the keychain
+	client := newKeychainClient(cfg.Server.URL)
 
 	// Login
 	if err := client.Login(loginEmail, loginPassword); err != nil {
@@ -20,7 +20,9 @@ func TestLoginCommand(t *testing.T) {
 	t.Setenv("HOME", tempHome)
 
 	// Mock server
+	var gotUserAgent string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.UserAgent()
 		if r.URL.Path != "/auth/login" {
 			t.Errorf("expected /auth/login, got %s", r.URL.Path)
 		}
@@ -92,6 +94,11 @@ func TestLoginCommand(t *testing.T) {
 	if token != "test-token" {
 		t.Errorf("expected 'test-token', got '%s'", token)
 	}
+
+	// Verify the request identified the agent version
+	if !strings.Contains(gotUserAgent, "devtools-sync-agent/"+version) {
+		t.Errorf("expected User-Agent to contain agent version %s, got %q", version, gotUserAgent)
+	}
 }
 
 func TestLoginCommand_InvalidCredentials(t *testing.T) {
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 
-	"github.com/mark-chris/devtools-sync/agent/internal/api"
 	"github.com/mark-chris/devtools-sync/agent/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -26,9 +25,8 @@ func runLogout(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Create authenticated client
-	kc := keychainFactory()
-	client := api.NewAuthenticatedClient(cfg.Server.URL, kc)
+	// Logout always clears credentials from the keychain
+	client := newKeychainClient(cfg.Server.URL)
 
 	// Logout
 	if err := client.Logout(); err != nil {
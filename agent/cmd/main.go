@@ -1,13 +1,43 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/mark-chris/devtools-sync/agent/internal/profile"
 	"github.com/spf13/cobra"
 )
 
-const version = "0.1.0"
+// Build information, set at link time with
+// -ldflags "-X main.version=1.2.3 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version = "0.1.0"
+	commit  = "unknown"
+)
+
+var (
+	quiet         bool
+	retryStatuses []int
+	configFile    string
+)
+
+// dataOut keeps the real command output while --quiet discards it (nil otherwise)
+var dataOut io.Writer
+
+// dataOutput returns where cmd writes data the user asked for, such as an
+// export or a --json report. Unlike cmd.OutOrStdout, it is not silenced by
+// --quiet, which only suppresses progress and informational messages.
+func dataOutput(cmd *cobra.Command) io.Writer {
+	if dataOut != nil {
+		return dataOut
+	}
+	return cmd.OutOrStdout()
+}
 
 var rootCmd = &cobra.Command{
 	Use:   "devtools-sync",
@@ -16,9 +46,39 @@ var rootCmd = &cobra.Command{
 and configurations across multiple machines.`,
 }
 
+func init() {
+	configureRoot(rootCmd)
+}
+
+// configureRoot adds the global flags shared by every command to root
+func configureRoot(root *cobra.Command) {
+	root.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational output (requested data, such as exports and --json reports, is still printed)")
+	root.PersistentFlags().IntSliceVar(&retryStatuses, "retry-status", nil, "Also retry server requests answered with these HTTP statuses, e.g. 500 (429 or 5xx only; on top of 429, 502, 503 and 504)")
+	root.PersistentFlags().StringVar(&configFile, "config", "", "Read the configuration from this file instead of ~/.devtools-sync/config.yaml")
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		config.SetPath(configFile)
+		for _, code := range retryStatuses {
+			if err := api.ValidateRetryStatus(code); err != nil {
+				return &usageError{err: fmt.Errorf("invalid --retry-status: %w", err)}
+			}
+		}
+		if quiet {
+			dataOut = root.OutOrStdout()
+			root.SetOut(io.Discard)
+			profile.SetOutput(io.Discard)
+			log.SetOutput(io.Discard)
+		}
+		return nil
+	}
+}
+
 func main() {
+	markUsageErrors(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		var silent *silentExitError
+		if !errors.As(err, &silent) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
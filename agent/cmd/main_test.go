@@ -1,8 +1,24 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func TestVersionConstant(t *testing.T) {
@@ -32,3 +48,257 @@ func TestMainWithEnvironment(t *testing.T) {
 		t.Errorf("expected environment variable to be set to http://test:9090, got %s", url)
 	}
 }
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"generic error", errors.New("boom"), exitCodeError},
+		{"usage error", &usageError{err: errors.New("unknown flag: --bogus")}, exitCodeUsage},
+		{"unknown command", errors.New(`unknown command "bogus" for "devtools-sync"`), exitCodeUsage},
+		{"not authenticated", fmt.Errorf("failed to push: %w", api.ErrNotAuthenticated), exitCodeNotAuthenticated},
+		{"session expired", fmt.Errorf("failed to list server profiles: %w", api.ErrSessionExpired), exitCodeNotAuthenticated},
+		{"server unreachable", fmt.Errorf("failed to push: %w", fmt.Errorf("%w: dial tcp: connection refused", api.ErrServerUnreachable)), exitCodeServerUnreachable},
+		{"vscode not found", fmt.Errorf("failed to save profile: %w", vscode.ErrVSCodeNotFound), exitCodeVSCodeNotFound},
+		{"install failed", fmt.Errorf("failed to load profile: %w", vscode.ErrExtensionInstallFailed), exitCodeExtensionInstall},
+		{"silent exit", &silentExitError{code: 7}, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileSaveCommand_VSCodeNotFoundExitCode(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"profile", "save", "work"})
+
+	err := cmd.Execute()
+	if !errors.Is(err, vscode.ErrVSCodeNotFound) {
+		t.Fatalf("expected ErrVSCodeNotFound, got: %v", err)
+	}
+	if code := exitCode(err); code != exitCodeVSCodeNotFound {
+		t.Errorf("exitCode() = %d, want %d", code, exitCodeVSCodeNotFound)
+	}
+}
+
+// newTestRoot returns a root command configured like rootCmd, with cmds added
+func newTestRoot(t *testing.T, cmds ...*cobra.Command) *cobra.Command {
+	t.Helper()
+	root := &cobra.Command{Use: "devtools-sync"}
+	configureRoot(root)
+	root.AddCommand(cmds...)
+	markUsageErrors(root)
+	t.Cleanup(func() {
+		quiet = false
+		dataOut = nil
+		retryStatuses = nil
+		configFile = ""
+		config.SetPath("")
+		profile.SetOutput(os.Stdout)
+		log.SetOutput(os.Stderr)
+	})
+	return root
+}
+
+func TestUsageErrorsExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown flag", []string{"profile", "list", "--bogus"}},
+		{"missing argument", []string{"profile", "save"}},
+		{"too many arguments", []string{"profile", "save", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+
+			root := newTestRoot(t, profileCmd)
+			root.SetOut(&bytes.Buffer{})
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(tt.args)
+
+			err := root.Execute()
+			if err == nil {
+				t.Fatal("expected usage error")
+			}
+			if code := exitCode(err); code != exitCodeUsage {
+				t.Errorf("exitCode(%v) = %d, want %d", err, code, exitCodeUsage)
+			}
+		})
+	}
+}
+
+func TestQuietSuppressesOutput(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	createTestProfile(t, profilesDir, "work", 2)
+
+	for _, args := range [][]string{
+		{"profile", "list", "--quiet"},
+		{"-q", "profile", "list"},
+	} {
+		root := newTestRoot(t, profileCmd)
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetErr(out)
+		root.SetArgs(args)
+
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("%v: expected no output, got: %q", args, out.String())
+		}
+		quiet = false
+	}
+
+	root := newTestRoot(t, profileCmd)
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetArgs([]string{"profile", "list"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("work")) {
+		t.Errorf("expected profile list output without --quiet, got: %q", out.String())
+	}
+}
+
+func TestQuietKeepsRequestedData(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	createTestProfile(t, profilesDir, "work", 2)
+	t.Cleanup(func() {
+		versionJSON = false
+		versionCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	for _, args := range [][]string{
+		{"-q", "profile", "export", "work"},
+		{"-q", "version", "--json"},
+	} {
+		root := newTestRoot(t, profileCmd, versionCmd)
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		root.SetOut(stdout)
+		root.SetErr(stderr)
+		root.SetArgs(args)
+
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		if !json.Valid(stdout.Bytes()) || stdout.Len() == 0 {
+			t.Errorf("%v: expected JSON on stdout, got: %q", args, stdout.String())
+		}
+		quiet = false
+		dataOut = nil
+	}
+}
+
+func TestRetryStatusRejectsNonRetryableCodes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := newTestRoot(t, profileCmd)
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"--retry-status", "500,400", "profile", "list"})
+
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid --retry-status") {
+		t.Fatalf("expected --retry-status validation error, got: %v", err)
+	}
+	if code := exitCode(err); code != exitCodeUsage {
+		t.Errorf("exitCode(%v) = %d, want %d", err, code, exitCodeUsage)
+	}
+}
+
+func TestRetryStatusAppliesToClients(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["work"]`))
+	}))
+	defer server.Close()
+	t.Setenv(api.APIKeyEnvVar, "test-key")
+
+	retryStatuses = []int{http.StatusInternalServerError}
+	t.Cleanup(func() { retryStatuses = nil })
+
+	names, err := newAuthenticatedClient(server.URL).ListProfiles()
+	if err != nil {
+		t.Fatalf("expected 500 to be retried, got: %v", err)
+	}
+	if attempts != 2 || len(names) != 1 {
+		t.Errorf("attempts = %d, names = %v; want a retry and the server's profiles", attempts, names)
+	}
+}
+
+func TestConfigFlag_ReadsCustomFile(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	// The default config points elsewhere and must be ignored
+	setupTestConfig(t, tempHome, "http://default.example.com", filepath.Join(tempHome, ".devtools-sync", "profiles"))
+
+	customPath := filepath.Join(t.TempDir(), "team.yaml")
+	if err := os.WriteFile(customPath, []byte("server:\n  url: http://custom.example.com:9000\n"), 0644); err != nil {
+		t.Fatalf("failed to write custom config: %v", err)
+	}
+
+	root := newTestRoot(t, configCmd)
+	out := &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(out)
+	root.SetArgs([]string{"--config", customPath, "config", "show"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("config show with --config failed: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "http://custom.example.com:9000") || strings.Contains(got, "default.example.com") {
+		t.Errorf("expected the custom config to be shown, got: %s", got)
+	}
+}
+
+func TestConfigFlag_MissingFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	missing := filepath.Join(t.TempDir(), "nope.yaml")
+
+	root := newTestRoot(t, configCmd)
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"--config", missing, "config", "show"})
+
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "config file "+missing+" does not exist") {
+		t.Errorf("expected a missing config file error, got %v", err)
+	}
+}
@@ -1,36 +1,100 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
 	"github.com/mark-chris/devtools-sync/agent/internal/config"
 	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var profileCmd = &cobra.Command{
 	Use:   "profile",
 	Short: "Manage extension profiles",
-	Long:  "Save, load, and list VS Code extension profiles",
+	Long:  "Save, load, and list VS Code extension profiles. Commands that change profiles, extensions or the server accept --dry-run to print what they would do instead.",
+}
+
+// profileDryRun makes mutating profile commands print their plan without
+// touching disk, VS Code or the server
+var profileDryRun bool
+
+// profileUploader is the part of the API client used by profile save --upload
+type profileUploader interface {
+	UploadProfileWithOptions(profile *api.Profile, opts api.UploadOptions) (*api.UploadResult, error)
+}
+
+// profileUploaderFactory creates the client used for uploads (can be overridden in tests)
+var profileUploaderFactory = func(serverURL string) profileUploader {
+	return newAuthenticatedClient(serverURL)
+}
+
+var (
+	profileSaveExtensionDirs []string
+	profileSaveNoAutoDirs    bool
+	profileSaveUpload        bool
+	profileSaveVariant       string
+	profileSaveDescription   string
+)
+
+// hostname returns the machine's host name (can be overridden in tests)
+var hostname = os.Hostname
+
+// hostVariant returns the profile variant matching this machine: the
+// lowercased host name up to the first dot, or "" if it cannot be read
+func hostVariant() string {
+	host, err := hostname()
+	if err != nil {
+		return ""
+	}
+	host, _, _ = strings.Cut(host, ".")
+	return strings.ToLower(host)
 }
 
 var profileSaveCmd = &cobra.Command{
 	Use:   "save <name>",
 	Short: "Save current extensions to a profile",
-	Long:  "Capture the current VS Code extensions and save them to a named profile",
+	Long:  "Capture the current VS Code extensions and save them to a named profile. Use --extensions-dir to also scan the extension directories of other VS Code installs, --variant to save an environment-specific variant such as work@laptop, --description to say what the profile is for, and --upload to push the saved profile to the server. Re-saving a profile keeps its description unless --description is given.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
+		if cmd.Flags().Changed("variant") {
+			if _, v := profile.ParseName(name); v != "" {
+				return fmt.Errorf("profile '%s' already names a variant; drop --variant or the '%s' suffix", name, profile.VariantSeparator)
+			}
+			if err := profile.ValidateVariant(profileSaveVariant); err != nil {
+				return err
+			}
+			name = profile.VariantName(name, profileSaveVariant)
+		}
+
 		// Load config to get profiles directory
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		if profileSaveNoAutoDirs && len(profileSaveExtensionDirs) == 0 {
+			return fmt.Errorf("--no-auto-dirs requires at least one --extensions-dir")
+		}
+
 		// Save profile
-		prof, err := profile.Save(name, cfg.Profiles.Directory)
+		prof, err := profile.SaveWithOptions(name, cfg.Profiles.Directory, profile.SaveOptions{
+			ExtensionDirs: profileSaveExtensionDirs,
+			NoAutoDirs:    profileSaveNoAutoDirs,
+			DryRun:        profileDryRun,
+			Description:   strings.TrimSpace(profileSaveDescription),
+		})
 		if err != nil {
 			if strings.Contains(err.Error(), "VS Code") {
 				return fmt.Errorf("failed to save profile: %w\n\nMake sure:\n  1. VS Code is installed\n  2. The 'code' command is available in your PATH\n  3. You can run 'code --version' successfully", err)
@@ -38,57 +102,376 @@ var profileSaveCmd = &cobra.Command{
 			return fmt.Errorf("failed to save profile '%s': %w", name, err)
 		}
 
-		cmd.Printf("Saved %d extensions to profile '%s'\n", len(prof.Extensions), name)
+		if profileDryRun {
+			cmd.Printf("Would save %d extensions to profile '%s'\n", len(prof.Extensions), prof.Name)
+			if profileSaveUpload {
+				cmd.Printf("Would upload profile '%s' to server\n", prof.Name)
+			}
+			return nil
+		}
+
+		cmd.Printf("Saved %d extensions to profile '%s'\n", len(prof.Extensions), prof.Name)
+
+		if profileSaveUpload {
+			// The local save is kept even if the upload fails
+			client := profileUploaderFactory(cfg.Server.URL)
+			if _, err := client.UploadProfileWithOptions(convertToAPIProfile(prof), api.UploadOptions{CompressThreshold: api.DefaultCompressThreshold}); err != nil {
+				return fmt.Errorf("profile '%s' was saved locally but upload failed: %w\n\nRetry with 'devtools-sync sync push'", prof.Name, err)
+			}
+			cmd.Printf("Uploaded profile '%s' to server\n", prof.Name)
+		}
+
 		return nil
 	},
 }
 
+var (
+	profileLoadForceReinstall bool
+	profileLoadParallel       int
+	profileLoadValidateOnly   bool
+	profileLoadMarketplace    bool
+	profileLoadVariant        string
+	profileLoadNoVariant      bool
+	profileLoadSettings       bool
+	profileLoadKeybindings    bool
+	profileLoadYes            bool
+	profileLoadFromURL        string
+	profileLoadAllowHTTP      bool
+	profileLoadSave           string
+)
+
+// isInteractive reports whether stdin is a terminal (can be overridden in tests)
+var isInteractive = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// confirm asks a yes/no question on the command's input and reports whether
+// it was answered yes (can be overridden in tests). The question goes to
+// stderr so that it is still shown with --quiet.
+var confirm = func(cmd *cobra.Command, question string) (bool, error) {
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s [y/N]: ", question)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
 var profileLoadCmd = &cobra.Command{
-	Use:               "load <name>",
-	Short:             "Load extensions from a profile",
-	Long:              "Install VS Code extensions from a saved profile",
-	Args:              cobra.ExactArgs(1),
+	Use:   "load <name> | --from-url <url>",
+	Short: "Load extensions from a profile",
+	Long: `Install VS Code extensions from a saved profile. Already installed extensions are skipped unless --force-reinstall is given.
+An extension whose install fails with a marketplace or network error is retried up to twice,
+with backoff; an extension that does not exist fails immediately.
+
+With --validate-only, nothing is installed: each extension ID is checked for 'publisher.name'
+format and, with --marketplace, looked up in the VS Code Marketplace. Unresolved IDs are
+reported and the command exits non-zero. If the marketplace cannot be reached, validation
+falls back to format checks only.
+
+When a variant of the profile exists for this machine (e.g. 'work@laptop' on host 'laptop'),
+'load work' loads the variant instead. Use --variant to pick another variant, or --no-variant
+to load the plain profile.
+
+If the profile carries VS Code settings or keybindings, they replace the local settings.json
+and keybindings.json, which are first backed up next to the originals. Use --settings=false or
+--keybindings=false to keep the local files.
+
+In an interactive terminal, the extensions to install, upgrade and reinstall and the
+user files to overwrite are shown first and the load asks for confirmation. Use --yes
+to skip the prompt; non-interactive runs proceed without asking.
+
+With --from-url, the profile JSON is downloaded from an https URL (such as a gist or a
+profile shared by a teammate) instead of read from the profiles directory, so no sync setup
+is needed. Use --save <name> to also keep it as a local profile. Plain http URLs, and
+redirects to them, are refused unless --allow-http is given.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if profileLoadFromURL != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	ValidArgsFunction: profileNameCompletion,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := args[0]
-
 		// Load config to get profiles directory
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		// Load profile
-		prof, err := profile.Load(name, cfg.Profiles.Directory)
+		if profileLoadParallel < 0 {
+			return fmt.Errorf("--parallel must be zero or positive, got %d", profileLoadParallel)
+		}
+
+		if profileLoadFromURL != "" {
+			return runLoadFromURL(cmd, cfg)
+		}
+		if cmd.Flags().Changed("save") {
+			return fmt.Errorf("--save can only be used with --from-url")
+		}
+		name := args[0]
+
+		if profileLoadMarketplace && !profileLoadValidateOnly {
+			return fmt.Errorf("--marketplace can only be used with --validate-only")
+		}
+
+		if profileLoadNoVariant && profileLoadVariant != "" {
+			return fmt.Errorf("--variant and --no-variant cannot be used together")
+		}
+		if !profileLoadNoVariant {
+			variant := profileLoadVariant
+			if variant == "" {
+				variant = hostVariant()
+			}
+			if resolved := profile.ResolveVariant(name, variant, cfg.Profiles.Directory); resolved != name {
+				cmd.Printf("Using variant profile '%s'\n", resolved)
+				name = resolved
+			}
+		}
+
+		if profileLoadValidateOnly {
+			return runValidateOnly(cmd, cfg, name)
+		}
+
+		// Work out the changes before making any
+		opts := profileLoadOptions(cfg)
+		plan, err := profile.PlanLoad(name, cfg.Profiles.Directory, opts)
 		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				// List available profiles for better UX
-				profiles, _ := profile.List(cfg.Profiles.Directory)
-				if len(profiles) > 0 {
-					names := make([]string, len(profiles))
-					for i, p := range profiles {
-						names[i] = p.Name
-					}
-					return fmt.Errorf("profile '%s' not found\n\nAvailable profiles: %s\n\nUse 'devtools-sync profile list' to see all profiles", name, strings.Join(names, ", "))
-				}
-				return fmt.Errorf("profile '%s' not found\n\nNo profiles available. Create one with:\n  devtools-sync profile save <name>", name)
+			return loadProfileError(cfg, name, err)
+		}
+
+		return applyLoadPlan(cmd, cfg, name, plan, opts, nil)
+	},
+}
+
+// profileLoadOptions returns the load options set by the profile load flags
+func profileLoadOptions(cfg *config.Config) profile.LoadOptions {
+	return profile.LoadOptions{
+		ForceReinstall:    profileLoadForceReinstall,
+		Parallel:          profileLoadParallel,
+		BlockedExtensions: cfg.VSCode.BlockedExtensions,
+		DryRun:            profileDryRun,
+		SkipSettings:      !profileLoadSettings,
+		SkipKeybindings:   !profileLoadKeybindings,
+		Versions:          vscode.NewMarketplaceClient(marketplaceURL),
+	}
+}
+
+// applyLoadPlan asks to confirm plan when needed and applies it. beforeApply,
+// if set, runs once the load is confirmed and before anything is installed.
+func applyLoadPlan(cmd *cobra.Command, cfg *config.Config, name string, plan *profile.LoadPlan, opts profile.LoadOptions, beforeApply func() error) error {
+	if !profileDryRun && !profileLoadYes && !plan.Empty() && isInteractive() {
+		printLoadPlan(cmd, plan)
+		ok, err := confirm(cmd, "Apply these changes?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			cmd.Printf("Load cancelled. No changes were made.\n")
+			return nil
+		}
+	}
+
+	if beforeApply != nil {
+		if err := beforeApply(); err != nil {
+			return err
+		}
+	}
+
+	// Load profile
+	result, err := profile.ApplyPlan(plan, opts)
+	if err != nil {
+		return loadProfileError(cfg, name, err)
+	}
+
+	if err := result.Err(); err != nil {
+		return fmt.Errorf("failed to load profile '%s': %d of %d extension(s) failed to install: %w", name, len(result.Failed), len(result.Profile.Extensions), err)
+	}
+
+	if profileDryRun {
+		cmd.Printf("Dry run: no extensions were installed.\n")
+		return nil
+	}
+
+	cmd.Printf("Installing %d extensions from profile '%s'...\n", len(result.Profile.Extensions), name)
+	cmd.Printf("Done!\n")
+	return nil
+}
+
+// runLoadFromURL loads the profile downloaded from --from-url, saving it
+// locally first with --save
+func runLoadFromURL(cmd *cobra.Command, cfg *config.Config) error {
+	if profileLoadValidateOnly {
+		return fmt.Errorf("--validate-only cannot be used with --from-url")
+	}
+	if profileLoadVariant != "" || profileLoadNoVariant {
+		return fmt.Errorf("--variant and --no-variant cannot be used with --from-url")
+	}
+	if err := validateProfileURL(profileLoadFromURL, profileLoadAllowHTTP); err != nil {
+		return err
+	}
+
+	saveName := ""
+	if cmd.Flags().Changed("save") {
+		saveName = profile.NormalizeName(profileLoadSave)
+		if err := profile.ValidateName(saveName); err != nil {
+			return fmt.Errorf("invalid --save name: %w", err)
+		}
+	}
+
+	data, err := api.NewClient("", clientOptions()...).FetchURL(profileLoadFromURL, profileLoadAllowHTTP)
+	if err != nil {
+		return fmt.Errorf("failed to download profile: %w", err)
+	}
+
+	var prof profile.Profile
+	if err := json.Unmarshal(data, &prof); err != nil {
+		return fmt.Errorf("failed to parse profile from %s: %w", profileLoadFromURL, err)
+	}
+	if saveName != "" {
+		prof.Name = saveName
+	}
+
+	opts := profileLoadOptions(cfg)
+	plan, err := profile.PlanProfile(&prof, opts)
+	if err != nil {
+		return fmt.Errorf("failed to load profile from %s: %w", profileLoadFromURL, err)
+	}
+
+	var save func() error
+	switch {
+	case saveName != "" && profileDryRun:
+		cmd.Printf("Would save profile '%s' to %s\n", saveName, cfg.Profiles.Directory)
+	case saveName != "":
+		save = func() error {
+			if err := saveProfile(&prof, cfg.Profiles.Directory); err != nil {
+				return fmt.Errorf("failed to save profile '%s': %w", saveName, err)
 			}
-			if strings.Contains(err.Error(), "VS Code") {
-				return fmt.Errorf("failed to load profile: %w\n\nMake sure:\n  1. VS Code is installed\n  2. The 'code' command is available in your PATH", err)
+			cmd.Printf("Saved profile '%s'\n", saveName)
+			return nil
+		}
+	}
+
+	return applyLoadPlan(cmd, cfg, prof.Name, plan, opts, save)
+}
+
+// validateProfileURL checks that rawURL is an absolute https URL, or http
+// when allowHTTP is set
+func validateProfileURL(rawURL string, allowHTTP bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid --from-url '%s': expected an absolute URL such as https://example.com/profile.json", rawURL)
+	}
+	switch {
+	case u.Scheme == "https":
+		return nil
+	case u.Scheme == "http" && allowHTTP:
+		return nil
+	case u.Scheme == "http":
+		return fmt.Errorf("refusing to download a profile over plain http from %s; use https or pass --allow-http", u.Host)
+	default:
+		return fmt.Errorf("unsupported --from-url scheme '%s': use https", u.Scheme)
+	}
+}
+
+// loadProfileError adds troubleshooting hints to an error loading a profile
+func loadProfileError(cfg *config.Config, name string, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		// List available profiles for better UX
+		profiles, _ := profile.List(cfg.Profiles.Directory)
+		if len(profiles) > 0 {
+			names := make([]string, len(profiles))
+			for i, p := range profiles {
+				names[i] = p.Name
 			}
-			return fmt.Errorf("failed to load profile '%s': %w", name, err)
+			return fmt.Errorf("profile '%s' not found\n\nAvailable profiles: %s\n\nUse 'devtools-sync profile list' to see all profiles", name, strings.Join(names, ", "))
 		}
+		return fmt.Errorf("profile '%s' not found\n\nNo profiles available. Create one with:\n  devtools-sync profile save <name>", name)
+	}
+	if strings.Contains(err.Error(), "VS Code") {
+		return fmt.Errorf("failed to load profile: %w\n\nMake sure:\n  1. VS Code is installed\n  2. The 'code' command is available in your PATH", err)
+	}
+	return fmt.Errorf("failed to load profile '%s': %w", name, err)
+}
 
-		cmd.Printf("Installing %d extensions from profile '%s'...\n", len(prof.Extensions), name)
-		cmd.Printf("Done!\n")
+// printLoadPlan lists the changes loading a profile will make. It is printed
+// to stderr with the confirmation prompt, so --quiet does not hide it.
+func printLoadPlan(cmd *cobra.Command, plan *profile.LoadPlan) {
+	w := cmd.ErrOrStderr()
+	_, _ = fmt.Fprintf(w, "Loading profile '%s' will:\n", plan.Profile.Name)
+	printPlanExtensions(w, "Install", "+", plan.Install)
+	printPlanExtensions(w, "Upgrade", "^", plan.Upgrade)
+	printPlanExtensions(w, "Reinstall", "*", plan.Reinstall)
+	for _, name := range plan.UserFiles {
+		_, _ = fmt.Fprintf(w, "  Overwrite %s (a backup is kept)\n", name)
+	}
+	if len(plan.Skipped) > 0 {
+		_, _ = fmt.Fprintf(w, "  Skip %d already installed extension(s)\n", len(plan.Skipped))
+	}
+	if len(plan.Blocked) > 0 {
+		_, _ = fmt.Fprintf(w, "  Skip %d blocked extension(s)\n", len(plan.Blocked))
+	}
+}
+
+// printPlanExtensions lists exts under an action heading
+func printPlanExtensions(w io.Writer, action, marker string, exts []profile.Extension) {
+	if len(exts) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "  %s %d extension(s):\n", action, len(exts))
+	for _, ext := range exts {
+		_, _ = fmt.Fprintf(w, "    %s %s (%s)\n", marker, ext.ID, ext.Version)
+	}
+}
+
+// marketplaceURL is the gallery queried by --marketplace and for the versions
+// allowed by extension version constraints; overridden in tests
+var marketplaceURL = vscode.DefaultMarketplaceURL
+
+// runValidateOnly checks a profile's extension IDs without installing anything
+func runValidateOnly(cmd *cobra.Command, cfg *config.Config, name string) error {
+	var resolver profile.ExtensionResolver
+	if profileLoadMarketplace {
+		resolver = vscode.NewMarketplaceClient(marketplaceURL)
+	}
+
+	result, err := profile.ValidateExtensions(name, cfg.Profiles.Directory, resolver)
+	if err != nil {
+		return fmt.Errorf("failed to validate profile '%s': %w", name, err)
+	}
+
+	if result.MarketplaceErr != nil {
+		cmd.PrintErrf("Warning: %v; falling back to format-only validation\n", result.MarketplaceErr)
+	}
+
+	mode := "format only"
+	if result.MarketplaceChecked {
+		mode = "format and marketplace"
+	}
+	cmd.Printf("Validated %d extension(s) in profile '%s' (%s)\n", len(result.Profile.Extensions), result.Profile.Name, mode)
+
+	if len(result.Unresolved) == 0 {
+		cmd.Printf("All extension IDs resolved. No changes were made.\n")
 		return nil
-	},
+	}
+
+	cmd.Printf("\nUnresolved (%d):\n", len(result.Unresolved))
+	for _, u := range result.Unresolved {
+		cmd.Printf("  - %s: %s\n", u.Extension.ID, u.Reason)
+	}
+	return fmt.Errorf("profile '%s' has %d unresolved extension ID(s)", name, len(result.Unresolved))
 }
 
+var profileListGroup bool
+
 var profileListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all profiles",
-	Long:  "Display all saved extension profiles with their metadata",
+	Long:  "Display all saved extension profiles with their metadata. With --group, variants such as work@laptop are listed under their base name.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load config to get profiles directory
 		cfg, err := config.Load()
@@ -97,39 +480,121 @@ var profileListCmd = &cobra.Command{
 		}
 
 		// List profiles
-		profiles, err := profile.List(cfg.Profiles.Directory)
+		profiles, skipped, err := profile.ListWithSkipped(cfg.Profiles.Directory)
 		if err != nil {
 			return err
 		}
 
 		if len(profiles) == 0 {
 			cmd.Printf("No profiles found.\n")
+			printSkippedProfiles(cmd, skipped)
 			return nil
 		}
 
 		// Display profiles in a table format
-		cmd.Printf("%-20s %-15s %-25s\n", "NAME", "EXTENSIONS", "LAST UPDATED")
-		cmd.Printf("%s\n", strings.Repeat("-", 60))
+		cmd.Printf("%-20s %-15s %-25s %s\n", "NAME", "EXTENSIONS", "LAST UPDATED", "DESCRIPTION")
+		cmd.Printf("%s\n", strings.Repeat("-", 80))
 
-		for _, prof := range profiles {
-			cmd.Printf("%-20s %-15d %-25s\n",
-				prof.Name,
-				len(prof.Extensions),
-				prof.UpdatedAt.Format("2006-01-02 15:04:05"),
-			)
+		if profileListGroup {
+			for _, group := range profile.GroupByBase(profiles) {
+				cmd.Printf("%s\n", group.Base)
+				for _, prof := range group.Profiles {
+					printProfileRow(cmd, "  "+prof.Name, prof)
+				}
+			}
+		} else {
+			for _, prof := range profiles {
+				printProfileRow(cmd, prof.Name, prof)
+			}
 		}
 
+		printSkippedProfiles(cmd, skipped)
 		return nil
 	},
 }
 
+// printProfileRow prints one profile list row under the given label.
+// Profiles without a description leave the last column blank.
+func printProfileRow(cmd *cobra.Command, label string, prof profile.Profile) {
+	row := fmt.Sprintf("%-20s %-15d %-25s %s",
+		label,
+		len(prof.Extensions),
+		prof.UpdatedAt.Format("2006-01-02 15:04:05"),
+		prof.Description,
+	)
+	cmd.Printf("%s\n", strings.TrimRight(row, " "))
+}
+
+// printSkippedProfiles reports profile files that could not be read so
+// corrupt profiles don't silently disappear from the list
+func printSkippedProfiles(cmd *cobra.Command, skipped []profile.SkippedFile) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	noun := "profiles"
+	if len(skipped) == 1 {
+		noun = "profile"
+	}
+	cmd.Printf("\n%d %s could not be read:\n", len(skipped), noun)
+	for _, s := range skipped {
+		cmd.Printf("  %s: %v\n", filepath.Base(s.Path), s.Err)
+	}
+}
+
+var (
+	profileDiffExitCode   bool
+	profileDiffVerbose    bool
+	profileDiffRemote     bool
+	profileDiffPreRelease bool
+	profileDiffJSON       bool
+	profileDiffFile       string
+)
+
 var profileDiffCmd = &cobra.Command{
-	Use:               "diff <name>",
-	Short:             "Compare a profile with currently installed extensions",
-	Long:              "Show which extensions would be installed and which are already installed if loading this profile",
-	Args:              cobra.ExactArgs(1),
+	Use:   "diff [name]",
+	Short: "Compare a profile with currently installed extensions",
+	Long: `Show which extensions would be installed and which are already installed if loading this profile.
+
+With --remote, compare the local profile with the server's copy instead, showing extensions
+only in the local copy, only on the server, or at different versions.
+
+With --against-file, compare a profile file outside the profiles directory (for example one a
+colleague sent you) with installed extensions instead of a named profile. The file is validated
+before anything is compared.
+
+With --exit-code, exit with status 1 when the profile is not in sync (extensions to install,
+version mismatches, or extensions enabled on one side only) and 0 otherwise, printing details only with --verbose. Useful for CI gating.
+
+Version mismatches where the profile has the newer version are also listed as outdated. By default
+only stable releases count as newer; with --pre-release, pre-release versions (e.g. 1.2.0-beta)
+do too.
+
+With --json, print the differences as JSON instead. Every diff uses the same shape: extensions
+"added", "removed", "version_changed" or "state_changed" going from the installed extensions (or
+the server's copy with --remote) to the local profile. --exit-code still sets the exit status.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if profileDiffFile != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	ValidArgsFunction: profileNameCompletion,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if profileDiffFile != "" {
+			if profileDiffRemote {
+				return fmt.Errorf("--against-file cannot be used with --remote")
+			}
+			result, err := profile.DiffFile(profileDiffFile, profile.DiffOptions{PreRelease: profileDiffPreRelease})
+			if err != nil {
+				if strings.Contains(err.Error(), "VS Code") {
+					return fmt.Errorf("failed to diff profile: %w\n\nMake sure:\n  1. VS Code is installed\n  2. The 'code' command is available in your PATH", err)
+				}
+				return fmt.Errorf("failed to diff profile file %s: %w", profileDiffFile, err)
+			}
+			return reportDiff(cmd, result)
+		}
+
 		name := args[0]
 
 		// Load config to get profiles directory
@@ -138,8 +603,12 @@ var profileDiffCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		if profileDiffRemote {
+			return runRemoteDiff(cmd, cfg, name)
+		}
+
 		// Compare profile with installed extensions
-		result, err := profile.Diff(name, cfg.Profiles.Directory)
+		result, err := profile.DiffWithOptions(name, cfg.Profiles.Directory, profile.DiffOptions{PreRelease: profileDiffPreRelease})
 		if err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				// List available profiles for better UX
@@ -159,37 +628,223 @@ var profileDiffCmd = &cobra.Command{
 			return fmt.Errorf("failed to diff profile '%s': %w", name, err)
 		}
 
-		// Display results in a formatted manner
-		cmd.Printf("Profile: %s\n", result.ProfileName)
-		cmd.Printf("Total extensions in profile: %d\n\n", result.TotalInProfile)
+		return reportDiff(cmd, result)
+	},
+}
 
-		if len(result.ToInstall) > 0 {
-			cmd.Printf("To Install (%d):\n", len(result.ToInstall))
-			for _, ext := range result.ToInstall {
-				cmd.Printf("  + %s (%s)\n", ext.ID, ext.Version)
-			}
-			cmd.Printf("\n")
+// reportDiff prints a profile-vs-installed diff according to the --json,
+// --exit-code and --verbose flags
+func reportDiff(cmd *cobra.Command, result *profile.DiffResult) error {
+	if profileDiffJSON {
+		if err := printDiffJSON(cmd, result); err != nil {
+			return err
+		}
+		if profileDiffExitCode && !result.InSync() {
+			return silentExit(cmd, exitCodeError)
 		}
+		return nil
+	}
 
-		if len(result.AlreadyInstalled) > 0 {
-			cmd.Printf("Already Installed (%d):\n", len(result.AlreadyInstalled))
-			for _, ext := range result.AlreadyInstalled {
-				cmd.Printf("  = %s (%s)\n", ext.ID, ext.Version)
-			}
-			cmd.Printf("\n")
+	if profileDiffExitCode {
+		if profileDiffVerbose {
+			printDiffResult(cmd, result)
+		}
+		if !result.InSync() {
+			return silentExit(cmd, exitCodeError)
 		}
+		return nil
+	}
+
+	printDiffResult(cmd, result)
+	return nil
+}
 
-		if len(result.ToInstall) == 0 && len(result.AlreadyInstalled) == result.TotalInProfile {
-			cmd.Printf("All extensions from this profile are already installed.\n")
-		} else if len(result.ToInstall) > 0 {
-			cmd.Printf("Run 'devtools-sync profile load %s' to install missing extensions.\n", name)
+// runRemoteDiff compares the local profile name with the server's copy
+func runRemoteDiff(cmd *cobra.Command, cfg *config.Config, name string) error {
+	local, err := profile.Get(name, cfg.Profiles.Directory)
+	if err != nil {
+		return fmt.Errorf("failed to diff profile '%s': %w", name, err)
+	}
+
+	client := newAuthenticatedClient(cfg.Server.URL)
+	remote, err := client.DownloadProfile(local.Name)
+	if err != nil {
+		return fmt.Errorf("failed to diff profile '%s' against server: %w", name, err)
+	}
+
+	diff := profile.CompareProfiles(local, convertToLocalProfile(remote))
+
+	if profileDiffJSON {
+		if err := printDiffJSON(cmd, diff); err != nil {
+			return err
 		}
+		if profileDiffExitCode && !diff.Identical() {
+			return silentExit(cmd, exitCodeError)
+		}
+		return nil
+	}
 
+	if profileDiffExitCode {
+		if profileDiffVerbose {
+			printProfileDiff(cmd, diff)
+		}
+		if !diff.Identical() {
+			return silentExit(cmd, exitCodeError)
+		}
 		return nil
-	},
+	}
+
+	printProfileDiff(cmd, diff)
+	return nil
+}
+
+// printProfileDiff displays a local-vs-server profile diff
+func printProfileDiff(cmd *cobra.Command, diff *profile.DiffResult) {
+	cmd.Printf("Profile: %s (local vs server)\n\n", diff.ProfileName)
+
+	if added := diff.Changes.Added; len(added) > 0 {
+		cmd.Printf("Only Local (%d):\n", len(added))
+		for _, c := range added {
+			cmd.Printf("  + %s (%s)\n", c.ID, c.To.Version)
+		}
+		cmd.Printf("\n")
+	}
+
+	if removed := diff.Changes.Removed; len(removed) > 0 {
+		cmd.Printf("Only on Server (%d):\n", len(removed))
+		for _, c := range removed {
+			cmd.Printf("  - %s (%s)\n", c.ID, c.From.Version)
+		}
+		cmd.Printf("\n")
+	}
+
+	if changed := diff.Changes.VersionChanged; len(changed) > 0 {
+		cmd.Printf("Version Changed (%d):\n", len(changed))
+		for _, c := range changed {
+			cmd.Printf("  ~ %s (local %s, server %s)\n", c.ID, c.To.Version, c.From.Version)
+		}
+		cmd.Printf("\n")
+	}
+
+	if changed := diff.Changes.StateChanged; len(changed) > 0 {
+		cmd.Printf("State Changed (%d):\n", len(changed))
+		for _, c := range changed {
+			cmd.Printf("  ! %s (local %s, server %s)\n", c.ID, enabledState(c.To.Enabled), enabledState(c.From.Enabled))
+		}
+		cmd.Printf("\n")
+	}
+
+	if diff.Identical() {
+		cmd.Printf("Local profile matches the server copy.\n")
+	} else {
+		cmd.Printf("Run 'devtools-sync sync push' to upload the local profile.\n")
+	}
+}
+
+// enabledState describes an extension's enabled flag for display
+func enabledState(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// printDiffJSON writes any profile diff as indented JSON
+func printDiffJSON(cmd *cobra.Command, result *profile.DiffResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode diff for profile '%s': %w", result.ProfileName, err)
+	}
+	data = append(data, '\n')
+	if _, err := dataOutput(cmd).Write(data); err != nil {
+		return fmt.Errorf("failed to write diff for profile '%s': %w", result.ProfileName, err)
+	}
+	return nil
+}
+
+// printDiffResult displays a profile diff in a formatted manner
+func printDiffResult(cmd *cobra.Command, result *profile.DiffResult) {
+	cmd.Printf("Profile: %s\n", result.ProfileName)
+	cmd.Printf("Total extensions in profile: %d\n\n", result.TotalInProfile)
+
+	if len(result.ToInstall) > 0 {
+		cmd.Printf("To Install (%d):\n", len(result.ToInstall))
+		for _, ext := range result.ToInstall {
+			cmd.Printf("  + %s (%s)\n", ext.ID, ext.Version)
+		}
+		cmd.Printf("\n")
+	}
+
+	if len(result.AlreadyInstalled) > 0 {
+		cmd.Printf("Already Installed (%d):\n", len(result.AlreadyInstalled))
+		for _, ext := range result.AlreadyInstalled {
+			cmd.Printf("  = %s (%s)\n", ext.ID, ext.Version)
+		}
+		cmd.Printf("\n")
+	}
+
+	if len(result.VersionMismatches) > 0 {
+		cmd.Printf("Version Mismatch (%d):\n", len(result.VersionMismatches))
+		for _, m := range result.VersionMismatches {
+			cmd.Printf("  ~ %s (profile %s, installed %s)\n", m.ID, m.ProfileVersion, m.InstalledVersion)
+		}
+		cmd.Printf("\n")
+	}
+
+	if n := len(result.Changes.StateChanged); n > 0 {
+		cmd.Printf("State Mismatch (%d):\n", n)
+		for _, c := range result.Changes.StateChanged {
+			cmd.Printf("  ! %s (profile %s, installed %s)\n", c.ID, enabledState(c.To.Enabled), enabledState(c.From.Enabled))
+		}
+		cmd.Printf("\n")
+	}
+
+	if len(result.Outdated) > 0 {
+		cmd.Printf("Outdated (%d):\n", len(result.Outdated))
+		for _, m := range result.Outdated {
+			cmd.Printf("  ^ %s (installed %s, profile has %s)\n", m.ID, m.InstalledVersion, m.ProfileVersion)
+		}
+		cmd.Printf("\n")
+	}
+
+	if len(result.ToInstall) == 0 && len(result.AlreadyInstalled) == result.TotalInProfile {
+		cmd.Printf("All extensions from this profile are already installed.\n")
+	} else if len(result.ToInstall) > 0 {
+		cmd.Printf("Run 'devtools-sync profile load %s' to install missing extensions.\n", result.ProfileName)
+	}
 }
 
 func init() {
+	profileCmd.PersistentFlags().BoolVar(&profileDryRun, "dry-run", false, "Print what would change without touching disk, VS Code or the server")
+
+	profileSaveCmd.Flags().StringArrayVar(&profileSaveExtensionDirs, "extensions-dir", nil, "Additional extensions directory to scan (repeatable)")
+	profileSaveCmd.Flags().StringVar(&profileSaveVariant, "variant", "", "Save as an environment-specific variant, stored as <name>@<variant>")
+	profileSaveCmd.Flags().StringVar(&profileSaveDescription, "description", "", "Describe what the profile is for (kept on later saves unless given again)")
+	profileSaveCmd.Flags().BoolVar(&profileSaveUpload, "upload", false, "Upload the profile to the server after saving")
+	profileSaveCmd.Flags().BoolVar(&profileSaveNoAutoDirs, "no-auto-dirs", false, "Scan only --extensions-dir directories, skipping auto-detected VS Code and Insiders directories")
+
+	profileLoadCmd.Flags().BoolVar(&profileLoadForceReinstall, "force-reinstall", false, "Reinstall every extension in the profile, even if already installed")
+	profileLoadCmd.Flags().StringVar(&profileLoadVariant, "variant", "", "Prefer this variant of the profile (default: this machine's host name)")
+	profileLoadCmd.Flags().BoolVar(&profileLoadNoVariant, "no-variant", false, "Load the named profile exactly, ignoring variants")
+	profileLoadCmd.Flags().BoolVar(&profileLoadValidateOnly, "validate-only", false, "Check extension IDs without installing anything")
+	profileLoadCmd.Flags().BoolVar(&profileLoadMarketplace, "marketplace", false, "With --validate-only, also look up each ID in the VS Code Marketplace")
+	profileLoadCmd.Flags().BoolVar(&profileLoadSettings, "settings", true, "Apply the profile's VS Code settings, if it has any")
+	profileLoadCmd.Flags().BoolVar(&profileLoadKeybindings, "keybindings", true, "Apply the profile's VS Code keybindings, if it has any")
+	profileLoadCmd.Flags().BoolVarP(&profileLoadYes, "yes", "y", false, "Apply the profile without asking for confirmation")
+	profileLoadCmd.Flags().StringVar(&profileLoadFromURL, "from-url", "", "Load the profile JSON at this https URL instead of a local profile")
+	profileLoadCmd.Flags().BoolVar(&profileLoadAllowHTTP, "allow-http", false, "With --from-url, allow a plain http URL and redirects to http")
+	profileLoadCmd.Flags().StringVar(&profileLoadSave, "save", "", "With --from-url, also save the downloaded profile under this name")
+	profileLoadCmd.Flags().IntVar(&profileLoadParallel, "parallel", profile.DefaultParallel, "Number of concurrent VS Code installs, each installing a batch of extensions (0 or 1 installs serially, one extension at a time)")
+
+	profileListCmd.Flags().BoolVar(&profileListGroup, "group", false, "Group variants under their base profile name")
+
+	profileDiffCmd.Flags().BoolVar(&profileDiffExitCode, "exit-code", false, "Exit with status 1 if the profile is not in sync, suppressing details")
+	profileDiffCmd.Flags().BoolVar(&profileDiffVerbose, "verbose", false, "Show details with --exit-code")
+	profileDiffCmd.Flags().BoolVar(&profileDiffPreRelease, "pre-release", false, "Treat pre-release versions as newer than stable ones when finding outdated extensions")
+	profileDiffCmd.Flags().BoolVar(&profileDiffJSON, "json", false, "Print the differences as JSON")
+	profileDiffCmd.Flags().BoolVar(&profileDiffRemote, "remote", false, "Compare the local profile with the server's copy instead of installed extensions")
+	profileDiffCmd.Flags().StringVar(&profileDiffFile, "against-file", "", "Compare the profile file at this path instead of a named profile")
+
 	profileCmd.AddCommand(profileSaveCmd)
 	profileCmd.AddCommand(profileLoadCmd)
 	profileCmd.AddCommand(profileListCmd)
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileExportOutput string
+	profileExportForce  bool
+
+	profileExportWithSettings    bool
+	profileExportWithKeybindings bool
+)
+
+var profileExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Export a profile as JSON",
+	Long: `Write a profile as JSON to stdout, so it can be piped to other tools:
+
+  devtools-sync profile export work | jq '.extensions[].id'
+
+With --output the JSON is written to a file instead. Existing files are not
+overwritten unless --force is given.
+
+The export includes whatever settings and keybindings the profile carries. To
+share just the extension list, leave them out with --with-settings=false and
+--with-keybindings=false.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: profileNameCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		// Arguments are valid past this point; keep usage text out of
+		// stdout when the export itself fails
+		cmd.SilenceUsage = true
+
+		// Load config to get profiles directory
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		prof, err := profile.Get(name, cfg.Profiles.Directory)
+		if err != nil {
+			return fmt.Errorf("failed to export profile '%s': %w", name, err)
+		}
+
+		if !profileExportWithSettings {
+			prof.Settings = nil
+		}
+		if !profileExportWithKeybindings {
+			prof.Keybindings = nil
+		}
+
+		data, err := json.MarshalIndent(prof, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode profile '%s': %w", name, err)
+		}
+		data = append(data, '\n')
+
+		if profileExportOutput == "" {
+			if _, err := dataOutput(cmd).Write(data); err != nil {
+				return fmt.Errorf("failed to write profile '%s': %w", name, err)
+			}
+			return nil
+		}
+
+		if err := writeExportFile(profileExportOutput, data, profileExportForce); err != nil {
+			return fmt.Errorf("failed to export profile '%s': %w", name, err)
+		}
+		cmd.Printf("Exported profile '%s' to %s\n", prof.Name, profileExportOutput)
+		return nil
+	},
+}
+
+// writeExportFile writes data to path, refusing to replace an existing file
+// unless force is set
+func writeExportFile(path string, data []byte, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		}
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func init() {
+	profileExportCmd.Flags().StringVarP(&profileExportOutput, "output", "o", "", "Write the profile to this file instead of stdout")
+	profileExportCmd.Flags().BoolVar(&profileExportForce, "force", false, "Overwrite the --output file if it exists")
+	profileExportCmd.Flags().BoolVar(&profileExportWithSettings, "with-settings", true, "Include the profile's VS Code settings")
+	profileExportCmd.Flags().BoolVar(&profileExportWithKeybindings, "with-keybindings", true, "Include the profile's VS Code keybindings")
+
+	profileCmd.AddCommand(profileExportCmd)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runProfileExport creates profile "work" and profile "shared", which carries
+// settings and keybindings, and runs profile export with args, returning
+// stdout and stderr separately
+func runProfileExport(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	createTestProfile(t, profilesDir, "work", 2)
+
+	shared, err := json.MarshalIndent(profile.Profile{
+		Name:        "shared",
+		Extensions:  []profile.Extension{{ID: "golang.go", Version: "1.0.0", Enabled: true}},
+		Settings:    json.RawMessage(`{"editor.fontSize": 14}`),
+		Keybindings: json.RawMessage(`[{"key": "ctrl+k", "command": "workbench.action.quickOpen"}]`),
+	}, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "shared.json"), shared, 0644); err != nil {
+		t.Fatalf("failed to write profile file: %v", err)
+	}
+
+	t.Cleanup(func() {
+		profileExportOutput = ""
+		profileExportForce = false
+		profileExportWithSettings = true
+		profileExportWithKeybindings = true
+		profileExportCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs(append([]string{"profile", "export"}, args...))
+
+	err = cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestProfileExportCommand_Stdout(t *testing.T) {
+	stdout, stderr, err := runProfileExport(t, "work")
+	if err != nil {
+		t.Fatalf("profile export failed: %v", err)
+	}
+	if stderr != "" {
+		t.Errorf("expected nothing on stderr, got: %q", stderr)
+	}
+
+	// stdout must be nothing but the profile JSON so it can be piped
+	var exported profile.Profile
+	if err := json.Unmarshal([]byte(stdout), &exported); err != nil {
+		t.Fatalf("stdout is not valid profile JSON: %v\n%s", err, stdout)
+	}
+	if exported.Name != "work" || len(exported.Extensions) != 2 {
+		t.Errorf("unexpected exported profile: %+v", exported)
+	}
+}
+
+func TestProfileExportCommand_OutputFile(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "work.json")
+
+	stdout, _, err := runProfileExport(t, "work", "--output", outPath)
+	if err != nil {
+		t.Fatalf("profile export --output failed: %v", err)
+	}
+	if !strings.Contains(stdout, "Exported profile 'work' to "+outPath) {
+		t.Errorf("expected export to be reported, got: %q", stdout)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("expected output file to be created: %v", err)
+	}
+	var exported profile.Profile
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("output file is not valid profile JSON: %v", err)
+	}
+	if exported.Name != "work" {
+		t.Errorf("exported name = %q, want work", exported.Name)
+	}
+}
+
+func TestProfileExportCommand_RefusesOverwriteWithoutForce(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "work.json")
+	if err := os.WriteFile(outPath, []byte("keep me"), 0644); err != nil {
+		t.Fatalf("failed to write existing file: %v", err)
+	}
+
+	_, _, err := runProfileExport(t, "work", "--output", outPath)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected overwrite to be refused with a --force hint, got: %v", err)
+	}
+	if data, _ := os.ReadFile(outPath); string(data) != "keep me" {
+		t.Errorf("existing file was modified: %q", data)
+	}
+
+	if _, _, err := runProfileExport(t, "work", "--output", outPath, "--force"); err != nil {
+		t.Fatalf("profile export --force failed: %v", err)
+	}
+	if data, _ := os.ReadFile(outPath); !strings.Contains(string(data), `"name": "work"`) {
+		t.Errorf("expected --force to overwrite the file, got: %q", data)
+	}
+}
+
+func TestProfileExportCommand_NotFound(t *testing.T) {
+	stdout, _, err := runProfileExport(t, "missing")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+	if stdout != "" {
+		t.Errorf("expected no stdout on failure, got: %q", stdout)
+	}
+}
+
+func TestProfileExportCommand_IncludesSettingsByDefault(t *testing.T) {
+	stdout, _, err := runProfileExport(t, "shared")
+	if err != nil {
+		t.Fatalf("profile export failed: %v", err)
+	}
+
+	var exported map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(stdout), &exported); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v\n%s", err, stdout)
+	}
+	for _, key := range []string{"settings", "keybindings"} {
+		if _, ok := exported[key]; !ok {
+			t.Errorf("expected %q in exported profile, got: %s", key, stdout)
+		}
+	}
+}
+
+func TestProfileExportCommand_OmitsSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		omitted []string
+		kept    []string
+	}{
+		{"without settings", []string{"--with-settings=false"}, []string{"settings"}, []string{"keybindings"}},
+		{"without keybindings", []string{"--with-keybindings=false"}, []string{"keybindings"}, []string{"settings"}},
+		{"without both", []string{"--with-settings=false", "--with-keybindings=false"}, []string{"settings", "keybindings"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, err := runProfileExport(t, append([]string{"shared"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("profile export failed: %v", err)
+			}
+
+			var exported map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(stdout), &exported); err != nil {
+				t.Fatalf("stdout is not valid JSON: %v\n%s", err, stdout)
+			}
+			for _, key := range tt.omitted {
+				if _, ok := exported[key]; ok {
+					t.Errorf("expected %q to be omitted, got: %s", key, stdout)
+				}
+			}
+			for _, key := range tt.kept {
+				if _, ok := exported[key]; !ok {
+					t.Errorf("expected %q to be kept, got: %s", key, stdout)
+				}
+			}
+			if _, ok := exported["extensions"]; !ok {
+				t.Errorf("expected extensions in exported profile, got: %s", stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+// remoteProfileRenamer is the part of the API client used to rename server profiles
+type remoteProfileRenamer interface {
+	ListProfiles() ([]string, error)
+	RenameProfile(oldName, newName string) error
+}
+
+// remoteRenamerFactory creates the client used for remote renames (can be overridden in tests)
+var remoteRenamerFactory = func(serverURL string) remoteProfileRenamer {
+	return newAuthenticatedClient(serverURL)
+}
+
+var (
+	profileRenameLocalOnly  bool
+	profileRenameRemoteOnly bool
+)
+
+var profileRenameCmd = &cobra.Command{
+	Use:               "rename <old-name> <new-name>",
+	Short:             "Rename a profile locally and on the server",
+	Long:              "Rename a profile both locally and on the server. Use --local-only or --remote-only to rename just one side. With --dry-run, both sides are checked (the server is only read) but nothing is renamed.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: profileNameCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldName := args[0]
+		newName := profile.NormalizeName(args[1])
+
+		if err := profile.ValidateName(newName); err != nil {
+			return fmt.Errorf("invalid profile name '%s': %w", args[1], err)
+		}
+		if newName == oldName {
+			return fmt.Errorf("profile is already named '%s'", oldName)
+		}
+
+		renameLocal := !profileRenameRemoteOnly
+		renameRemote := !profileRenameLocalOnly
+
+		// Load config to get profiles directory and server URL
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Check the server side before changing anything locally
+		var client remoteProfileRenamer
+		if renameRemote {
+			client = remoteRenamerFactory(cfg.Server.URL)
+			if err := checkRemoteRename(client, oldName, newName); err != nil {
+				return err
+			}
+		}
+
+		if renameLocal {
+			opts := profile.RenameOptions{DryRun: profileDryRun}
+			if _, err := profile.RenameWithOptions(oldName, newName, cfg.Profiles.Directory, opts); err != nil {
+				return fmt.Errorf("failed to rename local profile '%s': %w", oldName, err)
+			}
+			if profileDryRun {
+				cmd.Printf("Would rename local profile '%s' to '%s'\n", oldName, newName)
+			} else {
+				cmd.Printf("Renamed local profile '%s' to '%s'\n", oldName, newName)
+			}
+		}
+
+		if renameRemote && profileDryRun {
+			cmd.Printf("Would rename server profile '%s' to '%s'\n", oldName, newName)
+		} else if renameRemote {
+			if err := client.RenameProfile(oldName, newName); err != nil {
+				if renameLocal {
+					return fmt.Errorf("local profile was renamed but the server rename failed: %w\n\nRetry with 'devtools-sync profile rename --remote-only %s %s'", err, oldName, newName)
+				}
+				return fmt.Errorf("failed to rename server profile '%s': %w", oldName, err)
+			}
+			cmd.Printf("Renamed server profile '%s' to '%s'\n", oldName, newName)
+		}
+
+		return nil
+	},
+}
+
+// checkRemoteRename verifies that oldName exists on the server and that
+// newName does not collide with another server profile
+func checkRemoteRename(client remoteProfileRenamer, oldName, newName string) error {
+	names, err := client.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to list server profiles: %w", err)
+	}
+
+	found := false
+	for _, name := range names {
+		if name == oldName {
+			found = true
+			continue
+		}
+		if strings.EqualFold(name, newName) {
+			return fmt.Errorf("a profile named '%s' already exists on server", name)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("profile '%s' not found on server", oldName)
+	}
+
+	return nil
+}
+
+func init() {
+	profileRenameCmd.Flags().BoolVar(&profileRenameLocalOnly, "local-only", false, "Only rename the local profile")
+	profileRenameCmd.Flags().BoolVar(&profileRenameRemoteOnly, "remote-only", false, "Only rename the profile on the server")
+	profileRenameCmd.MarkFlagsMutuallyExclusive("local-only", "remote-only")
+
+	profileCmd.AddCommand(profileRenameCmd)
+}
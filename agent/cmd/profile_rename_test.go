@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// fakeRenameClient records rename calls instead of talking to a server
+type fakeRenameClient struct {
+	profiles []string
+	renames  [][2]string
+}
+
+func (f *fakeRenameClient) ListProfiles() ([]string, error) {
+	return f.profiles, nil
+}
+
+func (f *fakeRenameClient) RenameProfile(oldName, newName string) error {
+	f.renames = append(f.renames, [2]string{oldName, newName})
+	return nil
+}
+
+// runProfileRename sets up a config and fake client, then runs profile rename with args
+func runProfileRename(t *testing.T, fake *fakeRenameClient, localProfiles []string, args ...string) (string, string, error) {
+	t.Helper()
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	for _, name := range localProfiles {
+		createTestProfile(t, profilesDir, name, 1)
+	}
+
+	originalFactory := remoteRenamerFactory
+	remoteRenamerFactory = func(serverURL string) remoteProfileRenamer { return fake }
+	t.Cleanup(func() {
+		remoteRenamerFactory = originalFactory
+		profileRenameLocalOnly = false
+		profileRenameRemoteOnly = false
+		profileRenameCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "rename"}, args...))
+
+	err := cmd.Execute()
+	return output.String(), profilesDir, err
+}
+
+func TestProfileRenameCommand_Both(t *testing.T) {
+	fake := &fakeRenameClient{profiles: []string{"work"}}
+
+	output, profilesDir, err := runProfileRename(t, fake, []string{"work"}, "work", "office")
+	if err != nil {
+		t.Fatalf("profile rename failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesDir, "office.json")); err != nil {
+		t.Errorf("expected local profile to be renamed: %v", err)
+	}
+	if len(fake.renames) != 1 || fake.renames[0] != [2]string{"work", "office"} {
+		t.Errorf("expected one server rename work -> office, got %v", fake.renames)
+	}
+	if !strings.Contains(output, "Renamed local profile") || !strings.Contains(output, "Renamed server profile") {
+		t.Errorf("expected both renames to be reported, got: %s", output)
+	}
+}
+
+func TestProfileRenameCommand_LocalOnly(t *testing.T) {
+	fake := &fakeRenameClient{}
+
+	_, profilesDir, err := runProfileRename(t, fake, []string{"work"}, "--local-only", "work", "office")
+	if err != nil {
+		t.Fatalf("profile rename --local-only failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesDir, "office.json")); err != nil {
+		t.Errorf("expected local profile to be renamed: %v", err)
+	}
+	if len(fake.renames) != 0 {
+		t.Errorf("expected no server rename calls, got %v", fake.renames)
+	}
+}
+
+func TestProfileRenameCommand_RemoteOnly(t *testing.T) {
+	fake := &fakeRenameClient{profiles: []string{"work"}}
+
+	_, profilesDir, err := runProfileRename(t, fake, []string{"work"}, "--remote-only", "work", "office")
+	if err != nil {
+		t.Fatalf("profile rename --remote-only failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesDir, "work.json")); err != nil {
+		t.Errorf("expected local profile to be untouched: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(profilesDir, "office.json")); !os.IsNotExist(err) {
+		t.Errorf("expected no local office.json, got err=%v", err)
+	}
+	if len(fake.renames) != 1 {
+		t.Errorf("expected one server rename call, got %v", fake.renames)
+	}
+}
+
+func TestProfileRenameCommand_RemoteOnlyIgnoresLocalCollision(t *testing.T) {
+	// A local 'office' profile must not block renaming on the server
+	fake := &fakeRenameClient{profiles: []string{"work"}}
+
+	_, _, err := runProfileRename(t, fake, []string{"work", "office"}, "--remote-only", "work", "office")
+	if err != nil {
+		t.Fatalf("profile rename --remote-only failed: %v", err)
+	}
+	if len(fake.renames) != 1 {
+		t.Errorf("expected one server rename call, got %v", fake.renames)
+	}
+}
+
+func TestProfileRenameCommand_RemoteCollision(t *testing.T) {
+	fake := &fakeRenameClient{profiles: []string{"work", "Office"}}
+
+	_, profilesDir, err := runProfileRename(t, fake, []string{"work"}, "work", "office")
+	if err == nil {
+		t.Fatal("expected error for server name collision, got nil")
+	}
+	if !strings.Contains(err.Error(), "already exists on server") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Nothing should change when the server check fails
+	if _, err := os.Stat(filepath.Join(profilesDir, "work.json")); err != nil {
+		t.Errorf("expected local profile to be untouched: %v", err)
+	}
+	if len(fake.renames) != 0 {
+		t.Errorf("expected no server rename calls, got %v", fake.renames)
+	}
+}
+
+func TestProfileRenameCommand_FlagsMutuallyExclusive(t *testing.T) {
+	fake := &fakeRenameClient{profiles: []string{"work"}}
+
+	_, _, err := runProfileRename(t, fake, []string{"work"}, "--local-only", "--remote-only", "work", "office")
+	if err == nil {
+		t.Fatal("expected error when both --local-only and --remote-only are set")
+	}
+}
+
+func TestProfileRenameCommand_DryRun(t *testing.T) {
+	fake := &fakeRenameClient{profiles: []string{"work"}}
+	resetProfileDryRun(t)
+
+	output, profilesDir, err := runProfileRename(t, fake, []string{"work"}, "--dry-run", "work", "office")
+	if err != nil {
+		t.Fatalf("profile rename --dry-run failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesDir, "work.json")); err != nil {
+		t.Errorf("expected local profile to be untouched: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(profilesDir, "office.json")); !os.IsNotExist(err) {
+		t.Errorf("expected no renamed file, stat err = %v", err)
+	}
+	if len(fake.renames) != 0 {
+		t.Errorf("expected no server rename calls, got %v", fake.renames)
+	}
+	if !strings.Contains(output, "Would rename local profile 'work' to 'office'") || !strings.Contains(output, "Would rename server profile 'work' to 'office'") {
+		t.Errorf("expected both planned renames to be reported, got: %s", output)
+	}
+}
+
+func TestProfileRenameCommand_DryRunStillChecksCollisions(t *testing.T) {
+	fake := &fakeRenameClient{profiles: []string{"work"}}
+	resetProfileDryRun(t)
+
+	_, _, err := runProfileRename(t, fake, []string{"work", "office"}, "--dry-run", "--local-only", "work", "office")
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected collision error in dry run, got %v", err)
+	}
+}
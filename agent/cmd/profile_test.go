@@ -3,14 +3,21 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
 	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func TestProfileListCommand_Empty(t *testing.T) {
@@ -151,6 +158,56 @@ logging:
 	}
 }
 
+func TestProfileListCommand_ShowsDescription(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+
+	now := time.Now()
+	for _, prof := range []profile.Profile{
+		{Name: "work", Description: "Go backend work", CreatedAt: now, UpdatedAt: now},
+		{Name: "legacy", CreatedAt: now, UpdatedAt: now},
+	} {
+		data, err := json.Marshal(prof)
+		if err != nil {
+			t.Fatalf("failed to marshal profile: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(profilesDir, prof.Name+".json"), data, 0644); err != nil {
+			t.Fatalf("failed to write profile file: %v", err)
+		}
+	}
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"profile", "list"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("profile list command failed: %v", err)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "DESCRIPTION") {
+		t.Errorf("expected description column, got: %s", got)
+	}
+	for _, line := range strings.Split(got, "\n") {
+		switch {
+		case strings.HasPrefix(line, "work "):
+			if !strings.HasSuffix(line, " Go backend work") {
+				t.Errorf("expected work row to end with its description, got: %q", line)
+			}
+		case strings.HasPrefix(line, "legacy "):
+			if strings.HasSuffix(line, " ") {
+				t.Errorf("expected legacy row without trailing padding, got: %q", line)
+			}
+		}
+	}
+}
+
 func TestProfileSaveCommand_ValidationError(t *testing.T) {
 	// Create temporary home directory
 	tempHome := t.TempDir()
@@ -198,6 +255,34 @@ logging:
 	}
 }
 
+func TestProfileSaveCommand_NoAutoDirsRequiresExtensionsDir(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	t.Cleanup(func() {
+		profileSaveExtensionDirs = nil
+		profileSaveNoAutoDirs = false
+		profileSaveCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"profile", "save", "work", "--no-auto-dirs"})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error for --no-auto-dirs without --extensions-dir")
+	}
+	if !strings.Contains(err.Error(), "--extensions-dir") {
+		t.Errorf("expected error to mention --extensions-dir, got: %v", err)
+	}
+}
+
 func TestProfileLoadCommand_NotFound(t *testing.T) {
 	// Create temporary home directory
 	tempHome := t.TempDir()
@@ -280,3 +365,1266 @@ func TestProfileCommands_RequireArguments(t *testing.T) {
 		})
 	}
 }
+
+func TestProfileListCommand_ReportsCorruptProfiles(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	createTestProfile(t, profilesDir, "work", 2)
+
+	for _, name := range []string{"broken.json", "truncated.json"} {
+		if err := os.WriteFile(filepath.Join(profilesDir, name), []byte(`{"name":`), 0644); err != nil {
+			t.Fatalf("failed to write corrupt profile: %v", err)
+		}
+	}
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"profile", "list"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("profile list command failed: %v", err)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "work") {
+		t.Errorf("expected output to contain 'work', got: %s", got)
+	}
+	if !strings.Contains(got, "2 profiles could not be read") {
+		t.Errorf("expected corrupt profile footer, got: %s", got)
+	}
+	if !strings.Contains(got, "broken.json") || !strings.Contains(got, "truncated.json") {
+		t.Errorf("expected corrupt files to be named, got: %s", got)
+	}
+}
+
+// writeInstalledExtension lays out an installed extension under HOME so it is
+// found by directory scanning when the VS Code CLI is unavailable
+func writeInstalledExtension(t *testing.T, home, id, version string) {
+	t.Helper()
+	parts := strings.SplitN(id, ".", 2)
+	dir := filepath.Join(home, ".vscode", "extensions", id+"-"+version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create extension dir: %v", err)
+	}
+	manifest := `{"name": "` + parts[1] + `", "publisher": "` + parts[0] + `", "version": "` + version + `"}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+}
+
+// runProfileDiff sets up a profile with ms-python.python@1.0.0 and golang.go@2.0.0
+// and runs profile diff with args, returning output and error
+func runProfileDiff(t *testing.T, installed map[string]string, args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+
+	prof := profile.Profile{
+		Name: "ci",
+		Extensions: []profile.Extension{
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			{ID: "golang.go", Version: "2.0.0", Enabled: true},
+		},
+	}
+	data, err := json.MarshalIndent(prof, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "ci.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	for id, version := range installed {
+		writeInstalledExtension(t, tempHome, id, version)
+	}
+
+	t.Cleanup(func() {
+		profileDiffExitCode = false
+		profileDiffVerbose = false
+		profileDiffPreRelease = false
+		profileDiffJSON = false
+		profileDiffCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "diff", "ci"}, args...))
+
+	err = cmd.Execute()
+	return output.String(), err
+}
+
+func TestProfileDiffCommand_ExitCodeInSync(t *testing.T) {
+	out, err := runProfileDiff(t, map[string]string{
+		"ms-python.python": "1.0.0",
+		"golang.go":        "2.0.0",
+	}, "--exit-code")
+	if err != nil {
+		t.Fatalf("expected exit 0 when in sync, got: %v", err)
+	}
+	if out != "" {
+		t.Errorf("expected no output without --verbose, got: %q", out)
+	}
+}
+
+func TestProfileDiffCommand_ExitCodeDrift(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed map[string]string
+	}{
+		{"missing extension", map[string]string{"ms-python.python": "1.0.0"}},
+		{"version mismatch", map[string]string{"ms-python.python": "1.0.0", "golang.go": "2.1.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runProfileDiff(t, tt.installed, "--exit-code")
+			if err == nil {
+				t.Fatal("expected error when profile has drifted")
+			}
+			if code := exitCode(err); code != exitCodeError {
+				t.Errorf("exitCode() = %d, want %d", code, exitCodeError)
+			}
+			if out != "" {
+				t.Errorf("expected no output without --verbose, got: %q", out)
+			}
+		})
+	}
+}
+
+func TestProfileDiffCommand_ExitCodeVerbose(t *testing.T) {
+	out, err := runProfileDiff(t, map[string]string{
+		"ms-python.python": "1.0.0",
+		"golang.go":        "2.1.0",
+	}, "--exit-code", "--verbose")
+	if exitCode(err) != exitCodeError {
+		t.Fatalf("expected exit 1, got: %v", err)
+	}
+	if !strings.Contains(out, "~ golang.go (profile 2.0.0, installed 2.1.0)") {
+		t.Errorf("expected version mismatch in verbose output, got: %s", out)
+	}
+}
+
+// runProfileDiffFile writes content to a file outside the profiles directory
+// and runs profile diff --against-file on it, returning output and error
+func runProfileDiffFile(t *testing.T, installed map[string]string, content string, args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+
+	path := filepath.Join(t.TempDir(), "emailed.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	for id, version := range installed {
+		writeInstalledExtension(t, tempHome, id, version)
+	}
+
+	t.Cleanup(func() {
+		profileDiffFile = ""
+		profileDiffExitCode = false
+		profileDiffJSON = false
+		profileDiffCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "diff", "--against-file", path}, args...))
+
+	err := cmd.Execute()
+	return output.String(), err
+}
+
+func TestProfileDiffCommand_AgainstFile(t *testing.T) {
+	content := `{"name": "emailed", "extensions": [
+		{"id": "ms-python.python", "version": "1.0.0", "enabled": true},
+		{"id": "golang.go", "version": "2.0.0", "enabled": true}
+	]}`
+
+	out, err := runProfileDiffFile(t, map[string]string{"ms-python.python": "1.0.0"}, content)
+	if err != nil {
+		t.Fatalf("profile diff --against-file failed: %v", err)
+	}
+	if !strings.Contains(out, "emailed") {
+		t.Errorf("expected the file's profile name in output, got: %s", out)
+	}
+	if !strings.Contains(out, "golang.go") {
+		t.Errorf("expected golang.go to be listed for install, got: %s", out)
+	}
+
+	_, err = runProfileDiffFile(t, map[string]string{"ms-python.python": "1.0.0"}, content, "--exit-code")
+	if code := exitCode(err); code != exitCodeError {
+		t.Errorf("exitCode() = %d, want %d for a drifted file", code, exitCodeError)
+	}
+}
+
+func TestProfileDiffCommand_ExitCodeStateOnly(t *testing.T) {
+	// Installed at the profile's version, but the profile has it disabled
+	content := `{"name": "emailed", "extensions": [
+		{"id": "ms-python.python", "version": "1.0.0", "enabled": false}
+	]}`
+
+	out, err := runProfileDiffFile(t, map[string]string{"ms-python.python": "1.0.0"}, content, "--exit-code", "--verbose")
+	if code := exitCode(err); code != exitCodeError {
+		t.Errorf("exitCode() = %d, want %d when only the enabled state differs", code, exitCodeError)
+	}
+	if !strings.Contains(out, "! ms-python.python (profile disabled, installed enabled)") {
+		t.Errorf("expected the state mismatch in verbose output, got: %s", out)
+	}
+}
+
+func TestProfileDiffCommand_AgainstFileInvalid(t *testing.T) {
+	content := `{"name": "emailed", "extensions": [{"id": "bad-extension-id", "version": "1.0.0"}]}`
+
+	out, err := runProfileDiffFile(t, nil, content)
+	if err == nil {
+		t.Fatal("expected an invalid profile file to be rejected")
+	}
+	if !strings.Contains(err.Error(), "invalid profile") {
+		t.Errorf("expected an invalid profile error, got: %v", err)
+	}
+	if strings.Contains(out, "Profile:") {
+		t.Errorf("expected no diff output for an invalid file, got: %s", out)
+	}
+}
+
+func TestProfileDiffCommand_AgainstFileRejectsName(t *testing.T) {
+	_, err := runProfileDiffFile(t, nil, `{"name": "emailed", "extensions": []}`, "ci")
+	if err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("expected a name argument to be rejected with --against-file, got: %v", err)
+	}
+}
+
+func TestProfileDiffCommand_WithoutExitCodeSucceedsOnDrift(t *testing.T) {
+	out, err := runProfileDiff(t, map[string]string{"ms-python.python": "1.0.0"})
+	if err != nil {
+		t.Fatalf("expected success without --exit-code, got: %v", err)
+	}
+	if !strings.Contains(out, "+ golang.go (2.0.0)") {
+		t.Errorf("expected missing extension in output, got: %s", out)
+	}
+}
+
+// fakeUploadClient records uploads instead of talking to a server
+type fakeUploadClient struct {
+	uploads []*api.Profile
+	err     error
+}
+
+func (f *fakeUploadClient) UploadProfileWithOptions(p *api.Profile, opts api.UploadOptions) (*api.UploadResult, error) {
+	f.uploads = append(f.uploads, p)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &api.UploadResult{}, nil
+}
+
+// runProfileSaveUpload saves profile "work" from one installed extension with
+// --upload and any extra args, using fake as the server client
+func runProfileSaveUpload(t *testing.T, fake *fakeUploadClient, extraArgs ...string) (string, string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	writeInstalledExtension(t, tempHome, "golang.go", "0.40.0")
+
+	originalFactory := profileUploaderFactory
+	profileUploaderFactory = func(serverURL string) profileUploader { return fake }
+	t.Cleanup(func() {
+		profileUploaderFactory = originalFactory
+		profileSaveUpload = false
+		profileSaveDescription = ""
+		profileSaveCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "save", "work", "--upload"}, extraArgs...))
+
+	err := cmd.Execute()
+	return output.String(), profilesDir, err
+}
+
+func TestProfileSaveCommand_Upload(t *testing.T) {
+	fake := &fakeUploadClient{}
+
+	output, profilesDir, err := runProfileSaveUpload(t, fake)
+	if err != nil {
+		t.Fatalf("profile save --upload failed: %v", err)
+	}
+
+	if len(fake.uploads) != 1 {
+		t.Fatalf("expected exactly one upload, got %d", len(fake.uploads))
+	}
+	uploaded := fake.uploads[0]
+	if uploaded.Name != "work" || len(uploaded.Extensions) != 1 || uploaded.Extensions[0].ID != "golang.go" {
+		t.Errorf("expected the saved profile to be uploaded, got %+v", uploaded)
+	}
+	if _, err := os.Stat(filepath.Join(profilesDir, "work.json")); err != nil {
+		t.Errorf("expected local profile to be saved: %v", err)
+	}
+	if !strings.Contains(output, "Saved 1 extensions to profile 'work'") || !strings.Contains(output, "Uploaded profile 'work' to server") {
+		t.Errorf("expected save and upload to be reported, got: %s", output)
+	}
+}
+
+func TestProfileSaveCommand_Description(t *testing.T) {
+	fake := &fakeUploadClient{}
+
+	_, profilesDir, err := runProfileSaveUpload(t, fake, "--description", "  Go backend work ")
+	if err != nil {
+		t.Fatalf("profile save --description failed: %v", err)
+	}
+
+	saved, err := profile.Get("work", profilesDir)
+	if err != nil {
+		t.Fatalf("failed to read saved profile: %v", err)
+	}
+	if saved.Description != "Go backend work" {
+		t.Errorf("saved description = %q, want %q", saved.Description, "Go backend work")
+	}
+	if len(fake.uploads) != 1 || fake.uploads[0].Description != "Go backend work" {
+		t.Errorf("expected description to be uploaded, got %+v", fake.uploads)
+	}
+}
+
+func TestProfileSaveCommand_UploadFailureKeepsLocalProfile(t *testing.T) {
+	fake := &fakeUploadClient{err: errors.New("server unavailable")}
+
+	output, profilesDir, err := runProfileSaveUpload(t, fake)
+	if err == nil {
+		t.Fatal("expected error when upload fails")
+	}
+	if !strings.Contains(err.Error(), "saved locally but upload failed") || !strings.Contains(err.Error(), "server unavailable") {
+		t.Errorf("expected clear upload error, got: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(profilesDir, "work.json")); statErr != nil {
+		t.Errorf("expected local profile to be kept after failed upload: %v", statErr)
+	}
+	if !strings.Contains(output, "Saved 1 extensions to profile 'work'") {
+		t.Errorf("expected save to be reported, got: %s", output)
+	}
+}
+
+func TestProfileSaveCommand_WithoutUploadDoesNotUpload(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	writeInstalledExtension(t, tempHome, "golang.go", "0.40.0")
+
+	fake := &fakeUploadClient{}
+	originalFactory := profileUploaderFactory
+	profileUploaderFactory = func(serverURL string) profileUploader { return fake }
+	t.Cleanup(func() { profileUploaderFactory = originalFactory })
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"profile", "save", "work"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("profile save failed: %v", err)
+	}
+	if len(fake.uploads) != 0 {
+		t.Errorf("expected no upload without --upload, got %d", len(fake.uploads))
+	}
+}
+
+func TestProfileDiffCommand_Remote(t *testing.T) {
+	setupMockKeychain(t)
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/profiles/work" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.Profile{
+			Name:      "work",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Extensions: []api.Extension{
+				{ID: "golang.go", Version: "2.0.0", Enabled: true},
+				{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+				{ID: "old.ext", Version: "3.0.0", Enabled: true},
+			},
+		})
+	}))
+	defer server.Close()
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, server.URL, profilesDir)
+
+	prof := profile.Profile{
+		Name: "work",
+		Extensions: []profile.Extension{
+			{ID: "golang.go", Version: "2.1.0", Enabled: true},
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			{ID: "new.ext", Version: "0.1.0", Enabled: true},
+		},
+	}
+	data, err := json.MarshalIndent(prof, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "work.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	t.Cleanup(func() {
+		profileDiffRemote = false
+		profileDiffExitCode = false
+		profileDiffVerbose = false
+		profileDiffJSON = false
+		profileDiffCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"profile", "diff", "work", "--remote"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("profile diff --remote failed: %v", err)
+	}
+
+	got := output.String()
+	for _, want := range []string{
+		"Only Local (1):",
+		"+ new.ext (0.1.0)",
+		"Only on Server (1):",
+		"- old.ext (3.0.0)",
+		"Version Changed (1):",
+		"~ golang.go (local 2.1.0, server 2.0.0)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "ms-python.python") {
+		t.Errorf("unchanged extension should not be listed, got:\n%s", got)
+	}
+}
+
+// runProfileValidate writes a "team" profile with exts and runs
+// 'profile load team --validate-only' with the given extra args
+func runProfileValidate(t *testing.T, exts []profile.Extension, args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+
+	data, err := json.MarshalIndent(profile.Profile{Name: "team", Extensions: exts}, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "team.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	t.Cleanup(func() {
+		profileLoadValidateOnly = false
+		profileLoadMarketplace = false
+		profileLoadCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "load", "team", "--validate-only"}, args...))
+
+	err = cmd.Execute()
+	return output.String(), err
+}
+
+// setMarketplaceURL points --marketplace lookups at url for the test
+func setMarketplaceURL(t *testing.T, url string) {
+	t.Helper()
+	original := marketplaceURL
+	marketplaceURL = url
+	t.Cleanup(func() { marketplaceURL = original })
+}
+
+func TestProfileLoadCommand_ValidateOnlyFormat(t *testing.T) {
+	// PATH is cleared so any attempt to install would fail to find 'code'
+	t.Setenv("PATH", "")
+
+	out, err := runProfileValidate(t, []profile.Extension{
+		{ID: "golang.go", Version: "1.0.0"},
+		{ID: "not-an-id", Version: "1.0.0"},
+		{ID: "too.many.dots", Version: "1.0.0"},
+	})
+	if err == nil {
+		t.Fatal("expected error for unresolved extension IDs")
+	}
+	if !strings.Contains(err.Error(), "2 unresolved") {
+		t.Errorf("expected error to count 2 unresolved IDs, got: %v", err)
+	}
+	for _, want := range []string{"(format only)", "Unresolved (2):", "not-an-id", "too.many.dots"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "golang.go:") {
+		t.Errorf("valid ID should not be reported, got:\n%s", out)
+	}
+}
+
+func TestProfileLoadCommand_ValidateOnlyMarketplace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/extensionquery" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(body), "golang.go") {
+			_, _ = w.Write([]byte(`{"results":[{"extensions":[{"extensionName":"go","publisher":{"publisherName":"golang"}}]}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[{"extensions":[]}]}`))
+	}))
+	defer server.Close()
+	setMarketplaceURL(t, server.URL)
+
+	out, err := runProfileValidate(t, []profile.Extension{
+		{ID: "golang.go", Version: "1.0.0"},
+		{ID: "acme.unpublished", Version: "1.0.0"},
+	}, "--marketplace")
+	if err == nil {
+		t.Fatal("expected error for unpublished extension")
+	}
+	for _, want := range []string{"(format and marketplace)", "Unresolved (1):", "acme.unpublished: extension 'acme.unpublished' not found in the marketplace"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestProfileLoadCommand_ValidateOnlyOfflineFallsBack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	setMarketplaceURL(t, server.URL)
+
+	out, err := runProfileValidate(t, []profile.Extension{{ID: "golang.go", Version: "1.0.0"}}, "--marketplace")
+	if err != nil {
+		t.Fatalf("expected format-only validation to pass offline, got: %v", err)
+	}
+	for _, want := range []string{"falling back to format-only validation", "(format only)", "All extension IDs resolved"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+// setHostname makes hostVariant report host for the test
+func setHostname(t *testing.T, host string) {
+	t.Helper()
+	original := hostname
+	hostname = func() (string, error) { return host, nil }
+	t.Cleanup(func() { hostname = original })
+}
+
+// runProfileVariantCommand runs a profile subcommand against a profiles
+// directory containing empty profiles with the given names
+func runProfileVariantCommand(t *testing.T, names []string, args ...string) (string, string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	for _, name := range names {
+		data, err := json.Marshal(profile.Profile{Name: name, Extensions: []profile.Extension{}})
+		if err != nil {
+			t.Fatalf("failed to marshal profile: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(profilesDir, name+".json"), data, 0644); err != nil {
+			t.Fatalf("failed to write profile: %v", err)
+		}
+	}
+	writeInstalledExtension(t, tempHome, "golang.go", "0.40.0")
+
+	t.Cleanup(func() {
+		profileSaveVariant = ""
+		profileLoadVariant = ""
+		profileLoadNoVariant = false
+		profileLoadValidateOnly = false
+		profileListGroup = false
+		for _, c := range []*cobra.Command{profileSaveCmd, profileLoadCmd, profileListCmd} {
+			c.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		}
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile"}, args...))
+
+	err := cmd.Execute()
+	return output.String(), profilesDir, err
+}
+
+func TestProfileSaveCommand_Variant(t *testing.T) {
+	out, profilesDir, err := runProfileVariantCommand(t, nil, "save", "work", "--variant", "laptop")
+	if err != nil {
+		t.Fatalf("profile save --variant failed: %v", err)
+	}
+	if !strings.Contains(out, "profile 'work@laptop'") {
+		t.Errorf("expected the variant name to be reported, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(profilesDir, "work@laptop.json")); err != nil {
+		t.Errorf("expected work@laptop.json to be saved: %v", err)
+	}
+
+	_, _, err = runProfileVariantCommand(t, nil, "save", "work@desk", "--variant", "laptop")
+	if err == nil || !strings.Contains(err.Error(), "already names a variant") {
+		t.Errorf("expected error for a name that already has a variant, got: %v", err)
+	}
+}
+
+func TestProfileLoadCommand_PrefersHostVariant(t *testing.T) {
+	setHostname(t, "Laptop.example.com")
+
+	out, _, err := runProfileVariantCommand(t, []string{"work", "work@laptop"}, "load", "work", "--validate-only")
+	if err != nil {
+		t.Fatalf("profile load failed: %v", err)
+	}
+	if !strings.Contains(out, "Using variant profile 'work@laptop'") || !strings.Contains(out, "profile 'work@laptop'") {
+		t.Errorf("expected the host variant to be loaded, got: %s", out)
+	}
+
+	out, _, err = runProfileVariantCommand(t, []string{"work", "work@laptop"}, "load", "work", "--validate-only", "--no-variant")
+	if err != nil {
+		t.Fatalf("profile load --no-variant failed: %v", err)
+	}
+	if strings.Contains(out, "work@laptop") {
+		t.Errorf("expected --no-variant to load the plain profile, got: %s", out)
+	}
+}
+
+func TestProfileLoadCommand_PlainNameWithoutVariant(t *testing.T) {
+	setHostname(t, "desktop")
+
+	out, _, err := runProfileVariantCommand(t, []string{"work", "work@laptop"}, "load", "work", "--validate-only")
+	if err != nil {
+		t.Fatalf("profile load failed: %v", err)
+	}
+	if strings.Contains(out, "Using variant") || !strings.Contains(out, "profile 'work'") {
+		t.Errorf("expected the plain profile when no host variant exists, got: %s", out)
+	}
+}
+
+func TestProfileListCommand_Group(t *testing.T) {
+	out, _, err := runProfileVariantCommand(t, []string{"work", "work@laptop", "personal"}, "list", "--group")
+	if err != nil {
+		t.Fatalf("profile list --group failed: %v", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(out, "\n")[2:] {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			names = append(names, fields[0])
+		}
+	}
+	want := []string{"personal", "personal", "work", "work", "work@laptop"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("grouped names = %v, want %v\n%s", names, want, out)
+	}
+	if !strings.Contains(out, "\n  work@laptop") {
+		t.Errorf("expected variants to be indented under their base, got:\n%s", out)
+	}
+}
+
+func TestProfileDiffCommand_JSON(t *testing.T) {
+	out, err := runProfileDiff(t, map[string]string{
+		"ms-python.python": "1.0.0",
+		"golang.go":        "1.9.0",
+	}, "--json", "--exit-code")
+
+	if code := exitCode(err); code != exitCodeError {
+		t.Fatalf("expected --exit-code to apply with --json, got: %v", err)
+	}
+
+	var result profile.DiffResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", out, err)
+	}
+	if result.ProfileName != "ci" || result.TotalInProfile != 2 {
+		t.Errorf("unexpected diff header: %+v", result)
+	}
+	changed := result.Changes.VersionChanged
+	if len(changed) != 1 || changed[0].ID != "golang.go" || changed[0].From.Version != "1.9.0" || changed[0].To.Version != "2.0.0" {
+		t.Errorf("VersionChanged = %+v, want golang.go from 1.9.0 to 2.0.0", changed)
+	}
+	if len(result.Changes.Added) != 0 || len(result.Changes.Removed) != 0 {
+		t.Errorf("expected no added or removed extensions, got %+v", result.Changes)
+	}
+}
+
+func TestProfileDiffCommand_PreRelease(t *testing.T) {
+	// The profile pins golang.go 2.0.0 while a 2.1.0 pre-release is installed
+	installed := map[string]string{"ms-python.python": "1.0.0", "golang.go": "2.1.0-beta"}
+
+	out, err := runProfileDiff(t, installed)
+	if err != nil {
+		t.Fatalf("profile diff failed: %v", err)
+	}
+	if !strings.Contains(out, "Outdated (1):") || !strings.Contains(out, "^ golang.go (installed 2.1.0-beta, profile has 2.0.0)") {
+		t.Errorf("expected the stable profile version to be newer on the stable channel, got:\n%s", out)
+	}
+
+	out, err = runProfileDiff(t, installed, "--pre-release")
+	if err != nil {
+		t.Fatalf("profile diff --pre-release failed: %v", err)
+	}
+	if strings.Contains(out, "Outdated") {
+		t.Errorf("expected the installed pre-release to count as newer with --pre-release, got:\n%s", out)
+	}
+	if !strings.Contains(out, "~ golang.go (profile 2.0.0, installed 2.1.0-beta)") {
+		t.Errorf("expected the version mismatch to still be reported, got:\n%s", out)
+	}
+}
+
+// resetProfileDryRun clears the persistent --dry-run flag after the test
+func resetProfileDryRun(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		profileDryRun = false
+		profileCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+}
+
+func TestProfileSaveCommand_DryRun(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles-new")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	if err := os.Remove(profilesDir); err != nil {
+		t.Fatalf("failed to remove profiles dir: %v", err)
+	}
+	writeInstalledExtension(t, tempHome, "golang.go", "0.40.0")
+
+	fake := &fakeUploadClient{}
+	originalFactory := profileUploaderFactory
+	profileUploaderFactory = func(serverURL string) profileUploader { return fake }
+	resetProfileDryRun(t)
+	t.Cleanup(func() {
+		profileUploaderFactory = originalFactory
+		profileSaveUpload = false
+		profileSaveCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"profile", "save", "work", "--upload", "--dry-run"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("profile save --dry-run failed: %v", err)
+	}
+
+	out := output.String()
+	if !strings.Contains(out, "Would save 1 extensions to profile 'work'") || !strings.Contains(out, "Would upload profile 'work' to server") {
+		t.Errorf("expected the planned save and upload to be printed, got: %s", out)
+	}
+	if _, err := os.Stat(profilesDir); !os.IsNotExist(err) {
+		t.Errorf("expected profiles directory not to be created, stat err = %v", err)
+	}
+	if len(fake.uploads) != 0 {
+		t.Errorf("expected no uploads, got %d", len(fake.uploads))
+	}
+}
+
+func TestProfileLoadCommand_DryRun(t *testing.T) {
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	// With no VS Code CLI on PATH any real install attempt would fail
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	data, err := json.Marshal(profile.Profile{
+		Name: "work",
+		Extensions: []profile.Extension{
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "work.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	writeInstalledExtension(t, tempHome, "golang.go", "0.40.0")
+
+	planned := &bytes.Buffer{}
+	profile.SetOutput(planned)
+	resetProfileDryRun(t)
+	t.Cleanup(func() { profile.SetOutput(os.Stdout) })
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"profile", "load", "work", "--no-variant", "--dry-run"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("profile load --dry-run failed: %v\n%s", err, planned.String())
+	}
+
+	if !strings.Contains(planned.String(), "would install 1 extension(s):\n  + ms-python.python (1.0.0)") {
+		t.Errorf("expected the install plan to be printed, got: %s", planned.String())
+	}
+	if !strings.Contains(output.String(), "Dry run: no extensions were installed.") {
+		t.Errorf("expected a dry-run notice, got: %s", output.String())
+	}
+}
+
+// runProfileLoadSettings loads a profile carrying settings and keybindings
+// into a temp home whose VS Code user directory already has both files
+func runProfileLoadSettings(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("APPDATA", filepath.Join(tempHome, "AppData"))
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	data, err := json.Marshal(profile.Profile{
+		Name:        "editor",
+		Extensions:  []profile.Extension{},
+		Settings:    json.RawMessage(`{"editor.tabSize": 2}`),
+		Keybindings: json.RawMessage(`[]`),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "editor.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	// Listing installed extensions needs an extensions directory without the CLI
+	writeInstalledExtension(t, tempHome, "golang.go", "0.40.0")
+
+	userDir := vscode.UserDir()
+	if err := os.MkdirAll(userDir, 0755); err != nil {
+		t.Fatalf("failed to create user dir: %v", err)
+	}
+	for _, name := range []string{vscode.SettingsFile, vscode.KeybindingsFile} {
+		if err := os.WriteFile(filepath.Join(userDir, name), []byte("local"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	profile.SetOutput(io.Discard)
+	t.Cleanup(func() {
+		profile.SetOutput(os.Stdout)
+		profileLoadSettings = true
+		profileLoadKeybindings = true
+		profileLoadNoVariant = false
+		profileLoadYes = false
+		profileLoadCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "load", "editor", "--no-variant"}, args...))
+
+	err = cmd.Execute()
+	return userDir, err
+}
+
+// userFileBackups lists the backups made of name in dir
+func userFileBackups(t *testing.T, dir, name string) []string {
+	t.Helper()
+	backups, err := filepath.Glob(filepath.Join(dir, name+".*.bak"))
+	if err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	return backups
+}
+
+func TestProfileLoadCommand_AppliesSettingsWithBackup(t *testing.T) {
+	userDir, err := runProfileLoadSettings(t)
+	if err != nil {
+		t.Fatalf("profile load failed: %v", err)
+	}
+
+	for _, name := range []string{vscode.SettingsFile, vscode.KeybindingsFile} {
+		data, _ := os.ReadFile(filepath.Join(userDir, name))
+		if string(data) == "local" {
+			t.Errorf("expected %s to be replaced", name)
+		}
+		backups := userFileBackups(t, userDir, name)
+		if len(backups) != 1 {
+			t.Fatalf("expected one backup of %s, got %v", name, backups)
+		}
+		if data, _ := os.ReadFile(backups[0]); string(data) != "local" {
+			t.Errorf("backup of %s = %q, want the local file", name, data)
+		}
+	}
+}
+
+func TestProfileLoadCommand_SettingsToggle(t *testing.T) {
+	userDir, err := runProfileLoadSettings(t, "--settings=false")
+	if err != nil {
+		t.Fatalf("profile load --settings=false failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(userDir, vscode.SettingsFile)); string(data) != "local" {
+		t.Errorf("expected settings.json to be kept, got %q", data)
+	}
+	if backups := userFileBackups(t, userDir, vscode.SettingsFile); len(backups) != 0 {
+		t.Errorf("expected no settings backup, got %v", backups)
+	}
+	if data, _ := os.ReadFile(filepath.Join(userDir, vscode.KeybindingsFile)); string(data) != "[]\n" {
+		t.Errorf("expected keybindings.json to be written, got %q", data)
+	}
+}
+
+func TestProfileLoadCommand_KeybindingsToggle(t *testing.T) {
+	userDir, err := runProfileLoadSettings(t, "--keybindings=false")
+	if err != nil {
+		t.Fatalf("profile load --keybindings=false failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(userDir, vscode.KeybindingsFile)); string(data) != "local" {
+		t.Errorf("expected keybindings.json to be kept, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(userDir, vscode.SettingsFile)); string(data) == "local" {
+		t.Error("expected settings.json to be written")
+	}
+}
+
+// stubConfirm makes the terminal look interactive or not and answers every
+// confirmation prompt with answer. It returns the questions asked.
+func stubConfirm(t *testing.T, interactive, answer bool) *[]string {
+	t.Helper()
+	var asked []string
+	origInteractive, origConfirm := isInteractive, confirm
+	isInteractive = func() bool { return interactive }
+	confirm = func(cmd *cobra.Command, question string) (bool, error) {
+		asked = append(asked, question)
+		return answer, nil
+	}
+	t.Cleanup(func() {
+		isInteractive, confirm = origInteractive, origConfirm
+	})
+	return &asked
+}
+
+// settingsContent returns the content of settings.json in userDir
+func settingsContent(t *testing.T, userDir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(userDir, vscode.SettingsFile))
+	if err != nil {
+		t.Fatalf("failed to read settings: %v", err)
+	}
+	return string(data)
+}
+
+func TestProfileLoadCommand_ConfirmDeclined(t *testing.T) {
+	asked := stubConfirm(t, true, false)
+
+	userDir, err := runProfileLoadSettings(t)
+	if err != nil {
+		t.Fatalf("profile load failed: %v", err)
+	}
+
+	if len(*asked) != 1 {
+		t.Errorf("expected one confirmation prompt, got %v", *asked)
+	}
+	if got := settingsContent(t, userDir); got != "local" {
+		t.Errorf("expected settings to be left alone after declining, got %q", got)
+	}
+}
+
+func TestProfileLoadCommand_ConfirmAccepted(t *testing.T) {
+	asked := stubConfirm(t, true, true)
+
+	userDir, err := runProfileLoadSettings(t)
+	if err != nil {
+		t.Fatalf("profile load failed: %v", err)
+	}
+
+	if len(*asked) != 1 {
+		t.Errorf("expected one confirmation prompt, got %v", *asked)
+	}
+	if got := settingsContent(t, userDir); got == "local" {
+		t.Error("expected settings to be applied after confirming")
+	}
+}
+
+func TestProfileLoadCommand_YesSkipsPrompt(t *testing.T) {
+	asked := stubConfirm(t, true, false)
+
+	userDir, err := runProfileLoadSettings(t, "--yes")
+	if err != nil {
+		t.Fatalf("profile load --yes failed: %v", err)
+	}
+
+	if len(*asked) != 0 {
+		t.Errorf("expected no prompt with --yes, got %v", *asked)
+	}
+	if got := settingsContent(t, userDir); got == "local" {
+		t.Error("expected settings to be applied with --yes")
+	}
+}
+
+func TestProfileLoadCommand_NonInteractiveSkipsPrompt(t *testing.T) {
+	asked := stubConfirm(t, false, false)
+
+	userDir, err := runProfileLoadSettings(t)
+	if err != nil {
+		t.Fatalf("profile load failed: %v", err)
+	}
+
+	if len(*asked) != 0 {
+		t.Errorf("expected no prompt without a terminal, got %v", *asked)
+	}
+	if got := settingsContent(t, userDir); got == "local" {
+		t.Error("expected settings to be applied without a terminal")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"y", true},
+	}
+
+	for _, tt := range tests {
+		cmd := &cobra.Command{}
+		output := &bytes.Buffer{}
+		// The prompt goes to stderr, so --quiet discarding stdout does not hide it
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(output)
+		cmd.SetIn(strings.NewReader(tt.input))
+
+		got, err := confirm(cmd, "Apply these changes?")
+		if err != nil {
+			t.Fatalf("confirm(%q) failed: %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if output.String() != "Apply these changes? [y/N]: " {
+			t.Errorf("unexpected prompt %q", output.String())
+		}
+	}
+}
+
+func TestPrintLoadPlan_WritesToStderr(t *testing.T) {
+	cmd := &cobra.Command{}
+	output := &bytes.Buffer{}
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(output)
+
+	printLoadPlan(cmd, &profile.LoadPlan{
+		Profile:   &profile.Profile{Name: "editor"},
+		Install:   []profile.Extension{{ID: "golang.go", Version: "0.40.0"}},
+		UserFiles: []string{vscode.SettingsFile},
+	})
+
+	for _, want := range []string{
+		"Loading profile 'editor' will:",
+		"Install 1 extension(s):",
+		"+ golang.go (0.40.0)",
+		"Overwrite settings.json",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("expected %q on stderr, got:\n%s", want, output.String())
+		}
+	}
+}
+
+// runProfileLoadFromURL runs profile load with args against a temp home where
+// golang.go 0.40.0 is installed, returning the profiles directory and output
+func runProfileLoadFromURL(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	writeInstalledExtension(t, tempHome, "golang.go", "0.40.0")
+
+	profile.SetOutput(io.Discard)
+	t.Cleanup(func() {
+		profile.SetOutput(os.Stdout)
+		profileLoadFromURL = ""
+		profileLoadAllowHTTP = false
+		profileLoadSave = ""
+		profileLoadCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "load"}, args...))
+
+	err := cmd.Execute()
+	return profilesDir, output.String(), err
+}
+
+// serveProfileBody starts a test server answering every request with body
+func serveProfileBody(t *testing.T, body []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProfileLoadCommand_FromURL(t *testing.T) {
+	body, err := json.Marshal(profile.Profile{
+		Name:       "shared",
+		Extensions: []profile.Extension{{ID: "golang.go", Version: "0.40.0", Enabled: true}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	server := serveProfileBody(t, body)
+
+	profilesDir, output, err := runProfileLoadFromURL(t, "--from-url", server.URL+"/shared.json", "--allow-http", "--save", "team")
+	if err != nil {
+		t.Fatalf("profile load --from-url failed: %v\n%s", err, output)
+	}
+
+	if !strings.Contains(output, "Saved profile 'team'") || !strings.Contains(output, "Done!") {
+		t.Errorf("expected the profile to be saved and loaded, got: %s", output)
+	}
+	saved, err := profile.Get("team", profilesDir)
+	if err != nil {
+		t.Fatalf("expected the downloaded profile to be saved as 'team': %v", err)
+	}
+	if len(saved.Extensions) != 1 || saved.Extensions[0].ID != "golang.go" {
+		t.Errorf("saved profile has extensions %+v", saved.Extensions)
+	}
+}
+
+func TestProfileLoadCommand_FromURLRejectsBadBodies(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    []byte
+		wantErr string
+	}{
+		{"oversized", bytes.Repeat([]byte(" "), api.MaxResponseSize+1), "exceeds maximum allowed size"},
+		{"not json", []byte("<html>not a profile</html>"), "failed to parse profile"},
+		{"invalid profile", []byte(`{"name": "shared", "extensions": [{"id": "not-an-id", "version": "1.0.0"}]}`), "invalid profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := serveProfileBody(t, tt.body)
+
+			profilesDir, _, err := runProfileLoadFromURL(t, "--from-url", server.URL, "--allow-http", "--save", "team")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if _, err := os.Stat(filepath.Join(profilesDir, "team.json")); !os.IsNotExist(err) {
+				t.Errorf("expected nothing to be saved, stat err: %v", err)
+			}
+		})
+	}
+}
+
+func TestProfileLoadCommand_FromURLRequiresHTTPS(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	t.Cleanup(server.Close)
+
+	_, _, err := runProfileLoadFromURL(t, "--from-url", server.URL)
+	if err == nil || !strings.Contains(err.Error(), "--allow-http") {
+		t.Errorf("expected plain http to be refused, got %v", err)
+	}
+	if requested {
+		t.Error("expected no request to be made over plain http")
+	}
+}
+
+func TestValidateProfileURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		allowHTTP bool
+		wantErr   bool
+	}{
+		{"https://gist.example.com/raw/profile.json", false, false},
+		{"http://localhost:8080/profile.json", true, false},
+		{"http://localhost:8080/profile.json", false, true},
+		{"file:///etc/passwd", true, true},
+		{"profile.json", false, true},
+	}
+
+	for _, tt := range tests {
+		err := validateProfileURL(tt.url, tt.allowHTTP)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateProfileURL(%q, %v) error = %v, wantErr %v", tt.url, tt.allowHTTP, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// remoteTrash is the part of the API client used to manage the server trash
+type remoteTrash interface {
+	ListTrash() ([]api.TrashedProfile, error)
+	RestoreProfile(name string) (*api.Profile, error)
+}
+
+// remoteTrashFactory creates the client used for the server trash (can be overridden in tests)
+var remoteTrashFactory = func(serverURL string) remoteTrash {
+	return newAuthenticatedClient(serverURL)
+}
+
+var profileTrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List and restore profiles deleted from the server",
+	Long: `Profiles deleted from the server are moved to a trash instead of being removed
+right away. They can be restored until the server purges them after its
+retention period (30 days by default).`,
+}
+
+var profileTrashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted profiles that can still be restored",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load config
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		trash, err := remoteTrashFactory(cfg.Server.URL).ListTrash()
+		if err != nil {
+			return err
+		}
+
+		if len(trash) == 0 {
+			cmd.Printf("Trash is empty.\n")
+			return nil
+		}
+
+		cmd.Printf("%-20s %-12s %-25s %-25s\n", "NAME", "EXTENSIONS", "DELETED", "PURGED AFTER")
+		cmd.Printf("%s\n", strings.Repeat("-", 85))
+		for _, p := range trash {
+			cmd.Printf("%-20s %-12d %-25s %-25s\n",
+				p.Name,
+				p.Extensions,
+				p.DeletedAt.Local().Format("2006-01-02 15:04:05"),
+				p.PurgeAt.Local().Format("2006-01-02 15:04:05"),
+			)
+		}
+
+		return nil
+	},
+}
+
+var profileTrashRestoreCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Restore a deleted profile on the server",
+	Long:  "Take a profile out of the server trash. Run 'devtools-sync sync pull' afterwards to download it.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		// Load config
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if profileDryRun {
+			cmd.Printf("Would restore profile '%s' from server trash\n", name)
+			return nil
+		}
+
+		prof, err := remoteTrashFactory(cfg.Server.URL).RestoreProfile(name)
+		if err != nil {
+			return fmt.Errorf("failed to restore profile '%s': %w", name, err)
+		}
+
+		cmd.Printf("Restored profile '%s' (%d extensions) on server\n", prof.Name, len(prof.Extensions))
+		cmd.Printf("Run 'devtools-sync sync pull' to download it.\n")
+		return nil
+	},
+}
+
+func init() {
+	profileTrashCmd.AddCommand(profileTrashListCmd)
+	profileTrashCmd.AddCommand(profileTrashRestoreCmd)
+	profileCmd.AddCommand(profileTrashCmd)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// fakeTrashClient serves the server trash from memory
+type fakeTrashClient struct {
+	trash    []api.TrashedProfile
+	restored []string
+}
+
+func (f *fakeTrashClient) ListTrash() ([]api.TrashedProfile, error) {
+	return f.trash, nil
+}
+
+func (f *fakeTrashClient) RestoreProfile(name string) (*api.Profile, error) {
+	for i, p := range f.trash {
+		if p.Name == name {
+			f.trash = append(f.trash[:i], f.trash[i+1:]...)
+			f.restored = append(f.restored, name)
+			return &api.Profile{Name: name, Extensions: make([]api.Extension, p.Extensions)}, nil
+		}
+	}
+	return nil, fmt.Errorf("profile '%s' not found in server trash", name)
+}
+
+// runProfileTrash runs profile trash with args against fake
+func runProfileTrash(t *testing.T, fake *fakeTrashClient, args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	setupTestConfig(t, tempHome, "http://localhost:8080", filepath.Join(tempHome, ".devtools-sync", "profiles"))
+
+	originalFactory := remoteTrashFactory
+	remoteTrashFactory = func(serverURL string) remoteTrash { return fake }
+	t.Cleanup(func() { remoteTrashFactory = originalFactory })
+	resetProfileDryRun(t)
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "trash"}, args...))
+
+	err := cmd.Execute()
+	return output.String(), err
+}
+
+func TestProfileTrashCommand_ListAndRestore(t *testing.T) {
+	deletedAt := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeTrashClient{trash: []api.TrashedProfile{
+		{Name: "work", DeletedAt: deletedAt, PurgeAt: deletedAt.Add(30 * 24 * time.Hour), Extensions: 3},
+	}}
+
+	out, err := runProfileTrash(t, fake, "list")
+	if err != nil {
+		t.Fatalf("profile trash list failed: %v", err)
+	}
+	if !strings.Contains(out, "PURGED AFTER") || !strings.Contains(out, "work") {
+		t.Errorf("expected trashed profile to be listed, got: %s", out)
+	}
+
+	out, err = runProfileTrash(t, fake, "restore", "work")
+	if err != nil {
+		t.Fatalf("profile trash restore failed: %v", err)
+	}
+	if len(fake.restored) != 1 || fake.restored[0] != "work" {
+		t.Errorf("expected work to be restored, got %v", fake.restored)
+	}
+	if !strings.Contains(out, "Restored profile 'work' (3 extensions) on server") {
+		t.Errorf("expected restore to be reported, got: %s", out)
+	}
+
+	out, err = runProfileTrash(t, fake, "list")
+	if err != nil {
+		t.Fatalf("profile trash list failed: %v", err)
+	}
+	if !strings.Contains(out, "Trash is empty.") {
+		t.Errorf("expected empty trash after restore, got: %s", out)
+	}
+}
+
+func TestProfileTrashCommand_RestoreMissing(t *testing.T) {
+	_, err := runProfileTrash(t, &fakeTrashClient{}, "restore", "missing")
+	if err == nil || !strings.Contains(err.Error(), "not found in server trash") {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestProfileTrashCommand_RestoreDryRun(t *testing.T) {
+	fake := &fakeTrashClient{trash: []api.TrashedProfile{{Name: "work"}}}
+
+	out, err := runProfileTrash(t, fake, "restore", "work", "--dry-run")
+	if err != nil {
+		t.Fatalf("profile trash restore --dry-run failed: %v", err)
+	}
+	if len(fake.restored) != 0 {
+		t.Errorf("expected nothing restored with --dry-run, got %v", fake.restored)
+	}
+	if !strings.Contains(out, "Would restore profile 'work' from server trash") {
+		t.Errorf("expected dry-run notice, got: %s", out)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+var profileVerifyAllAgainstInstalled bool
+
+var profileVerifyAllCmd = &cobra.Command{
+	Use:   "verify-all",
+	Short: "Check every local profile for corruption and drift",
+	Long: `Check that every local profile file parses and passes validation. With
+--against-installed, also report profiles that have drifted from the currently
+installed extensions (missing extensions or version mismatches).
+
+Exits with status 1 if any profile is problematic, so it can run periodically
+or in CI.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load config to get profiles directory
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		checks, err := profile.VerifyAll(cfg.Profiles.Directory, profileVerifyAllAgainstInstalled)
+		if err != nil {
+			return fmt.Errorf("failed to verify profiles: %w", err)
+		}
+
+		if len(checks) == 0 {
+			cmd.Printf("No profiles found.\n")
+			return nil
+		}
+
+		problematic := 0
+		for _, c := range checks {
+			switch {
+			case c.Err != nil:
+				problematic++
+				cmd.Printf("  FAIL   %s: %v\n", c.Name, c.Err)
+			case !c.Healthy():
+				problematic++
+				cmd.Printf("  DRIFT  %s: %s\n", c.Name, describeDrift(c.Drift))
+			default:
+				cmd.Printf("  OK     %s\n", c.Name)
+			}
+		}
+
+		cmd.Printf("\n%d healthy, %d problematic (%d profile(s) checked)\n", len(checks)-problematic, problematic, len(checks))
+
+		if problematic > 0 {
+			return silentExit(cmd, exitCodeError)
+		}
+		return nil
+	},
+}
+
+// describeDrift summarizes how a profile differs from installed extensions
+func describeDrift(d *profile.DiffResult) string {
+	var parts []string
+	if n := len(d.ToInstall); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d not installed", n))
+	}
+	if n := len(d.VersionMismatches); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d version mismatch(es)", n))
+	}
+	if n := len(d.Changes.StateChanged); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d enabled state mismatch(es)", n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	profileVerifyAllCmd.Flags().BoolVar(&profileVerifyAllAgainstInstalled, "against-installed", false, "Also report drift from currently installed extensions")
+
+	profileCmd.AddCommand(profileVerifyAllCmd)
+}
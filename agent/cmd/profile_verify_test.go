@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runProfileVerifyAll runs profile verify-all against a profiles directory
+// prepared by setup, with golang.go 0.40.0 installed
+func runProfileVerifyAll(t *testing.T, setup func(profilesDir string), args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv("PATH", "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	writeInstalledExtension(t, tempHome, "golang.go", "0.40.0")
+	setup(profilesDir)
+
+	t.Cleanup(func() {
+		profileVerifyAllAgainstInstalled = false
+		profileVerifyAllCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(profileCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"profile", "verify-all"}, args...))
+
+	err := cmd.Execute()
+	return output.String(), err
+}
+
+func writeProfileFile(t *testing.T, profilesDir, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(profilesDir, file), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+}
+
+func TestProfileVerifyAllCommand_MixedProfiles(t *testing.T) {
+	out, err := runProfileVerifyAll(t, func(dir string) {
+		writeProfileFile(t, dir, "healthy.json", `{"name":"healthy","extensions":[{"id":"golang.go","version":"0.40.0","enabled":true}]}`)
+		writeProfileFile(t, dir, "drifted.json", `{"name":"drifted","extensions":[{"id":"golang.go","version":"0.39.0"},{"id":"ms-python.python","version":"1.0.0"}]}`)
+		writeProfileFile(t, dir, "corrupt.json", `{not json`)
+	}, "--against-installed")
+
+	if err == nil || exitCode(err) != exitCodeError {
+		t.Fatalf("expected exit code %d, got err %v", exitCodeError, err)
+	}
+	for _, want := range []string{
+		"OK     healthy",
+		"DRIFT  drifted: 1 not installed, 1 version mismatch(es)",
+		"FAIL   corrupt.json",
+		"1 healthy, 2 problematic (3 profile(s) checked)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestProfileVerifyAllCommand_StateDrift(t *testing.T) {
+	out, err := runProfileVerifyAll(t, func(dir string) {
+		writeProfileFile(t, dir, "disabled.json", `{"name":"disabled","extensions":[{"id":"golang.go","version":"0.40.0","enabled":false}]}`)
+	}, "--against-installed")
+
+	if exitCode(err) != exitCodeError {
+		t.Fatalf("expected exit code %d, got err %v", exitCodeError, err)
+	}
+	if !strings.Contains(out, "DRIFT  disabled: 1 enabled state mismatch(es)") {
+		t.Errorf("expected state drift to be reported, got:\n%s", out)
+	}
+}
+
+func TestProfileVerifyAllCommand_DriftIgnoredWithoutFlag(t *testing.T) {
+	out, err := runProfileVerifyAll(t, func(dir string) {
+		writeProfileFile(t, dir, "drifted.json", `{"name":"drifted","extensions":[{"id":"ms-python.python","version":"1.0.0"}]}`)
+	})
+	if err != nil {
+		t.Fatalf("expected success without --against-installed, got: %v", err)
+	}
+	if !strings.Contains(out, "1 healthy, 0 problematic") {
+		t.Errorf("expected all profiles healthy, got:\n%s", out)
+	}
+}
+
+func TestProfileVerifyAllCommand_InvalidProfile(t *testing.T) {
+	out, err := runProfileVerifyAll(t, func(dir string) {
+		writeProfileFile(t, dir, "bad.json", `{"name":"bad","extensions":[{"id":"no-publisher","version":"1.0.0"}]}`)
+	})
+	if exitCode(err) != exitCodeError {
+		t.Fatalf("expected exit code %d, got err %v", exitCodeError, err)
+	}
+	if !strings.Contains(out, "FAIL   bad: invalid profile") {
+		t.Errorf("expected validation failure to be reported, got:\n%s", out)
+	}
+}
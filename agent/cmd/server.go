@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var serverCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Inspect the sync server",
+	Long:  "Commands that report on the sync server itself. These require an account with the admin role.",
+}
+
+var serverStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show an overview of the server",
+	Long:  "Show the number of users, active users, profiles, refresh tokens and pending invites on the server.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load config
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		client := newAuthenticatedClient(cfg.Server.URL)
+
+		stats, err := client.ServerStats()
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("Server: %s\n", cfg.Server.URL)
+		cmd.Printf("  %-16s %d\n", "Users:", stats.Users)
+		cmd.Printf("  %-16s %d\n", "Active users:", stats.ActiveUsers)
+		cmd.Printf("  %-16s %d\n", "Profiles:", stats.Profiles)
+		cmd.Printf("  %-16s %d\n", "Refresh tokens:", stats.RefreshTokens)
+		cmd.Printf("  %-16s %d\n", "Pending invites:", stats.PendingInvites)
+
+		return nil
+	},
+}
+
+func init() {
+	serverCmd.AddCommand(serverStatsCmd)
+	rootCmd.AddCommand(serverCmd)
+}
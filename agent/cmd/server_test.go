@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// runServerStats runs server stats against a test server answering with handler
+func runServerStats(t *testing.T, handler http.HandlerFunc) (string, error) {
+	t.Helper()
+	setupMockKeychain(t)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	setupTestConfig(t, tempHome, server.URL, filepath.Join(tempHome, ".devtools-sync", "profiles"))
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(serverCmd)
+
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"server", "stats"})
+
+	err := cmd.Execute()
+	return output.String(), err
+}
+
+func TestServerStatsCommand(t *testing.T) {
+	got, err := runServerStats(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/admin/stats" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]int{
+			"users":           12,
+			"active_users":    9,
+			"profiles":        40,
+			"refresh_tokens":  17,
+			"pending_invites": 3,
+		})
+	})
+	if err != nil {
+		t.Fatalf("server stats failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"Users:           12",
+		"Active users:    9",
+		"Profiles:        40",
+		"Refresh tokens:  17",
+		"Pending invites: 3",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output, got: %s", want, got)
+		}
+	}
+}
+
+func TestServerStatsCommand_Forbidden(t *testing.T) {
+	_, err := runServerStats(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	if err == nil || !strings.Contains(err.Error(), "admin role required") {
+		t.Errorf("expected admin role error, got %v", err)
+	}
+}
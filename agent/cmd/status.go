@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+// statusClient is the part of the API client used by the status command
+type statusClient interface {
+	Health() (*api.HealthResponse, error)
+	ListProfilesDetailed() ([]api.ProfileSummary, error)
+}
+
+// statusProbeTimeout bounds the status command's health check, which is not
+// retried, so an offline server is reported promptly
+const statusProbeTimeout = 3 * time.Second
+
+// statusClientFactory creates the client used by the status command (can be overridden in tests)
+var statusClientFactory = func(serverURL string) statusClient {
+	return &probingStatusClient{
+		AuthenticatedClient: newAuthenticatedClient(serverURL),
+		probe:               newHealthProbeClient(serverURL),
+	}
+}
+
+// newHealthProbeClient creates a client that sends the health check once with
+// a short timeout, instead of retrying with backoff as other requests do
+func newHealthProbeClient(serverURL string) *api.Client {
+	opts := append(clientOptions(), api.WithMaxRetries(0), api.WithTimeout(statusProbeTimeout))
+	return api.NewClient(serverURL, opts...)
+}
+
+// probingStatusClient checks health with the probe client and lists profiles
+// with the authenticated client
+type probingStatusClient struct {
+	*api.AuthenticatedClient
+	probe *api.Client
+}
+
+func (c *probingStatusClient) Health() (*api.HealthResponse, error) {
+	return c.probe.Health()
+}
+
+var statusJSON bool
+
+// statusReport is the combined output of the status command
+type statusReport struct {
+	Server serverStatus `json:"server"`
+	Auth   authStatus   `json:"auth"`
+	Sync   syncStatus   `json:"sync"`
+}
+
+// serverStatus reports whether the server answered its health check
+type serverStatus struct {
+	URL       string `json:"url"`
+	Reachable bool   `json:"reachable"`
+	Status    string `json:"status,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// authStatus reports the stored credentials. The access token is decoded
+// locally, as whoami does.
+type authStatus struct {
+	Authenticated bool   `json:"authenticated"`
+	APIKey        bool   `json:"api_key,omitempty"`
+	Email         string `json:"email,omitempty"`
+	Role          string `json:"role,omitempty"`
+	Expired       bool   `json:"expired,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// syncStatus compares local profiles with the server's by name and update time
+type syncStatus struct {
+	Checked     bool     `json:"checked"`
+	Local       int      `json:"local"`
+	Remote      int      `json:"remote"`
+	LocalOnly   []string `json:"local_only"`
+	RemoteOnly  []string `json:"remote_only"`
+	LocalNewer  []string `json:"local_newer"`
+	RemoteNewer []string `json:"remote_newer"`
+	Skipped     string   `json:"skipped,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// InSync reports whether the sync section was checked and found no differences
+func (s syncStatus) InSync() bool {
+	return s.Checked && len(s.LocalOnly)+len(s.RemoteOnly)+len(s.LocalNewer)+len(s.RemoteNewer) == 0
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show server, login and sync status in one report",
+	Long: `Check whether the server is reachable (and its version), who you are logged in as,
+and how local profiles differ from the server's: profiles only on one side, and profiles
+updated more recently on one side than the other.
+
+Sections that cannot be checked are reported rather than failing the command: sync is
+skipped when the server is unreachable or you are not logged in. The health check is
+sent once, with a 3 second timeout, rather than retried.
+
+With --json, print the report as JSON instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		client := statusClientFactory(cfg.Server.URL)
+
+		report := statusReport{
+			Server: checkServerStatus(client, cfg.Server.URL),
+			Auth:   checkAuthStatus(cfg.Server.URL),
+		}
+		switch {
+		case !report.Server.Reachable:
+			report.Sync = syncStatus{Skipped: "server unreachable"}
+		case !report.Auth.Authenticated:
+			report.Sync = syncStatus{Skipped: "not logged in"}
+		default:
+			report.Sync = checkSyncStatus(client, cfg.Profiles.Directory)
+		}
+
+		if statusJSON {
+			encoder := json.NewEncoder(dataOutput(cmd))
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}
+
+		printStatusReport(cmd, report)
+		return nil
+	},
+}
+
+// checkServerStatus calls the server's health endpoint
+func checkServerStatus(client statusClient, serverURL string) serverStatus {
+	status := serverStatus{URL: serverURL}
+
+	health, err := client.Health()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Reachable = true
+	status.Status = health.Status
+	status.Version = health.Version
+	return status
+}
+
+// checkAuthStatus reads the stored credentials without contacting the server
+func checkAuthStatus(serverURL string) authStatus {
+	if os.Getenv(api.APIKeyEnvVar) != "" {
+		return authStatus{Authenticated: true, APIKey: true}
+	}
+
+	claims, err := newKeychainClient(serverURL).StoredTokenClaims()
+	if err != nil {
+		return authStatus{Error: err.Error()}
+	}
+
+	return authStatus{
+		Authenticated: true,
+		Email:         claims.Email,
+		Role:          claims.Role,
+		Expired:       claims.Expired(now()),
+	}
+}
+
+// checkSyncStatus compares the local profiles with the server's. Names are
+// matched case-insensitively, as the server does.
+func checkSyncStatus(client statusClient, profilesDir string) syncStatus {
+	local, err := profile.List(profilesDir)
+	if err != nil {
+		return syncStatus{Error: fmt.Sprintf("failed to list local profiles: %v", err)}
+	}
+
+	remote, err := client.ListProfilesDetailed()
+	if err != nil {
+		return syncStatus{Error: fmt.Sprintf("failed to list server profiles: %v", err)}
+	}
+
+	status := syncStatus{
+		Checked:     true,
+		Local:       len(local),
+		Remote:      len(remote),
+		LocalOnly:   []string{},
+		RemoteOnly:  []string{},
+		LocalNewer:  []string{},
+		RemoteNewer: []string{},
+	}
+
+	remoteByName := make(map[string]api.ProfileSummary, len(remote))
+	for _, r := range remote {
+		remoteByName[strings.ToLower(r.Name)] = r
+	}
+
+	for _, l := range local {
+		key := strings.ToLower(l.Name)
+		r, ok := remoteByName[key]
+		if !ok {
+			status.LocalOnly = append(status.LocalOnly, l.Name)
+			continue
+		}
+		delete(remoteByName, key)
+
+		// The server may store timestamps at a different precision
+		localUpdated := l.UpdatedAt.Truncate(time.Second)
+		remoteUpdated := r.UpdatedAt.Truncate(time.Second)
+		switch {
+		case localUpdated.After(remoteUpdated):
+			status.LocalNewer = append(status.LocalNewer, l.Name)
+		case remoteUpdated.After(localUpdated):
+			status.RemoteNewer = append(status.RemoteNewer, l.Name)
+		}
+	}
+	for _, r := range remoteByName {
+		status.RemoteOnly = append(status.RemoteOnly, r.Name)
+	}
+
+	sort.Strings(status.LocalOnly)
+	sort.Strings(status.RemoteOnly)
+	sort.Strings(status.LocalNewer)
+	sort.Strings(status.RemoteNewer)
+	return status
+}
+
+// printStatusReport displays a status report as text
+func printStatusReport(cmd *cobra.Command, report statusReport) {
+	server := report.Server
+	switch {
+	case !server.Reachable:
+		cmd.Printf("Server:  %s (unreachable: %s)\n", server.URL, server.Error)
+	case server.Version != "":
+		cmd.Printf("Server:  %s (%s, version %s)\n", server.URL, server.Status, server.Version)
+	default:
+		cmd.Printf("Server:  %s (%s)\n", server.URL, server.Status)
+	}
+
+	auth := report.Auth
+	switch {
+	case auth.APIKey:
+		cmd.Printf("Auth:    using the API key from %s\n", api.APIKeyEnvVar)
+	case !auth.Authenticated:
+		cmd.Printf("Auth:    not logged in (run 'devtools-sync login')\n")
+	case auth.Expired:
+		cmd.Printf("Auth:    logged in as %s (%s); the access token has expired and is renewed on the next request\n", auth.Email, auth.Role)
+	default:
+		cmd.Printf("Auth:    logged in as %s (%s)\n", auth.Email, auth.Role)
+	}
+
+	sync := report.Sync
+	switch {
+	case sync.Skipped != "":
+		cmd.Printf("Sync:    skipped (%s)\n", sync.Skipped)
+		return
+	case sync.Error != "":
+		cmd.Printf("Sync:    %s\n", sync.Error)
+		return
+	case sync.InSync():
+		cmd.Printf("Sync:    in sync (%d local, %d on server)\n", sync.Local, sync.Remote)
+		return
+	}
+
+	cmd.Printf("Sync:    out of sync (%d local, %d on server)\n", sync.Local, sync.Remote)
+	printStatusNames(cmd, "Only local:", sync.LocalOnly)
+	printStatusNames(cmd, "Only on server:", sync.RemoteOnly)
+	printStatusNames(cmd, "Newer locally:", sync.LocalNewer)
+	printStatusNames(cmd, "Newer on server:", sync.RemoteNewer)
+}
+
+// printStatusNames prints one line of profile names, skipping empty lists
+func printStatusNames(cmd *cobra.Command, label string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	cmd.Printf("  %-16s %s\n", label, strings.Join(names, ", "))
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the report as JSON")
+
+	rootCmd.AddCommand(statusCmd)
+}
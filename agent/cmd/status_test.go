@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/mark-chris/devtools-sync/agent/internal/keychain"
+	"github.com/mark-chris/devtools-sync/agent/internal/profile"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// fakeStatusClient is a statusClient with canned responses
+type fakeStatusClient struct {
+	health    *api.HealthResponse
+	healthErr error
+	profiles  []api.ProfileSummary
+	listErr   error
+	listed    bool
+}
+
+func (f *fakeStatusClient) Health() (*api.HealthResponse, error) {
+	return f.health, f.healthErr
+}
+
+func (f *fakeStatusClient) ListProfilesDetailed() ([]api.ProfileSummary, error) {
+	f.listed = true
+	return f.profiles, f.listErr
+}
+
+// statusTestTime is when the local test profiles were last updated
+var statusTestTime = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeStatusProfiles writes local profiles named work, home and laptop, all
+// updated at statusTestTime
+func writeStatusProfiles(t *testing.T, profilesDir string) {
+	t.Helper()
+	for _, name := range []string{"work", "home", "laptop"} {
+		data, err := json.Marshal(profile.Profile{
+			Name:       name,
+			UpdatedAt:  statusTestTime,
+			Extensions: []profile.Extension{{ID: "golang.go", Version: "1.0.0", Enabled: true}},
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal profile: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(profilesDir, name+".json"), data, 0644); err != nil {
+			t.Fatalf("failed to write profile: %v", err)
+		}
+	}
+}
+
+// divergedRemote is the server's view of writeStatusProfiles: work matches,
+// home is older on the server, laptop is missing and desk is only remote
+func divergedRemote() []api.ProfileSummary {
+	return []api.ProfileSummary{
+		{Name: "work", UpdatedAt: statusTestTime},
+		{Name: "Home", UpdatedAt: statusTestTime.Add(-time.Hour)},
+		{Name: "desk", UpdatedAt: statusTestTime},
+	}
+}
+
+// runStatus runs status with args against client, with token stored in a
+// mock keychain ("" stores none)
+func runStatus(t *testing.T, client *fakeStatusClient, token string, args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv(api.APIKeyEnvVar, "")
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	writeStatusProfiles(t, profilesDir)
+
+	mockKC := keychain.NewMockKeychain()
+	if token != "" {
+		_ = mockKC.Set(keychain.KeyAccessToken, token)
+	}
+	origKeychain := keychainFactory
+	keychainFactory = func() keychain.Keychain { return mockKC }
+	origClient := statusClientFactory
+	statusClientFactory = func(serverURL string) statusClient { return client }
+	origNow := now
+	now = func() time.Time { return statusTestTime.Add(5 * time.Minute) }
+	t.Cleanup(func() {
+		keychainFactory = origKeychain
+		statusClientFactory = origClient
+		now = origNow
+		statusJSON = false
+		statusCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(statusCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"status"}, args...))
+
+	err := cmd.Execute()
+	return output.String(), err
+}
+
+func TestStatusCommand_Healthy(t *testing.T) {
+	client := &fakeStatusClient{
+		health:   &api.HealthResponse{Status: "healthy", Service: "devtools-sync-server", Version: "0.1.0"},
+		profiles: divergedRemote(),
+	}
+
+	out, err := runStatus(t, client, whoamiTestToken)
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"Server:  http://localhost:8080 (healthy, version 0.1.0)",
+		"Auth:    logged in as dev@example.com (admin)",
+		"Sync:    out of sync (3 local, 3 on server)",
+		"Only local:      laptop",
+		"Only on server:  desk",
+		"Newer locally:   home",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Newer on server:") {
+		t.Errorf("expected no profiles newer on server, got:\n%s", out)
+	}
+}
+
+func TestStatusCommand_HealthyInSync(t *testing.T) {
+	client := &fakeStatusClient{
+		health: &api.HealthResponse{Status: "healthy"},
+		profiles: []api.ProfileSummary{
+			{Name: "work", UpdatedAt: statusTestTime},
+			{Name: "home", UpdatedAt: statusTestTime},
+			{Name: "laptop", UpdatedAt: statusTestTime},
+		},
+	}
+
+	out, err := runStatus(t, client, whoamiTestToken)
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if !strings.Contains(out, "Sync:    in sync (3 local, 3 on server)") {
+		t.Errorf("expected in sync report, got:\n%s", out)
+	}
+}
+
+func TestStatusCommand_JSON(t *testing.T) {
+	client := &fakeStatusClient{
+		health:   &api.HealthResponse{Status: "healthy", Version: "0.1.0"},
+		profiles: divergedRemote(),
+	}
+
+	out, err := runStatus(t, client, whoamiTestToken, "--json")
+	if err != nil {
+		t.Fatalf("status --json failed: %v", err)
+	}
+
+	var report statusReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+	}
+	if !report.Server.Reachable || report.Server.Version != "0.1.0" {
+		t.Errorf("unexpected server section: %+v", report.Server)
+	}
+	if !report.Auth.Authenticated || report.Auth.Email != "dev@example.com" {
+		t.Errorf("unexpected auth section: %+v", report.Auth)
+	}
+	if !report.Sync.Checked || !reflect.DeepEqual(report.Sync.RemoteOnly, []string{"desk"}) {
+		t.Errorf("unexpected sync section: %+v", report.Sync)
+	}
+}
+
+func TestStatusCommand_Unauthenticated(t *testing.T) {
+	client := &fakeStatusClient{
+		health:   &api.HealthResponse{Status: "healthy"},
+		profiles: divergedRemote(),
+	}
+
+	out, err := runStatus(t, client, "")
+	if err != nil {
+		t.Fatalf("status should not fail when logged out: %v", err)
+	}
+
+	if !strings.Contains(out, "Auth:    not logged in (run 'devtools-sync login')") {
+		t.Errorf("expected not logged in report, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Sync:    skipped (not logged in)") {
+		t.Errorf("expected sync to be skipped, got:\n%s", out)
+	}
+	if client.listed {
+		t.Error("server profiles should not be listed when logged out")
+	}
+}
+
+func TestStatusCommand_Offline(t *testing.T) {
+	client := &fakeStatusClient{
+		healthErr: errors.New("server unreachable: connection refused"),
+	}
+
+	out, err := runStatus(t, client, whoamiTestToken, "--json")
+	if err != nil {
+		t.Fatalf("status should not fail when offline: %v", err)
+	}
+
+	var report statusReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+	}
+	if report.Server.Reachable || !strings.Contains(report.Server.Error, "connection refused") {
+		t.Errorf("expected an unreachable server, got: %+v", report.Server)
+	}
+	// Credentials are local, so they are still reported
+	if !report.Auth.Authenticated {
+		t.Errorf("expected stored credentials to be reported, got: %+v", report.Auth)
+	}
+	if report.Sync.Checked || report.Sync.Skipped != "server unreachable" {
+		t.Errorf("expected sync to be skipped, got: %+v", report.Sync)
+	}
+	if client.listed {
+		t.Error("server profiles should not be listed when the server is unreachable")
+	}
+}
+
+func TestCheckSyncStatus_ListError(t *testing.T) {
+	dir := t.TempDir()
+	writeStatusProfiles(t, dir)
+
+	status := checkSyncStatus(&fakeStatusClient{listErr: errors.New("server returned status 500")}, dir)
+	if status.Checked {
+		t.Error("expected the sync section not to be checked")
+	}
+	if !strings.Contains(status.Error, "status 500") {
+		t.Errorf("expected the list error to be reported, got: %q", status.Error)
+	}
+}
+
+func TestCheckSyncStatus_NewerOnServer(t *testing.T) {
+	dir := t.TempDir()
+	writeStatusProfiles(t, dir)
+
+	status := checkSyncStatus(&fakeStatusClient{profiles: []api.ProfileSummary{
+		{Name: "work", UpdatedAt: statusTestTime.Add(time.Hour)},
+		// Sub-second differences are precision, not edits
+		{Name: "home", UpdatedAt: statusTestTime.Add(300 * time.Millisecond)},
+		{Name: "laptop", UpdatedAt: statusTestTime},
+	}}, dir)
+
+	if !reflect.DeepEqual(status.RemoteNewer, []string{"work"}) {
+		t.Errorf("RemoteNewer = %v, want [work]", status.RemoteNewer)
+	}
+	if len(status.LocalNewer) != 0 || len(status.LocalOnly) != 0 || len(status.RemoteOnly) != 0 {
+		t.Errorf("unexpected differences: %+v", status)
+	}
+}
+
+func TestStatusClientFactory_HealthIsNotRetried(t *testing.T) {
+	t.Setenv(api.APIKeyEnvVar, "dts_test")
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if _, err := statusClientFactory(server.URL).Health(); err == nil {
+		t.Fatal("expected an unhealthy server to be reported")
+	}
+	if attempts != 1 {
+		t.Errorf("expected the health check to be sent once, got %d attempts", attempts)
+	}
+}
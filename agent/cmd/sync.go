@@ -2,10 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark-chris/devtools-sync/agent/internal/api"
 	"github.com/mark-chris/devtools-sync/agent/internal/config"
@@ -19,11 +23,89 @@ var syncCmd = &cobra.Command{
 	Long:  "Push local profiles to server or pull profiles from server",
 }
 
+// syncClient is the part of the API client used by sync push and pull
+type syncClient interface {
+	remoteProfileDeleter
+	UploadProfileWithOptions(profile *api.Profile, opts api.UploadOptions) (*api.UploadResult, error)
+	DownloadProfile(name string) (*api.Profile, error)
+	ListProfilesModifiedSince(since time.Time) ([]api.ProfileSummary, error)
+}
+
+// syncClientFactory creates the client used by sync commands (can be overridden in tests)
+var syncClientFactory = func(serverURL string) syncClient {
+	return newAuthenticatedClient(serverURL)
+}
+
+// defaultSyncParallel is the default number of concurrent uploads or downloads
+const defaultSyncParallel = 4
+
+var (
+	syncPushCompressThreshold   int
+	syncPushDeleteRemoteMissing bool
+	syncPushYes                 bool
+	syncPushParallel            int
+	syncPushTag                 string
+	syncPullParallel            int
+	syncPullForce               bool
+	syncPullFull                bool
+)
+
+// runParallel calls fn for each index in [0, n) using up to parallel
+// concurrent workers; parallel of 0 or 1 runs them one at a time in order
+func runParallel(n, parallel int, fn func(i int)) {
+	if parallel <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
 var syncPushCmd = &cobra.Command{
 	Use:   "push",
 	Short: "Push profiles to server",
-	Long:  "Upload all local profiles to the server",
+	Long: `Upload all local profiles to the server. Uploads of at least --compress-threshold bytes are gzip-compressed.
+
+With --delete-remote-missing, server profiles that were pushed from this machine but no longer exist
+locally are deleted, mirroring local deletions. Profiles pushed from other machines are never deleted.
+Deletion requires --yes.
+
+Up to --parallel profiles are uploaded at once; results are reported in profile order.
+
+With --tag, the server version created by each upload is labeled with the tag (e.g. stable or v2025.1).
+A tag names the newest version carrying it, so pushing with an existing tag moves it forward.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if syncPushCompressThreshold < 0 {
+			return fmt.Errorf("--compress-threshold must be zero or positive, got %d", syncPushCompressThreshold)
+		}
+		if syncPushParallel < 0 {
+			return fmt.Errorf("--parallel must be zero or positive, got %d", syncPushParallel)
+		}
+		syncPushTag = strings.TrimSpace(syncPushTag)
+		if cmd.Flags().Changed("tag") {
+			if err := api.ValidateTag(syncPushTag); err != nil {
+				return fmt.Errorf("invalid --tag: %w", err)
+			}
+		}
+
 		// Load config
 		cfg, err := config.Load()
 		if err != nil {
@@ -31,8 +113,7 @@ var syncPushCmd = &cobra.Command{
 		}
 
 		// Create authenticated client
-		kc := keychainFactory()
-		client := api.NewAuthenticatedClient(cfg.Server.URL, kc)
+		client := syncClientFactory(cfg.Server.URL)
 
 		// List local profiles
 		profiles, err := profile.List(cfg.Profiles.Directory)
@@ -40,46 +121,279 @@ var syncPushCmd = &cobra.Command{
 			return fmt.Errorf("failed to list local profiles: %w", err)
 		}
 
-		if len(profiles) == 0 {
+		if len(profiles) == 0 && !syncPushDeleteRemoteMissing {
 			cmd.Println("No profiles to push")
 			return nil
 		}
 
-		// Push each profile
+		// Profiles previously pushed from this machine, the only ones mirror mode may delete
+		pushedFromHere, err := loadPushedProfiles(cfg.Server.URL)
+		if err != nil {
+			return err
+		}
+
+		// Upload profiles concurrently, keeping each outcome in profile order
+		results := make([]*api.UploadResult, len(profiles))
+		errs := make([]error, len(profiles))
+		runParallel(len(profiles), syncPushParallel, func(i int) {
+			results[i], errs[i] = pushProfile(client, &profiles[i])
+		})
+
 		pushed := make([]string, 0)
 		failed := make([]string, 0)
+		var failures []error
 
-		for _, prof := range profiles {
-			// Convert to API profile
-			apiProfile := convertToAPIProfile(&prof)
-
-			// Upload to server with authentication
-			if err := client.UploadProfile(apiProfile); err != nil {
-				cmd.Printf("Failed to push profile '%s': %v\n", prof.Name, err)
+		for i, prof := range profiles {
+			if errs[i] != nil {
+				cmd.PrintErrf("Failed to push profile '%s': %v\n", prof.Name, errs[i])
 				failed = append(failed, prof.Name)
+				failures = append(failures, errs[i])
 				continue
 			}
+			if results[i].Compressed {
+				cmd.Printf("Compressed '%s': %s -> %s\n", prof.Name, formatBytes(results[i].RawSize), formatBytes(results[i].UploadSize))
+			}
+			if syncPushTag != "" {
+				if results[i].Version > 0 {
+					cmd.Printf("Tagged '%s' version %d as '%s'\n", prof.Name, results[i].Version, syncPushTag)
+				} else {
+					cmd.Printf("Tagged '%s' as '%s'\n", prof.Name, syncPushTag)
+				}
+			}
 
 			pushed = append(pushed, prof.Name)
+			pushedFromHere[prof.Name] = true
 		}
 
 		// Report results
 		if len(pushed) > 0 {
 			cmd.Printf("Pushed %d profile(s): %v\n", len(pushed), pushed)
 		}
+
+		var mirrorErr error
+		if syncPushDeleteRemoteMissing {
+			mirrorErr = deleteRemoteMissing(cmd, client, profiles, pushedFromHere)
+		}
+		if err := savePushedProfiles(cfg.Server.URL, pushedFromHere); err != nil {
+			return err
+		}
 		if len(failed) > 0 {
-			cmd.Printf("Failed to push %d profile(s): %v\n", len(failed), failed)
+			return fmt.Errorf("failed to push %d profile(s) %v: %w", len(failed), failed, errors.Join(failures...))
 		}
+		return mirrorErr
+	},
+}
+
+// pushProfile uploads one local profile. The server rejects duplicate
+// extension entries, but the upload normalizes them away first (see
+// vscode.NormalizeExtensions), keeping the highest version of each.
+func pushProfile(client syncClient, prof *profile.Profile) (*api.UploadResult, error) {
+	// Upload to server with authentication
+	return client.UploadProfileWithOptions(convertToAPIProfile(prof), api.UploadOptions{
+		CompressThreshold: syncPushCompressThreshold,
+		Tag:               syncPushTag,
+	})
+}
 
+// remoteProfileDeleter is the part of the API client used by push mirror mode
+type remoteProfileDeleter interface {
+	ListProfiles() ([]string, error)
+	DeleteProfile(name string) error
+}
+
+// deleteRemoteMissing deletes server profiles that were pushed from this
+// machine but no longer exist locally. Remote profiles this machine never
+// pushed are left alone. Deleted names are removed from pushedFromHere.
+func deleteRemoteMissing(cmd *cobra.Command, client remoteProfileDeleter, local []profile.Profile, pushedFromHere map[string]bool) error {
+	remote, err := client.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to list server profiles: %w", err)
+	}
+
+	localNames := make(map[string]bool, len(local))
+	for _, p := range local {
+		localNames[p.Name] = true
+	}
+
+	var toDelete, kept []string
+	for _, name := range remote {
+		if localNames[name] {
+			continue
+		}
+		if pushedFromHere[name] {
+			toDelete = append(toDelete, name)
+		} else {
+			kept = append(kept, name)
+		}
+	}
+
+	if len(kept) > 0 {
+		cmd.Printf("Keeping %d remote profile(s) not pushed from this machine: %v\n", len(kept), kept)
+	}
+	if len(toDelete) == 0 {
 		return nil
-	},
+	}
+
+	if !syncPushYes {
+		cmd.Printf("Would delete %d remote profile(s) missing locally: %v\n", len(toDelete), toDelete)
+		return fmt.Errorf("refusing to delete remote profiles without confirmation\n\nRe-run with --yes to delete them")
+	}
+
+	deleted := make([]string, 0, len(toDelete))
+	failed := make([]string, 0)
+	var failures []error
+	for _, name := range toDelete {
+		if err := client.DeleteProfile(name); err != nil {
+			cmd.PrintErrf("Failed to delete remote profile '%s': %v\n", name, err)
+			failed = append(failed, name)
+			failures = append(failures, err)
+			continue
+		}
+		delete(pushedFromHere, name)
+		deleted = append(deleted, name)
+	}
+
+	if len(deleted) > 0 {
+		cmd.Printf("Deleted %d remote profile(s): %v\n", len(deleted), deleted)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete %d remote profile(s) %v: %w", len(failed), failed, errors.Join(failures...))
+	}
+
+	return nil
+}
+
+// pushedProfilesPath returns the file recording which profiles this machine
+// has pushed, per server URL
+func pushedProfilesPath() string {
+	return filepath.Join(config.GetConfigDir(), "pushed-profiles.json")
+}
+
+// loadPushedProfiles returns the names of profiles pushed from this machine to serverURL
+func loadPushedProfiles(serverURL string) (map[string]bool, error) {
+	pushed := make(map[string]bool)
+
+	data, err := os.ReadFile(pushedProfilesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pushed, nil
+		}
+		return nil, fmt.Errorf("failed to read pushed profiles record: %w", err)
+	}
+
+	var record map[string][]string
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse pushed profiles record: %w", err)
+	}
+	for _, name := range record[serverURL] {
+		pushed[name] = true
+	}
+
+	return pushed, nil
+}
+
+// savePushedProfiles records the names of profiles pushed from this machine to serverURL
+func savePushedProfiles(serverURL string, pushed map[string]bool) error {
+	record := make(map[string][]string)
+	if data, err := os.ReadFile(pushedProfilesPath()); err == nil {
+		_ = json.Unmarshal(data, &record)
+	}
+
+	names := make([]string, 0, len(pushed))
+	for name := range pushed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	record[serverURL] = names
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pushed profiles record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(pushedProfilesPath()), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(pushedProfilesPath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write pushed profiles record: %w", err)
+	}
+
+	return nil
+}
+
+// summaryModifiedAt returns when the server last modified a profile. The
+// cursor must come from the server's clock: UpdatedAt is set by whichever
+// client uploaded the profile. Servers without ModifiedAt filter on
+// UpdatedAt, so it is the cursor for them.
+func summaryModifiedAt(summary api.ProfileSummary) time.Time {
+	if summary.ModifiedAt.IsZero() {
+		return summary.UpdatedAt
+	}
+	return summary.ModifiedAt
+}
+
+func lastPullPath() string {
+	return filepath.Join(config.GetConfigDir(), "last-pull.json")
+}
+
+// loadLastPull returns the last-pull cursor for serverURL: the newest server
+// modification time seen by a completed pull, or the zero time if there is none
+func loadLastPull(serverURL string) (time.Time, error) {
+	data, err := os.ReadFile(lastPullPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to read last pull record: %w", err)
+	}
+
+	var record map[string]time.Time
+	if err := json.Unmarshal(data, &record); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse last pull record: %w", err)
+	}
+
+	return record[serverURL], nil
+}
+
+// saveLastPull records the last-pull cursor for serverURL
+func saveLastPull(serverURL string, cursor time.Time) error {
+	record := make(map[string]time.Time)
+	if data, err := os.ReadFile(lastPullPath()); err == nil {
+		_ = json.Unmarshal(data, &record)
+	}
+	record[serverURL] = cursor.UTC()
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal last pull record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(lastPullPath()), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(lastPullPath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write last pull record: %w", err)
+	}
+
+	return nil
 }
 
 var syncPullCmd = &cobra.Command{
 	Use:   "pull",
 	Short: "Pull profiles from server",
-	Long:  "Download profiles from the server to local storage",
+	Long: `Download profiles from the server to local storage. Up to --parallel profiles
+are downloaded at once; results are reported in profile order.
+
+Local profiles updated more recently than the server copy are skipped unless
+--force is given, in which case the server copy always overwrites them.
+
+Only profiles updated on the server since the last successful pull are
+downloaded. Use --full to consider every server profile again, for example to
+restore deleted local copies. --force implies --full, so a local copy edited
+since the last pull is overwritten even if the server copy has not changed.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if syncPullParallel < 0 {
+			return fmt.Errorf("--parallel must be zero or positive, got %d", syncPullParallel)
+		}
+
 		// Load config
 		cfg, err := config.Load()
 		if err != nil {
@@ -87,11 +401,19 @@ var syncPullCmd = &cobra.Command{
 		}
 
 		// Create authenticated client
-		kc := keychainFactory()
-		client := api.NewAuthenticatedClient(cfg.Server.URL, kc)
+		client := syncClientFactory(cfg.Server.URL)
+
+		// Fetch only what changed since the last complete pull. --force must
+		// also reach profiles the server has not changed since then.
+		var since time.Time
+		if !syncPullFull && !syncPullForce {
+			if since, err = loadLastPull(cfg.Server.URL); err != nil {
+				return err
+			}
+		}
 
 		// List server profiles
-		serverProfiles, err := client.ListProfiles()
+		summaries, err := client.ListProfilesModifiedSince(since)
 		if err != nil {
 			if strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "no such host") {
 				return fmt.Errorf("failed to connect to server at %s: %w\n\nMake sure:\n  1. The server is running\n  2. The server URL is correct (check with 'devtools-sync config show')\n  3. You can reach the server from your network", cfg.Server.URL, err)
@@ -99,88 +421,169 @@ var syncPullCmd = &cobra.Command{
 			return fmt.Errorf("failed to list server profiles: %w\n\nCheck your server connection with:\n  curl %s/health", err, cfg.Server.URL)
 		}
 
-		if len(serverProfiles) == 0 {
-			cmd.Println("No profiles on server")
+		if len(summaries) == 0 {
+			if since.IsZero() {
+				cmd.Println("No profiles on server")
+			} else {
+				cmd.Println("No profiles changed on server since the last pull")
+			}
 			return nil
 		}
 
-		pulled := make([]string, 0)
-		skipped := make([]string, 0)
-		failed := make([]string, 0)
-
-		// Download each profile
-		for _, name := range serverProfiles {
-			// Download from server with authentication
-			apiProfile, err := client.DownloadProfile(name)
-			if err != nil {
-				cmd.Printf("Failed to download profile '%s': %v\n", name, err)
-				failed = append(failed, name)
-				continue
+		serverProfiles := make([]string, len(summaries))
+		cursor := since
+		for i, summary := range summaries {
+			serverProfiles[i] = summary.Name
+			if modified := summaryModifiedAt(summary); modified.After(cursor) {
+				cursor = modified
 			}
+		}
 
-			// Check if local profile exists and is newer
-			localProfilePath := filepath.Join(cfg.Profiles.Directory, name+".json")
-			if _, err := os.Stat(localProfilePath); err == nil {
-				// Local profile exists, check if it's newer
-				localProfile, err := profile.Get(name, cfg.Profiles.Directory)
-				if err == nil && localProfile.UpdatedAt.After(apiProfile.UpdatedAt) {
-					cmd.Printf("Skipping '%s' (local version is newer)\n", name)
-					skipped = append(skipped, name)
-					continue
-				}
-			}
+		// Download profiles concurrently, keeping each outcome in profile order
+		outcomes := make([]pullOutcome, len(serverProfiles))
+		runParallel(len(serverProfiles), syncPullParallel, func(i int) {
+			outcomes[i] = pullProfile(client, serverProfiles[i], cfg.Profiles.Directory, syncPullForce)
+		})
 
-			// Convert to local profile and save
-			localProfile := convertToLocalProfile(apiProfile)
+		pulled := make([]string, 0)
+		skipped := make([]string, 0)
+		forced := make([]string, 0)
+		failed := make([]string, 0)
+		var failures []error
 
-			// Save profile to disk
-			if err := saveProfile(localProfile, cfg.Profiles.Directory); err != nil {
-				cmd.Printf("Failed to save profile '%s': %v\n", name, err)
+		for i, name := range serverProfiles {
+			outcome := outcomes[i]
+			switch {
+			case outcome.err != nil:
+				cmd.PrintErrf("Failed to %s profile '%s': %v\n", outcome.failedStep, name, outcome.err)
 				failed = append(failed, name)
-				continue
+				failures = append(failures, outcome.err)
+			case outcome.skipped:
+				cmd.Printf("Skipping '%s' (local version is newer)\n", name)
+				skipped = append(skipped, name)
+			case outcome.forced:
+				cmd.Printf("Forcing '%s' (overwriting newer local version)\n", name)
+				forced = append(forced, name)
+				pulled = append(pulled, name)
+			default:
+				pulled = append(pulled, name)
 			}
-
-			pulled = append(pulled, name)
 		}
 
 		// Report results
 		if len(pulled) > 0 {
 			cmd.Printf("Pulled %d profile(s): %v\n", len(pulled), pulled)
 		}
+		if len(forced) > 0 {
+			cmd.Printf("Forced %d profile(s) (overwrote newer local): %v\n", len(forced), forced)
+		}
 		if len(skipped) > 0 {
 			cmd.Printf("Skipped %d profile(s) (local is newer): %v\n", len(skipped), skipped)
 		}
 		if len(failed) > 0 {
-			cmd.Printf("Failed %d profile(s): %v\n", len(failed), failed)
+			// Keep the old cursor so failed profiles are fetched again
+			return fmt.Errorf("failed to pull %d profile(s) %v: %w", len(failed), failed, errors.Join(failures...))
 		}
 
-		return nil
+		return saveLastPull(cfg.Server.URL, cursor)
 	},
 }
 
+// pullOutcome is the result of pulling one server profile
+type pullOutcome struct {
+	skipped    bool
+	forced     bool
+	failedStep string
+	err        error
+}
+
+// pullProfile downloads one server profile and saves it locally unless the
+// local copy is newer. With force a newer local copy is overwritten and the
+// outcome is marked forced.
+func pullProfile(client syncClient, name, profilesDir string, force bool) pullOutcome {
+	// Download from server with authentication
+	apiProfile, err := client.DownloadProfile(name)
+	if err != nil {
+		return pullOutcome{failedStep: "download", err: err}
+	}
+
+	// Check if local profile exists and is newer
+	var forced bool
+	localProfilePath := filepath.Join(profilesDir, name+".json")
+	if _, err := os.Stat(localProfilePath); err == nil {
+		// Local profile exists, check if it's newer
+		localProfile, err := profile.Get(name, profilesDir)
+		if err == nil && localProfile.UpdatedAt.After(apiProfile.UpdatedAt) {
+			if !force {
+				return pullOutcome{skipped: true}
+			}
+			forced = true
+		}
+	}
+
+	// Convert to local profile and save to disk
+	if err := saveProfile(convertToLocalProfile(apiProfile), profilesDir); err != nil {
+		return pullOutcome{failedStep: "save", err: err}
+	}
+
+	return pullOutcome{forced: forced}
+}
+
 func init() {
+	syncPushCmd.Flags().BoolVar(&syncPushDeleteRemoteMissing, "delete-remote-missing", false, "Delete server profiles pushed from this machine that no longer exist locally")
+	syncPushCmd.Flags().BoolVar(&syncPushYes, "yes", false, "Confirm deletions made by --delete-remote-missing")
+	syncPushCmd.Flags().IntVar(&syncPushCompressThreshold, "compress-threshold", api.DefaultCompressThreshold, "Gzip-compress uploads of at least this many bytes (0 disables compression)")
+
+	syncPushCmd.Flags().StringVar(&syncPushTag, "tag", "", "Label the server version created by each upload (letters, digits, '.', '_' and '-')")
+	syncPushCmd.Flags().IntVar(&syncPushParallel, "parallel", defaultSyncParallel, "Number of profiles to upload concurrently (0 or 1 uploads one at a time)")
+	syncPullCmd.Flags().IntVar(&syncPullParallel, "parallel", defaultSyncParallel, "Number of profiles to download concurrently (0 or 1 downloads one at a time)")
+	syncPullCmd.Flags().BoolVar(&syncPullFull, "full", false, "Consider every server profile, not just those updated since the last pull")
+	syncPullCmd.Flags().BoolVar(&syncPullForce, "force", false, "Overwrite local profiles with the server copy even if the local copy is newer (implies --full)")
+
 	syncCmd.AddCommand(syncPushCmd)
 	syncCmd.AddCommand(syncPullCmd)
 	rootCmd.AddCommand(syncCmd)
 }
 
+// formatBytes renders a byte count for display, e.g. "512 B" or "4.2 KB"
+func formatBytes(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
 // Helper functions to convert between local and API profile types
 
 func convertToAPIProfile(p *profile.Profile) *api.Profile {
 	extensions := make([]api.Extension, len(p.Extensions))
 	for i, ext := range p.Extensions {
 		extensions[i] = api.Extension{
-			ID:      ext.ID,
-			Version: ext.Version,
-			Enabled: ext.Enabled,
+			ID:       ext.ID,
+			Version:  ext.Version,
+			Enabled:  ext.Enabled,
+			Required: ext.Required,
+
+			VersionConstraint: ext.VersionConstraint,
+
+			DisplayName: ext.DisplayName,
+			Publisher:   ext.Publisher,
+			Description: ext.Description,
 		}
 	}
 
 	return &api.Profile{
-		Name:       p.Name,
-		CreatedAt:  p.CreatedAt,
-		UpdatedAt:  p.UpdatedAt,
-		Extensions: extensions,
+		Name:        p.Name,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		Extensions:  extensions,
+		Description: p.Description,
+		Settings:    p.Settings,
+		Keybindings: p.Keybindings,
 	}
 }
 
@@ -188,17 +591,27 @@ func convertToLocalProfile(p *api.Profile) *profile.Profile {
 	extensions := make([]profile.Extension, len(p.Extensions))
 	for i, ext := range p.Extensions {
 		extensions[i] = profile.Extension{
-			ID:      ext.ID,
-			Version: ext.Version,
-			Enabled: ext.Enabled,
+			ID:       ext.ID,
+			Version:  ext.Version,
+			Enabled:  ext.Enabled,
+			Required: ext.Required,
+
+			VersionConstraint: ext.VersionConstraint,
+
+			DisplayName: ext.DisplayName,
+			Publisher:   ext.Publisher,
+			Description: ext.Description,
 		}
 	}
 
 	return &profile.Profile{
-		Name:       p.Name,
-		CreatedAt:  p.CreatedAt,
-		UpdatedAt:  p.UpdatedAt,
-		Extensions: extensions,
+		Name:        p.Name,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		Extensions:  extensions,
+		Description: p.Description,
+		Settings:    p.Settings,
+		Keybindings: p.Keybindings,
 	}
 }
 
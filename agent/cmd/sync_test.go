@@ -2,12 +2,18 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -15,6 +21,7 @@ import (
 	"github.com/mark-chris/devtools-sync/agent/internal/keychain"
 	"github.com/mark-chris/devtools-sync/agent/internal/profile"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // setupMockKeychain configures the keychainFactory for tests with a pre-authenticated token
@@ -161,7 +168,7 @@ func TestSyncPullCommand_NoProfiles(t *testing.T) {
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode([]string{}); err != nil {
+		if err := json.NewEncoder(w).Encode([]api.ProfileSummary{}); err != nil {
 			t.Fatalf("failed to encode response: %v", err)
 		}
 	}))
@@ -214,7 +221,7 @@ func TestSyncPullCommand_WithProfiles(t *testing.T) {
 
 		if r.URL.Path == "/api/v1/profiles" {
 			// List profiles
-			if err := json.NewEncoder(w).Encode([]string{"test-profile"}); err != nil {
+			if err := json.NewEncoder(w).Encode([]api.ProfileSummary{{Name: "test-profile", UpdatedAt: time.Now()}}); err != nil {
 				t.Fatalf("failed to encode response: %v", err)
 			}
 		} else if strings.HasPrefix(r.URL.Path, "/api/v1/profiles/") {
@@ -287,7 +294,7 @@ func TestSyncPullCommand_SkipsNewerLocal(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 
 		if r.URL.Path == "/api/v1/profiles" {
-			if err := json.NewEncoder(w).Encode([]string{"test-profile"}); err != nil {
+			if err := json.NewEncoder(w).Encode([]api.ProfileSummary{{Name: "test-profile", UpdatedAt: oldTime}}); err != nil {
 				t.Fatalf("failed to encode response: %v", err)
 			}
 		} else if strings.HasPrefix(r.URL.Path, "/api/v1/profiles/") {
@@ -390,3 +397,812 @@ func createTestProfile(t *testing.T, profilesDir, name string, extCount int) {
 		t.Fatalf("failed to write profile file: %v", err)
 	}
 }
+
+func TestSyncPushCommand_CompressThreshold(t *testing.T) {
+	setupMockKeychain(t)
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	encodings := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatalf("failed to open gzip body: %v", err)
+			}
+			body = zr
+		}
+
+		var prof api.Profile
+		if err := json.NewDecoder(body).Decode(&prof); err != nil {
+			t.Fatalf("failed to decode profile: %v", err)
+		}
+		encodings[prof.Name] = r.Header.Get("Content-Encoding")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, server.URL, profilesDir)
+	createTestProfile(t, profilesDir, "small", 1)
+	createTestProfile(t, profilesDir, "large", 40)
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(syncCmd)
+
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"sync", "push", "--compress-threshold", "1024"})
+	t.Cleanup(func() { syncPushCompressThreshold = api.DefaultCompressThreshold })
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sync push command failed: %v", err)
+	}
+
+	if encodings["small"] != "" {
+		t.Errorf("expected 'small' to be uploaded raw, got encoding %q", encodings["small"])
+	}
+	if encodings["large"] != "gzip" {
+		t.Errorf("expected 'large' to be gzip-compressed, got encoding %q", encodings["large"])
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "Compressed 'large':") {
+		t.Errorf("expected size report for 'large', got: %s", got)
+	}
+	if strings.Contains(got, "Compressed 'small'") {
+		t.Errorf("expected no size report for 'small', got: %s", got)
+	}
+}
+
+func TestSyncPushCommand_NegativeCompressThreshold(t *testing.T) {
+	setupMockKeychain(t)
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	setupTestConfig(t, tempHome, "http://localhost:8080", filepath.Join(tempHome, ".devtools-sync", "profiles"))
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(syncCmd)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"sync", "push", "--compress-threshold", "-1"})
+	t.Cleanup(func() { syncPushCompressThreshold = api.DefaultCompressThreshold })
+
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error for negative --compress-threshold")
+	}
+}
+
+// runSyncPushMirror runs sync push against a server holding "work", "old" and
+// "other". Locally only "work" exists, and "old" was previously pushed from
+// this machine. Returns the output, the deleted profile names and the error.
+func runSyncPushMirror(t *testing.T, args ...string) (string, []string, error) {
+	t.Helper()
+	setupMockKeychain(t)
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	var mu sync.Mutex
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode([]string{"work", "old", "other"})
+		case http.MethodDelete:
+			mu.Lock()
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/api/v1/profiles/"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, server.URL, profilesDir)
+	createTestProfile(t, profilesDir, "work", 1)
+	if err := savePushedProfiles(server.URL, map[string]bool{"work": true, "old": true}); err != nil {
+		t.Fatalf("failed to record pushed profiles: %v", err)
+	}
+
+	t.Cleanup(func() {
+		syncPushDeleteRemoteMissing = false
+		syncPushYes = false
+		syncPushCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(syncCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"sync", "push"}, args...))
+
+	err := cmd.Execute()
+
+	mu.Lock()
+	defer mu.Unlock()
+	return output.String(), deleted, err
+}
+
+func TestSyncPushCommand_DeleteRemoteMissing(t *testing.T) {
+	output, deleted, err := runSyncPushMirror(t, "--delete-remote-missing", "--yes")
+	if err != nil {
+		t.Fatalf("sync push --delete-remote-missing failed: %v", err)
+	}
+
+	if len(deleted) != 1 || deleted[0] != "old" {
+		t.Errorf("expected only 'old' to be deleted, got %v", deleted)
+	}
+	if !strings.Contains(output, "Keeping 1 remote profile(s) not pushed from this machine: [other]") {
+		t.Errorf("expected 'other' to be reported as kept, got: %s", output)
+	}
+}
+
+func TestSyncPushCommand_PreservesRemoteWithoutMirror(t *testing.T) {
+	_, deleted, err := runSyncPushMirror(t)
+	if err != nil {
+		t.Fatalf("sync push failed: %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("expected no deletions without --delete-remote-missing, got %v", deleted)
+	}
+}
+
+func TestSyncPushCommand_DeleteRemoteMissingRequiresYes(t *testing.T) {
+	output, deleted, err := runSyncPushMirror(t, "--delete-remote-missing")
+	if err == nil {
+		t.Fatal("expected error without --yes")
+	}
+	if len(deleted) != 0 {
+		t.Errorf("expected no deletions without --yes, got %v", deleted)
+	}
+	if !strings.Contains(output, "Would delete 1 remote profile(s) missing locally: [old]") {
+		t.Errorf("expected pending deletion to be listed, got: %s", output)
+	}
+}
+
+func TestPushedProfiles_RoundTripPerServer(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := savePushedProfiles("https://a.example.com", map[string]bool{"work": true}); err != nil {
+		t.Fatalf("savePushedProfiles failed: %v", err)
+	}
+	if err := savePushedProfiles("https://b.example.com", map[string]bool{"home": true}); err != nil {
+		t.Fatalf("savePushedProfiles failed: %v", err)
+	}
+
+	pushed, err := loadPushedProfiles("https://a.example.com")
+	if err != nil {
+		t.Fatalf("loadPushedProfiles failed: %v", err)
+	}
+	if len(pushed) != 1 || !pushed["work"] {
+		t.Errorf("expected only 'work' for server a, got %v", pushed)
+	}
+}
+
+func TestConvertProfile_RoundTripsSettingsAndKeybindings(t *testing.T) {
+	local := &profile.Profile{
+		Name:        "work",
+		Extensions:  []profile.Extension{{ID: "golang.go", Version: "1.0.0", Enabled: true}},
+		Settings:    json.RawMessage(`{"editor.fontSize":14}`),
+		Keybindings: json.RawMessage(`[{"key":"ctrl+k","command":"noop"}]`),
+	}
+
+	// Push converts to the API type, which travels as JSON, and pull back
+	data, err := json.Marshal(convertToAPIProfile(local))
+	if err != nil {
+		t.Fatalf("failed to marshal API profile: %v", err)
+	}
+	var remote api.Profile
+	if err := json.Unmarshal(data, &remote); err != nil {
+		t.Fatalf("failed to unmarshal API profile: %v", err)
+	}
+	pulled := convertToLocalProfile(&remote)
+
+	if !bytes.Equal(pulled.Settings, local.Settings) {
+		t.Errorf("settings = %s, want %s", pulled.Settings, local.Settings)
+	}
+	if !bytes.Equal(pulled.Keybindings, local.Keybindings) {
+		t.Errorf("keybindings = %s, want %s", pulled.Keybindings, local.Keybindings)
+	}
+}
+
+func TestConvertProfile_KeepsExtensionMetadata(t *testing.T) {
+	ext := profile.Extension{
+		ID:          "golang.go",
+		Version:     "1.0.0",
+		Enabled:     true,
+		DisplayName: "Go",
+		Publisher:   "golang",
+		Description: "Rich Go language support",
+	}
+
+	data, err := json.Marshal(convertToAPIProfile(&profile.Profile{Name: "work", Extensions: []profile.Extension{ext}}))
+	if err != nil {
+		t.Fatalf("failed to marshal API profile: %v", err)
+	}
+	var remote api.Profile
+	if err := json.Unmarshal(data, &remote); err != nil {
+		t.Fatalf("failed to unmarshal API profile: %v", err)
+	}
+	pulled := convertToLocalProfile(&remote)
+
+	if len(pulled.Extensions) != 1 || pulled.Extensions[0] != ext {
+		t.Errorf("extensions = %+v, want [%+v]", pulled.Extensions, ext)
+	}
+}
+
+func TestSyncPushCommand_APIKeyFromEnv(t *testing.T) {
+	// The API key takes precedence over the keychain, which must not be touched
+	origFactory := keychainFactory
+	keychainFactory = func() keychain.Keychain {
+		t.Error("keychain should not be used when DEVTOOLS_SYNC_API_KEY is set")
+		return keychain.NewMockKeychain()
+	}
+	t.Cleanup(func() { keychainFactory = origFactory })
+
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	t.Setenv(api.APIKeyEnvVar, "ci-key")
+
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, server.URL, profilesDir)
+	createTestProfile(t, profilesDir, "work", 2)
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(syncCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"sync", "push"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sync push command failed: %v", err)
+	}
+
+	if len(authHeaders) != 1 || authHeaders[0] != "Bearer ci-key" {
+		t.Errorf("expected one request carrying the API key, got %v", authHeaders)
+	}
+}
+
+func TestSyncPushCommand_NormalizesDuplicateExtensions(t *testing.T) {
+	setupMockKeychain(t)
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	pushed := make(map[string]api.Profile)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var prof api.Profile
+		if err := json.NewDecoder(r.Body).Decode(&prof); err != nil {
+			t.Errorf("failed to decode profile: %v", err)
+		}
+		pushed[prof.Name] = prof
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, server.URL, profilesDir)
+	createTestProfile(t, profilesDir, "work", 1)
+
+	dup := profile.Profile{
+		Name: "dup",
+		Extensions: []profile.Extension{
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "golang.go", Version: "0.41.0", Enabled: true},
+		},
+	}
+	data, err := json.Marshal(dup)
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "dup.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(syncCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"sync", "push"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("sync push failed: %v\n%s", err, output.String())
+	}
+	if len(pushed) != 2 {
+		t.Fatalf("expected both profiles to be uploaded, got %v", pushed)
+	}
+	// The duplicate entries are merged, keeping the highest version
+	if exts := pushed["dup"].Extensions; len(exts) != 1 || exts[0].Version != "0.41.0" {
+		t.Errorf("expected dup to be uploaded with golang.go 0.41.0 only, got %+v", exts)
+	}
+}
+
+// fakeSyncClient serves sync push and pull from memory, recording the
+// highest number of concurrent uploads or downloads
+type fakeSyncClient struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	uploaded    []string
+	tags        map[string]string
+	remote      map[string]*api.Profile
+	failing     map[string]bool
+	sinces      []time.Time
+
+	// modified holds server modification times; profiles without one are
+	// treated as modified at their UpdatedAt
+	modified map[string]time.Time
+}
+
+// track marks one call in flight until the returned func is called. It
+// briefly holds each call so concurrent calls overlap.
+func (f *fakeSyncClient) track() func() {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	return func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}
+}
+
+func (f *fakeSyncClient) UploadProfileWithOptions(p *api.Profile, opts api.UploadOptions) (*api.UploadResult, error) {
+	defer f.track()()
+	if f.failing[p.Name] {
+		return nil, errors.New("upload rejected")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploaded = append(f.uploaded, p.Name)
+	if opts.Tag != "" {
+		if f.tags == nil {
+			f.tags = make(map[string]string)
+		}
+		f.tags[p.Name] = opts.Tag
+	}
+	return &api.UploadResult{Version: len(f.uploaded)}, nil
+}
+
+func (f *fakeSyncClient) DownloadProfile(name string) (*api.Profile, error) {
+	defer f.track()()
+	if f.failing[name] {
+		return nil, errors.New("download failed")
+	}
+	return f.remote[name], nil
+}
+
+func (f *fakeSyncClient) ListProfiles() ([]string, error) {
+	names := make([]string, 0, len(f.remote))
+	for name := range f.remote {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *fakeSyncClient) ListProfilesModifiedSince(since time.Time) ([]api.ProfileSummary, error) {
+	f.sinces = append(f.sinces, since)
+	names, _ := f.ListProfiles()
+	summaries := make([]api.ProfileSummary, 0, len(names))
+	for _, name := range names {
+		p := f.remote[name]
+		modified, ok := f.modified[name]
+		if !ok {
+			modified = p.UpdatedAt
+		}
+		if modified.After(since) {
+			summaries = append(summaries, api.ProfileSummary{Name: name, UpdatedAt: p.UpdatedAt, ModifiedAt: modified})
+		}
+	}
+	return summaries, nil
+}
+
+func (f *fakeSyncClient) DeleteProfile(name string) error {
+	return nil
+}
+
+// runSyncWithFake runs a sync subcommand with fake as the client
+func runSyncWithFake(t *testing.T, fake *fakeSyncClient, setup func(profilesDir string), args ...string) (string, string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+
+	profilesDir := filepath.Join(tempHome, ".devtools-sync", "profiles")
+	setupTestConfig(t, tempHome, "http://localhost:8080", profilesDir)
+	setup(profilesDir)
+
+	originalFactory := syncClientFactory
+	syncClientFactory = func(serverURL string) syncClient { return fake }
+	t.Cleanup(func() {
+		syncClientFactory = originalFactory
+		syncPushParallel = defaultSyncParallel
+		syncPullParallel = defaultSyncParallel
+		syncPullForce = false
+		syncPushTag = ""
+		syncPullFull = false
+		for _, c := range []*cobra.Command{syncPushCmd, syncPullCmd} {
+			c.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		}
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(syncCmd)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs(append([]string{"sync"}, args...))
+
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestSyncPushCommand_ParallelCap(t *testing.T) {
+	fake := &fakeSyncClient{failing: map[string]bool{"p3": true}}
+
+	stdout, stderr, err := runSyncWithFake(t, fake, func(dir string) {
+		for i := 1; i <= 6; i++ {
+			createTestProfile(t, dir, fmt.Sprintf("p%d", i), 1)
+		}
+	}, "push", "--parallel", "2")
+
+	if err == nil || !strings.Contains(err.Error(), "failed to push 1 profile(s) [p3]") {
+		t.Fatalf("expected p3 failure to be aggregated, got: %v", err)
+	}
+	if fake.maxInFlight != 2 {
+		t.Errorf("max concurrent uploads = %d, want 2", fake.maxInFlight)
+	}
+	if len(fake.uploaded) != 5 {
+		t.Errorf("expected 5 profiles uploaded, got %v", fake.uploaded)
+	}
+	if !strings.Contains(stdout, "Pushed 5 profile(s): [p1 p2 p4 p5 p6]") {
+		t.Errorf("expected ordered summary, got: %s", stdout)
+	}
+	if !strings.Contains(stderr, "Failed to push profile 'p3': upload rejected") {
+		t.Errorf("expected p3 failure to be reported, got: %s", stderr)
+	}
+}
+
+func TestSyncPushCommand_SerialWithParallelOne(t *testing.T) {
+	fake := &fakeSyncClient{}
+
+	_, _, err := runSyncWithFake(t, fake, func(dir string) {
+		for i := 1; i <= 3; i++ {
+			createTestProfile(t, dir, fmt.Sprintf("p%d", i), 1)
+		}
+	}, "push", "--parallel", "1")
+
+	if err != nil {
+		t.Fatalf("sync push failed: %v", err)
+	}
+	if fake.maxInFlight != 1 {
+		t.Errorf("max concurrent uploads = %d, want 1", fake.maxInFlight)
+	}
+	if strings.Join(fake.uploaded, ",") != "p1,p2,p3" {
+		t.Errorf("expected uploads in order, got %v", fake.uploaded)
+	}
+}
+
+func TestSyncPullCommand_ParallelCap(t *testing.T) {
+	fake := &fakeSyncClient{remote: map[string]*api.Profile{}, failing: map[string]bool{"p2": true}}
+	for i := 1; i <= 6; i++ {
+		name := fmt.Sprintf("p%d", i)
+		fake.remote[name] = &api.Profile{Name: name, UpdatedAt: time.Now(), Extensions: []api.Extension{{ID: "golang.go", Version: "1.0.0"}}}
+	}
+
+	var profilesDir string
+	stdout, stderr, err := runSyncWithFake(t, fake, func(dir string) { profilesDir = dir }, "pull", "--parallel", "3")
+
+	if err == nil || !strings.Contains(err.Error(), "failed to pull 1 profile(s) [p2]") {
+		t.Fatalf("expected p2 failure to be aggregated, got: %v", err)
+	}
+	if fake.maxInFlight != 3 {
+		t.Errorf("max concurrent downloads = %d, want 3", fake.maxInFlight)
+	}
+	if !strings.Contains(stdout, "Pulled 5 profile(s): [p1 p3 p4 p5 p6]") {
+		t.Errorf("expected ordered summary, got: %s", stdout)
+	}
+	if !strings.Contains(stderr, "Failed to download profile 'p2': download failed") {
+		t.Errorf("expected p2 failure to be reported, got: %s", stderr)
+	}
+	for _, name := range []string{"p1", "p3", "p6"} {
+		if _, err := os.Stat(filepath.Join(profilesDir, name+".json")); err != nil {
+			t.Errorf("expected %s to be saved: %v", name, err)
+		}
+	}
+}
+
+func TestSyncPullCommand_ForceOverwritesNewerLocal(t *testing.T) {
+	serverTime := time.Now().Add(-24 * time.Hour)
+	fake := &fakeSyncClient{remote: map[string]*api.Profile{
+		"newer-local": {Name: "newer-local", UpdatedAt: serverTime, Extensions: []api.Extension{{ID: "golang.go", Version: "9.9.9"}}},
+		"server-only": {Name: "server-only", UpdatedAt: serverTime, Extensions: []api.Extension{{ID: "golang.go", Version: "1.0.0"}}},
+	}}
+
+	var profilesDir string
+	stdout, _, err := runSyncWithFake(t, fake, func(dir string) {
+		profilesDir = dir
+		createTestProfile(t, dir, "newer-local", 2)
+	}, "pull", "--force")
+
+	if err != nil {
+		t.Fatalf("sync pull --force failed: %v", err)
+	}
+	if !strings.Contains(stdout, "Forcing 'newer-local' (overwriting newer local version)") {
+		t.Errorf("expected forced overwrite to be reported, got: %s", stdout)
+	}
+	if !strings.Contains(stdout, "Forced 1 profile(s) (overwrote newer local): [newer-local]") {
+		t.Errorf("expected forced summary, got: %s", stdout)
+	}
+	if !strings.Contains(stdout, "Pulled 2 profile(s): [newer-local server-only]") {
+		t.Errorf("expected both profiles pulled, got: %s", stdout)
+	}
+	if strings.Contains(stdout, "Skipped") {
+		t.Errorf("expected nothing skipped with --force, got: %s", stdout)
+	}
+
+	prof, err := profile.Get("newer-local", profilesDir)
+	if err != nil {
+		t.Fatalf("failed to read pulled profile: %v", err)
+	}
+	if len(prof.Extensions) != 1 || prof.Extensions[0].Version != "9.9.9" {
+		t.Errorf("expected local profile overwritten with server copy, got %+v", prof.Extensions)
+	}
+}
+
+func TestSyncPullCommand_WithoutForceSkipsNewerLocal(t *testing.T) {
+	fake := &fakeSyncClient{remote: map[string]*api.Profile{
+		"newer-local": {Name: "newer-local", UpdatedAt: time.Now().Add(-24 * time.Hour), Extensions: []api.Extension{{ID: "golang.go", Version: "9.9.9"}}},
+	}}
+
+	var profilesDir string
+	stdout, _, err := runSyncWithFake(t, fake, func(dir string) {
+		profilesDir = dir
+		createTestProfile(t, dir, "newer-local", 2)
+	}, "pull")
+
+	if err != nil {
+		t.Fatalf("sync pull failed: %v", err)
+	}
+	if !strings.Contains(stdout, "Skipped 1 profile(s) (local is newer): [newer-local]") {
+		t.Errorf("expected newer local profile to be skipped, got: %s", stdout)
+	}
+	if strings.Contains(stdout, "Forc") {
+		t.Errorf("expected no forced overwrites without --force, got: %s", stdout)
+	}
+
+	prof, err := profile.Get("newer-local", profilesDir)
+	if err != nil {
+		t.Fatalf("failed to read local profile: %v", err)
+	}
+	if len(prof.Extensions) != 2 {
+		t.Errorf("expected local profile to be kept, got %+v", prof.Extensions)
+	}
+}
+
+func TestSyncPushCommand_NegativeParallel(t *testing.T) {
+	_, _, err := runSyncWithFake(t, &fakeSyncClient{}, func(string) {}, "push", "--parallel", "-1")
+	if err == nil || !strings.Contains(err.Error(), "--parallel") {
+		t.Errorf("expected --parallel validation error, got: %v", err)
+	}
+}
+
+func TestSyncPushCommand_Tag(t *testing.T) {
+	fake := &fakeSyncClient{}
+
+	stdout, _, err := runSyncWithFake(t, fake, func(dir string) {
+		createTestProfile(t, dir, "work", 1)
+	}, "push", "--tag", "v2025.1")
+	if err != nil {
+		t.Fatalf("sync push --tag failed: %v", err)
+	}
+
+	if fake.tags["work"] != "v2025.1" {
+		t.Errorf("uploaded tags = %v, want work tagged v2025.1", fake.tags)
+	}
+	if !strings.Contains(stdout, "Tagged 'work' version 1 as 'v2025.1'") {
+		t.Errorf("expected tagged version to be reported, got: %s", stdout)
+	}
+}
+
+func TestSyncPushCommand_InvalidTag(t *testing.T) {
+	fake := &fakeSyncClient{}
+
+	_, _, err := runSyncWithFake(t, fake, func(dir string) {
+		createTestProfile(t, dir, "work", 1)
+	}, "push", "--tag", "not/valid")
+	if err == nil || !strings.Contains(err.Error(), "invalid --tag") {
+		t.Errorf("expected invalid tag error, got: %v", err)
+	}
+	if len(fake.uploaded) != 0 {
+		t.Errorf("expected nothing uploaded with an invalid tag, got %v", fake.uploaded)
+	}
+}
+
+// incrementalRemote returns server profiles updated an hour before, at, and
+// an hour after cursor
+func incrementalRemote(cursor time.Time) map[string]*api.Profile {
+	return map[string]*api.Profile{
+		"old":   {Name: "old", UpdatedAt: cursor.Add(-time.Hour)},
+		"at":    {Name: "at", UpdatedAt: cursor},
+		"newer": {Name: "newer", UpdatedAt: cursor.Add(time.Hour)},
+	}
+}
+
+func TestSyncPullCommand_FetchesOnlyChangesSinceLastPull(t *testing.T) {
+	cursor := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSyncClient{remote: incrementalRemote(cursor)}
+
+	var profilesDir string
+	stdout, _, err := runSyncWithFake(t, fake, func(dir string) {
+		profilesDir = dir
+		if err := saveLastPull("http://localhost:8080", cursor); err != nil {
+			t.Fatalf("failed to seed last pull: %v", err)
+		}
+	}, "pull")
+	if err != nil {
+		t.Fatalf("sync pull failed: %v", err)
+	}
+
+	if len(fake.sinces) != 1 || !fake.sinces[0].Equal(cursor) {
+		t.Errorf("listed with since %v, want the stored cursor %v", fake.sinces, cursor)
+	}
+	if !strings.Contains(stdout, "Pulled 1 profile(s): [newer]") {
+		t.Errorf("expected only the newer profile to be pulled, got: %s", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(profilesDir, "old.json")); !os.IsNotExist(err) {
+		t.Errorf("expected unchanged profile not to be downloaded, stat err: %v", err)
+	}
+
+	// The cursor advances to the newest update seen
+	next, err := loadLastPull("http://localhost:8080")
+	if err != nil || !next.Equal(cursor.Add(time.Hour)) {
+		t.Errorf("cursor after pull = %v, %v; want %v", next, err, cursor.Add(time.Hour))
+	}
+}
+
+func TestSyncPullCommand_CursorUsesServerModifiedAt(t *testing.T) {
+	cursor := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSyncClient{
+		remote: map[string]*api.Profile{
+			// Uploaded after the last pull by a client with a slow clock
+			"slow-clock": {Name: "slow-clock", UpdatedAt: cursor.Add(-24 * time.Hour)},
+			// Uploaded by a client with a fast clock
+			"fast-clock": {Name: "fast-clock", UpdatedAt: cursor.Add(24 * time.Hour)},
+		},
+		modified: map[string]time.Time{
+			"slow-clock": cursor.Add(time.Minute),
+			"fast-clock": cursor.Add(2 * time.Minute),
+		},
+	}
+
+	stdout, _, err := runSyncWithFake(t, fake, func(dir string) {
+		if err := saveLastPull("http://localhost:8080", cursor); err != nil {
+			t.Fatalf("failed to seed last pull: %v", err)
+		}
+	}, "pull")
+	if err != nil {
+		t.Fatalf("sync pull failed: %v", err)
+	}
+	if !strings.Contains(stdout, "Pulled 2 profile(s)") {
+		t.Errorf("expected both profiles to be pulled, got: %s", stdout)
+	}
+
+	// The fast client's UpdatedAt must not push the cursor past later uploads
+	next, err := loadLastPull("http://localhost:8080")
+	if err != nil || !next.Equal(cursor.Add(2*time.Minute)) {
+		t.Errorf("cursor after pull = %v, %v; want the newest server modification %v", next, err, cursor.Add(2*time.Minute))
+	}
+}
+
+func TestSyncPullCommand_NothingChanged(t *testing.T) {
+	cursor := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSyncClient{remote: incrementalRemote(cursor)}
+
+	stdout, _, err := runSyncWithFake(t, fake, func(dir string) {
+		if err := saveLastPull("http://localhost:8080", cursor.Add(time.Hour)); err != nil {
+			t.Fatalf("failed to seed last pull: %v", err)
+		}
+	}, "pull")
+	if err != nil {
+		t.Fatalf("sync pull failed: %v", err)
+	}
+	if !strings.Contains(stdout, "No profiles changed on server since the last pull") {
+		t.Errorf("expected nothing-changed notice, got: %s", stdout)
+	}
+}
+
+func TestSyncPullCommand_FullIgnoresCursor(t *testing.T) {
+	cursor := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSyncClient{remote: incrementalRemote(cursor)}
+
+	stdout, _, err := runSyncWithFake(t, fake, func(dir string) {
+		if err := saveLastPull("http://localhost:8080", cursor); err != nil {
+			t.Fatalf("failed to seed last pull: %v", err)
+		}
+	}, "pull", "--full")
+	if err != nil {
+		t.Fatalf("sync pull --full failed: %v", err)
+	}
+	if len(fake.sinces) != 1 || !fake.sinces[0].IsZero() {
+		t.Errorf("listed with since %v, want the zero time", fake.sinces)
+	}
+	if !strings.Contains(stdout, "Pulled 3 profile(s)") {
+		t.Errorf("expected every profile to be pulled, got: %s", stdout)
+	}
+}
+
+func TestSyncPullCommand_ForceIgnoresCursor(t *testing.T) {
+	cursor := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	// Unchanged on the server since the last pull, but edited locally since
+	fake := &fakeSyncClient{remote: map[string]*api.Profile{
+		"edited": {Name: "edited", UpdatedAt: cursor.Add(-time.Hour), Extensions: []api.Extension{{ID: "golang.go", Version: "9.9.9"}}},
+	}}
+
+	var profilesDir string
+	stdout, _, err := runSyncWithFake(t, fake, func(dir string) {
+		profilesDir = dir
+		createTestProfile(t, dir, "edited", 2)
+		if err := saveLastPull("http://localhost:8080", cursor); err != nil {
+			t.Fatalf("failed to seed last pull: %v", err)
+		}
+	}, "pull", "--force")
+	if err != nil {
+		t.Fatalf("sync pull --force failed: %v", err)
+	}
+	if len(fake.sinces) != 1 || !fake.sinces[0].IsZero() {
+		t.Errorf("listed with since %v, want the zero time", fake.sinces)
+	}
+	if !strings.Contains(stdout, "Forced 1 profile(s) (overwrote newer local): [edited]") {
+		t.Errorf("expected the local copy to be overwritten, got: %s", stdout)
+	}
+
+	prof, err := profile.Get("edited", profilesDir)
+	if err != nil {
+		t.Fatalf("failed to read pulled profile: %v", err)
+	}
+	if len(prof.Extensions) != 1 || prof.Extensions[0].Version != "9.9.9" {
+		t.Errorf("expected local profile overwritten with server copy, got %+v", prof.Extensions)
+	}
+}
+
+func TestSyncPullCommand_FailureKeepsCursor(t *testing.T) {
+	cursor := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSyncClient{remote: incrementalRemote(cursor), failing: map[string]bool{"newer": true}}
+
+	_, _, err := runSyncWithFake(t, fake, func(dir string) {
+		if err := saveLastPull("http://localhost:8080", cursor); err != nil {
+			t.Fatalf("failed to seed last pull: %v", err)
+		}
+	}, "pull")
+	if err == nil {
+		t.Fatal("expected sync pull to fail")
+	}
+
+	if kept, err := loadLastPull("http://localhost:8080"); err != nil || !kept.Equal(cursor) {
+		t.Errorf("cursor after failed pull = %v, %v; want it unchanged at %v", kept, err, cursor)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "User administration commands",
+	Long:  "Commands for inspecting user activity on the server. These require an account with the admin role.",
+}
+
+var (
+	usersAuditEvent string
+	usersAuditActor string
+	usersAuditSince string
+	usersAuditLimit int
+	usersAuditAll   bool
+	usersAuditJSON  bool
+)
+
+// auditLister lists audit log pages; satisfied by *api.AuthenticatedClient
+type auditLister interface {
+	ListAuditLogs(q api.AuditQuery) (*api.AuditPage, error)
+}
+
+var usersAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "List audit log entries",
+	Long: `List audit log entries on the server, newest first, optionally filtered by
+event type, actor and age. Without --all at most --limit entries are shown;
+with --all every matching entry is fetched, --limit entries per request.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if usersAuditLimit < 0 {
+			return fmt.Errorf("--limit must be zero or positive, got %d", usersAuditLimit)
+		}
+
+		// Load config
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		client := newAuthenticatedClient(cfg.Server.URL)
+
+		entries, err := listAuditEntries(client, api.AuditQuery{
+			Event: usersAuditEvent,
+			Actor: usersAuditActor,
+			Since: usersAuditSince,
+			Limit: usersAuditLimit,
+		}, usersAuditAll)
+		if err != nil {
+			return err
+		}
+
+		if usersAuditJSON {
+			data, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode audit log entries: %w", err)
+			}
+			cmd.Println(string(data))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			cmd.Println("No audit log entries found.")
+			return nil
+		}
+
+		cmd.Printf("%-20s %-24s %-38s %-38s %-15s\n", "TIME", "EVENT", "ACTOR", "TARGET", "CLIENT IP")
+		cmd.Printf("%s\n", strings.Repeat("-", 139))
+		for _, e := range entries {
+			cmd.Printf("%-20s %-24s %-38s %-38s %-15s\n",
+				e.CreatedAt.Format("2006-01-02 15:04:05"),
+				e.EventType,
+				orDash(e.ActorID),
+				orDash(e.TargetID),
+				orDash(e.ClientIP),
+			)
+		}
+
+		return nil
+	},
+}
+
+// listAuditEntries returns the first page of entries matching q, or every
+// page when all is set
+func listAuditEntries(client auditLister, q api.AuditQuery, all bool) ([]api.AuditEntry, error) {
+	entries := make([]api.AuditEntry, 0)
+	for {
+		page, err := client.ListAuditLogs(q)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page.Entries...)
+
+		if !all || page.NextCursor == "" {
+			return entries, nil
+		}
+		if page.NextCursor == q.Cursor {
+			return nil, fmt.Errorf("server returned the same audit log cursor twice")
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	usersAuditCmd.Flags().StringVar(&usersAuditEvent, "event", "", "Only show entries of this event type (e.g. auth.login.failure)")
+	usersAuditCmd.Flags().StringVar(&usersAuditActor, "actor", "", "Only show entries performed by this user or agent ID")
+	usersAuditCmd.Flags().StringVar(&usersAuditSince, "since", "", "Only show entries newer than this period (e.g. 24h, 7d)")
+	usersAuditCmd.Flags().IntVar(&usersAuditLimit, "limit", 50, "Maximum number of entries to show, or page size with --all")
+	usersAuditCmd.Flags().BoolVar(&usersAuditAll, "all", false, "Fetch every matching entry, paging through results")
+	usersAuditCmd.Flags().BoolVar(&usersAuditJSON, "json", false, "Print entries as JSON")
+
+	usersCmd.AddCommand(usersAuditCmd)
+	rootCmd.AddCommand(usersCmd)
+}
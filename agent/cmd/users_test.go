@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// newFakeAuditServer serves pages of audit entries, two entries per page,
+// recording the query of each request
+func newFakeAuditServer(t *testing.T, entries []api.AuditEntry) (*httptest.Server, *[]map[string]string) {
+	t.Helper()
+	var queries []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/audit" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		query := make(map[string]string)
+		for k := range r.URL.Query() {
+			query[k] = r.URL.Query().Get(k)
+		}
+		queries = append(queries, query)
+
+		// The cursor is the index of the first entry on the page
+		start, _ := strconv.Atoi(query["cursor"])
+		end := min(start+2, len(entries))
+		page := api.AuditPage{Entries: entries[start:end]}
+		if end < len(entries) {
+			page.NextCursor = strconv.Itoa(end)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+	return server, &queries
+}
+
+func runUsersAudit(t *testing.T, serverURL string, args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	setupTestConfig(t, tempHome, serverURL, filepath.Join(tempHome, ".devtools-sync", "profiles"))
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(usersCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"users", "audit"}, args...))
+	t.Cleanup(func() {
+		usersAuditEvent, usersAuditActor, usersAuditSince = "", "", ""
+		usersAuditLimit, usersAuditAll, usersAuditJSON = 50, false, false
+		usersAuditCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	err := cmd.Execute()
+	return output.String(), err
+}
+
+func testAuditEntries() []api.AuditEntry {
+	created := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	return []api.AuditEntry{
+		{ID: "1", EventType: "auth.login.failure", ActorType: "user", ActorID: "actor-1", ClientIP: "10.0.0.1", CreatedAt: created},
+		{ID: "2", EventType: "auth.login.failure", ActorType: "user", ActorID: "actor-1", ClientIP: "10.0.0.2", CreatedAt: created},
+		{ID: "3", EventType: "auth.login.failure", ActorType: "user", ActorID: "actor-1", ClientIP: "10.0.0.3", CreatedAt: created},
+	}
+}
+
+func TestUsersAuditCommand_PassesFilters(t *testing.T) {
+	setupMockKeychain(t)
+	server, queries := newFakeAuditServer(t, testAuditEntries())
+
+	got, err := runUsersAudit(t, server.URL, "--event", "login_failure", "--actor", "actor-1", "--since", "24h", "--limit", "2")
+	if err != nil {
+		t.Fatalf("users audit failed: %v", err)
+	}
+
+	if len(*queries) != 1 {
+		t.Fatalf("expected a single request without --all, got %d", len(*queries))
+	}
+	want := map[string]string{"event": "login_failure", "actor": "actor-1", "since": "24h", "limit": "2"}
+	for k, v := range want {
+		if (*queries)[0][k] != v {
+			t.Errorf("expected query %s=%s, got %q", k, v, (*queries)[0][k])
+		}
+	}
+
+	if !strings.Contains(got, "auth.login.failure") || !strings.Contains(got, "10.0.0.2") || !strings.Contains(got, "2026-03-04 05:06:07") {
+		t.Errorf("expected audit rows in output, got: %s", got)
+	}
+	if strings.Contains(got, "10.0.0.3") {
+		t.Errorf("expected only the first page without --all, got: %s", got)
+	}
+}
+
+func TestUsersAuditCommand_AllPagesAsJSON(t *testing.T) {
+	setupMockKeychain(t)
+	server, queries := newFakeAuditServer(t, testAuditEntries())
+
+	got, err := runUsersAudit(t, server.URL, "--all", "--json", "--limit", "2")
+	if err != nil {
+		t.Fatalf("users audit failed: %v", err)
+	}
+
+	if len(*queries) != 2 || (*queries)[1]["cursor"] != "2" {
+		t.Errorf("expected a second request continuing from cursor 2, got %v", *queries)
+	}
+
+	var entries []api.AuditEntry
+	if err := json.Unmarshal([]byte(got), &entries); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", got, err)
+	}
+	if len(entries) != 3 || entries[2].ClientIP != "10.0.0.3" {
+		t.Errorf("expected all 3 entries, got %+v", entries)
+	}
+}
+
+func TestUsersAuditCommand_NoEntries(t *testing.T) {
+	setupMockKeychain(t)
+	server, _ := newFakeAuditServer(t, nil)
+
+	got, err := runUsersAudit(t, server.URL)
+	if err != nil {
+		t.Fatalf("users audit failed: %v", err)
+	}
+	if !strings.Contains(got, "No audit log entries found.") {
+		t.Errorf("expected empty message, got: %s", got)
+	}
+}
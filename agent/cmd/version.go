@@ -1,18 +1,57 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+
 	"github.com/spf13/cobra"
 )
 
+var versionJSON bool
+
+// versionInfo is the machine-readable form of the version command output
+type versionInfo struct {
+	Version string `json:"version"`
+	Go      string `json:"go"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	Commit  string `json:"commit"`
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
-	Long:  "Print the version number of DevTools Sync Agent",
-	Run: func(cmd *cobra.Command, args []string) {
-		cmd.Printf("devtools-sync version %s\n", version)
+	Long: `Print the version number of DevTools Sync Agent
+
+With --json, print the version, Go version, OS, architecture and commit the
+agent was built from as a JSON object, for example to assert the deployed
+version in CI.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !versionJSON {
+			cmd.Printf("devtools-sync version %s\n", version)
+			return nil
+		}
+
+		data, err := json.MarshalIndent(versionInfo{
+			Version: version,
+			Go:      runtime.Version(),
+			OS:      runtime.GOOS,
+			Arch:    runtime.GOARCH,
+			Commit:  commit,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode version: %w", err)
+		}
+		data = append(data, '\n')
+		if _, err := dataOutput(cmd).Write(data); err != nil {
+			return fmt.Errorf("failed to write version: %w", err)
+		}
+		return nil
 	},
 }
 
 func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print version and build information as JSON")
 	rootCmd.AddCommand(versionCmd)
 }
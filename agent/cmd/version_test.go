@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"runtime"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -31,3 +33,47 @@ func TestVersionCommand(t *testing.T) {
 		t.Errorf("version command output:\ngot:  %q\nwant: %q", got, want)
 	}
 }
+
+func TestVersionCommand_JSON(t *testing.T) {
+	t.Cleanup(func() {
+		versionJSON = false
+		versionCmd.Flags().Lookup("json").Changed = false
+	})
+
+	origCommit := commit
+	commit = "abc1234"
+	t.Cleanup(func() { commit = origCommit })
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(versionCmd)
+
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs([]string{"version", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("version --json failed: %v", err)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(output.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON object: %v\n%s", err, output.String())
+	}
+
+	want := map[string]string{
+		"version": "0.1.0",
+		"go":      runtime.Version(),
+		"os":      runtime.GOOS,
+		"arch":    runtime.GOARCH,
+		"commit":  "abc1234",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got fields %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// now returns the current time (can be overridden in tests)
+var now = time.Now
+
+var whoamiToken bool
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show who the stored access token belongs to",
+	Long: `Show the account the access token stored in the keychain was issued to. The
+token is decoded locally without contacting the server or verifying its signature.
+
+With --token, print all of its claims (subject, email, role, issued and expiry
+times) and whether it has expired, to help debug authentication problems. An
+expired token is renewed automatically on the next request if credentials are
+stored.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// The token always comes from the keychain, even with an API key set
+		claims, err := newKeychainClient(cfg.Server.URL).StoredTokenClaims()
+		if err != nil {
+			return fmt.Errorf("failed to read access token: %w", err)
+		}
+		expired := claims.Expired(now())
+
+		if !whoamiToken {
+			cmd.Printf("Logged in as %s (%s)\n", claims.Email, claims.Role)
+			if expired {
+				cmd.Printf("The access token has expired; it is renewed on the next request.\n")
+			}
+			return nil
+		}
+
+		cmd.Printf("Subject:  %s\n", claims.Subject)
+		cmd.Printf("Email:    %s\n", claims.Email)
+		cmd.Printf("Role:     %s\n", claims.Role)
+		cmd.Printf("Issued:   %s\n", formatClaimTime(claims.IssuedAt))
+		cmd.Printf("Expires:  %s\n", formatClaimTime(claims.ExpiresAt))
+		if expired {
+			cmd.Printf("Expired:  yes\n")
+		} else {
+			cmd.Printf("Expired:  no\n")
+		}
+		return nil
+	},
+}
+
+// formatClaimTime renders a token timestamp in UTC, or "-" if it is not set
+func formatClaimTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func init() {
+	whoamiCmd.Flags().BoolVar(&whoamiToken, "token", false, "Print the stored access token's claims and whether it has expired")
+
+	rootCmd.AddCommand(whoamiCmd)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/api"
+	"github.com/mark-chris/devtools-sync/agent/internal/keychain"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// whoamiTestToken is an HS256 token for dev@example.com (admin) issued at
+// 2026-01-01T00:00:00Z and expiring 15 minutes later
+const whoamiTestToken = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+	"eyJzdWIiOiI2ZjFjMmI4ZS0zZDRhLTRjNWItOWU3Zi0wYTFiMmMzZDRlNWYiLCJlbWFpbCI6ImRldkBleGFtcGxlLmNvbSIsInJvbGUiOiJhZG1pbiIsImlhdCI6MTc2NzIyNTYwMCwiZXhwIjoxNzY3MjI2NTAwfQ." +
+	"c_wCs1bN8uin_puKN-5TRkMdT1N5clTkwyn31PtEbig"
+
+// runWhoami runs whoami with args at the given time, with token stored in a
+// mock keychain ("" stores none)
+func runWhoami(t *testing.T, token string, at time.Time, args ...string) (string, error) {
+	t.Helper()
+	tempHome := t.TempDir()
+	t.Setenv("HOME", tempHome)
+	setupTestLoginConfig(t, tempHome, "http://localhost:8080")
+
+	mockKC := keychain.NewMockKeychain()
+	if token != "" {
+		_ = mockKC.Set(keychain.KeyAccessToken, token)
+	}
+	origFactory := keychainFactory
+	keychainFactory = func() keychain.Keychain { return mockKC }
+	origNow := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() {
+		keychainFactory = origFactory
+		now = origNow
+		whoamiToken = false
+		whoamiCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	})
+
+	cmd := &cobra.Command{Use: "devtools-sync"}
+	cmd.AddCommand(whoamiCmd)
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetErr(output)
+	cmd.SetArgs(append([]string{"whoami"}, args...))
+
+	err := cmd.Execute()
+	return output.String(), err
+}
+
+func TestWhoamiCommand_Token(t *testing.T) {
+	issued := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	out, err := runWhoami(t, whoamiTestToken, issued.Add(5*time.Minute), "--token")
+	if err != nil {
+		t.Fatalf("whoami --token failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"Subject:  6f1c2b8e-3d4a-4c5b-9e7f-0a1b2c3d4e5f",
+		"Email:    dev@example.com",
+		"Role:     admin",
+		"Issued:   2026-01-01T00:00:00Z",
+		"Expires:  2026-01-01T00:15:00Z",
+		"Expired:  no",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWhoamiCommand_TokenExpired(t *testing.T) {
+	issued := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	out, err := runWhoami(t, whoamiTestToken, issued.Add(time.Hour), "--token")
+	if err != nil {
+		t.Fatalf("whoami --token failed: %v", err)
+	}
+	if !strings.Contains(out, "Expired:  yes") {
+		t.Errorf("expected token to be flagged as expired, got:\n%s", out)
+	}
+}
+
+func TestWhoamiCommand_Summary(t *testing.T) {
+	issued := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	out, err := runWhoami(t, whoamiTestToken, issued.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("whoami failed: %v", err)
+	}
+	if !strings.Contains(out, "Logged in as dev@example.com (admin)") || !strings.Contains(out, "has expired") {
+		t.Errorf("expected account summary with expiry notice, got:\n%s", out)
+	}
+}
+
+func TestWhoamiCommand_NotLoggedIn(t *testing.T) {
+	_, err := runWhoami(t, "", time.Now(), "--token")
+	if !errors.Is(err, api.ErrNotAuthenticated) {
+		t.Errorf("expected ErrNotAuthenticated, got: %v", err)
+	}
+}
@@ -7,6 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/mark-chris/devtools-sync/agent/internal/keychain"
 )
@@ -14,20 +18,55 @@ import (
 // ErrNotAuthenticated is returned when no access token is available
 var ErrNotAuthenticated = errors.New("not authenticated: please run 'devtools-sync login' first")
 
+// ErrKeychainLocked is returned when the access token cannot be read because
+// the OS keychain is locked or otherwise unavailable
+var ErrKeychainLocked = errors.New("keychain locked or unavailable: unlock it and retry")
+
+// ErrSessionExpired is returned when the server rejects the access token and
+// no stored credentials are available to log in again
+var ErrSessionExpired = errors.New("session expired: please run 'devtools-sync login' again")
+
+// APIKeyEnvVar names the environment variable holding an API key for
+// non-interactive use such as CI. When set it takes precedence over the
+// token stored in the keychain.
+const APIKeyEnvVar = "DEVTOOLS_SYNC_API_KEY"
+
+// ErrAPIKeyRejected is returned when the server rejects the API key
+var ErrAPIKeyRejected = errors.New("API key rejected by server: check " + APIKeyEnvVar)
+
 // AuthenticatedClient wraps Client with authentication
 type AuthenticatedClient struct {
 	client   *Client
 	keychain keychain.Keychain
+	apiKey   string
+
+	// token caches the access token for the life of the process, so a
+	// keychain that locks mid-session does not break a running command
+	mu    sync.Mutex
+	token string
 }
 
 // NewAuthenticatedClient creates a new authenticated API client
-func NewAuthenticatedClient(baseURL string, kc keychain.Keychain) *AuthenticatedClient {
+func NewAuthenticatedClient(baseURL string, kc keychain.Keychain, opts ...ClientOption) *AuthenticatedClient {
 	return &AuthenticatedClient{
-		client:   NewClient(baseURL),
+		client:   NewClient(baseURL, opts...),
 		keychain: kc,
 	}
 }
 
+// NewAPIKeyClient creates an authenticated API client that sends apiKey as
+// the bearer credential on every request. It never touches the keychain, so
+// Login, Logout and auto re-login are unavailable.
+func NewAPIKeyClient(baseURL, apiKey string, opts ...ClientOption) *AuthenticatedClient {
+	return &AuthenticatedClient{
+		client: NewClient(baseURL, opts...),
+		apiKey: apiKey,
+	}
+}
+
+// errAPIKeyInUse is returned by keychain operations on an API key client
+var errAPIKeyInUse = errors.New("not available when authenticating with " + APIKeyEnvVar)
+
 // LoginRequest represents the login request body
 type LoginRequest struct {
 	Email    string `json:"email"`
@@ -49,6 +88,10 @@ type StoredCredentials struct {
 
 // Login authenticates with the server and stores the token
 func (ac *AuthenticatedClient) Login(email, password string) error {
+	if ac.apiKey != "" {
+		return errAPIKeyInUse
+	}
+
 	// Prepare request
 	loginReq := LoginRequest{
 		Email:    email,
@@ -76,9 +119,7 @@ func (ac *AuthenticatedClient) Login(email, password string) error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to server: %w", err)
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+	defer closeResponse(resp)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
@@ -100,6 +141,7 @@ func (ac *AuthenticatedClient) Login(email, password string) error {
 	if err := ac.keychain.Set(keychain.KeyAccessToken, loginResp.AccessToken); err != nil {
 		return fmt.Errorf("failed to store access token: %w", err)
 	}
+	ac.setCachedToken(loginResp.AccessToken)
 
 	// Store credentials for auto re-login
 	creds := StoredCredentials{
@@ -119,13 +161,19 @@ func (ac *AuthenticatedClient) Login(email, password string) error {
 }
 // AuthenticatedRequest executes an HTTP request with authentication and auto re-login on 401
 func (ac *AuthenticatedClient) AuthenticatedRequest(req *http.Request) (*http.Response, error) {
+	if ac.apiKey != "" {
+		return ac.apiKeyRequest(req)
+	}
+
+	// A body without GetBody would be replayed empty after re-login
+	if err := checkReplayableBody(req); err != nil {
+		return nil, err
+	}
+
 	// Get access token
-	token, err := ac.keychain.Get(keychain.KeyAccessToken)
+	token, err := ac.accessToken()
 	if err != nil {
-		if errors.Is(err, keychain.ErrNotFound) {
-			return nil, ErrNotAuthenticated
-		}
-		return nil, fmt.Errorf("failed to retrieve access token: %w", err)
+		return nil, err
 	}
 
 	// Add Authorization header
@@ -139,12 +187,16 @@ func (ac *AuthenticatedClient) AuthenticatedRequest(req *http.Request) (*http.Re
 
 	// If 401, attempt auto re-login
 	if resp.StatusCode == http.StatusUnauthorized {
-		_ = resp.Body.Close()
+		closeResponse(resp)
+		ac.setCachedToken("")
 
 		// Try to get stored credentials
 		credsJSON, err := ac.keychain.Get(keychain.KeyCredentials)
 		if err != nil {
-			return nil, fmt.Errorf("session expired: please run 'devtools-sync login' again")
+			if errors.Is(err, keychain.ErrUnavailable) {
+				return nil, fmt.Errorf("%w (%v)", ErrKeychainLocked, err)
+			}
+			return nil, ErrSessionExpired
 		}
 
 		var creds StoredCredentials
@@ -158,7 +210,7 @@ func (ac *AuthenticatedClient) AuthenticatedRequest(req *http.Request) (*http.Re
 		}
 
 		// Retry original request with new token
-		token, err = ac.keychain.Get(keychain.KeyAccessToken)
+		token, err = ac.accessToken()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve new access token: %w", err)
 		}
@@ -166,7 +218,7 @@ func (ac *AuthenticatedClient) AuthenticatedRequest(req *http.Request) (*http.Re
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
 		// Reset request body if needed
-		if req.GetBody != nil {
+		if req.Body != nil && req.Body != http.NoBody {
 			body, err := req.GetBody()
 			if err != nil {
 				return nil, fmt.Errorf("failed to reset request body: %w", err)
@@ -183,40 +235,121 @@ func (ac *AuthenticatedClient) AuthenticatedRequest(req *http.Request) (*http.Re
 	return resp, nil
 }
 
+// accessToken returns the cached access token, reading it from the keychain
+// on first use. A locked or unavailable keychain is reported as
+// ErrKeychainLocked, distinct from ErrNotAuthenticated for a missing token.
+func (ac *AuthenticatedClient) accessToken() (string, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if ac.token != "" {
+		return ac.token, nil
+	}
+
+	token, err := ac.keychain.Get(keychain.KeyAccessToken)
+	if err != nil {
+		if errors.Is(err, keychain.ErrNotFound) {
+			return "", ErrNotAuthenticated
+		}
+		return "", fmt.Errorf("%w (%v)", ErrKeychainLocked, err)
+	}
+	ac.token = token
+	return token, nil
+}
+
+// setCachedToken replaces the cached access token; "" clears it
+func (ac *AuthenticatedClient) setCachedToken(token string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.token = token
+}
+
+// apiKeyRequest executes an HTTP request authenticated with the API key.
+// A rejected key cannot be renewed, so 401 is returned as ErrAPIKeyRejected.
+func (ac *AuthenticatedClient) apiKeyRequest(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ac.apiKey))
+
+	resp, err := ac.client.retryableRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		closeResponse(resp)
+		return nil, ErrAPIKeyRejected
+	}
+
+	return resp, nil
+}
+
+// Health checks if the server is healthy. The health endpoint needs no
+// credentials, so none are sent.
+func (ac *AuthenticatedClient) Health() (*HealthResponse, error) {
+	return ac.client.Health()
+}
+
 // UploadProfile uploads a profile with authentication
 func (ac *AuthenticatedClient) UploadProfile(profile *Profile) error {
-	data, err := json.Marshal(profile)
+	_, err := ac.UploadProfileWithOptions(profile, UploadOptions{})
+	return err
+}
+
+// UploadProfileWithOptions uploads a profile with authentication, gzip-compressing
+// the body when it reaches opts.CompressThreshold and tagging the created
+// version with opts.Tag. Extensions are normalized first (see
+// normalizedForUpload).
+func (ac *AuthenticatedClient) UploadProfileWithOptions(profile *Profile, opts UploadOptions) (*UploadResult, error) {
+	data, err := json.Marshal(normalizedForUpload(profile))
 	if err != nil {
-		return fmt.Errorf("failed to marshal profile: %w", err)
+		return nil, fmt.Errorf("failed to marshal profile: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/api/v1/profiles", ac.client.baseURL)
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	result := &UploadResult{RawSize: len(data), UploadSize: len(data)}
+	if shouldCompress(len(data), opts.CompressThreshold) {
+		data, err = gzipBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		result.UploadSize = len(data)
+		result.Compressed = true
+	}
+
+	query := ""
+	if opts.Tag != "" {
+		query = "?tag=" + url.QueryEscape(opts.Tag)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/profiles%s", ac.client.baseURL, query)
+	req, err := newJSONRequest(http.MethodPost, url, data)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.GetBody = func() (io.ReadCloser, error) {
-		return io.NopCloser(bytes.NewReader(data)), nil
+	if result.Compressed {
+		req.Header.Set("Content-Encoding", "gzip")
 	}
 
 	resp, err := ac.AuthenticatedRequest(req)
 	if err != nil {
-		return fmt.Errorf("failed to upload profile: %w", err)
+		return nil, fmt.Errorf("failed to upload profile: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
+	defer closeResponse(resp)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
-		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	// Servers without versioning do not report one; that is not an error
+	var uploaded Profile
+	if body, err := readLimitedResponse(resp.Body, MaxResponseSize); err == nil && json.Unmarshal(body, &uploaded) == nil {
+		result.Version = uploaded.Version
+	}
+
+	return result, nil
 }
 
 // ListProfiles retrieves all profile names with authentication
 func (ac *AuthenticatedClient) ListProfiles() ([]string, error) {
-	url := fmt.Sprintf("%s/api/v1/profiles", ac.client.baseURL)
+	url := fmt.Sprintf("%s/api/v1/profiles?names_only=true", ac.client.baseURL)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -226,7 +359,7 @@ func (ac *AuthenticatedClient) ListProfiles() ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to list profiles: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
+	defer closeResponse(resp)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
@@ -246,6 +379,46 @@ func (ac *AuthenticatedClient) ListProfiles() ([]string, error) {
 	return profiles, nil
 }
 
+// ListProfilesDetailed retrieves the metadata of all profiles with authentication
+func (ac *AuthenticatedClient) ListProfilesDetailed() ([]ProfileSummary, error) {
+	url := fmt.Sprintf("%s/api/v1/profiles?names_only=false", ac.client.baseURL)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	defer closeResponse(resp)
+
+	return parseProfileSummaries(resp)
+}
+
+// ListProfilesModifiedSince retrieves the metadata of the profiles updated
+// strictly after since, with authentication. A zero since lists every profile.
+func (ac *AuthenticatedClient) ListProfilesModifiedSince(since time.Time) ([]ProfileSummary, error) {
+	query := "names_only=false"
+	if !since.IsZero() {
+		query += "&modified_since=" + url.QueryEscape(since.UTC().Format(time.RFC3339Nano))
+	}
+
+	url := fmt.Sprintf("%s/api/v1/profiles?%s", ac.client.baseURL, query)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	defer closeResponse(resp)
+
+	return parseProfileSummaries(resp)
+}
+
 // DownloadProfile retrieves a specific profile with authentication
 func (ac *AuthenticatedClient) DownloadProfile(name string) (*Profile, error) {
 	url := fmt.Sprintf("%s/api/v1/profiles/%s", ac.client.baseURL, name)
@@ -258,7 +431,108 @@ func (ac *AuthenticatedClient) DownloadProfile(name string) (*Profile, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to download profile: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
+	defer closeResponse(resp)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("profile '%s' not found on server", name)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile Profile
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
+// RenameProfile renames a profile on the server with authentication
+func (ac *AuthenticatedClient) RenameProfile(oldName, newName string) error {
+	data, err := json.Marshal(map[string]string{"name": newName})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/profiles/%s/rename", ac.client.baseURL, oldName)
+	req, err := newJSONRequest(http.MethodPost, url, data)
+	if err != nil {
+		return err
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to rename profile: %w", err)
+	}
+	defer closeResponse(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("profile '%s' not found on server", oldName)
+	case http.StatusConflict:
+		return fmt.Errorf("a profile named '%s' already exists on server", newName)
+	default:
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// UpdateProfile replaces a profile on the server with authentication
+func (ac *AuthenticatedClient) UpdateProfile(name string, profile *Profile) error {
+	data, err := json.Marshal(normalizedForUpload(profile))
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/profiles/%s", ac.client.baseURL, name)
+	req, err := newJSONRequest(http.MethodPut, url, data)
+	if err != nil {
+		return err
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// PatchProfile updates only the given metadata of a profile on the server
+// with authentication (see Client.PatchProfile)
+func (ac *AuthenticatedClient) PatchProfile(name string, meta *ProfileMetadata) (*Profile, error) {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile metadata: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/profiles/%s", ac.client.baseURL, name)
+	req, err := newJSONRequest(http.MethodPatch, url, data)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch profile: %w", err)
+	}
+	defer closeResponse(resp)
 
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, fmt.Errorf("profile '%s' not found on server", name)
@@ -282,9 +556,406 @@ func (ac *AuthenticatedClient) DownloadProfile(name string) (*Profile, error) {
 	return &profile, nil
 }
 
+// DeleteProfile deletes a profile on the server with authentication. The
+// server moves it to the trash, from which RestoreProfile can bring it back
+// until it is purged.
+func (ac *AuthenticatedClient) DeleteProfile(name string) error {
+	url := fmt.Sprintf("%s/api/v1/profiles/%s", ac.client.baseURL, name)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	defer closeResponse(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("profile '%s' not found on server", name)
+	default:
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// TrashedProfile is a deleted profile waiting in the server's trash
+type TrashedProfile struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	PurgeAt     time.Time `json:"purge_at"`
+	Extensions  int       `json:"extensions"`
+}
+
+// ListTrash retrieves the user's deleted profiles that can still be restored,
+// most recently deleted first
+func (ac *AuthenticatedClient) ListTrash() ([]TrashedProfile, error) {
+	url := fmt.Sprintf("%s/api/v1/profiles/trash", ac.client.baseURL)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var trash []TrashedProfile
+	if err := json.Unmarshal(body, &trash); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return trash, nil
+}
+
+// RestoreProfile takes a deleted profile out of the server's trash and
+// returns it
+func (ac *AuthenticatedClient) RestoreProfile(name string) (*Profile, error) {
+	url := fmt.Sprintf("%s/api/v1/profiles/%s/restore", ac.client.baseURL, name)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore profile: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("profile '%s' not found in server trash", name)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile Profile
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
+// ProfileTag is a tag of a server profile and the version it names
+type ProfileTag struct {
+	Tag       string    `json:"tag"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidateTag checks that tag is a valid version tag: at most 64 letters,
+// digits, '.', '_' or '-'. Mirrors the server's profiles.ValidateTag.
+func ValidateTag(tag string) error {
+	if tag == "" {
+		return errors.New("tag cannot be empty")
+	}
+	if len(tag) > 64 {
+		return errors.New("tag must be at most 64 characters")
+	}
+	for _, r := range tag {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return fmt.Errorf("invalid tag '%s': only letters, digits, '.', '_' and '-' are allowed", tag)
+		}
+	}
+	return nil
+}
+
+// ListProfileTags retrieves the tags of a profile, newest version first
+func (ac *AuthenticatedClient) ListProfileTags(name string) ([]ProfileTag, error) {
+	url := fmt.Sprintf("%s/api/v1/profiles/%s/tags", ac.client.baseURL, name)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profile tags: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("profile '%s' not found on server", name)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []ProfileTag
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return tags, nil
+}
+
+// DownloadProfileByTag retrieves a profile as it was at the version tag names
+func (ac *AuthenticatedClient) DownloadProfileByTag(name, tag string) (*Profile, error) {
+	url := fmt.Sprintf("%s/api/v1/profiles/%s/tags/%s", ac.client.baseURL, name, tag)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download profile: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("profile '%s' with tag '%s' not found on server", name, tag)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile Profile
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
+// StaleProfile is a profile reported by the server's stale profile report
+type StaleProfile struct {
+	Name       string    `json:"name"`
+	UserID     string    `json:"user_id"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Extensions int       `json:"extensions"`
+}
+
+// ListStaleProfiles retrieves profiles not updated within olderThan (e.g. "90d").
+// Requires an admin account.
+func (ac *AuthenticatedClient) ListStaleProfiles(olderThan string) ([]StaleProfile, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/profiles/stale?older_than=%s", ac.client.baseURL, url.QueryEscape(olderThan))
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale profiles: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("admin role required to list stale profiles")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		Profiles []StaleProfile `json:"profiles"`
+	}
+	if err := json.Unmarshal(body, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return report.Profiles, nil
+}
+
+// ServerStats is the server overview returned by the admin stats endpoint
+type ServerStats struct {
+	Users          int `json:"users"`
+	ActiveUsers    int `json:"active_users"`
+	Profiles       int `json:"profiles"`
+	RefreshTokens  int `json:"refresh_tokens"`
+	PendingInvites int `json:"pending_invites"`
+}
+
+// ServerStats retrieves counts of the server's users, profiles, refresh
+// tokens and pending invites. Requires an admin account.
+func (ac *AuthenticatedClient) ServerStats() (*ServerStats, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/admin/stats", ac.client.baseURL)
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server stats: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("admin role required to get server stats")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats ServerStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// AuditQuery filters an audit log listing. Empty fields are not filtered on.
+type AuditQuery struct {
+	// Event is the event type, e.g. auth.login.failure
+	Event string
+
+	// Actor is the ID of the user or agent that performed the action
+	Actor string
+
+	// Since limits results to entries newer than this period (e.g. 24h, 7d)
+	Since string
+
+	// Limit is the maximum number of entries per page; 0 uses the server default
+	Limit int
+
+	// Cursor continues a previous listing from AuditPage.NextCursor
+	Cursor string
+}
+
+// AuditEntry is an audit log entry returned by the server
+type AuditEntry struct {
+	ID         string                 `json:"id"`
+	EventType  string                 `json:"event_type"`
+	ActorType  string                 `json:"actor_type"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	TargetType string                 `json:"target_type,omitempty"`
+	TargetID   string                 `json:"target_id,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	ClientIP   string                 `json:"client_ip,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// AuditPage is one page of audit log entries, newest first
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListAuditLogs retrieves one page of audit log entries matching q.
+// Requires an admin account.
+func (ac *AuthenticatedClient) ListAuditLogs(q AuditQuery) (*AuditPage, error) {
+	params := url.Values{}
+	if q.Event != "" {
+		params.Set("event", q.Event)
+	}
+	if q.Actor != "" {
+		params.Set("actor", q.Actor)
+	}
+	if q.Since != "" {
+		params.Set("since", q.Since)
+	}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Cursor != "" {
+		params.Set("cursor", q.Cursor)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/audit", ac.client.baseURL)
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := ac.AuthenticatedRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("admin role required to list audit logs")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var page AuditPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &page, nil
+}
+
 // Logout removes stored credentials from keychain
 func (ac *AuthenticatedClient) Logout() error {
+	if ac.apiKey != "" {
+		return errAPIKeyInUse
+	}
+
 	// Delete access token
+	ac.setCachedToken("")
 	if err := ac.keychain.Delete(keychain.KeyAccessToken); err != nil {
 		return fmt.Errorf("failed to delete access token: %w", err)
 	}
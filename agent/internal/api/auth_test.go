@@ -1,11 +1,16 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark-chris/devtools-sync/agent/internal/keychain"
 )
@@ -198,6 +203,92 @@ func TestAuthenticatedClient_AutoRelogin(t *testing.T) {
 	}
 }
 
+// reloginBodyServer rejects the first request with the expired token, accepts
+// the replay after re-login, and records the body of every non-login request
+func reloginBodyServer(t *testing.T, bodies *[][]byte) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/login" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "new-token", "token_type": "Bearer", "expires_in": 3600})
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request body: %v", err)
+		}
+		*bodies = append(*bodies, body)
+
+		if r.Header.Get("Authorization") != "Bearer new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Profile{Name: "work"})
+	}))
+}
+
+func TestAuthenticatedClient_ReloginResendsFullBody(t *testing.T) {
+	desc := "laptop"
+	profile := &Profile{Name: "work", Extensions: []Extension{{ID: "golang.go", Version: "0.40.0", Enabled: true}}}
+
+	tests := []struct {
+		name string
+		call func(ac *AuthenticatedClient) error
+	}{
+		{"upload", func(ac *AuthenticatedClient) error { return ac.UploadProfile(profile) }},
+		{"compressed upload", func(ac *AuthenticatedClient) error {
+			_, err := ac.UploadProfileWithOptions(profile, UploadOptions{CompressThreshold: 1})
+			return err
+		}},
+		{"update", func(ac *AuthenticatedClient) error { return ac.UpdateProfile("work", profile) }},
+		{"patch", func(ac *AuthenticatedClient) error {
+			_, err := ac.PatchProfile("work", &ProfileMetadata{Description: &desc})
+			return err
+		}},
+		{"rename", func(ac *AuthenticatedClient) error { return ac.RenameProfile("work", "office") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := keychain.NewMockKeychain()
+			_ = kc.Set(keychain.KeyAccessToken, "expired-token")
+			_ = kc.Set(keychain.KeyCredentials, `{"email":"test@example.com","password":"password123"}`)
+
+			var bodies [][]byte
+			server := reloginBodyServer(t, &bodies)
+			defer server.Close()
+
+			if err := tt.call(NewAuthenticatedClient(server.URL, kc)); err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+
+			if len(bodies) != 2 {
+				t.Fatalf("expected the request to be sent twice, got %d", len(bodies))
+			}
+			if len(bodies[0]) == 0 || !bytes.Equal(bodies[0], bodies[1]) {
+				t.Errorf("replayed body differs from the original:\nfirst:  %q\nreplay: %q", bodies[0], bodies[1])
+			}
+		})
+	}
+}
+
+func TestAuthenticatedClient_RejectsBodyWithoutGetBody(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a body that cannot be replayed should not be sent")
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/protected", io.NopCloser(strings.NewReader(`{"a":1}`)))
+	_, err := NewAuthenticatedClient(server.URL, kc).AuthenticatedRequest(req)
+	if err == nil || !strings.Contains(err.Error(), "GetBody not set") {
+		t.Errorf("expected GetBody error, got %v", err)
+	}
+}
+
 func TestAuthenticatedClient_NoTokenError(t *testing.T) {
 	kc := keychain.NewMockKeychain()
 	client := NewAuthenticatedClient("http://example.com", kc)
@@ -211,3 +302,425 @@ func TestAuthenticatedClient_NoTokenError(t *testing.T) {
 		t.Errorf("expected ErrNotAuthenticated, got: %v", err)
 	}
 }
+
+func TestAuthenticatedClient_RenameProfile(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	var gotPath string
+	var gotBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	if err := client.RenameProfile("work", "office"); err != nil {
+		t.Fatalf("RenameProfile failed: %v", err)
+	}
+
+	if gotPath != "/api/v1/profiles/work/rename" {
+		t.Errorf("expected path /api/v1/profiles/work/rename, got %s", gotPath)
+	}
+	if gotBody["name"] != "office" {
+		t.Errorf("expected new name 'office', got %q", gotBody["name"])
+	}
+}
+
+func TestAuthenticatedClient_RenameProfile_Conflict(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	err := client.RenameProfile("work", "office")
+	if err == nil {
+		t.Fatal("expected error on conflict, got nil")
+	}
+	if err.Error() != "a profile named 'office' already exists on server" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAuthenticatedClient_DeleteProfile(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	var gotMethod, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		if r.URL.Path == "/api/v1/profiles/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	if err := client.DeleteProfile("work"); err != nil {
+		t.Fatalf("DeleteProfile failed: %v", err)
+	}
+	if gotMethod != http.MethodDelete || gotPath != "/api/v1/profiles/work" {
+		t.Errorf("expected DELETE /api/v1/profiles/work, got %s %s", gotMethod, gotPath)
+	}
+
+	err := client.DeleteProfile("missing")
+	if err == nil || err.Error() != "profile 'missing' not found on server" {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestAuthenticatedClient_ListTrashAndRestore(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	deletedAt := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	var gotMethods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethods = append(gotMethods, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/profiles/trash":
+			_ = json.NewEncoder(w).Encode([]TrashedProfile{{Name: "work", DeletedAt: deletedAt, PurgeAt: deletedAt.Add(30 * 24 * time.Hour), Extensions: 2}})
+		case "/api/v1/profiles/work/restore":
+			_ = json.NewEncoder(w).Encode(Profile{Name: "work", Extensions: []Extension{{ID: "golang.go", Version: "0.40.0"}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	trash, err := client.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(trash) != 1 || trash[0].Name != "work" || !trash[0].DeletedAt.Equal(deletedAt) || trash[0].Extensions != 2 {
+		t.Errorf("ListTrash = %+v, want the trashed work profile", trash)
+	}
+
+	prof, err := client.RestoreProfile("work")
+	if err != nil {
+		t.Fatalf("RestoreProfile failed: %v", err)
+	}
+	if prof.Name != "work" || len(prof.Extensions) != 1 {
+		t.Errorf("RestoreProfile = %+v, want the restored profile", prof)
+	}
+
+	if _, err := client.RestoreProfile("missing"); err == nil || !strings.Contains(err.Error(), "not found in server trash") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	want := []string{"GET /api/v1/profiles/trash", "POST /api/v1/profiles/work/restore", "POST /api/v1/profiles/missing/restore"}
+	if strings.Join(gotMethods, ",") != strings.Join(want, ",") {
+		t.Errorf("requests = %v, want %v", gotMethods, want)
+	}
+}
+
+func TestAuthenticatedClient_ListProfilesModes(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("names_only") == "true" {
+			_ = json.NewEncoder(w).Encode([]string{"work"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]ProfileSummary{{Name: "work", Extensions: 3}})
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+
+	names, err := client.ListProfiles()
+	if err != nil || len(names) != 1 || names[0] != "work" {
+		t.Errorf("ListProfiles() = %v, %v; want [work]", names, err)
+	}
+
+	summaries, err := client.ListProfilesDetailed()
+	if err != nil || len(summaries) != 1 || summaries[0].Extensions != 3 {
+		t.Errorf("ListProfilesDetailed() = %+v, %v; want one summary with 3 extensions", summaries, err)
+	}
+}
+
+// lockableKeychain wraps MockKeychain and fails every access while locked,
+// like an OS keychain that was locked after login
+type lockableKeychain struct {
+	*keychain.MockKeychain
+	locked bool
+	gets   int
+}
+
+func (k *lockableKeychain) Get(key string) (string, error) {
+	k.gets++
+	if k.locked {
+		return "", fmt.Errorf("failed to retrieve from keychain: %w: %w", keychain.ErrUnavailable, errors.New("user interaction is not allowed"))
+	}
+	return k.MockKeychain.Get(key)
+}
+
+func TestAuthenticatedClient_LockedKeychainIsActionable(t *testing.T) {
+	kc := &lockableKeychain{MockKeychain: keychain.NewMockKeychain(), locked: true}
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request should be sent without a token")
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	_, err := client.ListProfiles()
+	if !errors.Is(err, ErrKeychainLocked) {
+		t.Fatalf("expected ErrKeychainLocked, got %v", err)
+	}
+	if errors.Is(err, ErrNotAuthenticated) {
+		t.Error("a locked keychain must not be reported as not authenticated")
+	}
+	if !strings.Contains(err.Error(), "unlock it and retry") {
+		t.Errorf("expected an actionable message, got %q", err.Error())
+	}
+}
+
+func TestAuthenticatedClient_CachedTokenSurvivesKeychainLock(t *testing.T) {
+	kc := &lockableKeychain{MockKeychain: keychain.NewMockKeychain()}
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer valid-token" {
+			t.Errorf("Authorization = %q, want cached token", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]string{"work"})
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	if _, err := client.ListProfiles(); err != nil {
+		t.Fatalf("first request failed: %v", err)
+	}
+
+	kc.locked = true
+	if _, err := client.ListProfiles(); err != nil {
+		t.Fatalf("expected the cached token to be used after the keychain locked, got %v", err)
+	}
+	if kc.gets != 1 {
+		t.Errorf("keychain read %d times, want 1", kc.gets)
+	}
+}
+
+func TestAuthenticatedClient_LogoutClearsCachedToken(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]string{})
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	if _, err := client.ListProfiles(); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if err := client.Logout(); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	if _, err := client.ListProfiles(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("expected ErrNotAuthenticated after logout, got %v", err)
+	}
+}
+
+func TestAPIKeyClient_AuthenticatedRequest(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		if gotAuth != "Bearer ci-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewAPIKeyClient(server.URL, "ci-key")
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+	resp, err := client.AuthenticatedRequest(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if gotAuth != "Bearer ci-key" {
+		t.Errorf("expected API key as bearer credential, got %q", gotAuth)
+	}
+
+	rejected := NewAPIKeyClient(server.URL, "revoked-key")
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+	if _, err := rejected.AuthenticatedRequest(req); !errors.Is(err, ErrAPIKeyRejected) {
+		t.Errorf("expected ErrAPIKeyRejected, got: %v", err)
+	}
+}
+
+func TestAPIKeyClient_KeychainOperationsUnavailable(t *testing.T) {
+	client := NewAPIKeyClient("http://localhost:8080", "ci-key")
+
+	if err := client.Login("user@example.com", "password"); err == nil {
+		t.Error("expected Login to fail for an API key client")
+	}
+	if err := client.Logout(); err == nil {
+		t.Error("expected Logout to fail for an API key client")
+	}
+}
+
+func TestAuthenticatedClient_TaggedVersions(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	// A tiny versioned store: each upload is a new version, tags name the
+	// newest version carrying them
+	var versions []Profile
+	tags := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/profiles":
+			var p Profile
+			_ = json.NewDecoder(r.Body).Decode(&p)
+			p.Version = len(versions) + 1
+			versions = append(versions, p)
+			if tag := r.URL.Query().Get("tag"); tag != "" {
+				tags[tag] = p.Version
+			}
+			_ = json.NewEncoder(w).Encode(p)
+		case r.URL.Path == "/api/v1/profiles/work/tags":
+			list := []ProfileTag{}
+			for tag, v := range tags {
+				list = append(list, ProfileTag{Tag: tag, Version: v})
+			}
+			_ = json.NewEncoder(w).Encode(list)
+		case strings.HasPrefix(r.URL.Path, "/api/v1/profiles/work/tags/"):
+			v, ok := tags[strings.TrimPrefix(r.URL.Path, "/api/v1/profiles/work/tags/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(versions[v-1])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	upload := func(version, tag string) *UploadResult {
+		t.Helper()
+		result, err := client.UploadProfileWithOptions(&Profile{
+			Name:       "work",
+			Extensions: []Extension{{ID: "golang.go", Version: version, Enabled: true}},
+		}, UploadOptions{Tag: tag})
+		if err != nil {
+			t.Fatalf("upload failed: %v", err)
+		}
+		return result
+	}
+
+	upload("0.40.0", "")
+	if result := upload("0.41.0", "stable"); result.Version != 2 {
+		t.Errorf("tagged upload created version %d, want 2", result.Version)
+	}
+	upload("0.42.0", "")
+
+	prof, err := client.DownloadProfileByTag("work", "stable")
+	if err != nil {
+		t.Fatalf("DownloadProfileByTag failed: %v", err)
+	}
+	if prof.Version != 2 || prof.Extensions[0].Version != "0.41.0" {
+		t.Errorf("stable = version %d with golang.go %s, want version 2 with 0.41.0", prof.Version, prof.Extensions[0].Version)
+	}
+
+	list, err := client.ListProfileTags("work")
+	if err != nil {
+		t.Fatalf("ListProfileTags failed: %v", err)
+	}
+	if len(list) != 1 || list[0].Tag != "stable" || list[0].Version != 2 {
+		t.Errorf("ListProfileTags = %+v, want stable at version 2", list)
+	}
+
+	if _, err := client.DownloadProfileByTag("work", "missing"); err == nil || !strings.Contains(err.Error(), "with tag 'missing' not found") {
+		t.Errorf("expected tag not found error, got %v", err)
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	for _, valid := range []string{"stable", "v2025.1", "rc_1-b"} {
+		if err := ValidateTag(valid); err != nil {
+			t.Errorf("ValidateTag(%q) failed: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "a b", "a/b", strings.Repeat("x", 65)} {
+		if err := ValidateTag(invalid); err == nil {
+			t.Errorf("ValidateTag(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestAuthenticatedClient_ListProfilesModifiedSince(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	base := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	stored := []ProfileSummary{
+		{Name: "home", UpdatedAt: base.Add(-time.Hour)},
+		{Name: "work", UpdatedAt: base.Add(time.Hour)},
+	}
+	var gotQueries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQueries = append(gotQueries, r.URL.RawQuery)
+		list := []ProfileSummary{}
+		for _, p := range stored {
+			since, err := time.Parse(time.RFC3339, r.URL.Query().Get("modified_since"))
+			if err != nil || p.UpdatedAt.After(since) {
+				list = append(list, p)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+
+	all, err := client.ListProfilesModifiedSince(time.Time{})
+	if err != nil {
+		t.Fatalf("ListProfilesModifiedSince failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("zero since listed %d profiles, want 2", len(all))
+	}
+
+	newer, err := client.ListProfilesModifiedSince(base.In(time.FixedZone("CEST", 2*60*60)))
+	if err != nil {
+		t.Fatalf("ListProfilesModifiedSince failed: %v", err)
+	}
+	if len(newer) != 1 || newer[0].Name != "work" {
+		t.Errorf("ListProfilesModifiedSince = %+v, want only work", newer)
+	}
+
+	want := []string{"names_only=false", "names_only=false&modified_since=2026-06-01T12%3A00%3A00Z"}
+	if strings.Join(gotQueries, ",") != strings.Join(want, ",") {
+		t.Errorf("queries = %v, want %v", gotQueries, want)
+	}
+}
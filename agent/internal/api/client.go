@@ -10,7 +10,11 @@ import (
 	"math/rand/v2" // nosemgrep: go.lang.security.audit.crypto.math_random.math-random-used -- used for non-security jitter in retry backoff
 	"net"
 	"net/http"
+	"os"
+	"runtime"
 	"time"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
 )
 
 // MaxResponseSize is the maximum allowed response body size (1MB)
@@ -24,8 +28,33 @@ const (
 	MaxDelay      = 30 * time.Second
 	BackoffFactor = 2.0
 	JitterFactor  = 0.1
+
+	// RetryNoticeThreshold is the retry delay above which the user is told
+	// the client is waiting, so long backoffs don't look like a hang
+	RetryNoticeThreshold = 3 * time.Second
+)
+
+// Connection pool defaults. Idle connections are kept so repeated requests
+// (e.g. sync watch) reuse them instead of dialing and handshaking each time.
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultIdleConnTimeout     = 90 * time.Second
 )
 
+// Request bodies larger than DefaultExpectContinueThreshold are sent with
+// "Expect: 100-continue", so the server can reject them (401, 413, 422)
+// before the body is uploaded. The client waits up to
+// ExpectContinueTimeout for the server's answer before sending the body anyway.
+const (
+	DefaultExpectContinueThreshold = 1 << 20 // 1MB
+	ExpectContinueTimeout          = time.Second
+)
+
+// ErrServerUnreachable is returned when the server cannot be reached at the
+// network level (connection refused, DNS failure, timeout), after retries
+var ErrServerUnreachable = errors.New("server unreachable")
+
 // ErrResponseTooLarge is returned when a server response exceeds MaxResponseSize
 var ErrResponseTooLarge = errors.New("response body exceeds maximum allowed size")
 
@@ -33,22 +62,155 @@ var ErrResponseTooLarge = errors.New("response body exceeds maximum allowed size
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	userAgent  string
+
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+
+	expectContinueThreshold int64
+
+	maxRetries    int
+	initialDelay  time.Duration
+	maxDelay      time.Duration
+	retryOutput   io.Writer
+	retryStatuses map[int]bool
+	sleep         func(time.Duration)
+}
+
+// ClientOption configures optional Client behavior
+type ClientOption func(*Client)
+
+// WithUserAgent overrides the User-Agent header sent on every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout overrides how long a single request attempt may take, including
+// reading the response body (default 10s)
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithMaxRetries overrides how many times a failed request is retried
+// (default MaxRetries). Zero or less sends each request once, e.g. for a
+// quick probe that should report an unreachable server rather than wait for it.
+func WithMaxRetries(maxRetries int) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = max(maxRetries, 0)
+	}
+}
+
+// WithBackoff overrides the initial and maximum delay between retries
+func WithBackoff(initialDelay, maxDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.initialDelay = initialDelay
+		c.maxDelay = maxDelay
+	}
+}
+
+// WithRetryOutput sets where retry notices are written (default os.Stderr).
+// Pass io.Discard to silence them.
+func WithRetryOutput(w io.Writer) ClientOption {
+	return func(c *Client) {
+		c.retryOutput = w
+	}
+}
+
+// WithRetryStatuses adds HTTP statuses to retry on top of the default 429,
+// 502, 503 and 504, e.g. 500 behind a gateway that reports transient errors
+// that way. Only 429 and 5xx statuses are accepted, so requests rejected
+// with other 4xx statuses are never resent; codes failing
+// ValidateRetryStatus are ignored.
+func WithRetryStatuses(codes ...int) ClientOption {
+	return func(c *Client) {
+		for _, code := range codes {
+			if ValidateRetryStatus(code) != nil {
+				continue
+			}
+			if c.retryStatuses == nil {
+				c.retryStatuses = make(map[int]bool)
+			}
+			c.retryStatuses[code] = true
+		}
+	}
+}
+
+// ValidateRetryStatus checks that code may be passed to WithRetryStatuses:
+// 429 Too Many Requests or a 5xx server error
+func ValidateRetryStatus(code int) error {
+	if code == http.StatusTooManyRequests || (code >= 500 && code <= 599) {
+		return nil
+	}
+	return fmt.Errorf("HTTP status %d cannot be retried: only 429 and 5xx statuses are allowed", code)
+}
+
+// WithConnectionPool overrides how many idle connections are kept open, in
+// total and per host, and how long an idle connection is kept before closing
+func WithConnectionPool(maxIdleConns, maxIdleConnsPerHost int, idleConnTimeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.maxIdleConns = maxIdleConns
+		c.maxIdleConnsPerHost = maxIdleConnsPerHost
+		c.idleConnTimeout = idleConnTimeout
+	}
+}
+
+// WithExpectContinueThreshold overrides the request body size above which
+// requests are sent with "Expect: 100-continue". Zero or less disables it.
+func WithExpectContinueThreshold(size int64) ClientOption {
+	return func(c *Client) {
+		c.expectContinueThreshold = size
+	}
+}
+
+// UserAgent builds the User-Agent string identifying this agent build,
+// e.g. "devtools-sync-agent/0.1.0 (linux/amd64)"
+func UserAgent(version string) string {
+	return fmt.Sprintf("devtools-sync-agent/%s (%s/%s)", version, runtime.GOOS, runtime.GOARCH)
 }
 
 // HealthResponse represents the server health check response
 type HealthResponse struct {
 	Status  string `json:"status"`
 	Service string `json:"service"`
+	Version string `json:"version,omitempty"`
 }
 
 // NewClient creates a new API client
-func NewClient(baseURL string) *Client {
-	return &Client{
+func NewClient(baseURL string, opts ...ClientOption) *Client {
+	c := &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-	}
+		userAgent:               UserAgent("dev"),
+		maxIdleConns:            DefaultMaxIdleConns,
+		maxIdleConnsPerHost:     DefaultMaxIdleConnsPerHost,
+		idleConnTimeout:         DefaultIdleConnTimeout,
+		expectContinueThreshold: DefaultExpectContinueThreshold,
+		maxRetries:              MaxRetries,
+		initialDelay:            InitialDelay,
+		maxDelay:                MaxDelay,
+		retryOutput:             os.Stderr,
+		sleep:                   time.Sleep,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = c.maxIdleConns
+	transport.MaxIdleConnsPerHost = c.maxIdleConnsPerHost
+	transport.IdleConnTimeout = c.idleConnTimeout
+	transport.ExpectContinueTimeout = ExpectContinueTimeout
+	c.httpClient.Transport = transport
+
+	return c
 }
 
 // Health checks if the server is healthy
@@ -64,9 +226,7 @@ func (c *Client) Health() (*HealthResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to server: %w", err)
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+	defer closeResponse(resp)
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
@@ -85,6 +245,61 @@ func (c *Client) Health() (*HealthResponse, error) {
 	return &health, nil
 }
 
+// FetchURL downloads the document at rawURL, which need not be on the sync
+// server, with the client's retries and response size limit. Redirects to
+// anything but https are refused unless allowHTTP is set, so an https URL
+// cannot be downgraded to plain http along the way.
+func (c *Client) FetchURL(rawURL string, allowHTTP bool) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Copy the client so the redirect policy applies to this fetch only
+	fetcher := *c
+	httpClient := *c.httpClient
+	httpClient.CheckRedirect = httpsRedirectPolicy(allowHTTP)
+	fetcher.httpClient = &httpClient
+
+	resp, err := fetcher.retryableRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s returned status %d", rawURL, resp.StatusCode)
+	}
+
+	return readLimitedResponse(resp.Body, MaxResponseSize)
+}
+
+// maxRedirects matches net/http's default redirect limit
+const maxRedirects = 10
+
+// httpsRedirectPolicy returns an http.Client CheckRedirect func that follows
+// up to maxRedirects redirects, refusing non-https targets unless allowHTTP
+// is set
+func httpsRedirectPolicy(allowHTTP bool) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		if req.URL.Scheme != "https" && !allowHTTP {
+			return fmt.Errorf("refusing to follow redirect to non-https URL %s", req.URL.Redacted())
+		}
+		return nil
+	}
+}
+
+// closeResponse drains any unread body before closing it, so the
+// connection can be reused for the next request. Bodies larger than
+// MaxResponseSize are not drained and the connection is dropped.
+func closeResponse(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, MaxResponseSize))
+	_ = resp.Body.Close()
+}
+
 // readLimitedResponse reads up to maxSize bytes from the reader.
 // Returns ErrResponseTooLarge if the response exceeds the limit.
 func readLimitedResponse(r io.Reader, maxSize int64) ([]byte, error) {
@@ -101,12 +316,41 @@ func readLimitedResponse(r io.Reader, maxSize int64) ([]byte, error) {
 	return body, nil
 }
 
+// newJSONRequest creates a request with a JSON body that can be replayed:
+// GetBody is always set, so retries and re-login resend the full body
+func newJSONRequest(method, url string, data []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return req, nil
+}
+
+// checkReplayableBody rejects requests whose body could not be sent again
+func checkReplayableBody(req *http.Request) error {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return fmt.Errorf("request body cannot be retried (GetBody not set)")
+	}
+	return nil
+}
+
 // retryableRequest executes an HTTP request with exponential backoff retry
 func (c *Client) retryableRequest(req *http.Request) (*http.Response, error) {
 	var resp *http.Response
 	var err error
 
-	for attempt := 0; attempt <= MaxRetries; attempt++ {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.expectContinueThreshold > 0 && req.ContentLength > c.expectContinueThreshold {
+		req.Header.Set("Expect", "100-continue")
+	}
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		// Clone request body for retries
 		if attempt > 0 && req.Body != nil {
 			// For simplicity, we require GetBody to be set for retryable POST/PUT
@@ -123,41 +367,53 @@ func (c *Client) retryableRequest(req *http.Request) (*http.Response, error) {
 		resp, err = c.httpClient.Do(req)
 
 		// Success - return response
-		if err == nil && !isRetryableStatus(resp.StatusCode) {
+		if err == nil && !c.shouldRetryStatus(resp.StatusCode) {
 			return resp, nil
 		}
 
 		// Check if error is retryable
 		if err != nil && !isRetryableError(err) {
-			return nil, err
+			return nil, unreachableError(err)
 		}
 
 		// Check if status is retryable
-		if err == nil && !isRetryableStatus(resp.StatusCode) {
+		if err == nil && !c.shouldRetryStatus(resp.StatusCode) {
 			return resp, nil
 		}
 
 		// Don't retry after last attempt
-		if attempt == MaxRetries {
+		if attempt == c.maxRetries {
 			if err != nil {
-				return nil, err
+				return nil, unreachableError(err)
 			}
 			return resp, nil
 		}
 
 		// Calculate delay with exponential backoff and jitter
-		delay := calculateDelay(attempt)
-		time.Sleep(delay)
+		delay := c.calculateDelay(attempt)
+		if delay > RetryNoticeThreshold {
+			_, _ = fmt.Fprintf(c.retryOutput, "server unavailable, retrying in %s...\n", delay.Round(time.Second))
+		}
+		c.sleep(delay)
 
 		// Close response body before retry
 		if resp != nil {
-			_ = resp.Body.Close()
+			closeResponse(resp)
 		}
 	}
 
 	return resp, err
 }
 
+// unreachableError marks a network-level request failure with ErrServerUnreachable
+func unreachableError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %w", ErrServerUnreachable, err)
+	}
+	return err
+}
+
 // isRetryableError checks if an error should trigger a retry
 func isRetryableError(err error) bool {
 	// Network errors are retryable
@@ -186,14 +442,20 @@ func isRetryableStatus(status int) bool {
 	}
 }
 
+// shouldRetryStatus checks status against the default retryable statuses
+// and any added with WithRetryStatuses
+func (c *Client) shouldRetryStatus(status int) bool {
+	return isRetryableStatus(status) || c.retryStatuses[status]
+}
+
 // calculateDelay computes the delay with exponential backoff and jitter
-func calculateDelay(attempt int) time.Duration {
-	// Exponential backoff: InitialDelay * (BackoffFactor ^ attempt)
-	delay := float64(InitialDelay) * math.Pow(BackoffFactor, float64(attempt))
+func (c *Client) calculateDelay(attempt int) time.Duration {
+	// Exponential backoff: initialDelay * (BackoffFactor ^ attempt)
+	delay := float64(c.initialDelay) * math.Pow(BackoffFactor, float64(attempt))
 
-	// Cap at MaxDelay
-	if delay > float64(MaxDelay) {
-		delay = float64(MaxDelay)
+	// Cap at maxDelay
+	if delay > float64(c.maxDelay) {
+		delay = float64(c.maxDelay)
 	}
 
 	// Add jitter: ±10%
@@ -205,25 +467,54 @@ func calculateDelay(attempt int) time.Duration {
 
 // Profile represents an extension profile (matches internal/profile.Profile)
 type Profile struct {
-	Name       string      `json:"name"`
-	CreatedAt  time.Time   `json:"created_at"`
-	UpdatedAt  time.Time   `json:"updated_at"`
-	Extensions []Extension `json:"extensions"`
+	Name        string      `json:"name"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Extensions  []Extension `json:"extensions"`
+	Description string      `json:"description,omitempty"`
+
+	// Settings and Keybindings carry the profile's settings.json and
+	// keybindings.json (matches internal/profile.Profile)
+	Settings    json.RawMessage `json:"settings,omitempty"`
+	Keybindings json.RawMessage `json:"keybindings,omitempty"`
+
+	// Version is the number of the server upload this copy comes from; it is
+	// set by the server and ignored on upload
+	Version int `json:"version,omitempty"`
 }
 
 // Extension represents a VS Code extension (matches internal/profile.Extension)
 type Extension struct {
-	ID      string `json:"id"`
-	Version string `json:"version"`
-	Enabled bool   `json:"enabled"`
+	ID       string `json:"id"`
+	Version  string `json:"version"`
+	Enabled  bool   `json:"enabled"`
+	Required *bool  `json:"required,omitempty"`
+
+	VersionConstraint string `json:"version_constraint,omitempty"`
+
+	DisplayName string `json:"display_name,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
-// UploadProfile sends a profile to the server
+// normalizedForUpload returns a copy of profile with its extensions
+// deduplicated by ID, keeping the highest version of each, and sorted (see
+// vscode.NormalizeExtensions), leaving the caller's profile untouched
+func normalizedForUpload(profile *Profile) *Profile {
+	normalized := *profile
+	normalized.Extensions = vscode.NormalizeExtensions(profile.Extensions,
+		func(ext Extension) string { return ext.ID },
+		func(ext Extension) string { return ext.Version })
+	return &normalized
+}
+
+// UploadProfile sends a profile to the server. Extensions are normalized
+// first (see normalizedForUpload).
 func (c *Client) UploadProfile(profile *Profile) error {
 	url := fmt.Sprintf("%s/api/v1/profiles", c.baseURL)
 
 	// Marshal profile to JSON
-	data, err := json.Marshal(profile)
+	data, err := json.Marshal(normalizedForUpload(profile))
 	if err != nil {
 		return fmt.Errorf("failed to marshal profile: %w", err)
 	}
@@ -243,9 +534,7 @@ func (c *Client) UploadProfile(profile *Profile) error {
 	if err != nil {
 		return fmt.Errorf("failed to upload profile: %w", err)
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+	defer closeResponse(resp)
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
@@ -258,7 +547,7 @@ func (c *Client) UploadProfile(profile *Profile) error {
 
 // ListProfiles retrieves all profile names from server
 func (c *Client) ListProfiles() ([]string, error) {
-	url := fmt.Sprintf("%s/api/v1/profiles", c.baseURL)
+	url := fmt.Sprintf("%s/api/v1/profiles?names_only=true", c.baseURL)
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
@@ -269,9 +558,7 @@ func (c *Client) ListProfiles() ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to list profiles: %w", err)
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+	defer closeResponse(resp)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
@@ -291,6 +578,57 @@ func (c *Client) ListProfiles() ([]string, error) {
 	return profiles, nil
 }
 
+// ProfileSummary is a profile's metadata as returned by the detailed profile list
+type ProfileSummary struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Extensions  int       `json:"extensions"`
+
+	// ModifiedAt is the server's own modification time, the cursor for
+	// ListProfilesModifiedSince. Zero from servers that predate it.
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ListProfilesDetailed retrieves the metadata of all profiles from the server
+func (c *Client) ListProfilesDetailed() ([]ProfileSummary, error) {
+	url := fmt.Sprintf("%s/api/v1/profiles?names_only=false", c.baseURL)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.retryableRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	defer closeResponse(resp)
+
+	return parseProfileSummaries(resp)
+}
+
+// parseProfileSummaries decodes a detailed profile list response
+func parseProfileSummaries(resp *http.Response) ([]ProfileSummary, error) {
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []ProfileSummary
+	if err := json.Unmarshal(body, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return summaries, nil
+}
+
 // DownloadProfile retrieves a specific profile from the server
 func (c *Client) DownloadProfile(name string) (*Profile, error) {
 	url := fmt.Sprintf("%s/api/v1/profiles/%s", c.baseURL, name)
@@ -304,9 +642,7 @@ func (c *Client) DownloadProfile(name string) (*Profile, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to download profile: %w", err)
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+	defer closeResponse(resp)
 
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, fmt.Errorf("profile '%s' not found on server", name)
@@ -330,11 +666,12 @@ func (c *Client) DownloadProfile(name string) (*Profile, error) {
 	return &profile, nil
 }
 
-// UpdateProfile updates an existing profile on the server
+// UpdateProfile updates an existing profile on the server. Extensions are
+// normalized first (see normalizedForUpload).
 func (c *Client) UpdateProfile(name string, profile *Profile) error {
 	url := fmt.Sprintf("%s/api/v1/profiles/%s", c.baseURL, name)
 
-	data, err := json.Marshal(profile)
+	data, err := json.Marshal(normalizedForUpload(profile))
 	if err != nil {
 		return fmt.Errorf("failed to marshal profile: %w", err)
 	}
@@ -352,7 +689,7 @@ func (c *Client) UpdateProfile(name string, profile *Profile) error {
 	if err != nil {
 		return fmt.Errorf("failed to update profile: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
+	defer closeResponse(resp)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
@@ -362,6 +699,61 @@ func (c *Client) UpdateProfile(name string, profile *Profile) error {
 	return nil
 }
 
+// ProfileMetadata holds the profile fields PatchProfile may change. Nil
+// fields are left as they are on the server.
+type ProfileMetadata struct {
+	Description *string    `json:"description,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// PatchProfile updates only the given metadata of a profile on the server,
+// leaving its extensions intact, and returns the updated profile. An empty
+// ProfileMetadata just bumps the profile's UpdatedAt.
+func (c *Client) PatchProfile(name string, meta *ProfileMetadata) (*Profile, error) {
+	url := fmt.Sprintf("%s/api/v1/profiles/%s", c.baseURL, name)
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile metadata: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	resp, err := c.retryableRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch profile: %w", err)
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("profile '%s' not found on server", name)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := readLimitedResponse(resp.Body, MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile Profile
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
 // SyncRequest represents a sync request
 type SyncRequest struct {
 	ProfileName string      `json:"profile_name"`
@@ -405,7 +797,7 @@ func (c *Client) Sync(req *SyncRequest) (*SyncResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to sync: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
+	defer closeResponse(resp)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := readLimitedResponse(resp.Body, MaxResponseSize)
@@ -3,9 +3,13 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -227,6 +231,86 @@ func TestUploadProfile(t *testing.T) {
 	}
 }
 
+func TestNormalizedForUpload(t *testing.T) {
+	exts := []Extension{
+		{ID: "ms-python.python", Version: "2.0.0", Enabled: true},
+		{ID: "golang.go", Version: "0.40.0", Enabled: true},
+		{ID: "Golang.Go", Version: "0.41.0", Enabled: false},
+		{ID: "ms-python.python", Version: "1.9.0", Enabled: true},
+		{ID: "esbenp.prettier-vscode", Version: "10.0.0", Enabled: true},
+	}
+
+	got := normalizedForUpload(&Profile{Name: "work", Extensions: exts}).Extensions
+	want := []Extension{
+		{ID: "esbenp.prettier-vscode", Version: "10.0.0", Enabled: true},
+		{ID: "Golang.Go", Version: "0.41.0", Enabled: false},
+		{ID: "ms-python.python", Version: "2.0.0", Enabled: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizedForUpload() extensions = %+v, want %+v", got, want)
+	}
+}
+
+func TestUploadProfile_NormalizesExtensions(t *testing.T) {
+	var received Profile
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	input := []Extension{
+		{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+		{ID: "golang.go", Version: "0.40.0", Enabled: true},
+		{ID: "ms-python.python", Version: "1.2.0", Enabled: true},
+	}
+	testProfile := &Profile{Name: "test", Extensions: append([]Extension(nil), input...)}
+
+	client := NewClient(server.URL)
+	if err := client.UploadProfile(testProfile); err != nil {
+		t.Fatalf("UploadProfile() error = %v", err)
+	}
+
+	want := []Extension{
+		{ID: "golang.go", Version: "0.40.0", Enabled: true},
+		{ID: "ms-python.python", Version: "1.2.0", Enabled: true},
+	}
+	if !reflect.DeepEqual(received.Extensions, want) {
+		t.Errorf("server received %+v, want %+v", received.Extensions, want)
+	}
+	if !reflect.DeepEqual(testProfile.Extensions, input) {
+		t.Errorf("caller's profile was modified: %+v", testProfile.Extensions)
+	}
+}
+
+func TestUpdateProfile_NormalizesExtensions(t *testing.T) {
+	var received Profile
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testProfile := &Profile{Name: "test", Extensions: []Extension{
+		{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+		{ID: "MS-Python.Python", Version: "1.2.0", Enabled: true},
+	}}
+
+	client := NewClient(server.URL)
+	if err := client.UpdateProfile("test", testProfile); err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+
+	want := []Extension{{ID: "MS-Python.Python", Version: "1.2.0", Enabled: true}}
+	if !reflect.DeepEqual(received.Extensions, want) {
+		t.Errorf("server received %+v, want %+v", received.Extensions, want)
+	}
+}
+
 func TestListProfiles(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -266,6 +350,9 @@ func TestListProfiles(t *testing.T) {
 				if r.Method != http.MethodGet {
 					t.Errorf("expected method GET, got %s", r.Method)
 				}
+				if got := r.URL.Query().Get("names_only"); got != "true" {
+					t.Errorf("expected names_only=true, got %q", got)
+				}
 
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(tt.statusCode)
@@ -299,6 +386,35 @@ func TestListProfiles(t *testing.T) {
 	}
 }
 
+func TestListProfilesDetailed(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/profiles" {
+			t.Errorf("expected path /api/v1/profiles, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("names_only"); got != "false" {
+			t.Errorf("expected names_only=false, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]ProfileSummary{
+			{Name: "work", Description: "office laptop", UpdatedAt: updated, Extensions: 12},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	summaries, err := client.ListProfilesDetailed()
+	if err != nil {
+		t.Fatalf("ListProfilesDetailed() error = %v", err)
+	}
+
+	want := []ProfileSummary{{Name: "work", Description: "office laptop", UpdatedAt: updated, Extensions: 12}}
+	if !reflect.DeepEqual(summaries, want) {
+		t.Errorf("ListProfilesDetailed() = %+v, want %+v", summaries, want)
+	}
+}
+
 func TestDownloadProfile(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -429,6 +545,45 @@ func TestRetryableRequest_RetryOn503(t *testing.T) {
 	}
 }
 
+func TestRetryableRequest_WithMaxRetriesZero(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithMaxRetries(0))
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+
+	resp, err := client.retryableRequest(req)
+	if err != nil {
+		t.Fatalf("expected response, got error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt (no retry), got %d", attempts)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithTimeout(20*time.Millisecond), WithMaxRetries(0))
+
+	_, err := client.Health()
+	if !errors.Is(err, ErrServerUnreachable) {
+		t.Errorf("expected ErrServerUnreachable after the timeout, got: %v", err)
+	}
+}
+
 func TestRetryableRequest_NoRetryOn400(t *testing.T) {
 	attempts := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -455,6 +610,223 @@ func TestRetryableRequest_NoRetryOn400(t *testing.T) {
 	}
 }
 
+func TestRetryableRequest_CustomRetryStatus(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryStatuses(http.StatusInternalServerError))
+	client.sleep = func(time.Duration) {}
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+
+	resp, err := client.retryableRequest(req)
+	if err != nil {
+		t.Fatalf("expected success after retries, got error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if !client.shouldRetryStatus(http.StatusServiceUnavailable) {
+		t.Error("expected default retryable statuses to be kept")
+	}
+}
+
+func TestRetryableRequest_NoRetryOn500ByDefault(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+
+	resp, err := client.retryableRequest(req)
+	if err != nil {
+		t.Fatalf("expected response, got error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt (no retry), got %d", attempts)
+	}
+}
+
+func TestRetryableRequest_NeverRetries400EvenIfAdded(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryStatuses(http.StatusBadRequest, http.StatusConflict))
+	client.sleep = func(time.Duration) {}
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+
+	resp, err := client.retryableRequest(req)
+	if err != nil {
+		t.Fatalf("expected response, got error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt (no retry), got %d", attempts)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestValidateRetryStatus(t *testing.T) {
+	for _, code := range []int{429, 500, 502, 599} {
+		if err := ValidateRetryStatus(code); err != nil {
+			t.Errorf("ValidateRetryStatus(%d) = %v, want nil", code, err)
+		}
+	}
+	for _, code := range []int{200, 400, 401, 404, 409, 600} {
+		if err := ValidateRetryStatus(code); err == nil {
+			t.Errorf("ValidateRetryStatus(%d) = nil, want error", code)
+		}
+	}
+}
+
+func TestUnreachableError(t *testing.T) {
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	if err := unreachableError(netErr); !errors.Is(err, ErrServerUnreachable) || !errors.Is(err, netErr) {
+		t.Errorf("expected network error to wrap ErrServerUnreachable and the cause, got: %v", err)
+	}
+
+	other := errors.New("boom")
+	if err := unreachableError(other); err != other {
+		t.Errorf("expected non-network error to be returned unchanged, got: %v", err)
+	}
+}
+
+func TestClient_UserAgent(t *testing.T) {
+	var gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.UserAgent()
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(HealthResponse{Status: "healthy"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithUserAgent(UserAgent("1.2.3")))
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+
+	if !strings.HasPrefix(gotUserAgent, "devtools-sync-agent/1.2.3 (") {
+		t.Errorf("expected User-Agent to identify agent version 1.2.3, got %q", gotUserAgent)
+	}
+	if strings.HasPrefix(gotUserAgent, "Go-http-client") {
+		t.Errorf("expected custom User-Agent, got Go default %q", gotUserAgent)
+	}
+}
+
+func TestClient_DefaultUserAgent(t *testing.T) {
+	var gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.UserAgent()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+	resp, err := client.retryableRequest(req)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if !strings.HasPrefix(gotUserAgent, "devtools-sync-agent/") {
+		t.Errorf("expected default devtools-sync-agent User-Agent, got %q", gotUserAgent)
+	}
+}
+
+func TestRetryableRequest_LongDelayPrintsNotice(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	output := &strings.Builder{}
+	client := NewClient(server.URL, WithBackoff(8*time.Second, 30*time.Second), WithRetryOutput(output))
+	var slept []time.Duration
+	client.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+	resp, err := client.retryableRequest(req)
+	if err != nil {
+		t.Fatalf("expected success after retry, got error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if len(slept) != 1 {
+		t.Fatalf("expected 1 sleep, got %d", len(slept))
+	}
+	if !strings.Contains(output.String(), "server unavailable, retrying in") {
+		t.Errorf("expected retry notice for long delay, got: %q", output.String())
+	}
+}
+
+func TestRetryableRequest_ShortDelaySuppressesNotice(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	output := &strings.Builder{}
+	client := NewClient(server.URL, WithBackoff(100*time.Millisecond, time.Second), WithRetryOutput(output))
+	client.sleep = func(time.Duration) {}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+	resp, err := client.retryableRequest(req)
+	if err != nil {
+		t.Fatalf("expected success after retries, got error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if output.Len() != 0 {
+		t.Errorf("expected no retry notice for short delays, got: %q", output.String())
+	}
+}
+
+func TestCalculateDelay_RespectsMaxDelay(t *testing.T) {
+	client := NewClient("http://localhost", WithBackoff(time.Second, 5*time.Second))
+
+	// Attempt 10 would be 1024s uncapped; with jitter it must stay within ±10% of the cap
+	delay := client.calculateDelay(10)
+	maxWithJitter := time.Duration(float64(5*time.Second) * (1 + JitterFactor))
+	if delay > maxWithJitter {
+		t.Errorf("expected delay capped near 5s, got %s", delay)
+	}
+}
+
 func TestUpdateProfile(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -495,6 +867,59 @@ func TestUpdateProfile(t *testing.T) {
 	}
 }
 
+func TestPatchProfile(t *testing.T) {
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("expected PATCH, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/v1/profiles/test-profile" {
+			t.Errorf("expected /api/v1/profiles/test-profile, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Profile{
+			Name:        "test-profile",
+			Description: "laptop setup",
+			Extensions:  []Extension{{ID: "test.ext", Version: "1.0.0", Enabled: true}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	desc := "laptop setup"
+	profile, err := client.PatchProfile("test-profile", &ProfileMetadata{Description: &desc})
+	if err != nil {
+		t.Fatalf("PatchProfile() error = %v", err)
+	}
+
+	// Only provided fields are sent, so the server leaves the rest alone
+	if len(gotBody) != 1 || gotBody["description"] != "laptop setup" {
+		t.Errorf("request body = %v, want only description", gotBody)
+	}
+	if profile.Description != "laptop setup" {
+		t.Errorf("Description = %q, want %q", profile.Description, "laptop setup")
+	}
+	if len(profile.Extensions) != 1 {
+		t.Errorf("got %d extensions, want 1", len(profile.Extensions))
+	}
+}
+
+func TestPatchProfile_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if _, err := client.PatchProfile("missing", &ProfileMetadata{}); err == nil {
+		t.Error("expected error for missing profile")
+	}
+}
+
 func TestSync(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -565,3 +990,203 @@ func TestSync_ServerError(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestNewClient_ConnectionPool(t *testing.T) {
+	client := NewClient("http://localhost:8080")
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.httpClient.Transport)
+	}
+	if transport.MaxIdleConns != DefaultMaxIdleConns ||
+		transport.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost ||
+		transport.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Errorf("expected default pool settings, got %d/%d/%s",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+
+	client = NewClient("http://localhost:8080", WithConnectionPool(5, 2, time.Minute))
+	transport = client.httpClient.Transport.(*http.Transport)
+	if transport.MaxIdleConns != 5 || transport.MaxIdleConnsPerHost != 2 || transport.IdleConnTimeout != time.Minute {
+		t.Errorf("expected 5/2/1m0s, got %d/%d/%s",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+}
+
+func TestClient_ReusesConnection(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// UploadProfile ignores the response body; it must still be drained.
+		// Large enough that net/http will not drain it on Close by itself.
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(strings.Repeat("x", 512*1024)))
+	}))
+	var mu sync.Mutex
+	newConns := 0
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			newConns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	for i := 0; i < 3; i++ {
+		if err := client.UploadProfile(&Profile{Name: "work"}); err != nil {
+			t.Fatalf("UploadProfile failed: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if newConns != 1 {
+		t.Errorf("expected 3 sequential requests to share 1 connection, got %d connections", newConns)
+	}
+}
+
+// countingListener counts the bytes the server reads from its connections
+type countingListener struct {
+	net.Listener
+	mu sync.Mutex
+	n  int
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, l: l}, nil
+}
+
+func (l *countingListener) bytesRead() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.n
+}
+
+type countingConn struct {
+	net.Conn
+	l *countingListener
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.l.mu.Lock()
+	c.l.n += n
+	c.l.mu.Unlock()
+	return n, err
+}
+
+func TestClient_ExpectContinueShortCircuitsRejectedUpload(t *testing.T) {
+	var expect string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Reject on headers alone, as the server's body size limit does
+		expect = r.Header.Get("Expect")
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	listener := &countingListener{Listener: server.Listener}
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	const descriptionSize = 512 * 1024
+	client := NewClient(server.URL, WithExpectContinueThreshold(1024))
+	err := client.UploadProfile(&Profile{Name: "work", Description: strings.Repeat("x", descriptionSize)})
+	if err == nil || !strings.Contains(err.Error(), "413") {
+		t.Fatalf("expected a 413 error, got: %v", err)
+	}
+
+	if expect != "100-continue" {
+		t.Errorf("expected Expect: 100-continue on a large upload, got %q", expect)
+	}
+	// Only the request headers should have reached the server
+	if n := listener.bytesRead(); n >= descriptionSize {
+		t.Errorf("expected the rejected body not to be uploaded, server read %d bytes", n)
+	}
+}
+
+func TestClient_ExpectContinueOnlyAboveThreshold(t *testing.T) {
+	var expect []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expect = append(expect, r.Header.Get("Expect"))
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithExpectContinueThreshold(4096))
+	if err := client.UploadProfile(&Profile{Name: "small"}); err != nil {
+		t.Fatalf("UploadProfile failed: %v", err)
+	}
+	if err := client.UploadProfile(&Profile{Name: "large", Description: strings.Repeat("x", 8192)}); err != nil {
+		t.Fatalf("UploadProfile failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(expect, []string{"", "100-continue"}) {
+		t.Errorf("expected Expect only on the large upload, got %q", expect)
+	}
+}
+
+func TestNewClient_ExpectContinueDefaults(t *testing.T) {
+	client := NewClient("http://localhost:8080")
+	if client.expectContinueThreshold != DefaultExpectContinueThreshold {
+		t.Errorf("expected threshold %d, got %d", DefaultExpectContinueThreshold, client.expectContinueThreshold)
+	}
+	transport := client.httpClient.Transport.(*http.Transport)
+	if transport.ExpectContinueTimeout != ExpectContinueTimeout {
+		t.Errorf("expected continue timeout %s, got %s", ExpectContinueTimeout, transport.ExpectContinueTimeout)
+	}
+}
+
+func TestFetchURL_RedirectToHTTP(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"shared"}`))
+	}))
+	defer target.Close()
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/profile.json", http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	client := NewClient("", WithBackoff(time.Millisecond, time.Millisecond), WithRetryOutput(io.Discard))
+
+	if _, err := client.FetchURL(redirector.URL, false); err == nil || !strings.Contains(err.Error(), "non-https") {
+		t.Errorf("expected the http redirect to be refused, got: %v", err)
+	}
+
+	data, err := client.FetchURL(redirector.URL, true)
+	if err != nil {
+		t.Fatalf("expected the redirect to be followed with allowHTTP, got: %v", err)
+	}
+	if string(data) != `{"name":"shared"}` {
+		t.Errorf("unexpected body: %s", data)
+	}
+}
+
+func TestHTTPSRedirectPolicy(t *testing.T) {
+	origin, _ := http.NewRequest(http.MethodGet, "https://example.com/a", nil)
+	via := []*http.Request{origin}
+
+	tests := []struct {
+		target    string
+		allowHTTP bool
+		via       []*http.Request
+		wantErr   bool
+	}{
+		{"https://cdn.example.com/b", false, via, false},
+		{"http://cdn.example.com/b", false, via, true},
+		{"http://cdn.example.com/b", true, via, false},
+		{"https://cdn.example.com/b", false, make([]*http.Request, maxRedirects), true},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+		err := httpsRedirectPolicy(tt.allowHTTP)(req, tt.via)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("redirect to %s (allowHTTP %v, %d hops): error = %v, wantErr %v", tt.target, tt.allowHTTP, len(tt.via), err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+)
+
+// DefaultCompressThreshold is the upload size in bytes at which request
+// bodies are gzip-compressed
+const DefaultCompressThreshold = 4 * 1024
+
+// UploadOptions controls how a profile is uploaded
+type UploadOptions struct {
+	// CompressThreshold is the body size in bytes at or above which the
+	// upload is gzip-compressed. Zero disables compression.
+	CompressThreshold int
+
+	// Tag labels the version created by the upload (e.g. "stable"); see
+	// ValidateTag. Empty uploads without a tag.
+	Tag string
+}
+
+// UploadResult reports the size of an uploaded profile and the version the
+// server recorded for it (0 if the server does not report versions)
+type UploadResult struct {
+	RawSize    int
+	UploadSize int
+	Compressed bool
+	Version    int
+}
+
+// shouldCompress reports whether a body of size bytes should be compressed
+func shouldCompress(size, threshold int) bool {
+	return threshold > 0 && size >= threshold
+}
+
+// gzipBytes compresses data with gzip
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/keychain"
+)
+
+func TestShouldCompress(t *testing.T) {
+	tests := []struct {
+		size      int
+		threshold int
+		want      bool
+	}{
+		{100, 4096, false},
+		{4095, 4096, false},
+		{4096, 4096, true},
+		{10000, 4096, true},
+		{10000, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := shouldCompress(tt.size, tt.threshold); got != tt.want {
+			t.Errorf("shouldCompress(%d, %d) = %v, want %v", tt.size, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+// uploadServer records the size and encoding of the last uploaded body
+func uploadServer(t *testing.T, gotEncoding *string, gotWireSize *int, gotProfile *Profile) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotEncoding = r.Header.Get("Content-Encoding")
+
+		wire, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		*gotWireSize = len(wire)
+
+		var body io.Reader = bytes.NewReader(wire)
+		if *gotEncoding == "gzip" {
+			zr, err := gzip.NewReader(body)
+			if err != nil {
+				t.Fatalf("failed to open gzip body: %v", err)
+			}
+			body = zr
+		}
+		if err := json.NewDecoder(body).Decode(gotProfile); err != nil {
+			t.Fatalf("failed to decode profile: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+}
+
+func largeProfile(extensions int) *Profile {
+	p := &Profile{Name: "large"}
+	for i := 0; i < extensions; i++ {
+		p.Extensions = append(p.Extensions, Extension{ID: fmt.Sprintf("publisher.extension-%d", i), Version: "1.0.0", Enabled: true})
+	}
+	return p
+}
+
+func TestUploadProfileWithOptions_BelowThresholdUploadsRaw(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	var encoding string
+	var wireSize int
+	var received Profile
+	server := uploadServer(t, &encoding, &wireSize, &received)
+	defer server.Close()
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	result, err := client.UploadProfileWithOptions(&Profile{Name: "small"}, UploadOptions{CompressThreshold: DefaultCompressThreshold})
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	if result.Compressed || encoding != "" {
+		t.Errorf("expected raw upload, got compressed=%v encoding=%q", result.Compressed, encoding)
+	}
+	if result.RawSize != wireSize || result.UploadSize != wireSize {
+		t.Errorf("reported sizes raw=%d upload=%d, want both %d", result.RawSize, result.UploadSize, wireSize)
+	}
+	if received.Name != "small" {
+		t.Errorf("server received profile %q, want 'small'", received.Name)
+	}
+}
+
+func TestUploadProfileWithOptions_AboveThresholdCompresses(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	var encoding string
+	var wireSize int
+	var received Profile
+	server := uploadServer(t, &encoding, &wireSize, &received)
+	defer server.Close()
+
+	profile := largeProfile(200)
+	raw, _ := json.Marshal(profile)
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	result, err := client.UploadProfileWithOptions(profile, UploadOptions{CompressThreshold: DefaultCompressThreshold})
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	if !result.Compressed || encoding != "gzip" {
+		t.Fatalf("expected gzip upload, got compressed=%v encoding=%q", result.Compressed, encoding)
+	}
+	if result.RawSize != len(raw) {
+		t.Errorf("reported raw size %d, want %d", result.RawSize, len(raw))
+	}
+	if result.UploadSize != wireSize {
+		t.Errorf("reported upload size %d, want %d bytes on the wire", result.UploadSize, wireSize)
+	}
+	if result.UploadSize >= result.RawSize {
+		t.Errorf("expected compressed size %d to be smaller than raw %d", result.UploadSize, result.RawSize)
+	}
+	if len(received.Extensions) != 200 {
+		t.Errorf("server received %d extensions, want 200", len(received.Extensions))
+	}
+}
+
+func TestUploadProfileWithOptions_NormalizesExtensions(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	_ = kc.Set(keychain.KeyAccessToken, "valid-token")
+
+	var encoding string
+	var wireSize int
+	var received Profile
+	server := uploadServer(t, &encoding, &wireSize, &received)
+	defer server.Close()
+
+	profile := &Profile{Name: "work", Extensions: []Extension{
+		{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+		{ID: "golang.go", Version: "0.40.0", Enabled: true},
+		{ID: "golang.go", Version: "0.39.0", Enabled: true},
+	}}
+
+	client := NewAuthenticatedClient(server.URL, kc)
+	if _, err := client.UploadProfileWithOptions(profile, UploadOptions{}); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	if len(received.Extensions) != 2 || received.Extensions[0].ID != "golang.go" || received.Extensions[0].Version != "0.40.0" {
+		t.Errorf("server received %+v, want sorted list with golang.go 0.40.0 first", received.Extensions)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenClaims are the claims of a server-issued access token
+type TokenClaims struct {
+	Subject   string
+	Email     string
+	Role      string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the token had expired at now
+func (c *TokenClaims) Expired(now time.Time) bool {
+	return tokenExpired(c.ExpiresAt, now)
+}
+
+// tokenExpired reports whether a token expiring at exp has expired at now.
+// A token without an expiry never expires.
+func tokenExpired(exp, now time.Time) bool {
+	return !exp.IsZero() && !now.Before(exp)
+}
+
+// DecodeTokenClaims reads the claims of a JWT access token WITHOUT verifying
+// its signature. Only use it to inspect a token locally; the server remains
+// the authority on whether a token is valid.
+func DecodeTokenClaims(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed access token: expected 3 parts, got %d", len(parts))
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("malformed access token payload: %w", err)
+	}
+
+	var raw struct {
+		Sub   string `json:"sub"`
+		Email string `json:"email"`
+		Role  string `json:"role"`
+		Iat   int64  `json:"iat"`
+		Exp   int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("malformed access token claims: %w", err)
+	}
+
+	claims := &TokenClaims{
+		Subject: raw.Sub,
+		Email:   raw.Email,
+		Role:    raw.Role,
+	}
+	if raw.Iat != 0 {
+		claims.IssuedAt = time.Unix(raw.Iat, 0)
+	}
+	if raw.Exp != 0 {
+		claims.ExpiresAt = time.Unix(raw.Exp, 0)
+	}
+	return claims, nil
+}
+
+// StoredTokenClaims decodes the claims of the access token stored in the
+// keychain, without contacting the server or verifying the token
+func (ac *AuthenticatedClient) StoredTokenClaims() (*TokenClaims, error) {
+	if ac.apiKey != "" {
+		return nil, errAPIKeyInUse
+	}
+
+	token, err := ac.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeTokenClaims(token)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/keychain"
+)
+
+// testAccessToken is an HS256 token for dev@example.com (admin) issued at
+// 2026-01-01T00:00:00Z and expiring 15 minutes later
+const testAccessToken = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+	"eyJzdWIiOiI2ZjFjMmI4ZS0zZDRhLTRjNWItOWU3Zi0wYTFiMmMzZDRlNWYiLCJlbWFpbCI6ImRldkBleGFtcGxlLmNvbSIsInJvbGUiOiJhZG1pbiIsImlhdCI6MTc2NzIyNTYwMCwiZXhwIjoxNzY3MjI2NTAwfQ." +
+	"c_wCs1bN8uin_puKN-5TRkMdT1N5clTkwyn31PtEbig"
+
+func TestDecodeTokenClaims(t *testing.T) {
+	claims, err := DecodeTokenClaims(testAccessToken)
+	if err != nil {
+		t.Fatalf("DecodeTokenClaims failed: %v", err)
+	}
+
+	issued := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if claims.Subject != "6f1c2b8e-3d4a-4c5b-9e7f-0a1b2c3d4e5f" {
+		t.Errorf("Subject = %q", claims.Subject)
+	}
+	if claims.Email != "dev@example.com" || claims.Role != "admin" {
+		t.Errorf("Email, Role = %q, %q; want dev@example.com, admin", claims.Email, claims.Role)
+	}
+	if !claims.IssuedAt.Equal(issued) {
+		t.Errorf("IssuedAt = %v, want %v", claims.IssuedAt, issued)
+	}
+	if !claims.ExpiresAt.Equal(issued.Add(15 * time.Minute)) {
+		t.Errorf("ExpiresAt = %v, want %v", claims.ExpiresAt, issued.Add(15*time.Minute))
+	}
+
+	if claims.Expired(issued.Add(14 * time.Minute)) {
+		t.Error("expected token to be valid before exp")
+	}
+	if !claims.Expired(issued.Add(15 * time.Minute)) {
+		t.Error("expected token to be expired at exp")
+	}
+}
+
+func TestDecodeTokenClaims_Malformed(t *testing.T) {
+	for _, token := range []string{"", "not-a-jwt", "a.!!!.c", "a.bm90IGpzb24.c"} {
+		if _, err := DecodeTokenClaims(token); err == nil {
+			t.Errorf("DecodeTokenClaims(%q) succeeded, want error", token)
+		}
+	}
+}
+
+func TestTokenExpired_NoExpiry(t *testing.T) {
+	if tokenExpired(time.Time{}, time.Now()) {
+		t.Error("expected a token without exp never to expire")
+	}
+}
+
+func TestAuthenticatedClient_StoredTokenClaims(t *testing.T) {
+	kc := keychain.NewMockKeychain()
+	client := NewAuthenticatedClient("http://localhost", kc)
+
+	if _, err := client.StoredTokenClaims(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("expected ErrNotAuthenticated without a token, got: %v", err)
+	}
+
+	_ = kc.Set(keychain.KeyAccessToken, testAccessToken)
+	claims, err := client.StoredTokenClaims()
+	if err != nil {
+		t.Fatalf("StoredTokenClaims failed: %v", err)
+	}
+	if claims.Email != "dev@example.com" {
+		t.Errorf("Email = %q, want dev@example.com", claims.Email)
+	}
+
+	if _, err := NewAPIKeyClient("http://localhost", "key").StoredTokenClaims(); err == nil {
+		t.Error("expected API key client to have no stored token")
+	}
+}
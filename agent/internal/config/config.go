@@ -3,28 +3,59 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
 	"gopkg.in/yaml.v3"
 )
 
-// Config holds the agent configuration
+// Config holds the agent configuration. The JSON names match the YAML keys.
 type Config struct {
 	Server struct {
-		URL string `yaml:"url"`
-	} `yaml:"server"`
+		URL string `yaml:"url" json:"url"`
+	} `yaml:"server" json:"server"`
 	Profiles struct {
-		Directory string `yaml:"directory"`
-	} `yaml:"profiles"`
+		Directory string `yaml:"directory" json:"directory"`
+	} `yaml:"profiles" json:"profiles"`
 	Logging struct {
-		Level string `yaml:"level"`
-	} `yaml:"logging"`
+		Level string `yaml:"level" json:"level"`
+	} `yaml:"logging" json:"logging"`
+	VSCode struct {
+		// BlockedExtensions are extension IDs or publisher wildcards
+		// (publisher.*) that profile load never installs
+		BlockedExtensions []string `yaml:"blocked_extensions,omitempty" json:"blocked_extensions"`
+	} `yaml:"vscode" json:"vscode"`
+}
+
+// LogLevels are the accepted values for logging.level
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// DefaultLogLevel is the logging.level used when none, or an unknown one, is set
+const DefaultLogLevel = "info"
+
+// WarningOutput receives the warnings Load prints about settings it ignores
+// (can be overridden in tests)
+var WarningOutput io.Writer = os.Stderr
+
+// configPath overrides the default config file location when set
+var configPath string
+
+// SetPath makes GetConfigPath, Load and Save use the config file at path
+// instead of ~/.devtools-sync/config.yaml. An empty path restores the default.
+func SetPath(path string) {
+	configPath = path
 }
 
 // GetConfigPath returns the path to the config file
 func GetConfigPath() string {
+	if configPath != "" {
+		return configPath
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -41,23 +72,33 @@ func GetConfigDir() string {
 	return filepath.Join(homeDir, ".devtools-sync")
 }
 
-// Load reads configuration from YAML file and applies environment variable overrides
+// Load reads configuration from YAML file and applies environment variable
+// overrides. An unknown logging.level or invalid vscode.blocked_extensions
+// entry does not fail the load: it is reported on WarningOutput and replaced
+// by the default, so a typo does not break every command. Validate applies
+// the strict checks when writing the config.
 func Load() (*Config, error) {
 	cfg := &Config{}
 
 	// Set defaults
 	cfg.Server.URL = "http://localhost:8080"
 	cfg.Profiles.Directory = filepath.Join(GetConfigDir(), "profiles")
-	cfg.Logging.Level = "info"
+	cfg.Logging.Level = DefaultLogLevel
 
 	// Try to read config file
-	configPath := GetConfigPath()
-	if data, err := os.ReadFile(configPath); err == nil {
+	data, err := os.ReadFile(GetConfigPath())
+	switch {
+	case err == nil:
 		if err := yaml.Unmarshal(data, cfg); err != nil {
 			return nil, fmt.Errorf("failed to parse config file: %w", err)
 		}
+	case configPath != "" && os.IsNotExist(err):
+		// A file named with SetPath must exist
+		return nil, fmt.Errorf("config file %s does not exist", configPath)
+	case configPath != "":
+		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
 	}
-	// If file doesn't exist, we just use defaults (not an error)
+	// If the default file doesn't exist, we just use defaults (not an error)
 
 	// Apply environment variable overrides
 	if serverURL := os.Getenv("DEVTOOLS_SYNC_SERVER_URL"); serverURL != "" {
@@ -67,6 +108,8 @@ func Load() (*Config, error) {
 		cfg.Logging.Level = logLevel
 	}
 
+	cfg.dropInvalidSettings()
+
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -93,9 +136,60 @@ func (c *Config) Validate() error {
 		return errors.New("server URL must include a host")
 	}
 
+	// An empty level falls back to the default
+	if c.Logging.Level != "" && !slices.Contains(LogLevels, c.Logging.Level) {
+		return fmt.Errorf("logging level must be one of %s, got: %s", strings.Join(LogLevels, ", "), c.Logging.Level)
+	}
+
+	for _, pattern := range c.VSCode.BlockedExtensions {
+		if err := vscode.ValidateExtensionPattern(pattern); err != nil {
+			return fmt.Errorf("invalid vscode.blocked_extensions entry: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// dropInvalidSettings resets the settings Load tolerates to their defaults
+// when invalid, warning about each
+func (c *Config) dropInvalidSettings() {
+	if c.Logging.Level != "" && !slices.Contains(LogLevels, c.Logging.Level) {
+		fmt.Fprintf(WarningOutput, "Warning: ignoring unknown logging level %q (must be one of %s); using %s\n",
+			c.Logging.Level, strings.Join(LogLevels, ", "), DefaultLogLevel)
+		c.Logging.Level = DefaultLogLevel
+	}
+
+	var blocked []string
+	for _, pattern := range c.VSCode.BlockedExtensions {
+		if err := vscode.ValidateExtensionPattern(pattern); err != nil {
+			fmt.Fprintf(WarningOutput, "Warning: ignoring vscode.blocked_extensions entry: %v\n", err)
+			continue
+		}
+		blocked = append(blocked, pattern)
+	}
+	if len(blocked) != len(c.VSCode.BlockedExtensions) {
+		c.VSCode.BlockedExtensions = blocked
+	}
+}
+
+// RedactedValue replaces sensitive values in Redacted output
+const RedactedValue = "REDACTED"
+
+// Redacted returns a copy of the config with sensitive values masked, safe
+// to share in bug reports. Credentials embedded in the server URL are the
+// only sensitive values today; new secret fields must be masked here too.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.VSCode.BlockedExtensions = slices.Clone(c.VSCode.BlockedExtensions)
+
+	if parsedURL, err := url.Parse(c.Server.URL); err == nil && parsedURL.User != nil {
+		parsedURL.User = url.UserPassword(RedactedValue, RedactedValue)
+		redacted.Server.URL = parsedURL.String()
+	}
+
+	return &redacted
+}
+
 // IsInsecure returns true if the server URL uses HTTP on a non-localhost host
 func (c *Config) IsInsecure() bool {
 	parsedURL, err := url.Parse(c.Server.URL)
@@ -113,11 +207,10 @@ func (c *Config) IsInsecure() bool {
 
 // Save writes the configuration to the YAML file
 func (c *Config) Save() error {
-	configPath := GetConfigPath()
+	path := GetConfigPath()
 
 	// Ensure config directory exists
-	configDir := GetConfigDir()
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
@@ -126,7 +219,7 @@ func (c *Config) Save() error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
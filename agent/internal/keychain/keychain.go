@@ -11,6 +11,10 @@ import (
 // ErrNotFound is returned when a key doesn't exist
 var ErrNotFound = errors.New("key not found in keychain")
 
+// ErrUnavailable is returned when the OS keychain cannot be accessed, for
+// example because it is locked, as opposed to the key not existing
+var ErrUnavailable = errors.New("keychain unavailable")
+
 // Key constants for storing credentials
 const (
 	KeyAccessToken  = "devtools-sync-token"
@@ -77,7 +81,7 @@ func NewSystemKeychain() *SystemKeychain {
 func (s *SystemKeychain) Set(key, value string) error {
 	err := keyring.Set(ServiceName, key, value)
 	if err != nil {
-		return fmt.Errorf("failed to store in keychain: %w", err)
+		return fmt.Errorf("failed to store in keychain: %w: %w", ErrUnavailable, err)
 	}
 	return nil
 }
@@ -89,7 +93,7 @@ func (s *SystemKeychain) Get(key string) (string, error) {
 		if errors.Is(err, keyring.ErrNotFound) {
 			return "", ErrNotFound
 		}
-		return "", fmt.Errorf("failed to retrieve from keychain: %w", err)
+		return "", fmt.Errorf("failed to retrieve from keychain: %w: %w", ErrUnavailable, err)
 	}
 	return value, nil
 }
@@ -101,7 +105,7 @@ func (s *SystemKeychain) Delete(key string) error {
 		if errors.Is(err, keyring.ErrNotFound) {
 			return nil // Already deleted
 		}
-		return fmt.Errorf("failed to delete from keychain: %w", err)
+		return fmt.Errorf("failed to delete from keychain: %w: %w", ErrUnavailable, err)
 	}
 	return nil
 }
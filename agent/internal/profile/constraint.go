@@ -0,0 +1,110 @@
+package profile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// VersionLister returns the published versions of an extension;
+// satisfied by *vscode.MarketplaceClient
+type VersionLister interface {
+	ExtensionVersions(extensionID string) ([]string, error)
+}
+
+// versionRange is the half-open range [min, max) of versions a constraint
+// allows, as canonical "vMAJOR.MINOR.PATCH" strings
+type versionRange struct {
+	min, max string
+}
+
+// parseConstraint parses a version constraint:
+//
+//	^1.2.3  >=1.2.3 <2.0.0 (^0.2.3 allows <0.3.0, ^0.0.3 only 0.0.3)
+//	~1.2.3  >=1.2.3 <1.3.0 (~1.2 is ~1.2.0, ~1 allows <2.0.0)
+//	1.2.3   exactly 1.2.3
+func parseConstraint(constraint string) (versionRange, error) {
+	op, rest := "", strings.TrimSpace(constraint)
+	if strings.HasPrefix(rest, "^") || strings.HasPrefix(rest, "~") {
+		op, rest = rest[:1], rest[1:]
+	}
+
+	v := semverOf(rest)
+	if !semver.IsValid(v) || semver.Prerelease(v) != "" || semver.Build(v) != "" {
+		return versionRange{}, fmt.Errorf("invalid version constraint '%s': expected a version like ^1.2.3, ~1.2.3 or 1.2.3", constraint)
+	}
+	parts := strings.Count(rest, ".") + 1
+
+	// Canonical fills in omitted minor and patch numbers with zero
+	var nums [3]int
+	for i, s := range strings.Split(strings.TrimPrefix(semver.Canonical(v), "v"), ".") {
+		nums[i], _ = strconv.Atoi(s)
+	}
+	major, minor, patch := nums[0], nums[1], nums[2]
+
+	r := versionRange{min: fmt.Sprintf("v%d.%d.%d", major, minor, patch)}
+	switch {
+	case op == "" && parts < 3:
+		return versionRange{}, fmt.Errorf("invalid version constraint '%s': an exact version needs major, minor and patch", constraint)
+	case op == "":
+		r.max = fmt.Sprintf("v%d.%d.%d", major, minor, patch+1)
+	case op == "~" && parts == 1:
+		r.max = fmt.Sprintf("v%d.0.0", major+1)
+	case op == "~":
+		r.max = fmt.Sprintf("v%d.%d.0", major, minor+1)
+	case major > 0:
+		r.max = fmt.Sprintf("v%d.0.0", major+1)
+	case minor > 0:
+		r.max = fmt.Sprintf("v0.%d.0", minor+1)
+	default:
+		r.max = fmt.Sprintf("v0.0.%d", patch+1)
+	}
+	return r, nil
+}
+
+// semverOf adds the 'v' prefix the semver package expects
+func semverOf(version string) string {
+	return "v" + strings.TrimPrefix(version, "v")
+}
+
+// contains reports whether version is a stable release inside the range
+func (r versionRange) contains(version string) bool {
+	v := semverOf(version)
+	if !semver.IsValid(v) || semver.Prerelease(v) != "" {
+		return false
+	}
+	return semver.Compare(v, r.min) >= 0 && semver.Compare(v, r.max) < 0
+}
+
+// ValidateVersionConstraint checks that constraint is a caret (^1.2.3),
+// tilde (~1.2.3) or exact (1.2.3) version constraint
+func ValidateVersionConstraint(constraint string) error {
+	_, err := parseConstraint(constraint)
+	return err
+}
+
+// SatisfiesConstraint reports whether version is allowed by constraint.
+// Pre-release versions never satisfy a constraint.
+func SatisfiesConstraint(version, constraint string) bool {
+	r, err := parseConstraint(constraint)
+	return err == nil && r.contains(version)
+}
+
+// ResolveConstraint returns the highest of the available versions allowed by
+// constraint, and false if none is
+func ResolveConstraint(constraint string, available []string) (string, bool) {
+	r, err := parseConstraint(constraint)
+	if err != nil {
+		return "", false
+	}
+
+	best := ""
+	for _, version := range available {
+		if r.contains(version) && (best == "" || semver.Compare(semverOf(version), semverOf(best)) > 0) {
+			best = version
+		}
+	}
+	return best, best != ""
+}
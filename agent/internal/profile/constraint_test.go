@@ -0,0 +1,59 @@
+package profile
+
+import "testing"
+
+func TestResolveConstraint(t *testing.T) {
+	available := []string{
+		"2023.22.1", "2024.0.0", "2024.0.3", "2024.1.0", "2024.1.4",
+		"2024.2.0-insiders", "2024.3.1", "2025.0.0",
+	}
+
+	tests := []struct {
+		constraint string
+		want       string
+		wantOK     bool
+	}{
+		{"^2024.0.0", "2024.3.1", true},  // caret allows minor and patch updates
+		{"~2024.1.0", "2024.1.4", true},  // tilde allows patch updates only
+		{"~2024.0", "2024.0.3", true},    // missing patch counts as zero
+		{"~2024", "2024.3.1", true},      // major-only tilde allows minor updates
+		{"2024.1.0", "2024.1.0", true},   // exact version
+		{"^2024.3.2", "", false},         // nothing newer within the major
+		{"^v2025.0.0", "2025.0.0", true}, // leading v accepted
+		{"~2024.2.0", "", false},         // pre-releases never satisfy
+		{"not-a-version", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			got, ok := ResolveConstraint(tt.constraint, available)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ResolveConstraint(%q) = %q, %v; want %q, %v", tt.constraint, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResolveConstraint_CaretBelowOne(t *testing.T) {
+	available := []string{"0.1.9", "0.2.3", "0.2.7", "0.3.0", "0.0.3", "0.0.4"}
+
+	if got, _ := ResolveConstraint("^0.2.3", available); got != "0.2.7" {
+		t.Errorf("^0.2.3 resolved to %q, want 0.2.7", got)
+	}
+	if got, _ := ResolveConstraint("^0.0.3", available); got != "0.0.3" {
+		t.Errorf("^0.0.3 resolved to %q, want 0.0.3", got)
+	}
+}
+
+func TestValidateVersionConstraint(t *testing.T) {
+	for _, valid := range []string{"^1.2.3", "~1.2.3", "~1.2", "^1", "1.2.3"} {
+		if err := ValidateVersionConstraint(valid); err != nil {
+			t.Errorf("ValidateVersionConstraint(%q) failed: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", ">=1.0.0", "^1.2.3-beta", "1.2", "latest"} {
+		if err := ValidateVersionConstraint(invalid); err == nil {
+			t.Errorf("ValidateVersionConstraint(%q) succeeded, want an error", invalid)
+		}
+	}
+}
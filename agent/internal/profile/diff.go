@@ -0,0 +1,81 @@
+package profile
+
+// ExtensionChange is one extension that differs between the two sides of a
+// diff. From is the extension on the side being compared against (installed
+// extensions or the server's copy) and To the one in the profile; either is
+// nil when the extension is only on one side.
+type ExtensionChange struct {
+	ID   string     `json:"id"`
+	From *Extension `json:"from,omitempty"`
+	To   *Extension `json:"to,omitempty"`
+}
+
+// ExtensionChanges groups the differences between two sets of extensions.
+// It is shared by every kind of diff so they report and serialize alike;
+// empty buckets serialize as [] rather than null.
+type ExtensionChanges struct {
+	// Added extensions are only on the To side
+	Added []ExtensionChange `json:"added"`
+
+	// Removed extensions are only on the From side
+	Removed []ExtensionChange `json:"removed"`
+
+	// VersionChanged extensions are on both sides at different versions.
+	// Extensions without a recorded version on either side are not compared.
+	VersionChanged []ExtensionChange `json:"version_changed"`
+
+	// StateChanged extensions are on both sides but enabled on only one. An
+	// extension can also be in VersionChanged.
+	StateChanged []ExtensionChange `json:"state_changed"`
+}
+
+// Empty reports whether both sides have the same extensions at the same
+// versions and enabled states
+func (c ExtensionChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.VersionChanged) == 0 && len(c.StateChanged) == 0
+}
+
+// CompareExtensions returns the changes that turn from into to. Extension IDs
+// are matched exactly. Added, VersionChanged and StateChanged keep the order
+// of to; Removed keeps the order of from.
+func CompareExtensions(from, to []Extension) ExtensionChanges {
+	changes := ExtensionChanges{
+		Added:          make([]ExtensionChange, 0),
+		Removed:        make([]ExtensionChange, 0),
+		VersionChanged: make([]ExtensionChange, 0),
+		StateChanged:   make([]ExtensionChange, 0),
+	}
+
+	fromByID := make(map[string]Extension, len(from))
+	for _, ext := range from {
+		fromByID[ext.ID] = ext
+	}
+	toIDs := make(map[string]bool, len(to))
+
+	for _, ext := range to {
+		toIDs[ext.ID] = true
+		toExt := ext
+		fromExt, ok := fromByID[ext.ID]
+		if !ok {
+			changes.Added = append(changes.Added, ExtensionChange{ID: ext.ID, To: &toExt})
+			continue
+		}
+
+		change := ExtensionChange{ID: ext.ID, From: &fromExt, To: &toExt}
+		if fromExt.Version != "" && ext.Version != "" && fromExt.Version != ext.Version {
+			changes.VersionChanged = append(changes.VersionChanged, change)
+		}
+		if fromExt.Enabled != ext.Enabled {
+			changes.StateChanged = append(changes.StateChanged, change)
+		}
+	}
+
+	for _, ext := range from {
+		if !toIDs[ext.ID] {
+			fromExt := ext
+			changes.Removed = append(changes.Removed, ExtensionChange{ID: ext.ID, From: &fromExt})
+		}
+	}
+
+	return changes
+}
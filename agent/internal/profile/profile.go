@@ -1,73 +1,259 @@
 package profile
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
 )
 
+// output receives progress and summary messages from Load
+var output io.Writer = os.Stdout
+
+// SetOutput sets where Load writes progress and summary messages
+// (default os.Stdout). Pass io.Discard to silence them.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// Variables to allow overriding VS Code interactions in tests
+var (
+	listInstalledExtensions = vscode.ListExtensions
+	listExtensionsInDirs    = vscode.ListExtensionsFromDirs
+	defaultExtensionDirs    = vscode.DefaultExtensionDirs
+	installExtensions       = func(extensionIDs []string, force bool) []error {
+		if force {
+			return vscode.ForceInstallExtensions(extensionIDs)
+		}
+		return vscode.InstallExtensions(extensionIDs)
+	}
+	writeUserFile = vscode.WriteUserFile
+	sleep         = time.Sleep
+)
+
 // Extension represents a VS Code extension in a profile
 type Extension struct {
 	ID      string `json:"id"`
 	Version string `json:"version"`
 	Enabled bool   `json:"enabled"`
+
+	// Required marks whether a failed install fails the load. Unset means
+	// required, so profiles written before the field existed keep their
+	// behavior; use IsRequired rather than reading it directly.
+	Required *bool `json:"required,omitempty"`
+
+	// VersionConstraint optionally lets loading pick any version in a range
+	// instead of the latest: ^2024.0.0 allows minor and patch updates,
+	// ~2024.1.0 only patch updates, and 2024.1.0 that exact version.
+	// The chosen version is recorded in Version.
+	VersionConstraint string `json:"version_constraint,omitempty"`
+
+	// DisplayName, Publisher and Description are copied from the extension
+	// manifest when the profile is saved, so profiles can be shown with
+	// readable names. They are informational only and ignored when loading.
+	DisplayName string `json:"display_name,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// IsRequired reports whether failing to install the extension fails the load
+func (e Extension) IsRequired() bool {
+	return e.Required == nil || *e.Required
 }
 
 // Profile represents a saved VS Code configuration
 type Profile struct {
-	Name       string      `json:"name"`
-	CreatedAt  time.Time   `json:"created_at"`
-	UpdatedAt  time.Time   `json:"updated_at"`
-	Extensions []Extension `json:"extensions"`
+	Name        string      `json:"name"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Extensions  []Extension `json:"extensions"`
+	Description string      `json:"description,omitempty"`
+
+	// Settings and Keybindings hold the contents of VS Code's settings.json
+	// and keybindings.json; Load writes them unless told to skip them
+	Settings    json.RawMessage `json:"settings,omitempty"`
+	Keybindings json.RawMessage `json:"keybindings,omitempty"`
 }
 
-// Validate checks if the profile has valid data
-func Validate(profile *Profile) error {
-	// Check profile name
-	if profile.Name == "" {
+// ValidateName checks that a profile name is non-empty and safe to use as a filename
+func ValidateName(name string) error {
+	if name == "" {
 		return fmt.Errorf("profile name cannot be empty")
 	}
 
 	// Check for invalid filename characters
 	invalidChars := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"}
 	for _, char := range invalidChars {
-		if strings.Contains(profile.Name, char) {
+		if strings.Contains(name, char) {
 			return fmt.Errorf("profile name contains invalid characters")
 		}
 	}
 
+	return nil
+}
+
+// Validate checks if the profile has valid data
+func Validate(profile *Profile) error {
+	// Check profile name
+	if err := ValidateName(profile.Name); err != nil {
+		return err
+	}
+
 	// Check extension IDs
 	for _, ext := range profile.Extensions {
-		// Check if extension ID is empty
-		if ext.ID == "" {
-			return fmt.Errorf("extension ID cannot be empty")
+		if err := ValidateExtensionID(ext.ID); err != nil {
+			return err
 		}
-
-		// Check if extension ID contains spaces
-		if strings.Contains(ext.ID, " ") {
-			return fmt.Errorf("extension ID '%s' must be in format 'publisher.name'", ext.ID)
+		if ext.VersionConstraint != "" {
+			if err := ValidateVersionConstraint(ext.VersionConstraint); err != nil {
+				return fmt.Errorf("extension '%s': %w", ext.ID, err)
+			}
 		}
+	}
+
+	// VS Code expects settings as an object and keybindings as an array
+	if hasUserFile(profile.Settings) && !bytes.HasPrefix(bytes.TrimSpace(profile.Settings), []byte("{")) {
+		return fmt.Errorf("profile settings must be a JSON object")
+	}
+	if hasUserFile(profile.Keybindings) && !bytes.HasPrefix(bytes.TrimSpace(profile.Keybindings), []byte("[")) {
+		return fmt.Errorf("profile keybindings must be a JSON array")
+	}
+
+	return nil
+}
+
+// hasUserFile reports whether a profile carries user file contents; an
+// omitted or null field does not
+func hasUserFile(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null"))
+}
 
-		// Split by dot to check format
-		parts := strings.Split(ext.ID, ".")
+// ValidateExtensionID checks that an extension ID is in 'publisher.name' format
+func ValidateExtensionID(id string) error {
+	// Check if extension ID is empty
+	if id == "" {
+		return fmt.Errorf("extension ID cannot be empty")
+	}
+
+	// Check if extension ID contains spaces
+	if strings.Contains(id, " ") {
+		return fmt.Errorf("extension ID '%s' must be in format 'publisher.name'", id)
+	}
+
+	// Split by dot to check format
+	parts := strings.Split(id, ".")
+
+	// Must have exactly 2 parts (publisher.name)
+	if len(parts) != 2 {
+		return fmt.Errorf("extension ID '%s' must be in format 'publisher.name'", id)
+	}
+
+	// Both parts must be non-empty
+	if parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("extension ID '%s' must be in format 'publisher.name'", id)
+	}
+
+	return nil
+}
 
-		// Must have exactly 2 parts (publisher.name)
-		if len(parts) != 2 {
-			return fmt.Errorf("extension ID '%s' must be in format 'publisher.name'", ext.ID)
+// NormalizeExtensions returns exts deduplicated by ID, keeping the highest
+// version of each, and sorted by ID, so saved profiles are deterministic
+// (see vscode.NormalizeExtensions)
+func NormalizeExtensions(exts []Extension) []Extension {
+	return vscode.NormalizeExtensions(exts,
+		func(ext Extension) string { return ext.ID },
+		func(ext Extension) string { return ext.Version })
+}
+
+// ExtensionResolver reports whether an extension ID is published;
+// satisfied by *vscode.MarketplaceClient
+type ExtensionResolver interface {
+	ExtensionExists(extensionID string) (bool, error)
+}
+
+// UnresolvedExtension is an extension that failed validation
+type UnresolvedExtension struct {
+	Extension Extension
+	Reason    string
+}
+
+// ValidationResult describes the outcome of ValidateExtensions
+type ValidationResult struct {
+	Profile    *Profile
+	Unresolved []UnresolvedExtension
+
+	// MarketplaceChecked is set when every well-formed ID was looked up in
+	// the marketplace; it is false for format-only validation
+	MarketplaceChecked bool
+
+	// MarketplaceErr is set when the marketplace could not be reached and
+	// validation fell back to format checks only
+	MarketplaceErr error
+}
+
+// ValidateExtensions checks every extension ID in a profile without
+// installing anything. IDs are checked for 'publisher.name' format and, when
+// resolver is non-nil, looked up in the marketplace. If the marketplace is
+// unreachable the remaining IDs are only format-checked.
+func ValidateExtensions(name string, profilesDir string, resolver ExtensionResolver) (*ValidationResult, error) {
+	profile, err := Get(name, profilesDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateName(profile.Name); err != nil {
+		return nil, fmt.Errorf("invalid profile: %w", err)
+	}
+
+	result := &ValidationResult{Profile: profile, MarketplaceChecked: resolver != nil}
+	for _, ext := range profile.Extensions {
+		if err := ValidateExtensionID(ext.ID); err != nil {
+			result.Unresolved = append(result.Unresolved, UnresolvedExtension{Extension: ext, Reason: err.Error()})
+			continue
+		}
+		if resolver == nil || result.MarketplaceErr != nil {
+			continue
 		}
 
-		// Both parts must be non-empty
-		if parts[0] == "" || parts[1] == "" {
-			return fmt.Errorf("extension ID '%s' must be in format 'publisher.name'", ext.ID)
+		exists, err := resolver.ExtensionExists(ext.ID)
+		if err != nil {
+			result.MarketplaceErr = err
+			result.MarketplaceChecked = false
+			continue
+		}
+		if !exists {
+			result.Unresolved = append(result.Unresolved, UnresolvedExtension{
+				Extension: ext,
+				Reason:    fmt.Sprintf("extension '%s' not found in the marketplace", ext.ID),
+			})
 		}
 	}
 
-	return nil
+	return result, nil
+}
+
+// filterBlocked splits extensions into those allowed and those matching a
+// blocklist pattern
+func filterBlocked(extensions []Extension, blocked []string) (allowed, blockedExts []Extension) {
+	if len(blocked) == 0 {
+		return extensions, nil
+	}
+	for _, ext := range extensions {
+		if _, ok := vscode.MatchAnyExtensionPattern(blocked, ext.ID); ok {
+			blockedExts = append(blockedExts, ext)
+			continue
+		}
+		allowed = append(allowed, ext)
+	}
+	return allowed, blockedExts
 }
 
 // detectConflicts compares profile extensions with currently installed extensions
@@ -91,16 +277,87 @@ func detectConflicts(profileExtensions []Extension, installedExtensions []vscode
 	return toInstall, alreadyInstalled
 }
 
-// DiffResult contains the comparison between a profile and installed extensions
+// VersionMismatch is a profile extension installed at a different version
+type VersionMismatch struct {
+	ID               string
+	ProfileVersion   string
+	InstalledVersion string
+}
+
+// DiffResult is the comparison of a profile with another set of extensions:
+// the installed extensions (Diff) or another copy of the profile
+// (CompareProfiles). Changes is the view shared by every diff and the only
+// part serialized besides the name and total; the remaining fields are
+// filled in by installed-extension diffs.
 type DiffResult struct {
-	ProfileName      string
-	ToInstall        []Extension
-	AlreadyInstalled []Extension
-	TotalInProfile   int
+	ProfileName string           `json:"profile"`
+	Changes     ExtensionChanges `json:"changes"`
+
+	ToInstall         []Extension       `json:"-"`
+	AlreadyInstalled  []Extension       `json:"-"`
+	VersionMismatches []VersionMismatch `json:"-"`
+
+	// Outdated lists the version mismatches where the profile's version is
+	// newer than the installed one, per DiffOptions.PreRelease
+	Outdated []VersionMismatch `json:"-"`
+
+	TotalInProfile int `json:"total_in_profile"`
+}
+
+// DiffOptions controls how Diff compares versions
+type DiffOptions struct {
+	// PreRelease lets pre-release versions count as newer than stable ones
+	// when finding outdated extensions. By default only stable releases do.
+	PreRelease bool
+}
+
+// InSync reports whether every extension in the profile is installed at the
+// profile's version and in the profile's enabled state. Installed extensions
+// missing from the profile do not count.
+func (r *DiffResult) InSync() bool {
+	return len(r.ToInstall) == 0 && len(r.VersionMismatches) == 0 && len(r.Changes.StateChanged) == 0
+}
+
+// Identical reports whether both sides have the same extensions at the same
+// versions and enabled states
+func (r *DiffResult) Identical() bool {
+	return r.Changes.Empty()
+}
+
+// versionMismatches converts version changes between installed extensions
+// (From) and a profile (To) into VersionMismatches
+func versionMismatches(changes []ExtensionChange) []VersionMismatch {
+	var mismatches []VersionMismatch
+	for _, c := range changes {
+		mismatches = append(mismatches, VersionMismatch{
+			ID:               c.ID,
+			ProfileVersion:   c.To.Version,
+			InstalledVersion: c.From.Version,
+		})
+	}
+	return mismatches
+}
+
+// detectOutdated returns the mismatches whose profile version is newer than
+// the installed version
+func detectOutdated(mismatches []VersionMismatch, preRelease bool) []VersionMismatch {
+	var outdated []VersionMismatch
+	for _, m := range mismatches {
+		if vscode.CompareVersions(m.ProfileVersion, m.InstalledVersion, preRelease) > 0 {
+			outdated = append(outdated, m)
+		}
+	}
+	return outdated
 }
 
 // Diff compares a profile with currently installed extensions
 func Diff(profileName string, profilesDir string) (*DiffResult, error) {
+	return DiffWithOptions(profileName, profilesDir, DiffOptions{})
+}
+
+// DiffWithOptions compares a profile with currently installed extensions
+// using the given options
+func DiffWithOptions(profileName string, profilesDir string, opts DiffOptions) (*DiffResult, error) {
 	if profileName == "" {
 		return nil, fmt.Errorf("profile name cannot be empty")
 	}
@@ -115,50 +372,187 @@ func Diff(profileName string, profilesDir string) (*DiffResult, error) {
 		return nil, fmt.Errorf("failed to read profile file: %w", err)
 	}
 
-	// Parse profile
+	profile, err := parseProfile(data)
+	if err != nil {
+		return nil, err
+	}
+	return diffAgainstInstalled(profile, opts)
+}
+
+// DiffFile compares the profile file at path, which need not be in the
+// profiles directory, with currently installed extensions. The file is
+// parsed and validated before installed extensions are listed.
+func DiffFile(path string, opts DiffOptions) (*DiffResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+
+	profile, err := parseProfile(data)
+	if err != nil {
+		return nil, err
+	}
+	return diffAgainstInstalled(profile, opts)
+}
+
+// parseProfile parses and validates profile file contents
+func parseProfile(data []byte) (*Profile, error) {
 	var profile Profile
 	if err := json.Unmarshal(data, &profile); err != nil {
 		return nil, fmt.Errorf("failed to parse profile file: %w", err)
 	}
 
-	// Validate profile
 	if err := Validate(&profile); err != nil {
 		return nil, fmt.Errorf("invalid profile: %w", err)
 	}
+	return &profile, nil
+}
 
-	// Get currently installed extensions
-	installedExts, err := vscode.ListExtensions()
+// diffAgainstInstalled compares a profile with currently installed extensions
+func diffAgainstInstalled(profile *Profile, opts DiffOptions) (*DiffResult, error) {
+	installedExts, err := listInstalledExtensions()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list installed extensions: %w", err)
 	}
 
+	return diffInstalled(profile, installedExts, opts), nil
+}
+
+// diffInstalled compares a profile with the given installed extensions
+func diffInstalled(profile *Profile, installedExts []vscode.Extension, opts DiffOptions) *DiffResult {
+	installed := make([]Extension, len(installedExts))
+	for i, ext := range installedExts {
+		installed[i] = Extension{ID: ext.ID, Version: ext.Version, Enabled: ext.Enabled}
+	}
+	changes := CompareExtensions(installed, profile.Extensions)
+
 	// Detect conflicts
 	toInstall, alreadyInstalled := detectConflicts(profile.Extensions, installedExts)
+	mismatches := versionMismatches(changes.VersionChanged)
 
 	// Build result
-	result := &DiffResult{
-		ProfileName:      profile.Name,
-		ToInstall:        toInstall,
-		AlreadyInstalled: alreadyInstalled,
-		TotalInProfile:   len(profile.Extensions),
+	return &DiffResult{
+		ProfileName:       profile.Name,
+		Changes:           changes,
+		ToInstall:         toInstall,
+		AlreadyInstalled:  alreadyInstalled,
+		VersionMismatches: mismatches,
+		Outdated:          detectOutdated(mismatches, opts.PreRelease),
+		TotalInProfile:    len(profile.Extensions),
 	}
+}
 
-	return result, nil
+// CompareProfiles compares the extensions of a local profile with a remote
+// copy. Changes go from the remote copy to the local one: Added extensions
+// are only local and Removed ones only remote.
+func CompareProfiles(local, remote *Profile) *DiffResult {
+	return &DiffResult{
+		ProfileName:    local.Name,
+		Changes:        CompareExtensions(remote.Extensions, local.Extensions),
+		TotalInProfile: len(local.Extensions),
+	}
+}
+
+// NormalizeName trims surrounding whitespace from a profile name. Names are
+// compared case-insensitively, mirroring the server.
+func NormalizeName(name string) string {
+	return strings.TrimSpace(name)
+}
+
+// findNameCollision returns the name of an existing profile file that matches
+// name case-insensitively but not exactly, or "" if there is none
+func findNameCollision(name string, profilesDir string) (string, error) {
+	entries, err := os.ReadDir(profilesDir)
+	if err != nil {
+		// A dry-run save does not create the directory; nothing can collide
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read profiles directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		existing := strings.TrimSuffix(entry.Name(), ".json")
+		if existing != name && strings.EqualFold(existing, name) {
+			return existing, nil
+		}
+	}
+
+	return "", nil
+}
+
+// manifestText returns a manifest string for storing in a profile, dropping
+// unresolved localization placeholders such as "%displayName%"
+func manifestText(s string) string {
+	if len(s) > 1 && strings.HasPrefix(s, "%") && strings.HasSuffix(s, "%") {
+		return ""
+	}
+	return s
+}
+
+// captureExtensions lists installed extensions. Without custom directories it
+// asks VS Code directly; otherwise it scans the custom directories, plus the
+// auto-detected ones unless NoAutoDirs is set, merging duplicates so the
+// highest version wins.
+func captureExtensions(opts SaveOptions) ([]vscode.Extension, error) {
+	if len(opts.ExtensionDirs) == 0 {
+		return listInstalledExtensions()
+	}
+
+	var dirs []string
+	if !opts.NoAutoDirs {
+		dirs = append(dirs, defaultExtensionDirs()...)
+	}
+	dirs = append(dirs, opts.ExtensionDirs...)
+
+	return listExtensionsInDirs(dirs)
+}
+
+// SaveOptions controls where Save looks for installed extensions
+type SaveOptions struct {
+	// ExtensionDirs are extra extension directories to scan, e.g. those of
+	// VS Code installs started with a separate --user-data-dir
+	ExtensionDirs []string
+
+	// NoAutoDirs skips the auto-detected VS Code and Insiders directories,
+	// scanning only ExtensionDirs
+	NoAutoDirs bool
+
+	// DryRun captures and returns the profile without writing it
+	DryRun bool
+
+	// Description replaces the profile's description. When empty, the
+	// description of an existing profile is kept.
+	Description string
 }
 
 // Save captures current VS Code extensions to a profile
 func Save(name string, profilesDir string) (*Profile, error) {
+	return SaveWithOptions(name, profilesDir, SaveOptions{})
+}
+
+// SaveWithOptions captures VS Code extensions to a profile using the given options
+func SaveWithOptions(name string, profilesDir string, opts SaveOptions) (*Profile, error) {
+	name = NormalizeName(name)
 	if name == "" {
 		return nil, fmt.Errorf("profile name cannot be empty")
 	}
+	if opts.NoAutoDirs && len(opts.ExtensionDirs) == 0 {
+		return nil, fmt.Errorf("at least one extensions directory is required when auto-detected directories are skipped")
+	}
 
 	// Ensure profiles directory exists
-	if err := os.MkdirAll(profilesDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create profiles directory: %w", err)
+	if !opts.DryRun {
+		if err := os.MkdirAll(profilesDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create profiles directory: %w", err)
+		}
 	}
 
 	// Get current VS Code extensions
-	vscodeExts, err := vscode.ListExtensions()
+	vscodeExts, err := captureExtensions(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list VS Code extensions: %w", err)
 	}
@@ -167,27 +561,47 @@ func Save(name string, profilesDir string) (*Profile, error) {
 	extensions := make([]Extension, len(vscodeExts))
 	for i, ext := range vscodeExts {
 		extensions[i] = Extension{
-			ID:      ext.ID,
-			Version: ext.Version,
-			Enabled: ext.Enabled,
+			ID:          ext.ID,
+			Version:     ext.Version,
+			Enabled:     ext.Enabled,
+			DisplayName: manifestText(ext.DisplayName),
+			Publisher:   ext.Publisher,
+			Description: manifestText(ext.Description),
 		}
 	}
 
+	// Reject names that only differ in case from an existing profile, matching
+	// the server's uniqueness rule so local and remote profiles stay in step
+	if existing, err := findNameCollision(name, profilesDir); err != nil {
+		return nil, err
+	} else if existing != "" {
+		return nil, fmt.Errorf("a profile named '%s' already exists", existing)
+	}
+
 	// Create or update profile
 	profilePath := filepath.Join(profilesDir, name+".json")
 	now := time.Now()
 
 	profile := &Profile{
-		Name:       name,
-		UpdatedAt:  now,
-		Extensions: extensions,
+		Name:        name,
+		Description: opts.Description,
+		UpdatedAt:   now,
+		Extensions:  NormalizeExtensions(extensions),
 	}
 
-	// If profile exists, preserve created_at timestamp
+	// If profile exists, preserve created_at timestamp, description, the
+	// stored user files and per-extension settings that are not captured
+	// from VS Code
 	if existingData, err := os.ReadFile(profilePath); err == nil {
 		var existing Profile
 		if err := json.Unmarshal(existingData, &existing); err == nil {
 			profile.CreatedAt = existing.CreatedAt
+			if profile.Description == "" {
+				profile.Description = existing.Description
+			}
+			profile.Settings = existing.Settings
+			profile.Keybindings = existing.Keybindings
+			keepExtensionSettings(profile.Extensions, existing.Extensions)
 		}
 	}
 
@@ -196,6 +610,10 @@ func Save(name string, profilesDir string) (*Profile, error) {
 		profile.CreatedAt = now
 	}
 
+	if opts.DryRun {
+		return profile, nil
+	}
+
 	// Write profile to file
 	data, err := json.MarshalIndent(profile, "", "  ")
 	if err != nil {
@@ -209,11 +627,417 @@ func Save(name string, profilesDir string) (*Profile, error) {
 	return profile, nil
 }
 
+// LoadOptions controls how a profile is applied
+type LoadOptions struct {
+	// ForceReinstall installs every extension in the profile, including
+	// ones that are already installed
+	ForceReinstall bool
+
+	// Parallel is the maximum number of concurrent VS Code CLI installs,
+	// each installing a batch of extensions. 0 or 1 installs serially, one
+	// extension per invocation.
+	Parallel int
+
+	// BlockedExtensions are extension IDs or publisher wildcards
+	// (publisher.*) that are never installed, even if in the profile
+	BlockedExtensions []string
+
+	// DryRun reports what would be installed without installing anything.
+	// LoadResult.Installed and Upgraded then list the planned installs.
+	DryRun bool
+
+	// SkipSettings and SkipKeybindings leave the local settings.json and
+	// keybindings.json untouched even if the profile carries them
+	SkipSettings    bool
+	SkipKeybindings bool
+
+	// Versions looks up the published versions of extensions with a
+	// VersionConstraint that is not met by the installed version. When nil,
+	// such extensions can only be resolved from what is installed.
+	Versions VersionLister
+}
+
+// DefaultParallel is the default number of concurrent installs used by the CLI
+const DefaultParallel = 4
+
+// RenameOptions controls how a profile is renamed
+type RenameOptions struct {
+	// DryRun checks that the rename is possible and returns the renamed
+	// profile without moving or rewriting any file
+	DryRun bool
+}
+
+// Rename changes the name of a local profile, moving it to a new file.
+// The new name is normalized and must not collide with another profile;
+// changing only the case of a name is allowed.
+func Rename(oldName, newName string, profilesDir string) (*Profile, error) {
+	return RenameWithOptions(oldName, newName, profilesDir, RenameOptions{})
+}
+
+// RenameWithOptions renames a local profile using the given options
+func RenameWithOptions(oldName, newName string, profilesDir string, opts RenameOptions) (*Profile, error) {
+	newName = NormalizeName(newName)
+	if err := ValidateName(newName); err != nil {
+		return nil, err
+	}
+
+	profile, err := Get(oldName, profilesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if newName == oldName {
+		return nil, fmt.Errorf("profile is already named '%s'", oldName)
+	}
+
+	if !strings.EqualFold(newName, oldName) {
+		if _, err := os.Stat(filepath.Join(profilesDir, newName+".json")); err == nil {
+			return nil, fmt.Errorf("a profile named '%s' already exists", newName)
+		}
+		if existing, err := findNameCollision(newName, profilesDir); err != nil {
+			return nil, err
+		} else if existing != "" {
+			return nil, fmt.Errorf("a profile named '%s' already exists", existing)
+		}
+	}
+
+	profile.Name = newName
+	profile.UpdatedAt = time.Now()
+
+	if opts.DryRun {
+		return profile, nil
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile: %w", err)
+	}
+
+	// Move the file before rewriting it so a case-only rename also works on
+	// case-insensitive filesystems
+	oldPath := filepath.Join(profilesDir, oldName+".json")
+	newPath := filepath.Join(profilesDir, newName+".json")
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return nil, fmt.Errorf("failed to rename profile file: %w", err)
+	}
+
+	if err := os.WriteFile(newPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write profile file: %w", err)
+	}
+
+	return profile, nil
+}
+
+// keepExtensionSettings copies Required and VersionConstraint from the
+// extensions of a previously saved profile to the matching (by ID, compared
+// case-insensitively) captured extensions
+func keepExtensionSettings(extensions, previous []Extension) {
+	byID := make(map[string]Extension, len(previous))
+	for _, ext := range previous {
+		byID[strings.ToLower(ext.ID)] = ext
+	}
+	for i := range extensions {
+		if prev, ok := byID[strings.ToLower(extensions[i].ID)]; ok {
+			extensions[i].Required = prev.Required
+			extensions[i].VersionConstraint = prev.VersionConstraint
+		}
+	}
+}
+
+// resolveConstraints picks the version to install for every extension with a
+// VersionConstraint. An installed version that satisfies the constraint is
+// kept: the extension stays skipped, or is reinstalled at that version.
+// Otherwise the highest satisfying version from versions is chosen, moving
+// a skipped extension to toInstall so it is upgraded. The chosen version
+// replaces the extension's Version.
+func resolveConstraints(toInstall, skipped []Extension, installedVersions map[string]string, versions VersionLister) (install, skip []Extension, err error) {
+	for _, ext := range skipped {
+		if ext.VersionConstraint == "" || SatisfiesConstraint(installedVersions[ext.ID], ext.VersionConstraint) {
+			skip = append(skip, ext)
+			continue
+		}
+		toInstall = append(toInstall, ext)
+	}
+
+	for _, ext := range toInstall {
+		if ext.VersionConstraint != "" {
+			target, err := resolveConstraint(ext, installedVersions[ext.ID], versions)
+			if err != nil {
+				return nil, nil, err
+			}
+			ext.Version = target
+		}
+		install = append(install, ext)
+	}
+
+	return install, skip, nil
+}
+
+// resolveConstraint returns the version of ext to install, preferring the
+// installed version when it satisfies the constraint
+func resolveConstraint(ext Extension, installed string, versions VersionLister) (string, error) {
+	if SatisfiesConstraint(installed, ext.VersionConstraint) {
+		return installed, nil
+	}
+	if versions == nil {
+		return "", fmt.Errorf("cannot resolve %s for extension %s: available versions are unknown", ext.VersionConstraint, ext.ID)
+	}
+
+	available, err := versions.ExtensionVersions(ext.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up versions of extension %s: %w", ext.ID, err)
+	}
+	target, ok := ResolveConstraint(ext.VersionConstraint, available)
+	if !ok {
+		return "", fmt.Errorf("no available version of extension %s satisfies %s", ext.ID, ext.VersionConstraint)
+	}
+	return target, nil
+}
+
+// installSpec returns the argument passed to the installer for ext:
+// "publisher.name@version" when a version constraint pinned it, otherwise
+// the bare ID so the latest version is installed
+func installSpec(ext Extension) string {
+	if ext.VersionConstraint != "" && ext.Version != "" {
+		return ext.ID + "@" + ext.Version
+	}
+	return ext.ID
+}
+
+// installAll installs extensions in up to parallel batches installed
+// concurrently, each with a single VS Code CLI invocation. 0 or 1 installs
+// serially instead, one extension per invocation, for rate-limited networks.
+// Every extension is attempted; the returned slice holds the install error
+// for each extension, in order (nil on success).
+func installAll(extensions []Extension, force bool, parallel int) []error {
+	errs := make([]error, len(extensions))
+	if parallel <= 1 {
+		for i := range extensions {
+			installBatch(extensions, i, i+1, force, errs)
+		}
+		return errs
+	}
+
+	batches := min(parallel, len(extensions))
+	var wg sync.WaitGroup
+	for b := 0; b < batches; b++ {
+		// Contiguous, evenly sized slices of extensions
+		lo, hi := b*len(extensions)/batches, (b+1)*len(extensions)/batches
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			installBatch(extensions, lo, hi, force, errs)
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// installBatch installs extensions[lo:hi] with one installer invocation,
+// retrying transient failures, and records each install error in errs
+func installBatch(extensions []Extension, lo, hi int, force bool, errs []error) {
+	specs := make([]string, 0, hi-lo)
+	for _, ext := range extensions[lo:hi] {
+		specs = append(specs, installSpec(ext))
+	}
+	for i, err := range installExtensions(specs, force) {
+		if err != nil {
+			err = retryInstall(specs[i], force, err)
+		}
+		if err != nil {
+			errs[lo+i] = fmt.Errorf("failed to install extension %s: %w", extensions[lo+i].ID, err)
+		}
+	}
+}
+
+// installAttempts bounds how often an extension is installed while its
+// installs keep failing with transient (marketplace or network) errors
+const installAttempts = 3
+
+// installRetryDelay is the wait before the first retry of a transient install
+// failure; it doubles with each further retry
+var installRetryDelay = time.Second
+
+// retryInstall installs spec again on its own while the install fails with a
+// transient error, up to installAttempts installs in all, backing off
+// between them. It returns nil once an install succeeds, else the last error.
+func retryInstall(spec string, force bool, err error) error {
+	delay := installRetryDelay
+	for attempt := 1; attempt < installAttempts && vscode.IsTransientInstallError(err); attempt++ {
+		sleep(delay)
+		delay *= 2
+		err = installExtensions([]string{spec}, force)[0]
+	}
+	return err
+}
+
+// FailedExtension is an extension that could not be installed
+type FailedExtension struct {
+	Extension Extension
+	Err       error
+}
+
+// LoadResult reports what happened to each extension when loading a profile
+type LoadResult struct {
+	Profile *Profile
+
+	// Installed extensions were not installed before
+	Installed []Extension
+
+	// Upgraded extensions were installed at a different version and were
+	// reinstalled, either with ForceReinstall or because the installed
+	// version did not satisfy their VersionConstraint
+	Upgraded []Extension
+
+	// Skipped extensions were already installed
+	Skipped []Extension
+
+	// Failed required extensions could not be installed
+	Failed []FailedExtension
+
+	// OptionalFailed extensions are not required and could not be
+	// installed; they are warnings and do not fail the load
+	OptionalFailed []FailedExtension
+
+	// Blocked extensions matched LoadOptions.BlockedExtensions and were
+	// not installed
+	Blocked []Extension
+
+	// UserFiles are the VS Code user files (settings.json, keybindings.json)
+	// written from the profile, or that would be written in a dry run
+	UserFiles []string
+
+	// Backups are copies of user files made before they were overwritten
+	Backups []string
+}
+
+// Err returns an error joining every required install failure, or nil if
+// all required installs succeeded
+func (r *LoadResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	errs := make([]error, len(r.Failed))
+	for i, f := range r.Failed {
+		errs[i] = f.Err
+	}
+	return errors.Join(errs...)
+}
+
 // Load installs extensions from a profile
 func Load(name string, profilesDir string) (*Profile, error) {
+	return LoadWithOptions(name, profilesDir, LoadOptions{})
+}
+
+// LoadWithOptions installs extensions from a profile using the given options.
+// It returns an error if the profile cannot be read or any install failed;
+// use LoadWithResult for per-extension outcomes.
+func LoadWithOptions(name string, profilesDir string, opts LoadOptions) (*Profile, error) {
+	result, err := LoadWithResult(name, profilesDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	return result.Profile, nil
+}
+
+// LoadWithResult installs extensions from a profile and reports the outcome
+// for each extension. Install failures do not stop the remaining installs and
+// are reported in LoadResult.Failed rather than as the returned error, which
+// is only set when the profile cannot be loaded at all.
+func LoadWithResult(name string, profilesDir string, opts LoadOptions) (*LoadResult, error) {
+	plan, err := PlanLoad(name, profilesDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyPlan(plan, opts)
+}
+
+// LoadPlan is what loading a profile would change, computed by PlanLoad
+// without installing or writing anything
+type LoadPlan struct {
+	Profile *Profile
+
+	// Install extensions are not installed yet
+	Install []Extension
+
+	// Upgrade extensions are installed at a different version and will be
+	// reinstalled at the profile's version
+	Upgrade []Extension
+
+	// Reinstall extensions are already installed at the profile's version
+	// and will be installed again (only with ForceReinstall)
+	Reinstall []Extension
+
+	// Skipped extensions are already installed and left alone
+	Skipped []Extension
+
+	// Blocked extensions match LoadOptions.BlockedExtensions
+	Blocked []Extension
+
+	// UserFiles are the VS Code user files that will be overwritten
+	UserFiles []string
+
+	// toInstall holds Install, Upgrade and Reinstall in profile order
+	toInstall         []Extension
+	installedVersions map[string]string
+}
+
+// Empty reports whether applying the plan would change nothing
+func (p *LoadPlan) Empty() bool {
+	return len(p.toInstall) == 0 && len(p.UserFiles) == 0
+}
+
+// install installs the plan's extensions with installAll. Upgrades are always
+// forced: VS Code refuses to change the version of an installed extension
+// without --force and reports it as already installed.
+func (p *LoadPlan) install(opts LoadOptions) []error {
+	errs := make([]error, len(p.toInstall))
+	var plain, forced []int
+	for i, ext := range p.toInstall {
+		if opts.ForceReinstall || p.isUpgrade(ext) {
+			forced = append(forced, i)
+		} else {
+			plain = append(plain, i)
+		}
+	}
+
+	for _, group := range []struct {
+		indexes []int
+		force   bool
+	}{{plain, false}, {forced, true}} {
+		if len(group.indexes) == 0 {
+			continue
+		}
+		exts := make([]Extension, len(group.indexes))
+		for j, i := range group.indexes {
+			exts[j] = p.toInstall[i]
+		}
+		for j, err := range installAll(exts, group.force, opts.Parallel) {
+			errs[group.indexes[j]] = err
+		}
+	}
+	return errs
+}
+
+// isUpgrade reports whether installing ext replaces a different installed version
+func (p *LoadPlan) isUpgrade(ext Extension) bool {
+	installed, ok := p.installedVersions[ext.ID]
+	return ok && installed != "" && ext.Version != "" && installed != ext.Version
+}
+
+// PlanLoad reads and validates a profile and works out what LoadWithResult
+// would install, upgrade, skip and overwrite, without changing anything
+func PlanLoad(name string, profilesDir string, opts LoadOptions) (*LoadPlan, error) {
 	if name == "" {
 		return nil, fmt.Errorf("profile name cannot be empty")
 	}
+	if opts.Parallel < 0 {
+		return nil, fmt.Errorf("parallel install count cannot be negative")
+	}
 
 	// Read profile file
 	profilePath := filepath.Join(profilesDir, name+".json")
@@ -231,58 +1055,244 @@ func Load(name string, profilesDir string) (*Profile, error) {
 		return nil, fmt.Errorf("failed to parse profile file: %w", err)
 	}
 
+	return PlanProfile(&profile, opts)
+}
+
+// PlanProfile validates a profile that is not read from the profiles
+// directory, such as one downloaded from a URL, and plans loading it like
+// PlanLoad
+func PlanProfile(profile *Profile, opts LoadOptions) (*LoadPlan, error) {
+	if opts.Parallel < 0 {
+		return nil, fmt.Errorf("parallel install count cannot be negative")
+	}
+
 	// Validate profile before attempting installation
-	if err := Validate(&profile); err != nil {
+	if err := Validate(profile); err != nil {
 		return nil, fmt.Errorf("invalid profile: %w", err)
 	}
 
-	// Get installed extensions
-	installedExts, err := vscode.ListExtensions()
+	plan := &LoadPlan{Profile: profile, installedVersions: make(map[string]string)}
+
+	// Drop blocked extensions before anything is installed
+	var allowed []Extension
+	allowed, plan.Blocked = filterBlocked(profile.Extensions, opts.BlockedExtensions)
+
+	var toInstall, skipped []Extension
+	if opts.ForceReinstall {
+		// Reinstall everything regardless of current state. The installed
+		// list only tells upgrades apart, so failing to read it is not fatal.
+		toInstall = allowed
+		if installedExts, err := listInstalledExtensions(); err == nil {
+			for _, ext := range installedExts {
+				plan.installedVersions[ext.ID] = ext.Version
+			}
+		}
+	} else {
+		// Get installed extensions
+		installedExts, err := listInstalledExtensions()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed extensions: %w", err)
+		}
+		for _, ext := range installedExts {
+			plan.installedVersions[ext.ID] = ext.Version
+		}
+
+		// Detect conflicts
+		toInstall, skipped = detectConflicts(allowed, installedExts)
+	}
+
+	// Pin extensions with a version constraint to the version to install
+	var err error
+	plan.toInstall, plan.Skipped, err = resolveConstraints(toInstall, skipped, plan.installedVersions, opts.Versions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list installed extensions: %w", err)
+		return nil, err
 	}
 
-	// Detect conflicts
-	toInstall, alreadyInstalled := detectConflicts(profile.Extensions, installedExts)
+	for _, ext := range plan.toInstall {
+		_, installed := plan.installedVersions[ext.ID]
+		switch {
+		case plan.isUpgrade(ext):
+			plan.Upgrade = append(plan.Upgrade, ext)
+		case installed:
+			plan.Reinstall = append(plan.Reinstall, ext)
+		default:
+			plan.Install = append(plan.Install, ext)
+		}
+	}
+	plan.UserFiles = userFiles(profile, opts)
+
+	return plan, nil
+}
+
+// ApplyPlan installs the extensions of a plan from PlanLoad and writes the
+// profile's user files, reporting the outcome like LoadWithResult. opts
+// should be the options the plan was computed with.
+func ApplyPlan(plan *LoadPlan, opts LoadOptions) (*LoadResult, error) {
+	profile := plan.Profile
+	toInstall := plan.toInstall
+	result := &LoadResult{Profile: profile, Skipped: plan.Skipped, Blocked: plan.Blocked}
+
+	if len(result.Blocked) > 0 {
+		fmt.Fprintf(output, "Blocking %d extension(s) listed in vscode.blocked_extensions:\n", len(result.Blocked))
+		for _, ext := range result.Blocked {
+			fmt.Fprintf(output, "  - %s (blocked)\n", ext.ID)
+		}
+	}
+	if opts.ForceReinstall {
+		fmt.Fprintf(output, "Reinstalling all %d extension(s)\n", len(toInstall))
+	}
 
 	// Report skipped extensions (if any)
-	if len(alreadyInstalled) > 0 {
-		fmt.Printf("Skipping %d already installed extension(s):\n", len(alreadyInstalled))
-		for _, ext := range alreadyInstalled {
-			fmt.Printf("  - %s (already installed)\n", ext.ID)
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(output, "Skipping %d already installed extension(s):\n", len(result.Skipped))
+		for _, ext := range result.Skipped {
+			fmt.Fprintf(output, "  - %s (already installed)\n", ext.ID)
 		}
 	}
 
-	// Install only new extensions
-	for _, ext := range toInstall {
-		if err := vscode.InstallExtension(ext.ID); err != nil {
-			return nil, fmt.Errorf("failed to install extension %s: %w", ext.ID, err)
+	if opts.DryRun {
+		for _, ext := range toInstall {
+			if plan.isUpgrade(ext) {
+				result.Upgraded = append(result.Upgraded, ext)
+			} else {
+				result.Installed = append(result.Installed, ext)
+			}
+		}
+		fmt.Fprintf(output, "\nDry run: profile '%s' would install %d extension(s):\n", profile.Name, len(toInstall))
+		for _, ext := range toInstall {
+			fmt.Fprintf(output, "  + %s (%s)\n", ext.ID, ext.Version)
+		}
+		result.UserFiles = plan.UserFiles
+		for _, name := range result.UserFiles {
+			fmt.Fprintf(output, "Dry run: would overwrite %s\n", name)
+		}
+		return result, nil
+	}
+
+	// Install only new extensions and upgrades (or all of them when forcing)
+	errs := plan.install(opts)
+	for i, ext := range toInstall {
+		switch {
+		case errs[i] != nil && !ext.IsRequired():
+			result.OptionalFailed = append(result.OptionalFailed, FailedExtension{Extension: ext, Err: errs[i]})
+		case errs[i] != nil:
+			result.Failed = append(result.Failed, FailedExtension{Extension: ext, Err: errs[i]})
+		case plan.isUpgrade(ext):
+			result.Upgraded = append(result.Upgraded, ext)
+		default:
+			result.Installed = append(result.Installed, ext)
 		}
 	}
 
 	// Report summary after installation
-	fmt.Printf("\nProfile '%s' loaded successfully:\n", profile.Name)
-	fmt.Printf("  - Installed: %d extension(s)\n", len(toInstall))
-	fmt.Printf("  - Skipped: %d extension(s)\n", len(alreadyInstalled))
-	fmt.Printf("  - Total: %d extension(s)\n", len(profile.Extensions))
+	if len(result.Failed) == 0 {
+		fmt.Fprintf(output, "\nProfile '%s' loaded successfully:\n", profile.Name)
+	} else {
+		fmt.Fprintf(output, "\nProfile '%s' loaded with errors:\n", profile.Name)
+	}
+	fmt.Fprintf(output, "  - Installed: %d extension(s)\n", len(result.Installed))
+	if len(result.Upgraded) > 0 {
+		fmt.Fprintf(output, "  - Upgraded: %d extension(s)\n", len(result.Upgraded))
+	}
+	fmt.Fprintf(output, "  - Skipped: %d extension(s)\n", len(result.Skipped))
+	if len(result.Blocked) > 0 {
+		fmt.Fprintf(output, "  - Blocked: %d extension(s)\n", len(result.Blocked))
+	}
+	if len(result.Failed) > 0 {
+		fmt.Fprintf(output, "  - Failed: %d extension(s)\n", len(result.Failed))
+		for _, f := range result.Failed {
+			fmt.Fprintf(output, "      %v\n", f.Err)
+		}
+	}
+	if len(result.OptionalFailed) > 0 {
+		fmt.Fprintf(output, "  - Optional, not installed: %d extension(s)\n", len(result.OptionalFailed))
+		for _, f := range result.OptionalFailed {
+			fmt.Fprintf(output, "      Warning: %v\n", f.Err)
+		}
+	}
+	fmt.Fprintf(output, "  - Total: %d extension(s)\n", len(profile.Extensions))
 
-	return &profile, nil
+	if err := applyUserFiles(profile, opts, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
-// List returns all local profiles
+// userFiles returns the VS Code user files that loading profile writes
+func userFiles(profile *Profile, opts LoadOptions) []string {
+	var names []string
+	if hasUserFile(profile.Settings) && !opts.SkipSettings {
+		names = append(names, vscode.SettingsFile)
+	}
+	if hasUserFile(profile.Keybindings) && !opts.SkipKeybindings {
+		names = append(names, vscode.KeybindingsFile)
+	}
+	return names
+}
+
+// applyUserFiles writes the profile's settings and keybindings, backing up
+// the files they replace, and records what was written in result
+func applyUserFiles(profile *Profile, opts LoadOptions, result *LoadResult) error {
+	for _, name := range userFiles(profile, opts) {
+		raw := profile.Settings
+		if name == vscode.KeybindingsFile {
+			raw = profile.Keybindings
+		}
+
+		// Re-indent, since the contents keep the nesting of the profile file
+		var data bytes.Buffer
+		if err := json.Indent(&data, raw, "", "  "); err != nil {
+			return fmt.Errorf("invalid %s in profile: %w", name, err)
+		}
+		data.WriteByte('\n')
+
+		backup, err := writeUserFile(name, data.Bytes())
+		if backup != "" {
+			result.Backups = append(result.Backups, backup)
+		}
+		if err != nil {
+			return err
+		}
+		result.UserFiles = append(result.UserFiles, name)
+
+		if backup != "" {
+			fmt.Fprintf(output, "Wrote %s (previous version saved to %s)\n", name, backup)
+		} else {
+			fmt.Fprintf(output, "Wrote %s\n", name)
+		}
+	}
+	return nil
+}
+
+// SkippedFile describes a profile file that List could not read or parse
+type SkippedFile struct {
+	Path string
+	Err  error
+}
+
+// List returns all local profiles, ignoring files that cannot be read
 func List(profilesDir string) ([]Profile, error) {
+	profiles, _, err := ListWithSkipped(profilesDir)
+	return profiles, err
+}
+
+// ListWithSkipped returns all local profiles along with any profile files
+// that could not be read or parsed, so corrupt profiles can be reported
+func ListWithSkipped(profilesDir string) ([]Profile, []SkippedFile, error) {
 	// Ensure profiles directory exists
 	if err := os.MkdirAll(profilesDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create profiles directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to create profiles directory: %w", err)
 	}
 
 	// Read all .json files from profiles directory
 	entries, err := os.ReadDir(profilesDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read profiles directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to read profiles directory: %w", err)
 	}
 
 	profiles := make([]Profile, 0)
+	var skipped []SkippedFile
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -297,18 +1307,20 @@ func List(profilesDir string) ([]Profile, error) {
 		profilePath := filepath.Join(profilesDir, entry.Name())
 		data, err := os.ReadFile(profilePath)
 		if err != nil {
-			continue // Skip files that can't be read
+			skipped = append(skipped, SkippedFile{Path: profilePath, Err: err})
+			continue
 		}
 
 		var profile Profile
 		if err := json.Unmarshal(data, &profile); err != nil {
-			continue // Skip files that can't be parsed
+			skipped = append(skipped, SkippedFile{Path: profilePath, Err: err})
+			continue
 		}
 
 		profiles = append(profiles, profile)
 	}
 
-	return profiles, nil
+	return profiles, skipped, nil
 }
 
 // Get retrieves a specific profile by name
@@ -2,9 +2,14 @@ package profile
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -608,8 +613,8 @@ func TestLoad_SkipsAlreadyInstalled(t *testing.T) {
 
 	// Test verifies profile loads and validation passed
 	if err != nil {
-		// Error is acceptable if it's "failed to install extension" (VS Code not available)
-		if strings.Contains(err.Error(), "failed to install extension") {
+		// Error is acceptable if VS Code is not available
+		if strings.Contains(err.Error(), "failed to install extension") || errors.Is(err, vscode.ErrVSCodeNotFound) {
 			t.Logf("Load failed as expected without VS Code: %v", err)
 			return
 		}
@@ -908,3 +913,1660 @@ func TestBackwardCompatibility_OldProfiles(t *testing.T) {
 		t.Errorf("expected to find 'old-profile' in list")
 	}
 }
+
+// stubVSCode replaces the VS Code list/install functions for the duration of
+// the test and skips the waits between install retries. It returns a pointer
+// to the IDs passed to the installer.
+func stubVSCode(t *testing.T, installed []vscode.Extension) *[]string {
+	t.Helper()
+	var installedIDs []string
+	var mu sync.Mutex
+
+	origList := listInstalledExtensions
+	origInstall := installExtensions
+	origSleep := sleep
+	listInstalledExtensions = func() ([]vscode.Extension, error) {
+		return installed, nil
+	}
+	sleep = func(time.Duration) {}
+	installExtensions = perExtension(func(extensionID string, force bool) error {
+		mu.Lock()
+		defer mu.Unlock()
+		installedIDs = append(installedIDs, extensionID)
+		return nil
+	})
+	t.Cleanup(func() {
+		listInstalledExtensions = origList
+		installExtensions = origInstall
+		sleep = origSleep
+	})
+
+	return &installedIDs
+}
+
+// perExtension adapts a single-extension install stub to installExtensions,
+// installing each extension of a batch in turn
+func perExtension(install func(extensionID string, force bool) error) func([]string, bool) []error {
+	return func(extensionIDs []string, force bool) []error {
+		errs := make([]error, len(extensionIDs))
+		for i, id := range extensionIDs {
+			errs[i] = install(id, force)
+		}
+		return errs
+	}
+}
+
+// writeTestProfile writes a profile JSON file into dir
+func writeTestProfile(t *testing.T, dir string, profile Profile) {
+	t.Helper()
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, profile.Name+".json"), data, 0644); err != nil {
+		t.Fatalf("failed to write profile file: %v", err)
+	}
+}
+
+func TestLoadWithOptions_DefaultSkipsInstalled(t *testing.T) {
+	tempDir := t.TempDir()
+	installedIDs := stubVSCode(t, []vscode.Extension{
+		{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+	})
+
+	writeTestProfile(t, tempDir, Profile{
+		Name: "mixed",
+		Extensions: []Extension{
+			{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+		},
+	})
+
+	if _, err := LoadWithOptions("mixed", tempDir, LoadOptions{}); err != nil {
+		t.Fatalf("LoadWithOptions failed: %v", err)
+	}
+
+	if len(*installedIDs) != 1 || (*installedIDs)[0] != "golang.go" {
+		t.Errorf("expected only golang.go to be installed, got %v", *installedIDs)
+	}
+}
+
+func TestLoadWithOptions_ForceReinstallInstallsAll(t *testing.T) {
+	tempDir := t.TempDir()
+	installedIDs := stubVSCode(t, []vscode.Extension{
+		{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+	})
+
+	writeTestProfile(t, tempDir, Profile{
+		Name: "mixed",
+		Extensions: []Extension{
+			{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+		},
+	})
+
+	if _, err := LoadWithOptions("mixed", tempDir, LoadOptions{ForceReinstall: true}); err != nil {
+		t.Fatalf("LoadWithOptions failed: %v", err)
+	}
+
+	want := []string{"ms-python.python", "golang.go"}
+	if len(*installedIDs) != len(want) {
+		t.Fatalf("expected %d installs, got %v", len(want), *installedIDs)
+	}
+	for i, id := range want {
+		if (*installedIDs)[i] != id {
+			t.Errorf("install %d: expected %s, got %s", i, id, (*installedIDs)[i])
+		}
+	}
+}
+
+func TestSave_TrimsName(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, []vscode.Extension{{ID: "golang.go", Version: "0.40.0", Enabled: true}})
+
+	prof, err := Save("  work ", tempDir)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if prof.Name != "work" {
+		t.Errorf("Expected name 'work', got %q", prof.Name)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "work.json")); err != nil {
+		t.Errorf("Expected work.json to be written: %v", err)
+	}
+}
+
+func TestSave_NormalizesExtensions(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, []vscode.Extension{
+		{ID: "ms-python.python", Version: "2.0.0", Enabled: true},
+		{ID: "golang.go", Version: "0.39.0", Enabled: true},
+		{ID: "golang.go", Version: "0.40.0", Enabled: true},
+	})
+
+	if _, err := Save("work", tempDir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	saved, err := Get("work", tempDir)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	want := []Extension{
+		{ID: "golang.go", Version: "0.40.0", Enabled: true},
+		{ID: "ms-python.python", Version: "2.0.0", Enabled: true},
+	}
+	if !reflect.DeepEqual(saved.Extensions, want) {
+		t.Errorf("saved extensions = %+v, want %+v", saved.Extensions, want)
+	}
+}
+
+func TestSave_RejectsCaseOnlyCollision(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, nil)
+
+	if _, err := Save("work", tempDir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	_, err := Save("Work", tempDir)
+	if err == nil {
+		t.Fatal("Expected error saving 'Work' alongside 'work', got nil")
+	}
+	if !strings.Contains(err.Error(), "'work' already exists") {
+		t.Errorf("Expected collision error naming 'work', got: %v", err)
+	}
+
+	// Re-saving the exact name is still an update
+	if _, err := Save("work ", tempDir); err != nil {
+		t.Errorf("Expected re-save of 'work' to succeed, got: %v", err)
+	}
+}
+
+func TestRename(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestProfile(t, tempDir, Profile{Name: "work", Extensions: []Extension{{ID: "golang.go", Version: "0.40.0", Enabled: true}}})
+
+	prof, err := Rename("work", " office ", tempDir)
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if prof.Name != "office" {
+		t.Errorf("Expected name 'office', got %q", prof.Name)
+	}
+
+	renamed, err := Get("office", tempDir)
+	if err != nil {
+		t.Fatalf("Get after rename failed: %v", err)
+	}
+	if len(renamed.Extensions) != 1 {
+		t.Errorf("Expected extensions to be preserved, got %d", len(renamed.Extensions))
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "work.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected work.json to be removed, got err=%v", err)
+	}
+}
+
+func TestRename_CaseOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestProfile(t, tempDir, Profile{Name: "work"})
+
+	if _, err := Rename("work", "Work", tempDir); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if _, err := Get("Work", tempDir); err != nil {
+		t.Errorf("Expected profile 'Work' after rename: %v", err)
+	}
+}
+
+func TestRename_Collision(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestProfile(t, tempDir, Profile{Name: "work"})
+	writeTestProfile(t, tempDir, Profile{Name: "Office"})
+
+	_, err := Rename("work", "office", tempDir)
+	if err == nil {
+		t.Fatal("Expected collision error, got nil")
+	}
+	if !strings.Contains(err.Error(), "'Office' already exists") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := Get("work", tempDir); err != nil {
+		t.Errorf("Expected 'work' to be untouched: %v", err)
+	}
+}
+
+func TestListWithSkipped_ReportsCorruptFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestProfile(t, tempDir, Profile{Name: "work"})
+
+	corruptPath := filepath.Join(tempDir, "broken.json")
+	if err := os.WriteFile(corruptPath, []byte(`{"name": "broken", "extensions": [`), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt profile: %v", err)
+	}
+
+	profiles, skipped, err := ListWithSkipped(tempDir)
+	if err != nil {
+		t.Fatalf("ListWithSkipped failed: %v", err)
+	}
+
+	if len(profiles) != 1 || profiles[0].Name != "work" {
+		t.Errorf("Expected only 'work' profile, got %v", profiles)
+	}
+	if len(skipped) != 1 {
+		t.Fatalf("Expected 1 skipped file, got %d", len(skipped))
+	}
+	if skipped[0].Path != corruptPath {
+		t.Errorf("Expected skipped path %s, got %s", corruptPath, skipped[0].Path)
+	}
+	if skipped[0].Err == nil {
+		t.Error("Expected skipped file to carry its parse error")
+	}
+}
+
+func TestInstallAll_MaxConcurrencyMatchesParallel(t *testing.T) {
+	extensions := make([]Extension, 12)
+	for i := range extensions {
+		extensions[i] = Extension{ID: fmt.Sprintf("publisher.ext%d", i), Version: "1.0.0"}
+	}
+
+	for _, parallel := range []int{0, 1, 2, 4} {
+		t.Run(fmt.Sprintf("parallel=%d", parallel), func(t *testing.T) {
+			var mu sync.Mutex
+			var active, maxActive, calls int
+
+			origInstall := installExtensions
+			installExtensions = perExtension(func(extensionID string, force bool) error {
+				mu.Lock()
+				active++
+				calls++
+				if active > maxActive {
+					maxActive = active
+				}
+				mu.Unlock()
+
+				time.Sleep(20 * time.Millisecond)
+
+				mu.Lock()
+				active--
+				mu.Unlock()
+				return nil
+			})
+			t.Cleanup(func() { installExtensions = origInstall })
+
+			for _, err := range installAll(extensions, false, parallel) {
+				if err != nil {
+					t.Fatalf("installAll failed: %v", err)
+				}
+			}
+
+			want := parallel
+			if want < 1 {
+				want = 1
+			}
+			if maxActive != want {
+				t.Errorf("max concurrent installs = %d, want %d", maxActive, want)
+			}
+			if calls != len(extensions) {
+				t.Errorf("install called %d times, want %d", calls, len(extensions))
+			}
+		})
+	}
+}
+
+func TestInstallAll_ReportsEachFailure(t *testing.T) {
+	extensions := make([]Extension, 20)
+	for i := range extensions {
+		extensions[i] = Extension{ID: fmt.Sprintf("publisher.ext%d", i), Version: "1.0.0"}
+	}
+
+	var mu sync.Mutex
+	calls := 0
+	origInstall := installExtensions
+	installExtensions = perExtension(func(extensionID string, force bool) error {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		if extensionID == "publisher.ext0" || extensionID == "publisher.ext7" {
+			return errors.New("marketplace unavailable")
+		}
+		return nil
+	})
+	t.Cleanup(func() { installExtensions = origInstall })
+
+	errs := installAll(extensions, false, 2)
+	if calls != len(extensions) {
+		t.Errorf("expected every extension to be attempted, got %d calls", calls)
+	}
+	for i, err := range errs {
+		failing := i == 0 || i == 7
+		if failing && (err == nil || !strings.Contains(err.Error(), extensions[i].ID)) {
+			t.Errorf("expected failure for %s, got: %v", extensions[i].ID, err)
+		}
+		if !failing && err != nil {
+			t.Errorf("expected %s to succeed, got: %v", extensions[i].ID, err)
+		}
+	}
+}
+
+func TestLoadWithResult_MixedOutcomes(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, []vscode.Extension{
+		{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+	})
+	installExtensions = perExtension(func(extensionID string, force bool) error {
+		if extensionID == "broken.ext" {
+			return fmt.Errorf("%w %s: exit status 1", vscode.ErrExtensionInstallFailed, extensionID)
+		}
+		return nil
+	})
+
+	writeTestProfile(t, tempDir, Profile{
+		Name: "mixed",
+		Extensions: []Extension{
+			{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "broken.ext", Version: "1.0.0", Enabled: true},
+			{ID: "rust-lang.rust-analyzer", Version: "0.3.0", Enabled: true},
+		},
+	})
+
+	result, err := LoadWithResult("mixed", tempDir, LoadOptions{Parallel: 2})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+
+	if got := extensionIDs(result.Skipped); !reflect.DeepEqual(got, []string{"ms-python.python"}) {
+		t.Errorf("Skipped = %v", got)
+	}
+	if got := extensionIDs(result.Installed); !reflect.DeepEqual(got, []string{"golang.go", "rust-lang.rust-analyzer"}) {
+		t.Errorf("Installed = %v", got)
+	}
+	if len(result.Upgraded) != 0 {
+		t.Errorf("Upgraded = %v, want none", extensionIDs(result.Upgraded))
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Extension.ID != "broken.ext" {
+		t.Fatalf("Failed = %+v, want broken.ext", result.Failed)
+	}
+
+	aggErr := result.Err()
+	if aggErr == nil || !errors.Is(aggErr, vscode.ErrExtensionInstallFailed) {
+		t.Errorf("expected aggregate error wrapping ErrExtensionInstallFailed, got: %v", aggErr)
+	}
+
+	// The convenience form reports the same failure as an error
+	if _, err := LoadWithOptions("mixed", tempDir, LoadOptions{}); err == nil || !strings.Contains(err.Error(), "broken.ext") {
+		t.Errorf("expected LoadWithOptions to fail for broken.ext, got: %v", err)
+	}
+}
+
+func TestLoadWithResult_ForceReinstallReportsUpgrades(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, []vscode.Extension{
+		{ID: "ms-python.python", Version: "2023.0.0", Enabled: true},
+		{ID: "golang.go", Version: "0.40.0", Enabled: true},
+	})
+
+	writeTestProfile(t, tempDir, Profile{
+		Name: "force",
+		Extensions: []Extension{
+			{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "rust-lang.rust-analyzer", Version: "0.3.0", Enabled: true},
+		},
+	})
+
+	result, err := LoadWithResult("force", tempDir, LoadOptions{ForceReinstall: true})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+
+	if got := extensionIDs(result.Upgraded); !reflect.DeepEqual(got, []string{"ms-python.python"}) {
+		t.Errorf("Upgraded = %v", got)
+	}
+	if got := extensionIDs(result.Installed); !reflect.DeepEqual(got, []string{"golang.go", "rust-lang.rust-analyzer"}) {
+		t.Errorf("Installed = %v", got)
+	}
+	if len(result.Skipped) != 0 || result.Err() != nil {
+		t.Errorf("expected no skips or failures, got %+v", result)
+	}
+}
+
+func TestLoadWithResult_SkipsBlockedExtensions(t *testing.T) {
+	tempDir := t.TempDir()
+	attempted := stubVSCode(t, nil)
+
+	writeTestProfile(t, tempDir, Profile{
+		Name: "blocked",
+		Extensions: []Extension{
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+			{ID: "redhat.java", Version: "1.0.0", Enabled: true},
+			{ID: "redhat.vscode-yaml", Version: "1.0.0", Enabled: true},
+		},
+	})
+
+	for _, force := range []bool{false, true} {
+		*attempted = nil
+		result, err := LoadWithResult("blocked", tempDir, LoadOptions{
+			ForceReinstall:    force,
+			BlockedExtensions: []string{"ms-python.python", "RedHat.*"},
+		})
+		if err != nil {
+			t.Fatalf("LoadWithResult(force=%v) failed: %v", force, err)
+		}
+
+		if got := extensionIDs(result.Blocked); !reflect.DeepEqual(got, []string{"ms-python.python", "redhat.java", "redhat.vscode-yaml"}) {
+			t.Errorf("force=%v: Blocked = %v", force, got)
+		}
+		if got := extensionIDs(result.Installed); !reflect.DeepEqual(got, []string{"golang.go"}) {
+			t.Errorf("force=%v: Installed = %v", force, got)
+		}
+		if !reflect.DeepEqual(*attempted, []string{"golang.go"}) {
+			t.Errorf("force=%v: expected only golang.go to be installed, attempted %v", force, *attempted)
+		}
+	}
+}
+
+func TestLoadWithResult_OptionalFailureIsWarning(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, nil)
+	installExtensions = perExtension(func(extensionID string, force bool) error {
+		if extensionID == "optional.broken" {
+			return fmt.Errorf("%w %s: exit status 1", vscode.ErrExtensionInstallFailed, extensionID)
+		}
+		return nil
+	})
+
+	optional := false
+	writeTestProfile(t, tempDir, Profile{
+		Name: "optional",
+		Extensions: []Extension{
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "optional.broken", Version: "1.0.0", Enabled: true, Required: &optional},
+		},
+	})
+
+	result, err := LoadWithResult("optional", tempDir, LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+
+	if len(result.OptionalFailed) != 1 || result.OptionalFailed[0].Extension.ID != "optional.broken" {
+		t.Errorf("OptionalFailed = %+v, want optional.broken", result.OptionalFailed)
+	}
+	if len(result.Failed) != 0 || result.Err() != nil {
+		t.Errorf("expected optional failure not to fail the load, got Failed=%+v Err=%v", result.Failed, result.Err())
+	}
+	if got := extensionIDs(result.Installed); !reflect.DeepEqual(got, []string{"golang.go"}) {
+		t.Errorf("Installed = %v", got)
+	}
+
+	if _, err := LoadWithOptions("optional", tempDir, LoadOptions{}); err != nil {
+		t.Errorf("expected LoadWithOptions to succeed, got: %v", err)
+	}
+}
+
+func TestExtension_RequiredJSON(t *testing.T) {
+	// Profiles written before the field existed are required
+	var legacy Extension
+	if err := json.Unmarshal([]byte(`{"id":"golang.go","version":"0.40.0","enabled":true}`), &legacy); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !legacy.IsRequired() {
+		t.Error("expected extension without 'required' to be required")
+	}
+
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "required") {
+		t.Errorf("expected 'required' to be omitted when unset, got %s", data)
+	}
+
+	var optional Extension
+	if err := json.Unmarshal([]byte(`{"id":"golang.go","version":"0.40.0","enabled":true,"required":false}`), &optional); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if optional.IsRequired() {
+		t.Error("expected 'required: false' to be optional")
+	}
+}
+
+func extensionIDs(exts []Extension) []string {
+	ids := make([]string, len(exts))
+	for i, ext := range exts {
+		ids[i] = ext.ID
+	}
+	return ids
+}
+
+func TestLoadWithOptions_NegativeParallel(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, nil)
+	writeTestProfile(t, tempDir, Profile{Name: "work"})
+
+	if _, err := LoadWithOptions("work", tempDir, LoadOptions{Parallel: -1}); err == nil {
+		t.Error("expected error for negative parallel count")
+	}
+}
+
+// writeExtensionDir creates an installed extension under root the way VS Code lays it out
+func writeExtensionDir(t *testing.T, root, id, version string) {
+	t.Helper()
+	parts := strings.SplitN(id, ".", 2)
+	dir := filepath.Join(root, id+"-"+version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create extension dir: %v", err)
+	}
+	manifest := fmt.Sprintf(`{"name": %q, "publisher": %q, "version": %q}`, parts[1], parts[0], version)
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+}
+
+// stubExtensionDirs points auto-detection at autoDir and fails if the VS Code CLI is used
+func stubExtensionDirs(t *testing.T, autoDir string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	origList := listInstalledExtensions
+	origDefaults := defaultExtensionDirs
+	listInstalledExtensions = func() ([]vscode.Extension, error) {
+		t.Error("VS Code CLI should not be used when extension directories are given")
+		return nil, nil
+	}
+	defaultExtensionDirs = func() []string { return []string{autoDir} }
+	t.Cleanup(func() {
+		listInstalledExtensions = origList
+		defaultExtensionDirs = origDefaults
+	})
+}
+
+func extensionVersions(p *Profile) map[string]string {
+	versions := make(map[string]string)
+	for _, ext := range p.Extensions {
+		versions[ext.ID] = ext.Version
+	}
+	return versions
+}
+
+func TestSaveWithOptions_MergesCustomDirs(t *testing.T) {
+	autoDir := t.TempDir()
+	writeExtensionDir(t, autoDir, "golang.go", "0.40.0")
+	stubExtensionDirs(t, autoDir)
+
+	dirA := t.TempDir()
+	dirB := t.TempDir()
+	writeExtensionDir(t, dirA, "ms-python.python", "2024.0.0")
+	writeExtensionDir(t, dirB, "ms-python.python", "2024.2.0")
+	writeExtensionDir(t, dirB, "rust-lang.rust-analyzer", "0.3.0")
+
+	prof, err := SaveWithOptions("multi", t.TempDir(), SaveOptions{ExtensionDirs: []string{dirA, dirB}})
+	if err != nil {
+		t.Fatalf("SaveWithOptions failed: %v", err)
+	}
+
+	versions := extensionVersions(prof)
+	if versions["ms-python.python"] != "2024.2.0" {
+		t.Errorf("Expected highest python version 2024.2.0, got %q", versions["ms-python.python"])
+	}
+	if _, ok := versions["rust-lang.rust-analyzer"]; !ok {
+		t.Error("Expected extension from second custom dir")
+	}
+	if _, ok := versions["golang.go"]; !ok {
+		t.Error("Expected extension from auto-detected dir")
+	}
+	if len(prof.Extensions) != 3 {
+		t.Errorf("Expected 3 extensions, got %d", len(prof.Extensions))
+	}
+}
+
+func TestSaveWithOptions_NoAutoDirsSkipsDefaults(t *testing.T) {
+	autoDir := t.TempDir()
+	writeExtensionDir(t, autoDir, "golang.go", "0.40.0")
+	stubExtensionDirs(t, autoDir)
+
+	customDir := t.TempDir()
+	writeExtensionDir(t, customDir, "ms-python.python", "2024.0.0")
+
+	prof, err := SaveWithOptions("custom-only", t.TempDir(), SaveOptions{
+		ExtensionDirs: []string{customDir},
+		NoAutoDirs:    true,
+	})
+	if err != nil {
+		t.Fatalf("SaveWithOptions failed: %v", err)
+	}
+
+	versions := extensionVersions(prof)
+	if _, ok := versions["golang.go"]; ok {
+		t.Error("Expected auto-detected dir to be skipped with NoAutoDirs")
+	}
+	if len(prof.Extensions) != 1 {
+		t.Errorf("Expected 1 extension, got %d", len(prof.Extensions))
+	}
+}
+
+func TestSaveWithOptions_NoAutoDirsRequiresCustomDir(t *testing.T) {
+	stubVSCode(t, nil)
+
+	if _, err := SaveWithOptions("work", t.TempDir(), SaveOptions{NoAutoDirs: true}); err == nil {
+		t.Error("Expected error when NoAutoDirs is set without extension directories")
+	}
+}
+
+func TestDiff_InSync(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{
+		Name: "parity",
+		Extensions: []Extension{
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			{ID: "golang.go", Version: "2.0.0", Enabled: true},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		installed []vscode.Extension
+		want      bool
+	}{
+		{
+			name: "all installed at profile version",
+			installed: []vscode.Extension{
+				{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+				{ID: "golang.go", Version: "2.0.0", Enabled: true},
+				{ID: "extra.extension", Version: "0.1.0"},
+			},
+			want: true,
+		},
+		{
+			name: "missing extension",
+			installed: []vscode.Extension{
+				{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			},
+			want: false,
+		},
+		{
+			name: "version mismatch",
+			installed: []vscode.Extension{
+				{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+				{ID: "golang.go", Version: "2.1.0", Enabled: true},
+			},
+			want: false,
+		},
+		{
+			name: "installed version unknown",
+			installed: []vscode.Extension{
+				{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+				{ID: "golang.go", Enabled: true},
+			},
+			want: true,
+		},
+		{
+			name: "disabled locally",
+			installed: []vscode.Extension{
+				{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+				{ID: "golang.go", Version: "2.0.0", Enabled: false},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubVSCode(t, tt.installed)
+
+			result, err := Diff("parity", dir)
+			if err != nil {
+				t.Fatalf("Diff failed: %v", err)
+			}
+			if got := result.InSync(); got != tt.want {
+				t.Errorf("InSync() = %v, want %v (result: %+v)", got, tt.want, result)
+			}
+		})
+	}
+}
+
+func TestDiff_ReportsVersionMismatches(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{
+		Name:       "versions",
+		Extensions: []Extension{{ID: "golang.go", Version: "2.0.0", Enabled: true}},
+	})
+	stubVSCode(t, []vscode.Extension{{ID: "golang.go", Version: "2.1.0"}})
+
+	result, err := Diff("versions", dir)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	want := []VersionMismatch{{ID: "golang.go", ProfileVersion: "2.0.0", InstalledVersion: "2.1.0"}}
+	if !reflect.DeepEqual(result.VersionMismatches, want) {
+		t.Errorf("VersionMismatches = %+v, want %+v", result.VersionMismatches, want)
+	}
+}
+
+func TestCompareProfiles(t *testing.T) {
+	local := &Profile{
+		Name: "work",
+		Extensions: []Extension{
+			{ID: "golang.go", Version: "2.1.0", Enabled: true},
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			{ID: "new.ext", Version: "0.1.0", Enabled: true},
+		},
+	}
+	remote := &Profile{
+		Name: "work",
+		Extensions: []Extension{
+			{ID: "golang.go", Version: "2.0.0", Enabled: true},
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			{ID: "old.ext", Version: "3.0.0", Enabled: true},
+		},
+	}
+
+	diff := CompareProfiles(local, remote)
+
+	if ids := changeIDs(diff.Changes.Added); !reflect.DeepEqual(ids, []string{"new.ext"}) {
+		t.Errorf("Added = %v, want [new.ext]", ids)
+	}
+	if ids := changeIDs(diff.Changes.Removed); !reflect.DeepEqual(ids, []string{"old.ext"}) {
+		t.Errorf("Removed = %v, want [old.ext]", ids)
+	}
+	changed := diff.Changes.VersionChanged
+	if len(changed) != 1 || changed[0].ID != "golang.go" || changed[0].To.Version != "2.1.0" || changed[0].From.Version != "2.0.0" {
+		t.Errorf("VersionChanged = %+v, want golang.go from 2.0.0 to 2.1.0", changed)
+	}
+	if diff.Identical() {
+		t.Error("expected profiles to differ")
+	}
+
+	if !CompareProfiles(local, local).Identical() {
+		t.Error("expected a profile to be identical to itself")
+	}
+}
+
+// changeIDs returns the IDs of changes in order
+func changeIDs(changes []ExtensionChange) []string {
+	ids := make([]string, len(changes))
+	for i, c := range changes {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func TestCompareExtensions_StateChanged(t *testing.T) {
+	from := []Extension{{ID: "golang.go", Version: "1.0.0", Enabled: true}}
+	to := []Extension{{ID: "golang.go", Version: "1.0.0", Enabled: false}}
+
+	changes := CompareExtensions(from, to)
+	if ids := changeIDs(changes.StateChanged); !reflect.DeepEqual(ids, []string{"golang.go"}) {
+		t.Errorf("StateChanged = %v, want [golang.go]", ids)
+	}
+	if len(changes.VersionChanged) != 0 {
+		t.Errorf("VersionChanged = %+v, want none", changes.VersionChanged)
+	}
+	if changes.Empty() {
+		t.Error("expected a state change to count as a change")
+	}
+}
+
+func TestDiffResult_JSONShape(t *testing.T) {
+	local := &Profile{Name: "work", Extensions: []Extension{
+		{ID: "golang.go", Version: "2.1.0", Enabled: true},
+		{ID: "new.ext", Version: "0.1.0", Enabled: true},
+	}}
+	remote := &Profile{Name: "work", Extensions: []Extension{
+		{ID: "golang.go", Version: "2.0.0", Enabled: true},
+	}}
+
+	data, err := json.Marshal(CompareProfiles(local, remote))
+	if err != nil {
+		t.Fatalf("failed to marshal diff: %v", err)
+	}
+	want := `{"profile":"work","changes":{` +
+		`"added":[{"id":"new.ext","to":{"id":"new.ext","version":"0.1.0","enabled":true}}],` +
+		`"removed":[],` +
+		`"version_changed":[{"id":"golang.go","from":{"id":"golang.go","version":"2.0.0","enabled":true},"to":{"id":"golang.go","version":"2.1.0","enabled":true}}],` +
+		`"state_changed":[]},"total_in_profile":2}`
+	if string(data) != want {
+		t.Errorf("diff JSON =\n%s\nwant\n%s", data, want)
+	}
+
+	// An installed-extension diff serializes with the same keys and no
+	// installed-only fields
+	stubVSCode(t, []vscode.Extension{{ID: "golang.go", Version: "2.1.0", Enabled: true}})
+	dir := t.TempDir()
+	writeTestProfile(t, dir, *local)
+	result, err := Diff("work", dir)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	var decoded map[string]json.RawMessage
+	data, err = json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal diff: %v", err)
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode diff: %v", err)
+	}
+	var keys []string
+	for k := range decoded {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"changes", "profile", "total_in_profile"}) {
+		t.Errorf("installed diff JSON keys = %v", keys)
+	}
+}
+
+func TestDiff_UnifiedChangesMatchInstallBuckets(t *testing.T) {
+	stubVSCode(t, []vscode.Extension{
+		{ID: "golang.go", Version: "2.1.0", Enabled: true},
+		{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+		{ID: "extra.ext", Version: "1.0.0", Enabled: true},
+	})
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{Name: "work", Extensions: []Extension{
+		{ID: "golang.go", Version: "2.0.0", Enabled: true},
+		{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+		{ID: "rust-lang.rust-analyzer", Version: "0.3.0", Enabled: true},
+	}})
+
+	result, err := Diff("work", dir)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	if ids := extensionIDs(result.ToInstall); !reflect.DeepEqual(ids, []string{"rust-lang.rust-analyzer"}) {
+		t.Errorf("ToInstall = %v, want [rust-lang.rust-analyzer]", ids)
+	}
+	if ids := extensionIDs(result.AlreadyInstalled); !reflect.DeepEqual(ids, []string{"golang.go", "ms-python.python"}) {
+		t.Errorf("AlreadyInstalled = %v, want [golang.go ms-python.python]", ids)
+	}
+	want := []VersionMismatch{{ID: "golang.go", ProfileVersion: "2.0.0", InstalledVersion: "2.1.0"}}
+	if !reflect.DeepEqual(result.VersionMismatches, want) {
+		t.Errorf("VersionMismatches = %+v, want %+v", result.VersionMismatches, want)
+	}
+
+	if ids := changeIDs(result.Changes.Added); !reflect.DeepEqual(ids, extensionIDs(result.ToInstall)) {
+		t.Errorf("Changes.Added = %v, want the extensions to install", ids)
+	}
+	if ids := changeIDs(result.Changes.Removed); !reflect.DeepEqual(ids, []string{"extra.ext"}) {
+		t.Errorf("Changes.Removed = %v, want [extra.ext]", ids)
+	}
+	if ids := changeIDs(result.Changes.VersionChanged); !reflect.DeepEqual(ids, []string{"golang.go"}) {
+		t.Errorf("Changes.VersionChanged = %v, want [golang.go]", ids)
+	}
+}
+
+func TestNormalizeExtensions(t *testing.T) {
+	exts := []Extension{
+		{ID: "ms-python.python", Version: "2.0.0", Enabled: true},
+		{ID: "golang.go", Version: "0.41.0-beta", Enabled: true},
+		{ID: "Golang.Go", Version: "0.41.0", Enabled: false},
+		{ID: "ms-python.python", Version: "2.0.0", Enabled: false},
+	}
+
+	got := NormalizeExtensions(exts)
+	want := []Extension{
+		{ID: "Golang.Go", Version: "0.41.0", Enabled: false},
+		{ID: "ms-python.python", Version: "2.0.0", Enabled: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeExtensions = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffFile(t *testing.T) {
+	stubVSCode(t, []vscode.Extension{
+		{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+	})
+
+	// Outside any profiles directory
+	path := filepath.Join(t.TempDir(), "from-colleague.json")
+	writeTestProfile(t, filepath.Dir(path), Profile{
+		Name: "from-colleague",
+		Extensions: []Extension{
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			{ID: "golang.go", Version: "2.0.0", Enabled: true},
+		},
+	})
+
+	result, err := DiffFile(path, DiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffFile() error = %v", err)
+	}
+	if result.ProfileName != "from-colleague" {
+		t.Errorf("ProfileName = %q, want %q", result.ProfileName, "from-colleague")
+	}
+	if len(result.ToInstall) != 1 || result.ToInstall[0].ID != "golang.go" {
+		t.Errorf("ToInstall = %v, want [golang.go]", result.ToInstall)
+	}
+	if len(result.AlreadyInstalled) != 1 || result.AlreadyInstalled[0].ID != "ms-python.python" {
+		t.Errorf("AlreadyInstalled = %v, want [ms-python.python]", result.AlreadyInstalled)
+	}
+}
+
+func TestDiffFile_InvalidProfileRejectedBeforeListing(t *testing.T) {
+	origList := listInstalledExtensions
+	listInstalledExtensions = func() ([]vscode.Extension, error) {
+		t.Error("installed extensions should not be listed for an invalid profile")
+		return nil, nil
+	}
+	t.Cleanup(func() { listInstalledExtensions = origList })
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"malformed JSON", "{not json", "failed to parse profile file"},
+		{"bad extension ID", `{"name":"x","extensions":[{"id":"bad-extension-id","version":"1.0.0"}]}`, "invalid profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profile.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write profile file: %v", err)
+			}
+
+			_, err := DiffFile(path, DiffOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("DiffFile() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiffFile_MissingFile(t *testing.T) {
+	_, err := DiffFile(filepath.Join(t.TempDir(), "missing.json"), DiffOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to read profile file") {
+		t.Errorf("DiffFile() error = %v, want a read error", err)
+	}
+}
+
+func TestDiffWithOptions_PreReleaseOutdated(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{
+		Name: "channel",
+		Extensions: []Extension{
+			{ID: "golang.go", Version: "0.41.0-beta", Enabled: true},
+			{ID: "ms-python.python", Version: "2.0.0", Enabled: true},
+		},
+	})
+	stubVSCode(t, []vscode.Extension{
+		{ID: "golang.go", Version: "0.40.0"},
+		{ID: "ms-python.python", Version: "2.0.0-rc.1"},
+	})
+
+	stable, err := DiffWithOptions("channel", dir, DiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffWithOptions failed: %v", err)
+	}
+	if ids := mismatchIDs(stable.Outdated); !reflect.DeepEqual(ids, []string{"ms-python.python"}) {
+		t.Errorf("stable Outdated = %v, want [ms-python.python]", ids)
+	}
+
+	preRelease, err := DiffWithOptions("channel", dir, DiffOptions{PreRelease: true})
+	if err != nil {
+		t.Fatalf("DiffWithOptions failed: %v", err)
+	}
+	if ids := mismatchIDs(preRelease.Outdated); !reflect.DeepEqual(ids, []string{"golang.go", "ms-python.python"}) {
+		t.Errorf("pre-release Outdated = %v, want [golang.go ms-python.python]", ids)
+	}
+
+	if len(stable.VersionMismatches) != 2 || len(preRelease.VersionMismatches) != 2 {
+		t.Error("expected version mismatches to be reported in both modes")
+	}
+}
+
+func mismatchIDs(mismatches []VersionMismatch) []string {
+	ids := make([]string, len(mismatches))
+	for i, m := range mismatches {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func TestSaveWithOptions_DryRunWritesNothing(t *testing.T) {
+	profilesDir := filepath.Join(t.TempDir(), "profiles")
+	stubVSCode(t, []vscode.Extension{{ID: "golang.go", Version: "0.40.0", Enabled: true}})
+
+	prof, err := SaveWithOptions("work", profilesDir, SaveOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("SaveWithOptions failed: %v", err)
+	}
+
+	if len(prof.Extensions) != 1 {
+		t.Errorf("expected the captured profile to be returned, got %+v", prof)
+	}
+	if _, err := os.Stat(profilesDir); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be written, stat err = %v", err)
+	}
+}
+
+func TestLoadWithResult_DryRunInstallsNothing(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{
+		Name: "work",
+		Extensions: []Extension{
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			{ID: "esbenp.prettier-vscode", Version: "10.0.0", Enabled: true},
+		},
+	})
+	installed := stubVSCode(t, []vscode.Extension{
+		{ID: "golang.go", Version: "0.40.0"},
+		{ID: "ms-python.python", Version: "0.9.0"},
+	})
+
+	result, err := LoadWithResult("work", dir, LoadOptions{ForceReinstall: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+
+	if len(*installed) != 0 {
+		t.Errorf("expected no installs in dry run, got %v", *installed)
+	}
+	if got := extensionIDs(result.Upgraded); !reflect.DeepEqual(got, []string{"ms-python.python"}) {
+		t.Errorf("planned upgrades = %v, want [ms-python.python]", got)
+	}
+	if got := extensionIDs(result.Installed); !reflect.DeepEqual(got, []string{"golang.go", "esbenp.prettier-vscode"}) {
+		t.Errorf("planned installs = %v, want [golang.go esbenp.prettier-vscode]", got)
+	}
+}
+
+func TestRenameWithOptions_DryRunLeavesFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{Name: "work", Extensions: []Extension{}})
+
+	prof, err := RenameWithOptions("work", "office", dir, RenameOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("RenameWithOptions failed: %v", err)
+	}
+
+	if prof.Name != "office" {
+		t.Errorf("expected the renamed profile to be returned, got %q", prof.Name)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "work.json")); err != nil {
+		t.Errorf("expected work.json to remain: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "office.json")); !os.IsNotExist(err) {
+		t.Errorf("expected office.json not to exist, stat err = %v", err)
+	}
+}
+
+func TestSave_StoresExtensionMetadata(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, []vscode.Extension{
+		{ID: "golang.go", Version: "0.40.0", Enabled: true, DisplayName: "Go", Publisher: "golang", Description: "Rich Go language support"},
+		{ID: "ms-python.python", Version: "1.0.0", Enabled: true, DisplayName: "%displayName%", Publisher: "ms-python", Description: "%description%"},
+		{ID: "esbenp.prettier-vscode", Version: "10.0.0", Enabled: true},
+	})
+
+	if _, err := Save("work", tempDir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	saved, err := Get("work", tempDir)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	byID := make(map[string]Extension)
+	for _, ext := range saved.Extensions {
+		byID[ext.ID] = ext
+	}
+
+	if got := byID["golang.go"]; got.DisplayName != "Go" || got.Publisher != "golang" || got.Description != "Rich Go language support" {
+		t.Errorf("golang.go metadata = %+v, want manifest values", got)
+	}
+	// Unresolved localization placeholders are not worth storing
+	if got := byID["ms-python.python"]; got.DisplayName != "" || got.Description != "" || got.Publisher != "ms-python" {
+		t.Errorf("ms-python.python metadata = %+v, want placeholders dropped", got)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "work.json"))
+	if err != nil {
+		t.Fatalf("failed to read profile: %v", err)
+	}
+	if strings.Count(string(data), `"display_name"`) != 1 {
+		t.Errorf("expected empty metadata to be omitted, got:\n%s", data)
+	}
+}
+
+func TestSaveWithOptions_Description(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, []vscode.Extension{{ID: "golang.go", Version: "0.40.0", Enabled: true}})
+
+	// Without a description the field is left out of the file entirely
+	if _, err := Save("work", tempDir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tempDir, "work.json"))
+	if err != nil {
+		t.Fatalf("failed to read profile: %v", err)
+	}
+	if strings.Contains(string(data), `"description"`) {
+		t.Errorf("expected no description in profile, got:\n%s", data)
+	}
+
+	prof, err := SaveWithOptions("work", tempDir, SaveOptions{Description: "Go backend work"})
+	if err != nil {
+		t.Fatalf("SaveWithOptions failed: %v", err)
+	}
+	if prof.Description != "Go backend work" {
+		t.Errorf("Description = %q, want %q", prof.Description, "Go backend work")
+	}
+
+	// Re-saving without a description keeps the stored one
+	if _, err := Save("work", tempDir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved, err := Get("work", tempDir)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if saved.Description != "Go backend work" {
+		t.Errorf("Description after re-save = %q, want it kept", saved.Description)
+	}
+	if err := Validate(saved); err != nil {
+		t.Errorf("profile with description should be valid: %v", err)
+	}
+}
+
+func TestSaveWithOptions_KeepsStoredSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, []vscode.Extension{
+		{ID: "golang.go", Version: "0.41.0", Enabled: true},
+		{ID: "esbenp.prettier-vscode", Version: "10.0.0", Enabled: true},
+	})
+
+	optional := false
+	writeTestProfile(t, tempDir, Profile{
+		Name:        "work",
+		Settings:    json.RawMessage(`{"editor.tabSize": 2}`),
+		Keybindings: json.RawMessage(`[]`),
+		Extensions: []Extension{
+			{ID: "Golang.Go", Version: "0.40.0", VersionConstraint: "^0.40.0", Enabled: true},
+			{ID: "esbenp.prettier-vscode", Version: "9.0.0", Required: &optional, Enabled: true},
+		},
+	})
+
+	if _, err := Save("work", tempDir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved, err := Get("work", tempDir)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if !strings.Contains(string(saved.Settings), `"editor.tabSize": 2`) || string(saved.Keybindings) != `[]` {
+		t.Errorf("expected settings and keybindings to be kept, got %s and %s", saved.Settings, saved.Keybindings)
+	}
+	for _, ext := range saved.Extensions {
+		switch ext.ID {
+		case "golang.go":
+			if ext.Version != "0.41.0" || ext.VersionConstraint != "^0.40.0" {
+				t.Errorf("golang.go = %+v, want the captured version with the stored constraint", ext)
+			}
+		case "esbenp.prettier-vscode":
+			if ext.IsRequired() {
+				t.Errorf("esbenp.prettier-vscode = %+v, want it kept optional", ext)
+			}
+		}
+	}
+}
+
+func TestLoadWithResult_ProfileWithoutMetadata(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"name": "legacy", "created_at": "2024-01-01T00:00:00Z", "updated_at": "2024-01-01T00:00:00Z",
+  "extensions": [{"id": "golang.go", "version": "0.40.0", "enabled": true}]}`
+	if err := os.WriteFile(filepath.Join(dir, "legacy.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	installed := stubVSCode(t, nil)
+
+	result, err := LoadWithResult("legacy", dir, LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+	if len(*installed) != 1 || result.Profile.Extensions[0].DisplayName != "" {
+		t.Errorf("expected legacy profile to load unchanged, installed %v, profile %+v", *installed, result.Profile)
+	}
+}
+
+// stubUserFiles records user files written by Load instead of touching VS Code
+func stubUserFiles(t *testing.T) map[string]string {
+	t.Helper()
+	written := make(map[string]string)
+	original := writeUserFile
+	writeUserFile = func(name string, data []byte) (string, error) {
+		written[name] = string(data)
+		return name + ".bak", nil
+	}
+	t.Cleanup(func() { writeUserFile = original })
+	return written
+}
+
+func settingsProfile() Profile {
+	return Profile{
+		Name:        "editor",
+		Extensions:  []Extension{},
+		Settings:    json.RawMessage(`{"editor.tabSize": 2}`),
+		Keybindings: json.RawMessage(`[{"key": "ctrl+k", "command": "workbench.action.quickOpen"}]`),
+	}
+}
+
+func TestLoadWithResult_WritesSettingsAndKeybindings(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, settingsProfile())
+	stubVSCode(t, nil)
+	written := stubUserFiles(t)
+
+	result, err := LoadWithResult("editor", dir, LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+
+	if written[vscode.SettingsFile] != "{\n  \"editor.tabSize\": 2\n}\n" {
+		t.Errorf("settings.json = %q, want re-indented settings", written[vscode.SettingsFile])
+	}
+	if !strings.Contains(written[vscode.KeybindingsFile], "workbench.action.quickOpen") {
+		t.Errorf("keybindings.json = %q, want the profile keybindings", written[vscode.KeybindingsFile])
+	}
+	if !reflect.DeepEqual(result.Backups, []string{"settings.json.bak", "keybindings.json.bak"}) {
+		t.Errorf("Backups = %v, want one per written file", result.Backups)
+	}
+}
+
+func TestLoadWithResult_SkipSettingsAndKeybindings(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, settingsProfile())
+	stubVSCode(t, nil)
+	written := stubUserFiles(t)
+
+	result, err := LoadWithResult("editor", dir, LoadOptions{SkipSettings: true})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+	if _, ok := written[vscode.SettingsFile]; ok {
+		t.Error("expected settings.json not to be written")
+	}
+	if !reflect.DeepEqual(result.UserFiles, []string{vscode.KeybindingsFile}) {
+		t.Errorf("UserFiles = %v, want only keybindings.json", result.UserFiles)
+	}
+
+	delete(written, vscode.KeybindingsFile)
+	if _, err := LoadWithResult("editor", dir, LoadOptions{SkipSettings: true, SkipKeybindings: true}); err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+	if len(written) != 0 {
+		t.Errorf("expected no user files to be written, got %v", written)
+	}
+}
+
+func TestValidate_SettingsShape(t *testing.T) {
+	prof := Profile{Name: "editor", Settings: json.RawMessage(`[1]`)}
+	if err := Validate(&prof); err == nil {
+		t.Error("expected settings that are not an object to be rejected")
+	}
+
+	prof = Profile{Name: "editor", Keybindings: json.RawMessage(`{}`)}
+	if err := Validate(&prof); err == nil {
+		t.Error("expected keybindings that are not an array to be rejected")
+	}
+
+	prof = Profile{Name: "editor", Settings: json.RawMessage(`null`)}
+	if err := Validate(&prof); err != nil {
+		t.Errorf("expected null settings to be treated as absent, got %v", err)
+	}
+}
+
+// stubVersions serves published extension versions from memory
+type stubVersions map[string][]string
+
+func (s stubVersions) ExtensionVersions(extensionID string) ([]string, error) {
+	return s[extensionID], nil
+}
+
+func TestLoadWithResult_VersionConstraints(t *testing.T) {
+	tempDir := t.TempDir()
+	installed := stubVSCode(t, []vscode.Extension{
+		{ID: "ms-python.python", Version: "2024.1.0", Enabled: true},
+		{ID: "golang.go", Version: "0.39.0", Enabled: true},
+	})
+
+	writeTestProfile(t, tempDir, Profile{
+		Name: "pinned",
+		Extensions: []Extension{
+			{ID: "ms-python.python", Version: "2024.0.0", VersionConstraint: "^2024.0.0", Enabled: true},
+			{ID: "golang.go", Version: "0.40.0", VersionConstraint: "~0.40.0", Enabled: true},
+			{ID: "rust-lang.rust-analyzer", Version: "0.3.0", VersionConstraint: "^0.3.0", Enabled: true},
+			{ID: "esbenp.prettier-vscode", Version: "10.0.0", Enabled: true},
+		},
+	})
+
+	result, err := LoadWithResult("pinned", tempDir, LoadOptions{Versions: stubVersions{
+		"golang.go":               {"0.41.0", "0.40.3", "0.40.1", "0.39.0"},
+		"rust-lang.rust-analyzer": {"0.4.0", "0.3.9", "0.3.2"},
+	}})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+
+	// The installed python satisfies ^2024.0.0 and is left alone
+	if got := extensionIDs(result.Skipped); !reflect.DeepEqual(got, []string{"ms-python.python"}) {
+		t.Errorf("Skipped = %v", got)
+	}
+	if len(result.Upgraded) != 1 || result.Upgraded[0].ID != "golang.go" || result.Upgraded[0].Version != "0.40.3" {
+		t.Errorf("Upgraded = %+v, want golang.go at 0.40.3", result.Upgraded)
+	}
+
+	sort.Strings(*installed)
+	want := []string{"esbenp.prettier-vscode", "golang.go@0.40.3", "rust-lang.rust-analyzer@0.3.9"}
+	if !reflect.DeepEqual(*installed, want) {
+		t.Errorf("installed %v, want %v", *installed, want)
+	}
+}
+
+func TestLoadWithResult_VersionConstraintUpgradeIsForced(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, []vscode.Extension{{ID: "golang.go", Version: "0.39.0", Enabled: true}})
+	forced := make(map[string]bool)
+	installExtensions = perExtension(func(extensionID string, force bool) error {
+		forced[extensionID] = force
+		return nil
+	})
+
+	writeTestProfile(t, tempDir, Profile{
+		Name: "pinned",
+		Extensions: []Extension{
+			{ID: "golang.go", Version: "0.40.0", VersionConstraint: "~0.40.0", Enabled: true},
+			{ID: "esbenp.prettier-vscode", Version: "10.0.0", Enabled: true},
+		},
+	})
+
+	result, err := LoadWithResult("pinned", tempDir, LoadOptions{Versions: stubVersions{
+		"golang.go": {"0.40.3", "0.39.0"},
+	}})
+	if err != nil {
+		t.Fatalf("LoadWithResult failed: %v", err)
+	}
+
+	// VS Code only changes an installed version with --force
+	want := map[string]bool{"golang.go@0.40.3": true, "esbenp.prettier-vscode": false}
+	if !reflect.DeepEqual(forced, want) {
+		t.Errorf("installs (spec: forced) = %v, want %v", forced, want)
+	}
+	if len(result.Upgraded) != 1 || result.Upgraded[0].ID != "golang.go" {
+		t.Errorf("Upgraded = %+v, want golang.go", result.Upgraded)
+	}
+}
+
+func TestLoadWithResult_VersionConstraintUnresolvable(t *testing.T) {
+	tempDir := t.TempDir()
+	stubVSCode(t, nil)
+
+	writeTestProfile(t, tempDir, Profile{
+		Name:       "pinned",
+		Extensions: []Extension{{ID: "golang.go", VersionConstraint: "~0.40.0", Enabled: true}},
+	})
+
+	_, err := LoadWithResult("pinned", tempDir, LoadOptions{Versions: stubVersions{"golang.go": {"0.41.0"}}})
+	if err == nil || !strings.Contains(err.Error(), "no available version of extension golang.go satisfies ~0.40.0") {
+		t.Errorf("expected unresolvable constraint error, got: %v", err)
+	}
+
+	_, err = LoadWithResult("pinned", tempDir, LoadOptions{})
+	if err == nil || !strings.Contains(err.Error(), "available versions are unknown") {
+		t.Errorf("expected error without a version source, got: %v", err)
+	}
+}
+
+func TestValidate_VersionConstraint(t *testing.T) {
+	p := &Profile{Name: "pinned", Extensions: []Extension{{ID: "golang.go", VersionConstraint: ">=0.40"}}}
+	if err := Validate(p); err == nil || !strings.Contains(err.Error(), "invalid version constraint") {
+		t.Errorf("expected invalid constraint error, got: %v", err)
+	}
+}
+
+func TestInstallAll_Batches(t *testing.T) {
+	extensions := make([]Extension, 7)
+	for i := range extensions {
+		extensions[i] = Extension{ID: fmt.Sprintf("publisher.ext%d", i), Version: "1.0.0"}
+	}
+	extensions[6].VersionConstraint = "^1.0.0"
+
+	for _, tt := range []struct {
+		parallel int
+		sizes    []int
+	}{
+		// 0 and 1 install serially, one extension per invocation
+		{0, []int{1, 1, 1, 1, 1, 1, 1}},
+		{1, []int{1, 1, 1, 1, 1, 1, 1}},
+		{3, []int{2, 2, 3}},
+		{10, []int{1, 1, 1, 1, 1, 1, 1}},
+	} {
+		var mu sync.Mutex
+		var batches [][]string
+		origInstall := installExtensions
+		installExtensions = func(extensionIDs []string, force bool) []error {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, extensionIDs)
+			errs := make([]error, len(extensionIDs))
+			for i, id := range extensionIDs {
+				if id == "publisher.ext3" {
+					errs[i] = errors.New("not found")
+				}
+			}
+			return errs
+		}
+
+		errs := installAll(extensions, false, tt.parallel)
+		installExtensions = origInstall
+
+		sort.Slice(batches, func(i, j int) bool { return batches[i][0] < batches[j][0] })
+		var sizes []int
+		var specs []string
+		for _, b := range batches {
+			sizes = append(sizes, len(b))
+			specs = append(specs, b...)
+		}
+		if !reflect.DeepEqual(sizes, tt.sizes) {
+			t.Errorf("parallel=%d: batch sizes = %v, want %v", tt.parallel, sizes, tt.sizes)
+		}
+		if len(specs) != 7 || specs[6] != "publisher.ext6@1.0.0" {
+			t.Errorf("parallel=%d: installed %v, want every extension with the pinned one as id@version", tt.parallel, specs)
+		}
+		for i, err := range errs {
+			if (i == 3) != (err != nil) {
+				t.Errorf("parallel=%d: extension %d error = %v", tt.parallel, i, err)
+			}
+		}
+	}
+}
+
+func TestPlanLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{
+		Name: "work",
+		Extensions: []Extension{
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: true},
+			{ID: "esbenp.prettier-vscode", Version: "10.0.0", Enabled: true},
+			{ID: "evil.miner", Version: "1.0.0", Enabled: true},
+		},
+		Settings: json.RawMessage(`{"editor.tabSize": 2}`),
+	})
+	installed := stubVSCode(t, []vscode.Extension{
+		{ID: "golang.go", Version: "0.40.0"},
+		{ID: "ms-python.python", Version: "0.9.0"},
+	})
+
+	tests := []struct {
+		name          string
+		opts          LoadOptions
+		wantInstall   []string
+		wantUpgrade   []string
+		wantReinstall []string
+		wantSkipped   []string
+	}{
+		{
+			name:        "default",
+			opts:        LoadOptions{BlockedExtensions: []string{"evil.*"}},
+			wantInstall: []string{"esbenp.prettier-vscode"},
+			wantSkipped: []string{"golang.go", "ms-python.python"},
+		},
+		{
+			name:          "force reinstall",
+			opts:          LoadOptions{ForceReinstall: true, BlockedExtensions: []string{"evil.*"}},
+			wantInstall:   []string{"esbenp.prettier-vscode"},
+			wantUpgrade:   []string{"ms-python.python"},
+			wantReinstall: []string{"golang.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanLoad("work", dir, tt.opts)
+			if err != nil {
+				t.Fatalf("PlanLoad failed: %v", err)
+			}
+
+			for _, c := range []struct {
+				bucket    string
+				got, want []string
+			}{
+				{"Install", extensionIDs(plan.Install), tt.wantInstall},
+				{"Upgrade", extensionIDs(plan.Upgrade), tt.wantUpgrade},
+				{"Reinstall", extensionIDs(plan.Reinstall), tt.wantReinstall},
+				{"Skipped", extensionIDs(plan.Skipped), tt.wantSkipped},
+				{"Blocked", extensionIDs(plan.Blocked), []string{"evil.miner"}},
+			} {
+				if len(c.got) != 0 || len(c.want) != 0 {
+					if !reflect.DeepEqual(c.got, c.want) {
+						t.Errorf("%s = %v, want %v", c.bucket, c.got, c.want)
+					}
+				}
+			}
+			if !reflect.DeepEqual(plan.UserFiles, []string{vscode.SettingsFile}) {
+				t.Errorf("UserFiles = %v, want [%s]", plan.UserFiles, vscode.SettingsFile)
+			}
+			if plan.Empty() {
+				t.Error("expected a plan with changes")
+			}
+		})
+	}
+
+	if len(*installed) != 0 {
+		t.Errorf("expected planning to install nothing, got %v", *installed)
+	}
+}
+
+func TestPlanLoad_Empty(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{
+		Name:       "synced",
+		Extensions: []Extension{{ID: "golang.go", Version: "0.40.0", Enabled: true}},
+	})
+	stubVSCode(t, []vscode.Extension{{ID: "golang.go", Version: "0.40.0"}})
+
+	plan, err := PlanLoad("synced", dir, LoadOptions{})
+	if err != nil {
+		t.Fatalf("PlanLoad failed: %v", err)
+	}
+	if !plan.Empty() {
+		t.Errorf("expected an empty plan, got %+v", plan)
+	}
+}
+
+// stubSleep records the waits between install retries instead of sleeping
+func stubSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	var mu sync.Mutex
+	origSleep := sleep
+	sleep = func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, d)
+	}
+	t.Cleanup(func() { sleep = origSleep })
+	return &waits
+}
+
+func TestInstallAll_RetriesTransientFailure(t *testing.T) {
+	waits := stubSleep(t)
+	attempts := make(map[string]int)
+	var mu sync.Mutex
+	origInstall := installExtensions
+	installExtensions = perExtension(func(extensionID string, force bool) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[extensionID]++
+		if extensionID == "flaky.ext" && attempts[extensionID] < 3 {
+			return fmt.Errorf("%w %s: getaddrinfo ETIMEDOUT marketplace.visualstudio.com", vscode.ErrExtensionInstallFailed, extensionID)
+		}
+		return nil
+	})
+	t.Cleanup(func() { installExtensions = origInstall })
+
+	errs := installAll([]Extension{{ID: "golang.go"}, {ID: "flaky.ext"}}, false, 1)
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("install %d failed: %v", i, err)
+		}
+	}
+	if attempts["flaky.ext"] != 3 || attempts["golang.go"] != 1 {
+		t.Errorf("attempts = %v, want flaky.ext installed 3 times and golang.go once", attempts)
+	}
+	want := []time.Duration{installRetryDelay, 2 * installRetryDelay}
+	if !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+}
+
+func TestInstallAll_RetriesAreBounded(t *testing.T) {
+	stubSleep(t)
+	attempts := 0
+	origInstall := installExtensions
+	installExtensions = perExtension(func(extensionID string, force bool) error {
+		attempts++
+		return fmt.Errorf("%w %s: socket hang up", vscode.ErrExtensionInstallFailed, extensionID)
+	})
+	t.Cleanup(func() { installExtensions = origInstall })
+
+	errs := installAll([]Extension{{ID: "flaky.ext"}}, false, 1)
+
+	if !errors.Is(errs[0], vscode.ErrExtensionInstallFailed) {
+		t.Errorf("expected the last install error, got %v", errs[0])
+	}
+	if attempts != installAttempts {
+		t.Errorf("installed %d times, want %d", attempts, installAttempts)
+	}
+}
+
+func TestInstallAll_NotFoundFailsFast(t *testing.T) {
+	waits := stubSleep(t)
+	attempts := 0
+	origInstall := installExtensions
+	installExtensions = perExtension(func(extensionID string, force bool) error {
+		attempts++
+		return fmt.Errorf("%w %s: Extension '%s' not found.", vscode.ErrExtensionInstallFailed, extensionID, extensionID)
+	})
+	t.Cleanup(func() { installExtensions = origInstall })
+
+	errs := installAll([]Extension{{ID: "missing.ext"}}, false, 1)
+
+	if errs[0] == nil {
+		t.Fatal("expected the install to fail")
+	}
+	if attempts != 1 || len(*waits) != 0 {
+		t.Errorf("expected a single attempt without waiting, got %d attempts and waits %v", attempts, *waits)
+	}
+}
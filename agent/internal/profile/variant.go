@@ -0,0 +1,89 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// VariantSeparator joins a base profile name and a variant, as in "work@laptop"
+const VariantSeparator = "@"
+
+// ParseName splits a profile name into its base name and variant. Names
+// without a separator have an empty variant.
+func ParseName(name string) (base, variant string) {
+	base, variant, _ = strings.Cut(name, VariantSeparator)
+	return base, variant
+}
+
+// VariantName joins a base name and variant; an empty variant returns base
+func VariantName(base, variant string) string {
+	if variant == "" {
+		return base
+	}
+	return base + VariantSeparator + variant
+}
+
+// ValidateVariant checks that a variant can be appended to a profile name
+func ValidateVariant(variant string) error {
+	if variant == "" {
+		return fmt.Errorf("profile variant cannot be empty")
+	}
+	if strings.Contains(variant, VariantSeparator) {
+		return fmt.Errorf("profile variant cannot contain '%s'", VariantSeparator)
+	}
+	if err := ValidateName(variant); err != nil {
+		return fmt.Errorf("invalid profile variant: %w", err)
+	}
+	return nil
+}
+
+// ProfileGroup is a base profile name and every profile sharing it
+type ProfileGroup struct {
+	Base     string
+	Profiles []Profile
+}
+
+// GroupByBase groups profiles by base name. Groups are sorted by base name
+// and, within a group, the plain profile comes first followed by variants
+// in name order.
+func GroupByBase(profiles []Profile) []ProfileGroup {
+	byBase := make(map[string][]Profile)
+	for _, p := range profiles {
+		base, _ := ParseName(p.Name)
+		byBase[base] = append(byBase[base], p)
+	}
+
+	groups := make([]ProfileGroup, 0, len(byBase))
+	for base, members := range byBase {
+		sort.SliceStable(members, func(i, j int) bool {
+			_, vi := ParseName(members[i].Name)
+			_, vj := ParseName(members[j].Name)
+			return vi < vj
+		})
+		groups = append(groups, ProfileGroup{Base: base, Profiles: members})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Base < groups[j].Base })
+
+	return groups
+}
+
+// ResolveVariant returns the profile name to load for name. When name has no
+// variant and a profile named name@variant exists, that variant is preferred;
+// otherwise name is returned unchanged.
+func ResolveVariant(name, variant, profilesDir string) string {
+	if variant == "" {
+		return name
+	}
+	if _, v := ParseName(name); v != "" {
+		return name
+	}
+
+	candidate := VariantName(name, variant)
+	if _, err := os.Stat(filepath.Join(profilesDir, candidate+".json")); err == nil {
+		return candidate
+	}
+	return name
+}
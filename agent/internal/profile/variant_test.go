@@ -0,0 +1,92 @@
+package profile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name        string
+		wantBase    string
+		wantVariant string
+	}{
+		{"work", "work", ""},
+		{"work@laptop", "work", "laptop"},
+		{"work@", "work", ""},
+		{"work@laptop@extra", "work", "laptop@extra"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, variant := ParseName(tt.name)
+			if base != tt.wantBase || variant != tt.wantVariant {
+				t.Errorf("ParseName(%q) = (%q, %q), want (%q, %q)", tt.name, base, variant, tt.wantBase, tt.wantVariant)
+			}
+			if tt.wantVariant != "" && VariantName(base, variant) != tt.name {
+				t.Errorf("VariantName(%q, %q) = %q, want %q", base, variant, VariantName(base, variant), tt.name)
+			}
+		})
+	}
+}
+
+func TestValidateVariant(t *testing.T) {
+	if err := ValidateVariant("laptop"); err != nil {
+		t.Errorf("expected 'laptop' to be valid, got: %v", err)
+	}
+	for _, v := range []string{"", "a@b", "a/b"} {
+		if err := ValidateVariant(v); err == nil {
+			t.Errorf("expected variant %q to be rejected", v)
+		}
+	}
+}
+
+func TestGroupByBase(t *testing.T) {
+	profiles := []Profile{
+		{Name: "work@laptop"},
+		{Name: "personal"},
+		{Name: "work"},
+		{Name: "work@desktop"},
+	}
+
+	groups := GroupByBase(profiles)
+
+	var got [][]string
+	for _, g := range groups {
+		names := []string{g.Base}
+		for _, p := range g.Profiles {
+			names = append(names, p.Name)
+		}
+		got = append(got, names)
+	}
+	want := [][]string{
+		{"personal", "personal"},
+		{"work", "work", "work@desktop", "work@laptop"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByBase = %v, want %v", got, want)
+	}
+}
+
+func TestResolveVariant(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{Name: "work"})
+	writeTestProfile(t, dir, Profile{Name: "work@laptop"})
+
+	tests := []struct {
+		name    string
+		variant string
+		want    string
+	}{
+		{"work", "laptop", "work@laptop"},
+		{"work", "desktop", "work"},
+		{"work", "", "work"},
+		{"work@laptop", "desktop", "work@laptop"},
+	}
+
+	for _, tt := range tests {
+		if got := ResolveVariant(tt.name, tt.variant, dir); got != tt.want {
+			t.Errorf("ResolveVariant(%q, %q) = %q, want %q", tt.name, tt.variant, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package profile
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
+)
+
+// ProfileCheck is the outcome of verifying one local profile file
+type ProfileCheck struct {
+	// Name is the profile name, or the file name for unparseable files
+	Name string
+
+	// Err is set when the file cannot be parsed or fails Validate
+	Err error
+
+	// Drift compares the profile with installed extensions; only set by
+	// VerifyAll with againstInstalled, for profiles that passed validation
+	Drift *DiffResult
+}
+
+// Healthy reports whether the profile parsed, validated and, if checked,
+// matches the installed extensions
+func (c ProfileCheck) Healthy() bool {
+	return c.Err == nil && (c.Drift == nil || c.Drift.InSync())
+}
+
+// VerifyAll checks every profile file in profilesDir, sorted by name. Files
+// that cannot be read or parsed and profiles failing Validate are reported
+// with Err. With againstInstalled, valid profiles are also compared with the
+// installed extensions, which are listed once for all profiles.
+func VerifyAll(profilesDir string, againstInstalled bool) ([]ProfileCheck, error) {
+	profiles, skipped, err := ListWithSkipped(profilesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var installedExts []vscode.Extension
+	if againstInstalled && len(profiles) > 0 {
+		installedExts, err = listInstalledExtensions()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed extensions: %w", err)
+		}
+	}
+
+	checks := make([]ProfileCheck, 0, len(profiles)+len(skipped))
+	for _, s := range skipped {
+		checks = append(checks, ProfileCheck{
+			Name: filepath.Base(s.Path),
+			Err:  fmt.Errorf("failed to parse profile file: %w", s.Err),
+		})
+	}
+
+	for i := range profiles {
+		prof := &profiles[i]
+		check := ProfileCheck{Name: prof.Name}
+		if err := Validate(prof); err != nil {
+			check.Err = fmt.Errorf("invalid profile: %w", err)
+		} else if againstInstalled {
+			check.Drift = diffInstalled(prof, installedExts, DiffOptions{})
+		}
+		checks = append(checks, check)
+	}
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	return checks, nil
+}
@@ -0,0 +1,82 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark-chris/devtools-sync/agent/internal/vscode"
+)
+
+func TestVerifyAll(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{
+		Name:       "healthy",
+		Extensions: []Extension{{ID: "golang.go", Version: "2.0.0"}},
+	})
+	writeTestProfile(t, dir, Profile{
+		Name:       "drifted",
+		Extensions: []Extension{{ID: "golang.go", Version: "1.0.0"}, {ID: "ms-python.python", Version: "1.0.0"}},
+	})
+	writeTestProfile(t, dir, Profile{
+		Name:       "invalid",
+		Extensions: []Extension{{ID: "not-an-id", Version: "1.0.0"}},
+	})
+	if err := os.WriteFile(filepath.Join(dir, "corrupt.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatalf("failed to write corrupt profile: %v", err)
+	}
+	stubVSCode(t, []vscode.Extension{{ID: "golang.go", Version: "2.0.0"}})
+
+	checks, err := VerifyAll(dir, true)
+	if err != nil {
+		t.Fatalf("VerifyAll failed: %v", err)
+	}
+
+	byName := make(map[string]ProfileCheck)
+	for _, c := range checks {
+		byName[c.Name] = c
+	}
+	if len(checks) != 4 {
+		t.Fatalf("expected 4 checks, got %d: %+v", len(checks), checks)
+	}
+
+	if c := byName["healthy"]; !c.Healthy() {
+		t.Errorf("expected 'healthy' to be healthy, got %+v", c)
+	}
+	if c := byName["corrupt.json"]; c.Err == nil || c.Healthy() {
+		t.Errorf("expected corrupt file to fail, got %+v", c)
+	}
+	if c := byName["invalid"]; c.Err == nil || c.Drift != nil {
+		t.Errorf("expected invalid profile to fail validation without a drift check, got %+v", c)
+	}
+	c := byName["drifted"]
+	if c.Err != nil || c.Healthy() || c.Drift == nil {
+		t.Fatalf("expected 'drifted' to report drift, got %+v", c)
+	}
+	if len(c.Drift.ToInstall) != 1 || len(c.Drift.VersionMismatches) != 1 {
+		t.Errorf("drift = %+v, want 1 to install and 1 version mismatch", c.Drift)
+	}
+}
+
+func TestVerifyAll_WithoutInstalledCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProfile(t, dir, Profile{
+		Name:       "work",
+		Extensions: []Extension{{ID: "golang.go", Version: "1.0.0"}},
+	})
+
+	origList := listInstalledExtensions
+	listInstalledExtensions = func() ([]vscode.Extension, error) {
+		t.Error("installed extensions should not be listed without againstInstalled")
+		return nil, nil
+	}
+	t.Cleanup(func() { listInstalledExtensions = origList })
+
+	checks, err := VerifyAll(dir, false)
+	if err != nil {
+		t.Fatalf("VerifyAll failed: %v", err)
+	}
+	if len(checks) != 1 || !checks[0].Healthy() || checks[0].Drift != nil {
+		t.Errorf("expected one healthy check without drift, got %+v", checks)
+	}
+}
@@ -0,0 +1,153 @@
+package vscode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultMarketplaceURL is the base URL of the Visual Studio Marketplace gallery API
+const DefaultMarketplaceURL = "https://marketplace.visualstudio.com/_apis/public/gallery"
+
+// DefaultMarketplaceTimeout bounds each marketplace lookup
+const DefaultMarketplaceTimeout = 10 * time.Second
+
+// ErrMarketplaceUnavailable is returned when the marketplace cannot be reached
+// or returns an unexpected response, so callers can fall back to offline checks
+var ErrMarketplaceUnavailable = errors.New("extension marketplace unavailable")
+
+// maxMarketplaceResponseSize caps how much of a gallery response is read
+const maxMarketplaceResponseSize = 1 << 20
+
+// extensionQueryFilterName is the gallery filter type matching a full
+// "publisher.name" extension ID
+const extensionQueryFilterName = 7
+
+// MarketplaceClient looks up extensions in a VS Code extension gallery
+type MarketplaceClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewMarketplaceClient creates a client for the gallery API at baseURL
+func NewMarketplaceClient(baseURL string) *MarketplaceClient {
+	return &MarketplaceClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: DefaultMarketplaceTimeout},
+	}
+}
+
+type extensionQueryCriterion struct {
+	FilterType int    `json:"filterType"`
+	Value      string `json:"value"`
+}
+
+type extensionQueryFilter struct {
+	Criteria   []extensionQueryCriterion `json:"criteria"`
+	PageNumber int                       `json:"pageNumber"`
+	PageSize   int                       `json:"pageSize"`
+}
+
+type extensionQuery struct {
+	Filters []extensionQueryFilter `json:"filters"`
+	Flags   int                    `json:"flags"`
+}
+
+// extensionQueryFlagIncludeVersions asks the gallery to return every
+// published version of the matched extensions
+const extensionQueryFlagIncludeVersions = 0x1
+
+type extensionQueryResponse struct {
+	Results []struct {
+		Extensions []galleryExtension `json:"extensions"`
+	} `json:"results"`
+}
+
+type galleryExtension struct {
+	ExtensionName string `json:"extensionName"`
+	Publisher     struct {
+		PublisherName string `json:"publisherName"`
+	} `json:"publisher"`
+	Versions []struct {
+		Version string `json:"version"`
+	} `json:"versions"`
+}
+
+// ExtensionExists reports whether extensionID is published in the gallery.
+// Network failures and unexpected responses wrap ErrMarketplaceUnavailable.
+func (c *MarketplaceClient) ExtensionExists(extensionID string) (bool, error) {
+	ext, err := c.findExtension(extensionID, 0)
+	if err != nil {
+		return false, err
+	}
+	return ext != nil, nil
+}
+
+// ExtensionVersions returns the versions of extensionID published in the
+// gallery, newest first as listed by the gallery. An unknown extension has
+// no versions. Network failures and unexpected responses wrap
+// ErrMarketplaceUnavailable.
+func (c *MarketplaceClient) ExtensionVersions(extensionID string) ([]string, error) {
+	ext, err := c.findExtension(extensionID, extensionQueryFlagIncludeVersions)
+	if err != nil || ext == nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(ext.Versions))
+	for _, v := range ext.Versions {
+		versions = append(versions, v.Version)
+	}
+	return versions, nil
+}
+
+// findExtension queries the gallery for extensionID and returns the matching
+// extension, or nil if it is not published
+func (c *MarketplaceClient) findExtension(extensionID string, flags int) (*galleryExtension, error) {
+	body, err := json.Marshal(extensionQuery{
+		Filters: []extensionQueryFilter{{
+			Criteria:   []extensionQueryCriterion{{FilterType: extensionQueryFilterName, Value: extensionID}},
+			PageNumber: 1,
+			PageSize:   1,
+		}},
+		Flags: flags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode marketplace query: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/extensionquery", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create marketplace request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json;api-version=3.0-preview.1")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMarketplaceUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrMarketplaceUnavailable, resp.StatusCode)
+	}
+
+	var result extensionQueryResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxMarketplaceResponseSize)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse response: %v", ErrMarketplaceUnavailable, err)
+	}
+
+	for _, r := range result.Results {
+		for i, ext := range r.Extensions {
+			if strings.EqualFold(ext.Publisher.PublisherName+"."+ext.ExtensionName, extensionID) {
+				return &r.Extensions[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
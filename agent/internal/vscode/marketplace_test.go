@@ -0,0 +1,79 @@
+package vscode
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMarketplaceClient_ExtensionExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/extensionquery" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"extensions":[{"extensionName":"Python","publisher":{"publisherName":"ms-python"}}]}]}`))
+	}))
+	defer server.Close()
+
+	client := NewMarketplaceClient(server.URL + "/")
+
+	exists, err := client.ExtensionExists("ms-python.python")
+	if err != nil {
+		t.Fatalf("ExtensionExists failed: %v", err)
+	}
+	if !exists {
+		t.Error("expected ms-python.python to exist (case-insensitive match)")
+	}
+
+	exists, err = client.ExtensionExists("ms-python.other")
+	if err != nil {
+		t.Fatalf("ExtensionExists failed: %v", err)
+	}
+	if exists {
+		t.Error("expected a non-matching result to be reported as missing")
+	}
+}
+
+func TestMarketplaceClient_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewMarketplaceClient(server.URL).ExtensionExists("golang.go")
+	if !errors.Is(err, ErrMarketplaceUnavailable) {
+		t.Errorf("expected ErrMarketplaceUnavailable, got: %v", err)
+	}
+
+	server.Close()
+	_, err = NewMarketplaceClient(server.URL).ExtensionExists("golang.go")
+	if !errors.Is(err, ErrMarketplaceUnavailable) {
+		t.Errorf("expected ErrMarketplaceUnavailable for unreachable server, got: %v", err)
+	}
+}
+
+func TestMarketplaceClient_ExtensionVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"extensions":[{"extensionName":"python","publisher":{"publisherName":"ms-python"},` +
+			`"versions":[{"version":"2024.2.1"},{"version":"2024.2.0"},{"version":"2023.22.1"}]}]}]}`))
+	}))
+	defer server.Close()
+
+	client := NewMarketplaceClient(server.URL)
+
+	versions, err := client.ExtensionVersions("ms-python.python")
+	if err != nil {
+		t.Fatalf("ExtensionVersions failed: %v", err)
+	}
+	if len(versions) != 3 || versions[0] != "2024.2.1" || versions[2] != "2023.22.1" {
+		t.Errorf("ExtensionVersions = %v, want the three published versions", versions)
+	}
+
+	versions, err = client.ExtensionVersions("ms-python.other")
+	if err != nil || len(versions) != 0 {
+		t.Errorf("expected no versions for an unknown extension, got %v, %v", versions, err)
+	}
+}
@@ -0,0 +1,76 @@
+package vscode
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// User configuration files applied from profiles
+const (
+	SettingsFile    = "settings.json"
+	KeybindingsFile = "keybindings.json"
+)
+
+// backupTimeFormat is appended to the names of backed-up user files
+const backupTimeFormat = "20060102-150405"
+
+// Variable to allow overriding in tests
+var getUserDir = getUserDirImpl
+
+// UserDir returns the VS Code user directory holding settings.json and
+// keybindings.json
+func UserDir() string {
+	return getUserDir()
+}
+
+// getUserDirImpl returns the VS Code user directory for the current platform
+func getUserDirImpl() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE") // Windows
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Code", "User")
+	case "windows":
+		appdata := os.Getenv("APPDATA")
+		if appdata == "" {
+			appdata = home // Fallback to home if APPDATA not set
+		}
+		return filepath.Join(appdata, "Code", "User")
+	default:
+		return filepath.Join(home, ".config", "Code", "User")
+	}
+}
+
+// WriteUserFile replaces name (e.g. SettingsFile) in the VS Code user
+// directory with data. An existing file is first copied to a timestamped
+// backup next to it, whose path is returned; backup is "" when there was
+// nothing to back up.
+func WriteUserFile(name string, data []byte) (backup string, err error) {
+	dir := getUserDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create VS Code user directory: %w", err)
+	}
+
+	path := filepath.Join(dir, name)
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		backup = fmt.Sprintf("%s.%s.bak", path, time.Now().Format(backupTimeFormat))
+		if err := os.WriteFile(backup, existing, 0644); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", name, err)
+		}
+	case !os.IsNotExist(err):
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return backup, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,61 @@
+package vscode
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubUserDir points the VS Code user directory at a temp dir
+func stubUserDir(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "User")
+	original := getUserDir
+	getUserDir = func() string { return dir }
+	t.Cleanup(func() { getUserDir = original })
+	return dir
+}
+
+func TestWriteUserFile_NewFile(t *testing.T) {
+	dir := stubUserDir(t)
+
+	backup, err := WriteUserFile(SettingsFile, []byte(`{"editor.tabSize": 2}`))
+	if err != nil {
+		t.Fatalf("WriteUserFile failed: %v", err)
+	}
+	if backup != "" {
+		t.Errorf("expected no backup for a new file, got %s", backup)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, SettingsFile))
+	if err != nil || string(data) != `{"editor.tabSize": 2}` {
+		t.Errorf("settings.json = %q, %v; want the written contents", data, err)
+	}
+}
+
+func TestWriteUserFile_BacksUpExisting(t *testing.T) {
+	dir := stubUserDir(t)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create user dir: %v", err)
+	}
+	path := filepath.Join(dir, KeybindingsFile)
+	if err := os.WriteFile(path, []byte(`[{"key": "ctrl+k"}]`), 0644); err != nil {
+		t.Fatalf("failed to write keybindings: %v", err)
+	}
+
+	backup, err := WriteUserFile(KeybindingsFile, []byte(`[]`))
+	if err != nil {
+		t.Fatalf("WriteUserFile failed: %v", err)
+	}
+
+	if !strings.HasPrefix(backup, path+".") || !strings.HasSuffix(backup, ".bak") {
+		t.Errorf("backup path = %s, want %s.<timestamp>.bak", backup, path)
+	}
+	if data, _ := os.ReadFile(backup); string(data) != `[{"key": "ctrl+k"}]` {
+		t.Errorf("backup contents = %q, want the previous file", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `[]` {
+		t.Errorf("keybindings.json = %q, want the new contents", data)
+	}
+}
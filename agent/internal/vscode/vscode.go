@@ -15,6 +15,15 @@ import (
 	"golang.org/x/mod/semver"
 )
 
+// ErrVSCodeNotFound is returned when the VS Code CLI ('code') cannot be found
+var ErrVSCodeNotFound = errors.New("VS Code CLI 'code' not found")
+
+// ErrExtensionInstallFailed is returned when the VS Code CLI fails to install an extension
+var ErrExtensionInstallFailed = errors.New("failed to install extension")
+
+// Variable to allow overriding command execution in tests
+var execCommand = exec.Command
+
 // Extension represents a VS Code extension
 type Extension struct {
 	ID          string
@@ -56,20 +65,56 @@ func ListExtensions() ([]Extension, error) {
 		return extensions, nil
 	}
 
+	// Without the CLI or any extension directory there is nothing to fall back to
+	if errors.Is(err, ErrVSCodeNotFound) && !anyDirExists(getExtensionDirs()) {
+		return nil, err
+	}
+
 	// CLI failed, log and fall back to directory parsing with state detection
 	log.Printf("CLI method failed (%v), falling back to directory parsing", err)
 	return listExtensionsFromDirsWithState(getExtensionDirs(), getStatePaths())
 }
 
+// ListExtensionsFromDirs lists extensions installed in the given extension
+// directories, keeping the highest version of extensions found in more than one
+func ListExtensionsFromDirs(dirs []string) ([]Extension, error) {
+	return listExtensionsFromDirsWithState(dirs, getStatePaths())
+}
+
+// DefaultExtensionDirs returns the auto-detected extension directories for
+// VS Code and VS Code Insiders
+func DefaultExtensionDirs() []string {
+	return getExtensionDirs()
+}
+
+// anyDirExists reports whether at least one of dirs exists
+func anyDirExists(dirs []string) bool {
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// runError converts a failure to run the VS Code CLI into ErrVSCodeNotFound
+// when the binary is missing
+func runError(err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%w: %v", ErrVSCodeNotFound, err)
+	}
+	return err
+}
+
 // listExtensionsViaCLI lists extensions using the VS Code CLI
 func listExtensionsViaCLI() ([]Extension, error) {
-	cmd := exec.Command("code", "--list-extensions", "--show-versions")
+	cmd := execCommand("code", "--list-extensions", "--show-versions")
 	output, err := cmd.Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return nil, fmt.Errorf("VS Code CLI error: %w (stderr: %s)", err, string(exitErr.Stderr))
 		}
-		return nil, fmt.Errorf("failed to execute VS Code CLI: %w", err)
+		return nil, fmt.Errorf("failed to execute VS Code CLI: %w", runError(err))
 	}
 
 	// Parse output
@@ -101,9 +146,55 @@ func listExtensionsViaCLI() ([]Extension, error) {
 		})
 	}
 
+	// The CLI only reports IDs and versions
+	addManifestMetadata(extensions, getExtensionDirs())
+
 	return extensions, nil
 }
 
+// addManifestMetadata fills in DisplayName, Description and Publisher of
+// extensions from their package.json in dirs, preferring the manifest of the
+// same version. Extensions without a readable manifest are left unchanged.
+func addManifestMetadata(extensions []Extension, dirs []string) {
+	byVersion := make(map[string]Extension)
+	byID := make(map[string]Extension)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, entry.Name(), "package.json"))
+			if err != nil {
+				continue
+			}
+			manifest, err := parseManifest(data, entry.Name())
+			if err != nil {
+				continue
+			}
+			id := strings.ToLower(manifest.ID)
+			byVersion[id+"@"+manifest.Version] = manifest
+			byID[id] = manifest
+		}
+	}
+
+	for i, ext := range extensions {
+		id := strings.ToLower(ext.ID)
+		manifest, ok := byVersion[id+"@"+ext.Version]
+		if !ok {
+			if manifest, ok = byID[id]; !ok {
+				continue
+			}
+		}
+		extensions[i].DisplayName = manifest.DisplayName
+		extensions[i].Description = manifest.Description
+		extensions[i].Publisher = manifest.Publisher
+	}
+}
+
 // InstallExtension installs a VS Code extension by ID
 func InstallExtension(extensionID string) error {
 	if extensionID == "" {
@@ -111,15 +202,173 @@ func InstallExtension(extensionID string) error {
 	}
 
 	// Execute code --install-extension <id>
-	cmd := exec.Command("code", "--install-extension", extensionID)
+	return runInstall(execCommand("code", "--install-extension", extensionID), extensionID)
+}
+
+// ForceInstallExtension installs a VS Code extension by ID, reinstalling it
+// even if it is already present
+func ForceInstallExtension(extensionID string) error {
+	if extensionID == "" {
+		return errors.New("extension ID cannot be empty")
+	}
+
+	// Execute code --install-extension <id> --force
+	return runInstall(execCommand("code", "--install-extension", extensionID, "--force"), extensionID)
+}
+
+// InstallExtensions installs several extensions with a single VS Code CLI
+// invocation, passing one --install-extension flag per ID (IDs may carry an
+// @version suffix). The returned slice holds the error for each ID, in order
+// (nil on success). Extensions whose outcome the CLI does not report, as
+// happens with older CLIs that honor only one flag, are installed again one
+// at a time.
+func InstallExtensions(extensionIDs []string) []error {
+	return installBatch(extensionIDs, false)
+}
+
+// ForceInstallExtensions is InstallExtensions with --force, reinstalling
+// extensions that are already present
+func ForceInstallExtensions(extensionIDs []string) []error {
+	return installBatch(extensionIDs, true)
+}
+
+// installArgs builds the CLI arguments installing every ID in one invocation
+func installArgs(extensionIDs []string, force bool) []string {
+	args := make([]string, 0, 2*len(extensionIDs)+1)
+	for _, id := range extensionIDs {
+		args = append(args, "--install-extension", id)
+	}
+	if force {
+		args = append(args, "--force")
+	}
+	return args
+}
+
+func installBatch(extensionIDs []string, force bool) []error {
+	errs := make([]error, len(extensionIDs))
+	install := InstallExtension
+	if force {
+		install = ForceInstallExtension
+	}
+
+	for i, id := range extensionIDs {
+		if id == "" {
+			errs[i] = errors.New("extension ID cannot be empty")
+		}
+	}
+	if len(extensionIDs) <= 1 {
+		for i, id := range extensionIDs {
+			if errs[i] == nil {
+				errs[i] = install(id)
+			}
+		}
+		return errs
+	}
+
+	output, err := execCommand("code", installArgs(extensionIDs, force)...).CombinedOutput()
+	if err := runError(err); errors.Is(err, ErrVSCodeNotFound) {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	outcomes := parseInstallOutput(extensionIDs, string(output), force)
+	for i, id := range extensionIDs {
+		outcome, reported := outcomes[i]
+		switch {
+		case errs[i] != nil:
+		case !reported:
+			// Not mentioned in the output; retry on its own
+			errs[i] = install(id)
+		case outcome != "":
+			errs[i] = fmt.Errorf("%w %s: %s", ErrExtensionInstallFailed, id, outcome)
+		}
+	}
+	return errs
+}
+
+// parseInstallOutput reads the per-extension results of a batched install
+// from the CLI output. The result maps the index of each ID the output
+// reports on to "" on success or the line describing the failure; IDs are
+// matched case-insensitively and without their @version suffix. "is already
+// installed" only counts as success without force: with force, VS Code was
+// asked to replace the installed version and reporting it unchanged is a failure.
+func parseInstallOutput(extensionIDs []string, output string, force bool) map[int]string {
+	index := make(map[string]int, len(extensionIDs))
+	for i, id := range extensionIDs {
+		index[bareExtensionID(id)] = i
+	}
+
+	outcomes := make(map[int]string)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		// "Failed Installing Extensions: a.b, c.d" lists every failure
+		if rest, ok := strings.CutPrefix(line, "Failed Installing Extensions:"); ok {
+			for _, id := range strings.Split(rest, ",") {
+				if i, ok := index[bareExtensionID(id)]; ok && outcomes[i] == "" {
+					outcomes[i] = line
+				}
+			}
+			continue
+		}
+
+		// "Extension 'a.b' v1.0.0 was successfully installed." and similar
+		rest, ok := strings.CutPrefix(line, "Extension '")
+		if !ok {
+			continue
+		}
+		id, status, ok := strings.Cut(rest, "'")
+		if !ok {
+			continue
+		}
+		i, ok := index[bareExtensionID(id)]
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.Contains(status, "successfully installed"), !force && strings.Contains(status, "is already installed"):
+			outcomes[i] = ""
+		case strings.Contains(status, "not found"), strings.Contains(strings.ToLower(status), "failed"), strings.Contains(status, "is already installed"):
+			outcomes[i] = line
+		}
+	}
+	return outcomes
+}
+
+// bareExtensionID returns the lower-cased ID without an @version suffix
+func bareExtensionID(spec string) string {
+	id, _, _ := strings.Cut(strings.TrimSpace(spec), "@")
+	return strings.ToLower(id)
+}
+
+// runInstall runs an install command and classifies its failure as
+// ErrVSCodeNotFound or ErrExtensionInstallFailed
+func runInstall(cmd *exec.Cmd, extensionID string) error {
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("failed to install extension %s: %w (output: %s)", extensionID, err, string(output))
+		if err := runError(err); errors.Is(err, ErrVSCodeNotFound) {
+			return err
+		}
+		return fmt.Errorf("%w %s: %w (output: %s)", ErrExtensionInstallFailed, extensionID, err, string(output))
 	}
 
 	return nil
 }
 
+// IsTransientInstallError reports whether an install error may go away on a
+// retry: the CLI ran but the install failed for a reason other than the
+// extension not existing or not being compatible, such as a marketplace or
+// network error
+func IsTransientInstallError(err error) bool {
+	if !errors.Is(err, ErrExtensionInstallFailed) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return !strings.Contains(msg, "not found") && !strings.Contains(msg, "not compatible")
+}
+
 // getVSCodePaths returns common VS Code installation paths by platform
 func getVSCodePaths() []string {
 	switch runtime.GOOS {
@@ -221,6 +470,58 @@ func compareVersions(v1, v2 string) int {
 	return semver.Compare(v1, v2)
 }
 
+// CompareVersions compares two semantic version strings, returning -1, 0 or 1
+// like compareVersions. Unless includePreRelease is set, pre-release versions
+// (e.g. 1.1.0-beta) order before every stable version, so a pre-release is
+// never considered newer than a stable release.
+func CompareVersions(v1, v2 string, includePreRelease bool) int {
+	if !includePreRelease {
+		pre1, pre2 := IsPreRelease(v1), IsPreRelease(v2)
+		if pre1 && !pre2 {
+			return -1
+		}
+		if pre2 && !pre1 {
+			return 1
+		}
+	}
+
+	return compareVersions(v1, v2)
+}
+
+// NormalizeExtensions returns exts deduplicated by ID, keeping the highest
+// version of each, and sorted by ID, so saved and uploaded extension lists
+// are deterministic. IDs are compared case-insensitively, as VS Code and the
+// server do. id and version read an element's extension ID and version, so
+// the profile and API extension types share this implementation.
+func NormalizeExtensions[E any](exts []E, id, version func(E) string) []E {
+	byID := make(map[string]E, len(exts))
+	for _, ext := range exts {
+		key := strings.ToLower(id(ext))
+		if existing, found := byID[key]; found && CompareVersions(version(ext), version(existing), true) <= 0 {
+			continue
+		}
+		byID[key] = ext
+	}
+
+	result := make([]E, 0, len(byID))
+	for _, ext := range byID {
+		result = append(result, ext)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(id(result[i])) < strings.ToLower(id(result[j]))
+	})
+
+	return result
+}
+
+// IsPreRelease reports whether a semantic version has a pre-release suffix
+func IsPreRelease(version string) bool {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return semver.Prerelease(version) != ""
+}
+
 // scanExtensionDir scans a directory for installed extensions
 func scanExtensionDir(dir string) ([]Extension, error) {
 	// Check if directory exists
@@ -455,3 +756,38 @@ func listExtensionsFromDirsWithState(extensionDirs []string, statePaths []string
 	// Apply enabled state
 	return applyEnabledState(extensions, allDisabled), nil
 }
+
+// ValidateExtensionPattern checks that pattern is an extension ID
+// (publisher.name) or a publisher wildcard (publisher.*)
+func ValidateExtensionPattern(pattern string) error {
+	parts := strings.Split(pattern, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" ||
+		strings.ContainsAny(pattern, " ") || strings.Contains(parts[0], "*") ||
+		(strings.Contains(parts[1], "*") && parts[1] != "*") {
+		return fmt.Errorf("extension pattern '%s' must be in format 'publisher.name' or 'publisher.*'", pattern)
+	}
+	return nil
+}
+
+// MatchExtensionPattern reports whether extensionID matches pattern, an
+// extension ID or a publisher wildcard (publisher.*). Matching is
+// case-insensitive, like VS Code extension IDs.
+func MatchExtensionPattern(pattern, extensionID string) bool {
+	pattern = strings.ToLower(pattern)
+	extensionID = strings.ToLower(extensionID)
+	if publisher, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return strings.HasPrefix(extensionID, publisher+".")
+	}
+	return pattern == extensionID
+}
+
+// MatchAnyExtensionPattern returns the first of patterns matching
+// extensionID, and whether one matched
+func MatchAnyExtensionPattern(patterns []string, extensionID string) (string, bool) {
+	for _, pattern := range patterns {
+		if MatchExtensionPattern(pattern, extensionID) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
@@ -1,9 +1,12 @@
 package vscode
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -455,6 +458,29 @@ func TestCompareVersions(t *testing.T) {
 	}
 }
 
+func TestCompareVersions_PreRelease(t *testing.T) {
+	tests := []struct {
+		v1, v2         string
+		wantStable     int
+		wantPreRelease int
+	}{
+		{"1.0.0", "1.0.0-alpha", 1, 1},
+		{"1.1.0-beta", "1.0.0", -1, 1},
+		{"1.0.0", "1.1.0-beta", 1, -1},
+		{"1.1.0-beta.2", "1.1.0-beta.1", 1, 1},
+		{"1.2.0", "1.1.0", 1, 1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.v1, tt.v2, false); got != tt.wantStable {
+			t.Errorf("CompareVersions(%q, %q, stable) = %d, want %d", tt.v1, tt.v2, got, tt.wantStable)
+		}
+		if got := CompareVersions(tt.v1, tt.v2, true); got != tt.wantPreRelease {
+			t.Errorf("CompareVersions(%q, %q, pre-release) = %d, want %d", tt.v1, tt.v2, got, tt.wantPreRelease)
+		}
+	}
+}
+
 func TestMergeExtensions(t *testing.T) {
 	tests := []struct {
 		name string
@@ -1747,3 +1773,316 @@ func isVSCodeInstalled() bool {
 	_, err := exec.LookPath("code")
 	return err == nil
 }
+
+// stubExecCommand replaces the VS Code CLI with the given program for the test
+func stubExecCommand(t *testing.T, program string) {
+	t.Helper()
+	orig := execCommand
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		return exec.Command(program, args...)
+	}
+	t.Cleanup(func() { execCommand = orig })
+}
+
+func TestInstallExtension_VSCodeNotFound(t *testing.T) {
+	stubExecCommand(t, "devtools-sync-test-missing-code")
+
+	for name, install := range map[string]func(string) error{
+		"install":       InstallExtension,
+		"force-install": ForceInstallExtension,
+	} {
+		err := install("golang.go")
+		if !errors.Is(err, ErrVSCodeNotFound) {
+			t.Errorf("%s: expected ErrVSCodeNotFound, got: %v", name, err)
+		}
+		if errors.Is(err, ErrExtensionInstallFailed) {
+			t.Errorf("%s: missing binary should not be reported as an install failure", name)
+		}
+	}
+}
+
+func TestInstallExtension_InstallFailed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on the 'false' command")
+	}
+	stubExecCommand(t, "false")
+
+	err := InstallExtension("golang.go")
+	if !errors.Is(err, ErrExtensionInstallFailed) {
+		t.Errorf("expected ErrExtensionInstallFailed, got: %v", err)
+	}
+	if errors.Is(err, ErrVSCodeNotFound) {
+		t.Error("failed install should not be reported as VS Code missing")
+	}
+}
+
+func TestListExtensions_VSCodeNotFound(t *testing.T) {
+	stubExecCommand(t, "devtools-sync-test-missing-code")
+
+	origDirs := getExtensionDirs
+	missing := filepath.Join(t.TempDir(), "no-such-dir")
+	getExtensionDirs = func() []string { return []string{missing} }
+	t.Cleanup(func() { getExtensionDirs = origDirs })
+
+	_, err := ListExtensions()
+	if !errors.Is(err, ErrVSCodeNotFound) {
+		t.Errorf("expected ErrVSCodeNotFound, got: %v", err)
+	}
+}
+
+func TestListExtensions_MissingCLIFallsBackToDirs(t *testing.T) {
+	stubExecCommand(t, "devtools-sync-test-missing-code")
+
+	extDir := t.TempDir()
+	origDirs := getExtensionDirs
+	getExtensionDirs = func() []string { return []string{extDir} }
+	t.Cleanup(func() { getExtensionDirs = origDirs })
+
+	if _, err := ListExtensions(); err != nil {
+		t.Errorf("expected fallback to directory parsing, got: %v", err)
+	}
+}
+
+func TestMatchExtensionPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		id      string
+		want    bool
+	}{
+		{"ms-python.python", "ms-python.python", true},
+		{"ms-python.python", "MS-Python.Python", true},
+		{"ms-python.python", "ms-python.vscode-pylance", false},
+		{"ms-python.*", "ms-python.vscode-pylance", true},
+		{"MS-Python.*", "ms-python.python", true},
+		{"ms-python.*", "ms-pythonx.python", false},
+		{"ms-python.*", "golang.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchExtensionPattern(tt.pattern, tt.id); got != tt.want {
+			t.Errorf("MatchExtensionPattern(%q, %q) = %v, want %v", tt.pattern, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAnyExtensionPattern(t *testing.T) {
+	patterns := []string{"golang.go", "ms-python.*"}
+
+	if pattern, ok := MatchAnyExtensionPattern(patterns, "ms-python.python"); !ok || pattern != "ms-python.*" {
+		t.Errorf("expected match on 'ms-python.*', got %q (%v)", pattern, ok)
+	}
+	if _, ok := MatchAnyExtensionPattern(patterns, "esbenp.prettier-vscode"); ok {
+		t.Error("expected no match")
+	}
+}
+
+func TestValidateExtensionPattern(t *testing.T) {
+	valid := []string{"ms-python.python", "ms-python.*"}
+	for _, pattern := range valid {
+		if err := ValidateExtensionPattern(pattern); err != nil {
+			t.Errorf("ValidateExtensionPattern(%q) unexpected error: %v", pattern, err)
+		}
+	}
+
+	invalid := []string{"", "python", "*.python", "*", "ms-python.py*", "ms python.python", "a.b.c"}
+	for _, pattern := range invalid {
+		if err := ValidateExtensionPattern(pattern); err == nil {
+			t.Errorf("ValidateExtensionPattern(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestInstallArgs(t *testing.T) {
+	got := installArgs([]string{"golang.go", "ms-python.python@2024.1.0"}, false)
+	want := []string{"--install-extension", "golang.go", "--install-extension", "ms-python.python@2024.1.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("installArgs = %v, want %v", got, want)
+	}
+
+	got = installArgs([]string{"golang.go"}, true)
+	want = []string{"--install-extension", "golang.go", "--force"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("installArgs with force = %v, want %v", got, want)
+	}
+}
+
+func TestParseInstallOutput(t *testing.T) {
+	ids := []string{"golang.go", "MS-Python.Python@2024.1.0", "missing.ext", "broken.ext", "quiet.ext"}
+	output := `Installing extensions...
+Installing extension 'golang.go'...
+Extension 'golang.go' v0.40.0 was successfully installed.
+Extension 'ms-python.python' v2024.1.0 is already installed. Use '--force' option to update to latest version.
+Extension 'missing.ext' not found.
+Make sure you use the full extension ID, including the publisher, e.g.: ms-dotnettools.csharp
+Failed Installing Extensions: missing.ext, broken.ext
+`
+
+	got := parseInstallOutput(ids, output, false)
+	if outcome, ok := got[0]; !ok || outcome != "" {
+		t.Errorf("golang.go: got %q, %v; want success", outcome, ok)
+	}
+	if outcome, ok := got[1]; !ok || outcome != "" {
+		t.Errorf("ms-python.python: got %q, %v; want success (already installed)", outcome, ok)
+	}
+	if got[2] != "Extension 'missing.ext' not found." {
+		t.Errorf("missing.ext: got %q, want the not found line", got[2])
+	}
+	if got[3] != "Failed Installing Extensions: missing.ext, broken.ext" {
+		t.Errorf("broken.ext: got %q, want the failure summary", got[3])
+	}
+	if _, ok := got[4]; ok {
+		t.Errorf("quiet.ext: expected no reported outcome, got %q", got[4])
+	}
+
+	// With --force an unchanged install did not do what was asked
+	got = parseInstallOutput(ids, output, true)
+	if !strings.Contains(got[1], "is already installed") {
+		t.Errorf("ms-python.python with force: got %q, want the already installed line as a failure", got[1])
+	}
+	if outcome, ok := got[0]; !ok || outcome != "" {
+		t.Errorf("golang.go with force: got %q, %v; want success", outcome, ok)
+	}
+}
+
+// writeFakeCode writes a shell script standing in for the VS Code CLI. It
+// logs each invocation's arguments to the returned file and runs body.
+func writeFakeCode(t *testing.T, body string) (program, logFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on a shell script")
+	}
+	dir := t.TempDir()
+	logFile = filepath.Join(dir, "calls.log")
+	program = filepath.Join(dir, "code")
+	script := "#!/bin/sh\necho \"$@\" >> " + logFile + "\n" + body + "\n"
+	if err := os.WriteFile(program, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake CLI: %v", err)
+	}
+	return program, logFile
+}
+
+// fakeCodeCalls returns the logged invocations of a fake CLI
+func fakeCodeCalls(t *testing.T, logFile string) []string {
+	t.Helper()
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("failed to read fake CLI log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestInstallExtensions_SingleInvocation(t *testing.T) {
+	program, logFile := writeFakeCode(t, `
+echo "Extension 'golang.go' v0.40.0 was successfully installed."
+echo "Extension 'bad.ext' not found."
+echo "Failed Installing Extensions: bad.ext"
+exit 1`)
+	stubExecCommand(t, program)
+
+	errs := InstallExtensions([]string{"golang.go", "bad.ext"})
+	if errs[0] != nil {
+		t.Errorf("golang.go: expected success, got: %v", errs[0])
+	}
+	if !errors.Is(errs[1], ErrExtensionInstallFailed) || !strings.Contains(errs[1].Error(), "not found") {
+		t.Errorf("bad.ext: expected ErrExtensionInstallFailed with the CLI reason, got: %v", errs[1])
+	}
+
+	calls := fakeCodeCalls(t, logFile)
+	if len(calls) != 1 || calls[0] != "--install-extension golang.go --install-extension bad.ext" {
+		t.Errorf("CLI calls = %q, want one batched invocation", calls)
+	}
+}
+
+func TestInstallExtensions_FallsBackForUnreportedExtensions(t *testing.T) {
+	// An older CLI that only honors the last --install-extension flag
+	program, logFile := writeFakeCode(t, `
+for last; do :; done
+echo "Extension '$last' v1.0.0 was successfully installed."`)
+	stubExecCommand(t, program)
+
+	for i, err := range ForceInstallExtensions([]string{"a.one", "b.two", "c.three"}) {
+		if err != nil {
+			t.Errorf("extension %d: expected success, got: %v", i, err)
+		}
+	}
+
+	want := []string{
+		"--install-extension a.one --install-extension b.two --install-extension c.three --force",
+		"--install-extension a.one --force",
+		"--install-extension b.two --force",
+		"--install-extension c.three --force",
+	}
+	if calls := fakeCodeCalls(t, logFile); !reflect.DeepEqual(calls, want) {
+		t.Errorf("CLI calls = %q, want %q", calls, want)
+	}
+}
+
+func TestInstallExtensions_VSCodeNotFound(t *testing.T) {
+	stubExecCommand(t, "devtools-sync-test-missing-code")
+
+	for i, err := range InstallExtensions([]string{"golang.go", "ms-python.python"}) {
+		if !errors.Is(err, ErrVSCodeNotFound) {
+			t.Errorf("extension %d: expected ErrVSCodeNotFound, got: %v", i, err)
+		}
+	}
+}
+
+func TestIsTransientInstallError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"network error", fmt.Errorf("%w a.b: exit status 1 (output: getaddrinfo ENOTFOUND marketplace.visualstudio.com)", ErrExtensionInstallFailed), true},
+		{"batch failure", fmt.Errorf("%w a.b: Failed Installing Extensions: a.b", ErrExtensionInstallFailed), true},
+		{"extension not found", fmt.Errorf("%w a.b: Extension 'a.b' not found.", ErrExtensionInstallFailed), false},
+		{"incompatible", fmt.Errorf("%w a.b: Extension 'a.b' is not compatible with VS Code '1.80.0'.", ErrExtensionInstallFailed), false},
+		{"vscode missing", fmt.Errorf("%w: exec: \"code\": executable file not found in $PATH", ErrVSCodeNotFound), false},
+		{"other error", errors.New("extension ID cannot be empty"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientInstallError(tt.err); got != tt.want {
+				t.Errorf("IsTransientInstallError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddManifestMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeManifest := func(dirName, manifest string) {
+		t.Helper()
+		dir := filepath.Join(tmpDir, dirName)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeManifest("ms-python.python-2023.0.0", `{"name": "python", "publisher": "ms-python", "version": "2023.0.0", "displayName": "Python (old)"}`)
+	writeManifest("ms-python.python-2024.0.0", `{"name": "python", "publisher": "ms-python", "version": "2024.0.0", "displayName": "Python", "description": "Python extension"}`)
+	writeManifest("golang.go-0.40.0", `{"name": "go", "publisher": "golang", "version": "0.40.0", "displayName": "Go"}`)
+
+	// As listed by the CLI: IDs may differ in case from the manifest, and
+	// the installed version may have no directory of its own
+	extensions := []Extension{
+		{ID: "ms-python.python", Version: "2024.0.0", Enabled: true},
+		{ID: "Golang.Go", Version: "0.41.0", Enabled: true},
+		{ID: "unknown.ext", Version: "1.0.0", Enabled: true},
+	}
+	addManifestMetadata(extensions, []string{tmpDir, "/nonexistent/directory"})
+
+	want := []Extension{
+		{ID: "ms-python.python", Version: "2024.0.0", Enabled: true, DisplayName: "Python", Description: "Python extension", Publisher: "ms-python"},
+		{ID: "Golang.Go", Version: "0.41.0", Enabled: true, DisplayName: "Go", Publisher: "golang"},
+		{ID: "unknown.ext", Version: "1.0.0", Enabled: true},
+	}
+	for i := range want {
+		if extensions[i] != want[i] {
+			t.Errorf("extension %d = %+v, want %+v", i, extensions[i], want[i])
+		}
+	}
+}
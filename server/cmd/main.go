@@ -3,25 +3,40 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/mark-chris/devtools-sync/server/internal/api"
 	"github.com/mark-chris/devtools-sync/server/internal/auth"
 	"github.com/mark-chris/devtools-sync/server/internal/database"
 	"github.com/mark-chris/devtools-sync/server/internal/middleware"
 )
 
+// version is the server release, reported by /health (set with -ldflags "-X main.version=...")
+var version = "0.1.0"
+
 func healthHandler(w http.ResponseWriter, r *http.Request) {
+	// Marshal rather than format, so any version string stays valid JSON
+	body, _ := json.Marshal(map[string]string{
+		"status":  "healthy",
+		"service": "devtools-sync-server",
+		"version": version,
+	})
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_, _ = fmt.Fprintf(w, `{"status":"healthy","service":"devtools-sync-server"}`)
+	_, _ = w.Write(body)
 }
 
 func main() {
@@ -39,12 +54,56 @@ func main() {
 		log.Fatalf("Database URL validation failed: %v", err)
 	}
 
+	// Load optional denylist of common/breached passwords
+	passwordDenylist, err := auth.LoadPasswordDenylist(os.Getenv("PASSWORD_DENYLIST"))
+	if err != nil {
+		log.Fatalf("Password denylist configuration failed: %v", err)
+	}
+	if passwordDenylist != nil {
+		auth.SetPasswordDenylist(passwordDenylist)
+		log.Printf("Password denylist enabled: %d entries", passwordDenylist.Len())
+	}
+
+	// Build audit logger; handlers registered on the mux must be given auditLogger
+	auditLogger, err := newAuditLogger(os.Getenv("AUDIT_LOG"), isDev)
+	if err != nil {
+		log.Fatalf("Audit log configuration failed: %v", err)
+	}
+	if closer, ok := auditLogger.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				log.Printf("Warning: failed to close audit log: %v", err)
+			}
+		}()
+	}
+	log.Printf("Audit log: %s", auditLogDescription(os.Getenv("AUDIT_LOG")))
+
 	// Parse max body size configuration
 	maxBodySize := parseMaxBodySize(os.Getenv("MAX_BODY_SIZE"))
 	log.Printf("Request body size limit: %d bytes (%.2f MB)", maxBodySize, float64(maxBodySize)/(1024*1024))
 
 	corsOrigins := parseCORSOrigins(os.Getenv("CORS_ALLOWED_ORIGINS"))
 
+	slowRequestThreshold := parseSlowRequestThreshold(os.Getenv("SLOW_REQUEST_THRESHOLD"))
+	log.Printf("Slow request threshold: %s", slowRequestThreshold)
+
+	requestTimeout := parseRequestTimeout(os.Getenv("REQUEST_TIMEOUT"))
+	log.Printf("Request timeout: %s", requestTimeout)
+
+	// Build the refresh token usage tracker, which batches last_used_at writes.
+	// The refresh handler registered on the mux must be given
+	// tokenUsage.Record as its token updater.
+	tokenUsageFlushInterval := parseTokenUsageFlushInterval(os.Getenv("TOKEN_USAGE_FLUSH_INTERVAL"))
+	tokenUsage := auth.NewTokenUsageTracker(discardTokenUsage, tokenUsageFlushInterval)
+	log.Printf("Refresh token usage flush interval: %s", tokenUsageFlushInterval)
+
+	// Build the auth endpoint rate limiter; its background cleanup drops stale
+	// client entries so a long-running server does not grow without bound.
+	// Handlers registered on the mux must be given rateLimiter.
+	rateLimitCleanupInterval := parseRateLimitCleanupInterval(os.Getenv("RATE_LIMIT_CLEANUP_INTERVAL"))
+	rateLimiter := auth.NewRateLimiter(rateLimitCleanupInterval, auth.DefaultRateLimitMaxAge, auth.DefaultRateLimitMaxEntries)
+	log.Printf("Rate limit cleanup interval: %s", rateLimitCleanupInterval)
+
 	port := os.Getenv("SERVER_PORT")
 	if port == "" {
 		port = "8080"
@@ -57,14 +116,24 @@ func main() {
 		os.Getenv("TLS_CERT_FILE"),
 		os.Getenv("TLS_KEY_FILE"),
 		os.Getenv("TLS_MIN_VERSION"),
+		os.Getenv("TLS_CIPHER_SUITES"),
+		os.Getenv("TLS_CLIENT_CA"),
+		os.Getenv("TLS_CLIENT_AUTH"),
 	)
 
 	// Create mux and register handlers
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", healthHandler)
+	// The profile store check is added once profile routes are wired to a store
+	mux.HandleFunc("/ready", api.NewReadyHandler(api.DefaultReadinessTimeout, api.AuditLogReadinessCheck(auditLogger)))
 
 	// Apply CORS and body size limit middleware to all requests
-	handler := middleware.CORS(corsOrigins)(middleware.MaxBodySize(maxBodySize)(middleware.SecurityHeaders(mux)))
+	// Route groups needing a different deadline can wrap their handlers with their own middleware.Timeout
+	// Gzip request bodies are decoded before MaxBodySize, so the limit applies to the decompressed size
+	handler := middleware.CORS(corsOrigins)(middleware.DecompressRequest(middleware.MaxBodySize(maxBodySize)(middleware.SecurityHeaders(middleware.Timeout(requestTimeout)(mux)))))
+	// Expose verified client certificate names to handlers for auditing
+	handler = middleware.ClientCertificate(handler)
+	handler = middleware.RequestLogger(slowRequestThreshold)(handler)
 
 	// Create server with timeouts
 	srv := &http.Server{
@@ -91,6 +160,9 @@ func main() {
 	log.Printf("Server starting in %s mode on port %s", mode, port)
 	if tlsEnabled {
 		log.Printf("TLS enabled (min version: %s)", os.Getenv("TLS_MIN_VERSION"))
+		if tlsCfg.ClientAuth != tls.NoClientCert {
+			log.Printf("TLS client certificates: %s (CA: %s)", os.Getenv("TLS_CLIENT_AUTH"), os.Getenv("TLS_CLIENT_CA"))
+		}
 	} else if !isDev {
 		log.Printf("WARNING: TLS is not enabled in production mode. Set TLS_ENABLED=true or ensure a TLS-terminating reverse proxy is in front of this server.")
 	}
@@ -98,6 +170,7 @@ func main() {
 		log.Printf("CORS allowed origins: %v", corsOrigins)
 	}
 	log.Printf("Health endpoint: %s://localhost:%s/health", scheme, port)
+	log.Printf("Readiness endpoint: %s://localhost:%s/ready", scheme, port)
 
 	// Start server in a goroutine
 	go func() {
@@ -123,12 +196,16 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	rateLimiter.Stop()
+	if err := tokenUsage.Stop(); err != nil {
+		log.Printf("Warning: failed to flush refresh token usage: %v", err)
+	}
 	log.Println("Server stopped")
 }
 
 // loadTLSConfig validates TLS configuration and returns the tls.Config,
 // cert file path, and key file path. Returns nil config if TLS is disabled.
-func loadTLSConfig(enabled bool, certFile, keyFile, minVersion string) (*tls.Config, string, string) {
+func loadTLSConfig(enabled bool, certFile, keyFile, minVersion, cipherSuites, clientCA, clientAuth string) (*tls.Config, string, string) {
 	if !enabled {
 		return nil, "", ""
 	}
@@ -147,9 +224,63 @@ func loadTLSConfig(enabled bool, certFile, keyFile, minVersion string) (*tls.Con
 		log.Fatalf("TLS key file not found: %s", keyFile)
 	}
 
-	return &tls.Config{
-		MinVersion: parseTLSMinVersion(minVersion),
-	}, certFile, keyFile
+	tlsMinVersion := parseTLSMinVersion(minVersion)
+	suites, err := parseTLSCipherSuites(cipherSuites, tlsMinVersion)
+	if err != nil {
+		log.Fatalf("Invalid TLS_CIPHER_SUITES: %v", err)
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:   tlsMinVersion,
+		CipherSuites: suites,
+		ClientAuth:   parseTLSClientAuth(clientAuth),
+	}
+
+	if tlsCfg.ClientAuth != tls.NoClientCert {
+		if clientCA == "" {
+			log.Fatalf("TLS_CLIENT_CA is required when TLS_CLIENT_AUTH=%s", clientAuth)
+		}
+		pool, err := loadTLSClientCAs(clientCA)
+		if err != nil {
+			log.Fatalf("Invalid TLS_CLIENT_CA: %v", err)
+		}
+		tlsCfg.ClientCAs = pool
+	}
+
+	return tlsCfg, certFile, keyFile
+}
+
+// parseTLSClientAuth maps TLS_CLIENT_AUTH to a client certificate policy:
+// "require" rejects connections without a certificate signed by
+// TLS_CLIENT_CA, "request" verifies a certificate only if one is sent, and
+// "none" (the default) does not ask for one.
+func parseTLSClientAuth(value string) tls.ClientAuthType {
+	switch value {
+	case "", "none":
+		return tls.NoClientCert
+	case "request":
+		return tls.VerifyClientCertIfGiven
+	case "require":
+		return tls.RequireAndVerifyClientCert
+	default:
+		log.Fatalf("Invalid TLS_CLIENT_AUTH %q: must be \"require\", \"request\" or \"none\"", value)
+		return 0 // unreachable
+	}
+}
+
+// loadTLSClientCAs reads the PEM-encoded CA certificates trusted to sign
+// client certificates
+func loadTLSClientCAs(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
 }
 
 // parseTLSMinVersion parses the TLS_MIN_VERSION environment variable.
@@ -166,6 +297,45 @@ func parseTLSMinVersion(value string) uint16 {
 	}
 }
 
+// parseTLSCipherSuites parses a comma-separated list of cipher suite names
+// (e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) into IDs for
+// tls.Config.CipherSuites. Empty means Go's defaults. Only secure TLS 1.2
+// suites are accepted; TLS 1.3 suites are not configurable, so with a
+// minimum version of 1.3 the list is validated and then ignored.
+func parseTLSCipherSuites(value string, minVersion uint16) ([]uint16, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]*tls.CipherSuite)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		suite, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		if !slices.Contains(suite.SupportedVersions, tls.VersionTLS12) {
+			return nil, fmt.Errorf("cipher suite %q is TLS 1.3 only and cannot be configured", name)
+		}
+		ids = append(ids, suite.ID)
+	}
+
+	if minVersion >= tls.VersionTLS13 {
+		log.Printf("Warning: TLS_CIPHER_SUITES is ignored because TLS_MIN_VERSION is 1.3, whose cipher suites are not configurable")
+		return nil, nil
+	}
+
+	return ids, nil
+}
+
 // parseMaxBodySize parses the MAX_BODY_SIZE environment variable
 // Supports formats: "10MB", "1024KB", "1048576" (bytes)
 // Default: 10MB
@@ -214,6 +384,114 @@ func parseMaxBodySize(value string) int64 {
 	return num * multiplier
 }
 
+// parseSlowRequestThreshold parses the SLOW_REQUEST_THRESHOLD environment variable.
+// Accepts Go duration strings such as "500ms" or "2s"; "0" disables slow-request warnings.
+// Default: 1s
+func parseSlowRequestThreshold(value string) time.Duration {
+	if value == "" {
+		return time.Second
+	}
+
+	threshold, err := time.ParseDuration(value)
+	if err != nil || threshold < 0 {
+		log.Printf("Warning: Invalid SLOW_REQUEST_THRESHOLD value '%s', using default 1s", value)
+		return time.Second
+	}
+
+	return threshold
+}
+
+// parseRequestTimeout parses the REQUEST_TIMEOUT environment variable.
+// Requests still running after this long get a 503; "0" disables the timeout.
+// Default: 30s
+func parseRequestTimeout(value string) time.Duration {
+	if value == "" {
+		return 30 * time.Second
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.Printf("Warning: Invalid REQUEST_TIMEOUT value '%s', using default 30s", value)
+		return 30 * time.Second
+	}
+
+	return timeout
+}
+
+// discardTokenUsage is the token usage tracker's batch updater until refresh
+// tokens are stored in the database. No refresh route is registered yet, so
+// nothing is recorded.
+func discardTokenUsage(lastUsed map[uuid.UUID]time.Time) error {
+	return nil
+}
+
+// parseTokenUsageFlushInterval parses the TOKEN_USAGE_FLUSH_INTERVAL environment variable.
+// Controls how often batched refresh token last-used timestamps are written.
+// Default: 30s
+func parseTokenUsageFlushInterval(value string) time.Duration {
+	if value == "" {
+		return auth.DefaultTokenUsageFlushInterval
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		log.Printf("Warning: Invalid TOKEN_USAGE_FLUSH_INTERVAL value '%s', using default %s", value, auth.DefaultTokenUsageFlushInterval)
+		return auth.DefaultTokenUsageFlushInterval
+	}
+
+	return interval
+}
+
+// parseRateLimitCleanupInterval parses the RATE_LIMIT_CLEANUP_INTERVAL environment variable.
+// Controls how often the rate limiter drops entries with no recent attempts.
+// Default: 5m
+func parseRateLimitCleanupInterval(value string) time.Duration {
+	if value == "" {
+		return auth.DefaultRateLimitCleanupInterval
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		log.Printf("Warning: Invalid RATE_LIMIT_CLEANUP_INTERVAL value '%s', using default %s", value, auth.DefaultRateLimitCleanupInterval)
+		return auth.DefaultRateLimitCleanupInterval
+	}
+
+	return interval
+}
+
+// newAuditLogger builds the audit logger selected by the AUDIT_LOG environment
+// variable: "none" discards events, "stdout" writes JSON lines to stdout, and
+// "file:/path" writes JSON lines to /path with size-based rotation.
+// Empty defaults to stdout in development and is an error in production.
+func newAuditLogger(value string, isDev bool) (auth.AuditLogger, error) {
+	switch value {
+	case "":
+		if !isDev {
+			return nil, fmt.Errorf("AUDIT_LOG is required in production: set it to none, stdout, or file:/path")
+		}
+		return auth.NewWriterAuditLogger(os.Stdout), nil
+	case "none":
+		return auth.NoopAuditLogger{}, nil
+	case "stdout":
+		return auth.NewWriterAuditLogger(os.Stdout), nil
+	}
+
+	path, ok := strings.CutPrefix(value, "file:")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid AUDIT_LOG value %q: must be none, stdout, or file:/path", value)
+	}
+
+	return auth.NewFileAuditLogger(path, auth.FileAuditLoggerOptions{})
+}
+
+// auditLogDescription returns the audit log sink for startup logging
+func auditLogDescription(value string) string {
+	if value == "" {
+		return "stdout (development default)"
+	}
+	return value
+}
+
 // parseCORSOrigins parses the CORS_ALLOWED_ORIGINS environment variable.
 // Returns a slice of origin strings. Empty input returns nil.
 func parseCORSOrigins(value string) []string {
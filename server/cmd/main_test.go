@@ -1,23 +1,34 @@
 package main
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
 	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
 	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/mark-chris/devtools-sync/server/internal/middleware"
 )
 
 func TestLoadTLSConfig_Disabled(t *testing.T) {
-	tlsCfg, certFile, keyFile := loadTLSConfig(false, "", "", "1.2")
+	tlsCfg, certFile, keyFile := loadTLSConfig(false, "", "", "1.2", "", "", "")
 	if tlsCfg != nil {
 		t.Error("expected nil TLS config when disabled")
 	}
@@ -38,7 +49,7 @@ func TestLoadTLSConfig_Enabled(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tlsCfg, returnedCert, returnedKey := loadTLSConfig(true, certPath, keyPath, "1.2")
+	tlsCfg, returnedCert, returnedKey := loadTLSConfig(true, certPath, keyPath, "1.2", "", "", "")
 	if tlsCfg == nil {
 		t.Fatal("expected non-nil TLS config when enabled")
 	}
@@ -64,7 +75,7 @@ func TestLoadTLSConfig_EnabledTLS13(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tlsCfg, _, _ := loadTLSConfig(true, certPath, keyPath, "1.3")
+	tlsCfg, _, _ := loadTLSConfig(true, certPath, keyPath, "1.3", "", "", "")
 	if tlsCfg == nil {
 		t.Fatal("expected non-nil TLS config")
 	}
@@ -73,6 +84,60 @@ func TestLoadTLSConfig_EnabledTLS13(t *testing.T) {
 	}
 }
 
+func TestParseTLSCipherSuites(t *testing.T) {
+	suites, err := parseTLSCipherSuites("TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", tls.VersionTLS12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	if !slices.Equal(suites, want) {
+		t.Errorf("expected suites %v, got %v", want, suites)
+	}
+
+	if suites, err := parseTLSCipherSuites("", tls.VersionTLS12); err != nil || suites != nil {
+		t.Errorf("expected Go defaults for empty value, got %v, %v", suites, err)
+	}
+}
+
+func TestParseTLSCipherSuites_Invalid(t *testing.T) {
+	tests := []string{
+		"TLS_NOT_A_REAL_SUITE",
+		"TLS_RSA_WITH_RC4_128_SHA", // insecure
+		"TLS_AES_128_GCM_SHA256",   // TLS 1.3 only
+		"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,bogus",
+	}
+
+	for _, value := range tests {
+		t.Run(value, func(t *testing.T) {
+			if _, err := parseTLSCipherSuites(value, tls.VersionTLS12); err == nil {
+				t.Errorf("expected error for %q", value)
+			}
+		})
+	}
+}
+
+func TestParseTLSCipherSuites_IgnoredForTLS13(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	suites, err := parseTLSCipherSuites("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", tls.VersionTLS13)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if suites != nil {
+		t.Errorf("expected suites to be ignored for TLS 1.3, got %v", suites)
+	}
+	if !strings.Contains(logs.String(), "TLS_CIPHER_SUITES is ignored") {
+		t.Errorf("expected warning about ignored suites, got: %q", logs.String())
+	}
+
+	// Unknown names are still rejected
+	if _, err := parseTLSCipherSuites("bogus", tls.VersionTLS13); err == nil {
+		t.Error("expected error for unknown suite with TLS 1.3")
+	}
+}
+
 func TestParseMaxBodySize_Default(t *testing.T) {
 	result := parseMaxBodySize("")
 	expected := int64(10 * 1024 * 1024) // 10MB
@@ -283,7 +348,7 @@ func TestTLSServerIntegration(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tlsCfg, _, _ := loadTLSConfig(true, certPath, keyPath, "1.2")
+	tlsCfg, _, _ := loadTLSConfig(true, certPath, keyPath, "1.2", "", "", "")
 
 	// Load the keypair for the TLS listener
 	cert, err := tls.X509KeyPair(certPEM, keyPEM)
@@ -340,6 +405,180 @@ func TestTLSServerIntegration(t *testing.T) {
 	}
 }
 
+// generateTestClientCA creates a CA and a client certificate with the given
+// Common Name signed by it. Returns the PEM-encoded CA and the client keypair.
+func generateTestClientCA(t *testing.T, commonName string) (caPEM []byte, clientCert tls.Certificate) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Client CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate client key: %v", err)
+	}
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create client certificate: %v", err)
+	}
+
+	caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	return caPEM, tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+}
+
+// startMutualTLSServer serves a handler echoing the client certificate CN
+// over TLS configured by loadTLSConfig with the given TLS_CLIENT_AUTH value.
+// Returns the server URL and a client certificate signed by the client CA.
+func startMutualTLSServer(t *testing.T, clientAuth string) (string, tls.Certificate) {
+	t.Helper()
+
+	certPEM, keyPEM := generateTestCert(t)
+	caPEM, clientCert := generateTestClientCA(t, "build-agent-01")
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	caPath := filepath.Join(dir, "client-ca.pem")
+	for path, data := range map[string][]byte{certPath: certPEM, keyPath: keyPEM, caPath: caPEM} {
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tlsCfg, _, _ := loadTLSConfig(true, certPath, keyPath, "1.2", "", caPath, clientAuth)
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("failed to load test keypair: %v", err)
+	}
+	listenCfg := tlsCfg.Clone()
+	listenCfg.Certificates = []tls.Certificate{cert}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", listenCfg)
+	if err != nil {
+		t.Fatalf("failed to create TLS listener: %v", err)
+	}
+
+	srv := &http.Server{
+		Handler: middleware.ClientCertificate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cn, _ := middleware.ClientCommonName(r.Context())
+			_, _ = io.WriteString(w, cn)
+		})),
+		ReadHeaderTimeout: 5 * time.Second,
+		ErrorLog:          log.New(io.Discard, "", 0),
+	}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	return "https://" + ln.Addr().String(), clientCert
+}
+
+func mutualTLSClient(certs ...tls.Certificate) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+				Certificates:       certs,
+			},
+		},
+	}
+}
+
+func TestMutualTLS_RequireRejectsMissingClientCert(t *testing.T) {
+	serverURL, _ := startMutualTLSServer(t, "require")
+
+	resp, err := mutualTLSClient().Get(serverURL)
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected request without a client certificate to be rejected")
+	}
+}
+
+func TestMutualTLS_RequireAcceptsClientCert(t *testing.T) {
+	serverURL, clientCert := startMutualTLSServer(t, "require")
+
+	resp, err := mutualTLSClient(clientCert).Get(serverURL)
+	if err != nil {
+		t.Fatalf("expected request with a client certificate to succeed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "build-agent-01" {
+		t.Errorf("expected handler to see client CN 'build-agent-01', got %q", body)
+	}
+}
+
+func TestMutualTLS_NoneAcceptsMissingClientCert(t *testing.T) {
+	serverURL, _ := startMutualTLSServer(t, "none")
+
+	resp, err := mutualTLSClient().Get(serverURL)
+	if err != nil {
+		t.Fatalf("expected request without a client certificate to succeed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestParseTLSClientAuth(t *testing.T) {
+	tests := map[string]tls.ClientAuthType{
+		"":        tls.NoClientCert,
+		"none":    tls.NoClientCert,
+		"request": tls.VerifyClientCertIfGiven,
+		"require": tls.RequireAndVerifyClientCert,
+	}
+
+	for value, want := range tests {
+		if got := parseTLSClientAuth(value); got != want {
+			t.Errorf("parseTLSClientAuth(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestLoadTLSClientCAs_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadTLSClientCAs(path); err == nil {
+		t.Error("expected error for a file without PEM certificates")
+	}
+	if _, err := loadTLSClientCAs(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
 func TestParseCORSOrigins_Empty(t *testing.T) {
 	result := parseCORSOrigins("")
 	if len(result) != 0 {
@@ -379,3 +618,173 @@ func TestParseCORSOrigins_WhitespaceHandling(t *testing.T) {
 		t.Errorf("Expected trimmed second origin, got %q", result[1])
 	}
 }
+
+func TestParseSlowRequestThreshold(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"", time.Second},
+		{"500ms", 500 * time.Millisecond},
+		{"2s", 2 * time.Second},
+		{"0", 0},
+		{"invalid", time.Second},
+		{"-1s", time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := parseSlowRequestThreshold(tt.input)
+			if result != tt.expected {
+				t.Errorf("parseSlowRequestThreshold(%q) = %s, want %s", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRequestTimeout(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"", 30 * time.Second},
+		{"10s", 10 * time.Second},
+		{"2m", 2 * time.Minute},
+		{"0", 0},
+		{"invalid", 30 * time.Second},
+		{"-1s", 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := parseRequestTimeout(tt.input)
+			if result != tt.expected {
+				t.Errorf("parseRequestTimeout(%q) = %s, want %s", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseTokenUsageFlushInterval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"", 30 * time.Second},
+		{"5s", 5 * time.Second},
+		{"2m", 2 * time.Minute},
+		{"0", 30 * time.Second},
+		{"invalid", 30 * time.Second},
+		{"-1s", 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := parseTokenUsageFlushInterval(tt.input)
+			if result != tt.expected {
+				t.Errorf("parseTokenUsageFlushInterval(%q) = %s, want %s", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRateLimitCleanupInterval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"", 5 * time.Minute},
+		{"30s", 30 * time.Second},
+		{"1h", time.Hour},
+		{"0", 5 * time.Minute},
+		{"invalid", 5 * time.Minute},
+		{"-1s", 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := parseRateLimitCleanupInterval(tt.input)
+			if result != tt.expected {
+				t.Errorf("parseRateLimitCleanupInterval(%q) = %s, want %s", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewAuditLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	tests := []struct {
+		name    string
+		value   string
+		isDev   bool
+		want    string
+		wantErr bool
+	}{
+		{"dev default", "", true, "*auth.WriterAuditLogger", false},
+		{"production requires explicit value", "", false, "", true},
+		{"none", "none", false, "auth.NoopAuditLogger", false},
+		{"stdout", "stdout", false, "*auth.WriterAuditLogger", false},
+		{"file", "file:" + path, false, "*auth.FileAuditLogger", false},
+		{"file without path", "file:", true, "", true},
+		{"unknown", "syslog", true, "", true},
+		{"bare path", "/var/log/audit.log", true, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := newAuditLogger(tt.value, tt.isDev)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("newAuditLogger(%q) expected error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newAuditLogger(%q) failed: %v", tt.value, err)
+			}
+			if closer, ok := logger.(io.Closer); ok {
+				defer func() { _ = closer.Close() }()
+			}
+			if got := fmt.Sprintf("%T", logger); got != tt.want {
+				t.Errorf("newAuditLogger(%q) type = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected audit log file to be created: %v", err)
+	}
+}
+
+func TestHealthHandler_ReportsVersion(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	healthHandler(w, req)
+
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("health response is not valid JSON: %v", err)
+	}
+	if body["status"] != "healthy" || body["version"] != version {
+		t.Errorf("unexpected health response: %v", body)
+	}
+}
+
+func TestHealthHandler_EscapesVersion(t *testing.T) {
+	orig := version
+	version = "1.0.0-\x7f\"rc\""
+	defer func() { version = orig }()
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	healthHandler(w, req)
+
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("health response is not valid JSON: %v", err)
+	}
+	if body["version"] != version {
+		t.Errorf("expected version %q, got %q", version, body["version"])
+	}
+}
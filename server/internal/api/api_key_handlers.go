@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+	"github.com/mark-chris/devtools-sync/server/internal/middleware"
+)
+
+// StoreAPIKeyFunc is a function that stores a new API key
+type StoreAPIKeyFunc func(key *auth.APIKey) error
+
+// GetAPIKeyByIDFunc is a function that retrieves an API key by ID; returns nil if none exists
+type GetAPIKeyByIDFunc func(id uuid.UUID) (*auth.APIKey, error)
+
+// RevokeAPIKeyFunc is a function that persists a revoked API key
+type RevokeAPIKeyFunc func(key *auth.APIKey) error
+
+// maxAPIKeyNameLength bounds the label stored with an API key
+const maxAPIKeyNameLength = 100
+
+// CreateAPIKeyRequest represents the create API key request body. Role
+// defaults to the caller's role; ExpiresInDays of 0 creates a key that
+// does not expire.
+type CreateAPIKeyRequest struct {
+	Name          string `json:"name"`
+	Role          string `json:"role,omitempty"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty"`
+}
+
+// CreateAPIKeyResponse represents the create API key response body. Key is
+// only ever returned here; the server keeps just its hash.
+type CreateAPIKeyResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Key       string     `json:"key"`
+	Role      string     `json:"role"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewCreateAPIKeyHandler creates a handler that issues an API key for the
+// authenticated user, for use as DEVTOOLS_SYNC_API_KEY by the agent. The key
+// acts as the user with at most the requested role, which cannot exceed the
+// user's own. Requires RequireAuth middleware. If auditLogger is non-nil, the
+// event is audit-logged.
+func NewCreateAPIKeyHandler(
+	authService *auth.AuthService,
+	storeAPIKey StoreAPIKeyFunc,
+	auditLogger auth.AuditLogger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		var req CreateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid request body",
+			})
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len(req.Name) > maxAPIKeyNameLength {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Name is required and must be at most 100 characters",
+			})
+			return
+		}
+
+		if req.Role == "" {
+			req.Role = user.Role
+		}
+		if !canInviteRole(user.Role, req.Role) {
+			writeJSON(w, http.StatusForbidden, map[string]string{
+				"error": "Role must be viewer, manager, or admin, and no higher than your own",
+			})
+			return
+		}
+
+		if req.ExpiresInDays < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "expires_in_days must not be negative",
+			})
+			return
+		}
+
+		plaintext, err := authService.GenerateAPIKey()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to generate API key",
+			})
+			return
+		}
+
+		now := authService.Now()
+		key := &auth.APIKey{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			Name:      req.Name,
+			KeyHash:   authService.HashToken(plaintext),
+			KeyPrefix: plaintext[:auth.APIKeyDisplayPrefixLength],
+			Role:      req.Role,
+			CreatedAt: now,
+		}
+		if req.ExpiresInDays > 0 {
+			expiresAt := now.AddDate(0, 0, req.ExpiresInDays)
+			key.ExpiresAt = &expiresAt
+		}
+
+		if err := storeAPIKey(key); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to store API key",
+			})
+			return
+		}
+
+		if auditLogger != nil {
+			_ = auditLogger.Log(&auth.AuditLog{
+				EventType:  auth.AuditAPIKeyCreated,
+				ActorType:  auth.ActorTypeUser,
+				ActorID:    &user.ID,
+				TargetType: "api_key",
+				TargetID:   &key.ID,
+				Details:    map[string]interface{}{"name": key.Name, "role": key.Role},
+				ClientIP:   middleware.GetClientIP(r),
+				UserAgent:  r.UserAgent(),
+			})
+		}
+
+		writeJSON(w, http.StatusCreated, CreateAPIKeyResponse{
+			ID:        key.ID,
+			Name:      key.Name,
+			Key:       plaintext,
+			Role:      key.Role,
+			ExpiresAt: key.ExpiresAt,
+		})
+	}
+}
+
+// NewRevokeAPIKeyHandler creates a handler that revokes one of the
+// authenticated user's API keys, identified by the {id} path value. Other
+// users' keys are reported as not found. Revoking is idempotent. Requires
+// RequireAuth middleware. If auditLogger is non-nil, the event is audit-logged.
+func NewRevokeAPIKeyHandler(
+	authService *auth.AuthService,
+	getAPIKey GetAPIKeyByIDFunc,
+	revokeAPIKey RevokeAPIKeyFunc,
+	auditLogger auth.AuditLogger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid API key ID",
+			})
+			return
+		}
+
+		key, err := getAPIKey(id)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to retrieve API key",
+			})
+			return
+		}
+		if key == nil || key.UserID != user.ID {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": "API key not found",
+			})
+			return
+		}
+
+		if key.RevokedAt == nil {
+			now := authService.Now()
+			key.RevokedAt = &now
+			if err := revokeAPIKey(key); err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{
+					"error": "Failed to revoke API key",
+				})
+				return
+			}
+
+			if auditLogger != nil {
+				_ = auditLogger.Log(&auth.AuditLog{
+					EventType:  auth.AuditAPIKeyRevoked,
+					ActorType:  auth.ActorTypeUser,
+					ActorID:    &user.ID,
+					TargetType: "api_key",
+					TargetID:   &key.ID,
+					Details:    map[string]interface{}{"name": key.Name},
+					ClientIP:   middleware.GetClientIP(r),
+					UserAgent:  r.UserAgent(),
+				})
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{
+			"message": "API key revoked",
+		})
+	}
+}
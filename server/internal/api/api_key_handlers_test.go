@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+)
+
+func newAPIKeyTestService() *auth.AuthService {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+	return authService
+}
+
+func createAPIKey(t *testing.T, handler http.HandlerFunc, user *auth.User, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/v1/auth/api-keys", bytes.NewReader([]byte(body)))
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateAPIKeyHandler_IssuesHashedKey(t *testing.T) {
+	authService := newAPIKeyTestService()
+	user := &auth.User{ID: uuid.New(), Email: "dev@example.com", Role: "manager"}
+
+	var stored *auth.APIKey
+	storeAPIKey := func(key *auth.APIKey) error {
+		stored = key
+		return nil
+	}
+	auditLogger := auth.NewInMemoryAuditLogger()
+	handler := NewCreateAPIKeyHandler(authService, storeAPIKey, auditLogger)
+
+	w := createAPIKey(t, handler, user, `{"name": "ci", "role": "viewer", "expires_in_days": 30}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp CreateAPIKeyResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !auth.IsAPIKey(resp.Key) {
+		t.Errorf("key %q does not have the API key prefix", resp.Key)
+	}
+	if stored == nil {
+		t.Fatal("key was not stored")
+	}
+	if stored.KeyHash != authService.HashToken(resp.Key) || strings.Contains(stored.KeyHash, resp.Key) {
+		t.Error("only the key's hash should be stored")
+	}
+	if stored.KeyPrefix != resp.Key[:auth.APIKeyDisplayPrefixLength] {
+		t.Errorf("key prefix = %q, want the start of %q", stored.KeyPrefix, resp.Key)
+	}
+	if stored.UserID != user.ID || stored.Role != "viewer" || stored.ID != resp.ID {
+		t.Errorf("unexpected stored key: %+v", stored)
+	}
+	wantExpiry := authService.Now().AddDate(0, 0, 30)
+	if stored.ExpiresAt == nil || !stored.ExpiresAt.Equal(wantExpiry) {
+		t.Errorf("expires at = %v, want %v", stored.ExpiresAt, wantExpiry)
+	}
+
+	logs := auditLogger.GetLogs()
+	if len(logs) != 1 || logs[0].EventType != auth.AuditAPIKeyCreated {
+		t.Fatalf("expected one %s audit log, got %+v", auth.AuditAPIKeyCreated, logs)
+	}
+}
+
+func TestCreateAPIKeyHandler_DefaultsToCallerRole(t *testing.T) {
+	authService := newAPIKeyTestService()
+	user := &auth.User{ID: uuid.New(), Role: "manager"}
+
+	var stored *auth.APIKey
+	handler := NewCreateAPIKeyHandler(authService, func(key *auth.APIKey) error {
+		stored = key
+		return nil
+	}, nil)
+
+	w := createAPIKey(t, handler, user, `{"name": "laptop"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if stored.Role != "manager" || stored.ExpiresAt != nil {
+		t.Errorf("expected a non-expiring manager key, got %+v", stored)
+	}
+}
+
+func TestCreateAPIKeyHandler_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"role above own", `{"name": "ci", "role": "admin"}`, http.StatusForbidden},
+		{"unknown role", `{"name": "ci", "role": "owner"}`, http.StatusForbidden},
+		{"missing name", `{"name": "  "}`, http.StatusBadRequest},
+		{"negative expiry", `{"name": "ci", "expires_in_days": -1}`, http.StatusBadRequest},
+		{"invalid body", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &auth.User{ID: uuid.New(), Role: "manager"}
+			handler := NewCreateAPIKeyHandler(newAPIKeyTestService(), func(key *auth.APIKey) error {
+				t.Error("key should not be stored")
+				return nil
+			}, nil)
+
+			w := createAPIKey(t, handler, user, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRevokeAPIKeyHandler(t *testing.T) {
+	authService := newAPIKeyTestService()
+	owner := &auth.User{ID: uuid.New(), Role: "viewer"}
+	key := &auth.APIKey{ID: uuid.New(), UserID: owner.ID, Name: "ci", Role: "viewer"}
+
+	getAPIKey := func(id uuid.UUID) (*auth.APIKey, error) {
+		if id == key.ID {
+			return key, nil
+		}
+		return nil, nil
+	}
+	revoked := 0
+	revokeAPIKey := func(k *auth.APIKey) error {
+		revoked++
+		return nil
+	}
+	auditLogger := auth.NewInMemoryAuditLogger()
+	handler := NewRevokeAPIKeyHandler(authService, getAPIKey, revokeAPIKey, auditLogger)
+
+	revoke := func(user *auth.User, id string) int {
+		req := httptest.NewRequest("DELETE", "/api/v1/auth/api-keys/"+id, nil)
+		req.SetPathValue("id", id)
+		req = req.WithContext(contextWithUser(req.Context(), user))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Other users cannot see the key
+	if code := revoke(&auth.User{ID: uuid.New(), Role: "admin"}, key.ID.String()); code != http.StatusNotFound {
+		t.Errorf("revoking another user's key: expected 404, got %d", code)
+	}
+	if code := revoke(owner, "not-a-uuid"); code != http.StatusBadRequest {
+		t.Errorf("invalid ID: expected 400, got %d", code)
+	}
+
+	if code := revoke(owner, key.ID.String()); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if key.RevokedAt == nil || !key.RevokedAt.Equal(authService.Now()) {
+		t.Errorf("revoked at = %v, want %v", key.RevokedAt, authService.Now())
+	}
+
+	// Revoking again succeeds without another write
+	if code := revoke(owner, key.ID.String()); code != http.StatusOK {
+		t.Errorf("repeat revoke: expected 200, got %d", code)
+	}
+	if revoked != 1 {
+		t.Errorf("expected 1 revoke write, got %d", revoked)
+	}
+	if logs := auditLogger.GetLogs(); len(logs) != 1 || logs[0].EventType != auth.AuditAPIKeyRevoked {
+		t.Errorf("expected one %s audit log, got %+v", auth.AuditAPIKeyRevoked, logs)
+	}
+}
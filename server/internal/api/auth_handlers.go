@@ -3,8 +3,10 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/mark-chris/devtools-sync/server/internal/auth"
 	"github.com/mark-chris/devtools-sync/server/internal/middleware"
 )
@@ -15,6 +17,16 @@ type UserByEmailFunc func(email string) (*auth.User, error)
 // StoreRefreshTokenFunc is a function that stores a refresh token
 type StoreRefreshTokenFunc func(rt *auth.RefreshToken) error
 
+// RevokeDeviceRefreshTokensFunc is a function that revokes every unrevoked refresh
+// token a user holds for one device and returns how many were revoked
+type RevokeDeviceRefreshTokensFunc func(userID uuid.UUID, deviceID string, revokedAt time.Time) (int, error)
+
+// DeviceIDHeader is the request header clients use to identify the device they log in from
+const DeviceIDHeader = "X-Device-ID"
+
+// maxDeviceIDLength bounds the device identifier to the refresh_tokens.device_id column size
+const maxDeviceIDLength = 128
+
 // LoginRequest represents the login request body
 type LoginRequest struct {
 	Email    string `json:"email"`
@@ -31,10 +43,14 @@ type LoginResponse struct {
 // NewLoginHandler creates a new login handler.
 // If rateLimiter is non-nil, the rate limit for the client IP is reset on successful login.
 // If auditLogger is non-nil, login attempts (success and failure) are audit-logged.
+// If revokeDeviceTokens is non-nil and the request carries an X-Device-ID header,
+// the user's previous refresh tokens for that device are revoked before the new
+// one is stored, so repeated logins from one device leave a single active session.
 func NewLoginHandler(
 	authService *auth.AuthService,
 	userByEmail UserByEmailFunc,
 	storeRefreshToken StoreRefreshTokenFunc,
+	revokeDeviceTokens RevokeDeviceRefreshTokensFunc,
 	rateLimiter *auth.RateLimiter,
 	auditLogger auth.AuditLogger,
 ) http.HandlerFunc {
@@ -48,6 +64,14 @@ func NewLoginHandler(
 			return
 		}
 
+		deviceID := strings.TrimSpace(r.Header.Get(DeviceIDHeader))
+		if len(deviceID) > maxDeviceIDLength {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Device ID is too long",
+			})
+			return
+		}
+
 		// Get user by email
 		user, err := userByEmail(req.Email)
 		if err != nil || user == nil {
@@ -100,12 +124,23 @@ func NewLoginHandler(
 			return
 		}
 
+		// Replace any session this device already holds
+		if deviceID != "" && revokeDeviceTokens != nil {
+			if _, err := revokeDeviceTokens(user.ID, deviceID, authService.Now()); err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{
+					"error": "Failed to revoke previous device session",
+				})
+				return
+			}
+		}
+
 		// Store refresh token in database
 		refreshTokenRecord := &auth.RefreshToken{
 			UserID:     user.ID,
 			TokenHash:  authService.HashToken(refreshToken),
-			ExpiresAt:  time.Now().Add(7 * 24 * time.Hour),
-			CreatedAt:  time.Now(),
+			DeviceID:   deviceID,
+			ExpiresAt:  authService.Now().Add(7 * 24 * time.Hour),
+			CreatedAt:  authService.Now(),
 		}
 
 		if err := storeRefreshToken(refreshTokenRecord); err != nil {
@@ -130,7 +165,7 @@ func NewLoginHandler(
 			Name:     "refresh_token",
 			Value:    refreshToken,
 			Path:     "/",
-			Expires:  time.Now().Add(7 * 24 * time.Hour),
+			Expires:  authService.Now().Add(7 * 24 * time.Hour),
 			MaxAge:   7 * 24 * 60 * 60, // 7 days in seconds
 			HttpOnly: true,
 			Secure:   true,
@@ -222,7 +257,7 @@ func NewRefreshHandler(
 		}
 
 		// Check if token is expired
-		if time.Now().After(storedToken.ExpiresAt) {
+		if authService.Now().After(storedToken.ExpiresAt) {
 			if auditLogger != nil {
 				_ = auditLogger.Log(&auth.AuditLog{
 					EventType: auth.AuditRefreshFailure,
@@ -283,7 +318,7 @@ func NewRefreshHandler(
 		}
 
 		// Update last_used_at
-		now := time.Now()
+		now := authService.Now()
 		storedToken.LastUsedAt = &now
 		_ = updateRefreshToken(storedToken) // Ignore error - don't fail request if update fails
 
@@ -338,7 +373,7 @@ func NewLogoutHandler(
 		storedToken, err := getRefreshToken(tokenHash)
 		if err == nil && storedToken != nil {
 			// Revoke the token
-			now := time.Now()
+			now := authService.Now()
 			storedToken.RevokedAt = &now
 			_ = revokeRefreshToken(storedToken) // Ignore error - logout is idempotent
 
@@ -363,6 +398,62 @@ func NewLogoutHandler(
 	}
 }
 
+// RevokeAllRefreshTokensFunc is a function that revokes every unrevoked refresh
+// token belonging to a user and returns how many were revoked
+type RevokeAllRefreshTokensFunc func(userID uuid.UUID, revokedAt time.Time) (int, error)
+
+// LogoutEverywhereResponse represents the logout everywhere response body
+type LogoutEverywhereResponse struct {
+	Message         string `json:"message"`
+	SessionsRevoked int    `json:"sessions_revoked"`
+}
+
+// NewLogoutEverywhereHandler creates a handler that ends all of the authenticated
+// user's sessions by revoking every refresh token they hold, including the current one.
+// Requires RequireAuth middleware. If auditLogger is non-nil, the event is audit-logged.
+func NewLogoutEverywhereHandler(
+	authService *auth.AuthService,
+	revokeAllRefreshTokens RevokeAllRefreshTokensFunc,
+	auditLogger auth.AuditLogger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		revoked, err := revokeAllRefreshTokens(user.ID, authService.Now())
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to revoke sessions",
+			})
+			return
+		}
+
+		if auditLogger != nil {
+			_ = auditLogger.Log(&auth.AuditLog{
+				EventType: auth.AuditLogoutAll,
+				ActorType: auth.ActorTypeUser,
+				ActorID:   &user.ID,
+				Details:   map[string]interface{}{"sessions_revoked": revoked},
+				ClientIP:  middleware.GetClientIP(r),
+				UserAgent: r.UserAgent(),
+			})
+		}
+
+		clearRefreshTokenCookie(w)
+
+		writeJSON(w, http.StatusOK, LogoutEverywhereResponse{
+			Message:         "Logged out of all sessions",
+			SessionsRevoked: revoked,
+		})
+	}
+}
+
 // clearRefreshTokenCookie clears the refresh token cookie
 func clearRefreshTokenCookie(w http.ResponseWriter) {
 	http.SetCookie(w, &http.Cookie{
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,7 +49,7 @@ func TestLoginHandler_ValidCredentials(t *testing.T) {
 		return nil
 	}
 
-	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil)
+	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil, nil)
 
 	// Create request
 	body := map[string]string{
@@ -155,7 +156,7 @@ func TestLoginHandler_InvalidCredentials(t *testing.T) {
 		return nil
 	}
 
-	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil)
+	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil, nil)
 
 	body := map[string]string{
 		"email":    testUser.Email,
@@ -203,7 +204,7 @@ func TestLoginHandler_InactiveUser(t *testing.T) {
 		return nil
 	}
 
-	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil)
+	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil, nil)
 
 	body := map[string]string{
 		"email":    testUser.Email,
@@ -237,7 +238,7 @@ func TestLoginHandler_UserNotFound(t *testing.T) {
 		return nil
 	}
 
-	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil)
+	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil, nil)
 
 	body := map[string]string{
 		"email":    "nonexistent@example.com",
@@ -271,7 +272,7 @@ func TestLoginHandler_MalformedJSON(t *testing.T) {
 		return nil
 	}
 
-	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil)
+	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil, nil)
 
 	req := httptest.NewRequest("POST", "/auth/login", bytes.NewReader([]byte("invalid json")))
 	req.Header.Set("Content-Type", "application/json")
@@ -446,6 +447,70 @@ func TestRefreshHandler_ExpiredToken(t *testing.T) {
 	}
 }
 
+func TestRefreshHandler_ClockCrossesExpiry(t *testing.T) {
+	// Setup
+	secretKey := []byte("test-secret-key-min-32-bytes-long!")
+	authService := auth.NewAuthService(secretKey)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+
+	testUser := &auth.User{
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Role:     "admin",
+		IsActive: true,
+	}
+
+	refreshToken, _ := authService.GenerateRefreshToken()
+	storedToken := &auth.RefreshToken{
+		UserID:    testUser.ID,
+		TokenHash: authService.HashToken(refreshToken),
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}
+
+	getRefreshToken := func(tokenHash string) (*auth.RefreshToken, error) {
+		return storedToken, nil
+	}
+
+	getUserByID := func(userID string) (*auth.User, error) {
+		return testUser, nil
+	}
+
+	updateRefreshToken := func(rt *auth.RefreshToken) error {
+		return nil
+	}
+
+	handler := NewRefreshHandler(authService, getRefreshToken, getUserByID, updateRefreshToken, nil)
+
+	refresh := func() int {
+		req := httptest.NewRequest("POST", "/auth/refresh", nil)
+		req.AddCookie(&http.Cookie{
+			Name:  "refresh_token",
+			Value: refreshToken,
+		})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// One second before expiry the token is accepted
+	now = now.Add(time.Hour - time.Second)
+	if code := refresh(); code != http.StatusOK {
+		t.Errorf("before expiry: response code = %d, want %d", code, http.StatusOK)
+	}
+	if storedToken.LastUsedAt == nil || !storedToken.LastUsedAt.Equal(now) {
+		t.Errorf("last_used_at = %v, want %v", storedToken.LastUsedAt, now)
+	}
+
+	// One second after expiry the same token is rejected
+	now = now.Add(2 * time.Second)
+	if code := refresh(); code != http.StatusUnauthorized {
+		t.Errorf("after expiry: response code = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
 // RED: Test refresh with revoked token
 func TestRefreshHandler_RevokedToken(t *testing.T) {
 	// Setup
@@ -686,7 +751,7 @@ func TestLoginHandler_AuditLogsSuccess(t *testing.T) {
 
 	auditLogger := auth.NewInMemoryAuditLogger()
 
-	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, auditLogger)
+	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil, auditLogger)
 
 	body := map[string]string{"email": testUser.Email, "password": password}
 	bodyBytes, _ := json.Marshal(body)
@@ -735,7 +800,7 @@ func TestLoginHandler_AuditLogsFailure(t *testing.T) {
 
 	auditLogger := auth.NewInMemoryAuditLogger()
 
-	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, auditLogger)
+	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, nil, auditLogger)
 
 	body := map[string]string{"email": "unknown@example.com", "password": "AnyPass123!"}
 	bodyBytes, _ := json.Marshal(body)
@@ -877,7 +942,7 @@ func TestLoginHandler_ResetsRateLimitOnSuccess(t *testing.T) {
 	rl := auth.NewRateLimiter(time.Hour, time.Hour, 1000)
 	defer rl.Stop()
 
-	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, rl, nil)
+	handler := NewLoginHandler(authService, userByEmail, storeRefreshToken, nil, rl, nil)
 
 	clientIP := "10.0.0.50"
 
@@ -910,3 +975,343 @@ func TestLoginHandler_ResetsRateLimitOnSuccess(t *testing.T) {
 		}
 	}
 }
+
+func TestRefreshHandler_BatchesTokenUsage(t *testing.T) {
+	// Setup
+	secretKey := []byte("test-secret-key-min-32-bytes-long!")
+	authService := auth.NewAuthService(secretKey)
+
+	testUser := &auth.User{
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Role:     "admin",
+		IsActive: true,
+	}
+
+	refreshToken, _ := authService.GenerateRefreshToken()
+	storedToken := &auth.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    testUser.ID,
+		TokenHash: authService.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+
+	getRefreshToken := func(tokenHash string) (*auth.RefreshToken, error) {
+		return storedToken, nil
+	}
+
+	getUserByID := func(userID string) (*auth.User, error) {
+		return testUser, nil
+	}
+
+	var batches []map[uuid.UUID]time.Time
+	tracker := auth.NewTokenUsageTracker(func(lastUsed map[uuid.UUID]time.Time) error {
+		batches = append(batches, lastUsed)
+		return nil
+	}, time.Hour)
+
+	handler := NewRefreshHandler(authService, getRefreshToken, getUserByID, tracker.Record, nil)
+
+	// Act: several refreshes with the same token
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("POST", "/auth/refresh", nil)
+		req.AddCookie(&http.Cookie{
+			Name:  "refresh_token",
+			Value: refreshToken,
+		})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("refresh %d: response code = %d, want %d", i, w.Code, http.StatusOK)
+		}
+	}
+
+	if len(batches) != 0 {
+		t.Fatalf("expected no writes before shutdown, got %d", len(batches))
+	}
+
+	// Shutdown flushes the coalesced usage
+	if err := tracker.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	// Assert
+	if len(batches) != 1 {
+		t.Fatalf("expected 1 batched write, got %d", len(batches))
+	}
+	if _, ok := batches[0][storedToken.ID]; !ok || len(batches[0]) != 1 {
+		t.Errorf("expected batch with only token %s, got %v", storedToken.ID, batches[0])
+	}
+}
+
+func TestLogoutEverywhereHandler_RevokesOnlyCallersTokens(t *testing.T) {
+	// Setup
+	secretKey := []byte("test-secret-key-min-32-bytes-long!")
+	authService := auth.NewAuthService(secretKey)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+
+	alice := &auth.User{ID: uuid.New(), Email: "alice@example.com", Role: "viewer", IsActive: true}
+	bob := &auth.User{ID: uuid.New(), Email: "bob@example.com", Role: "viewer", IsActive: true}
+
+	alreadyRevoked := now.Add(-time.Hour)
+	tokens := []*auth.RefreshToken{
+		{ID: uuid.New(), UserID: alice.ID},
+		{ID: uuid.New(), UserID: alice.ID},
+		{ID: uuid.New(), UserID: alice.ID, RevokedAt: &alreadyRevoked},
+		{ID: uuid.New(), UserID: bob.ID},
+	}
+
+	revokeAll := func(userID uuid.UUID, revokedAt time.Time) (int, error) {
+		count := 0
+		for _, rt := range tokens {
+			if rt.UserID == userID && rt.RevokedAt == nil {
+				at := revokedAt
+				rt.RevokedAt = &at
+				count++
+			}
+		}
+		return count, nil
+	}
+
+	handler := NewLogoutEverywhereHandler(authService, revokeAll, nil)
+
+	req := httptest.NewRequest("POST", "/auth/logout-everywhere", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "current-token"})
+	req = req.WithContext(contextWithUser(req.Context(), alice))
+	w := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(w, req)
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var resp LogoutEverywhereResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.SessionsRevoked != 2 {
+		t.Errorf("sessions_revoked = %d, want 2", resp.SessionsRevoked)
+	}
+
+	for _, rt := range tokens {
+		switch {
+		case rt.UserID == alice.ID && rt.RevokedAt == nil:
+			t.Errorf("alice's token %s was not revoked", rt.ID)
+		case rt.UserID == bob.ID && rt.RevokedAt != nil:
+			t.Errorf("bob's token %s was revoked", rt.ID)
+		}
+	}
+	if !tokens[2].RevokedAt.Equal(alreadyRevoked) {
+		t.Errorf("already revoked token changed revoked_at to %v", tokens[2].RevokedAt)
+	}
+
+	// Verify cookie was cleared
+	var cleared bool
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "refresh_token" && cookie.MaxAge < 0 {
+			cleared = true
+		}
+	}
+	if !cleared {
+		t.Error("refresh token cookie was not cleared")
+	}
+}
+
+func TestLogoutEverywhereHandler_AuditLogsEvent(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	user := &auth.User{ID: uuid.New(), Email: "alice@example.com", Role: "viewer", IsActive: true}
+
+	revokeAll := func(userID uuid.UUID, revokedAt time.Time) (int, error) {
+		return 3, nil
+	}
+
+	auditLogger := auth.NewInMemoryAuditLogger()
+	handler := NewLogoutEverywhereHandler(authService, revokeAll, auditLogger)
+
+	req := httptest.NewRequest("POST", "/auth/logout-everywhere", nil)
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	req.RemoteAddr = "10.0.0.1:12345"
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	logs := auditLogger.GetLogs()
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 audit log, got %d", len(logs))
+	}
+
+	entry := logs[0]
+	if entry.EventType != auth.AuditLogoutAll {
+		t.Errorf("event type = %v, want %v", entry.EventType, auth.AuditLogoutAll)
+	}
+	if entry.ActorID == nil || *entry.ActorID != user.ID {
+		t.Errorf("actor ID = %v, want %v", entry.ActorID, user.ID)
+	}
+	if entry.Details["sessions_revoked"] != 3 {
+		t.Errorf("sessions_revoked detail = %v, want 3", entry.Details["sessions_revoked"])
+	}
+}
+
+func TestLogoutEverywhereHandler_RequiresUser(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	revokeAll := func(userID uuid.UUID, revokedAt time.Time) (int, error) {
+		t.Error("revoke should not be called without an authenticated user")
+		return 0, nil
+	}
+
+	handler := NewLogoutEverywhereHandler(authService, revokeAll, nil)
+
+	req := httptest.NewRequest("POST", "/auth/logout-everywhere", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+// deviceTokenStore is an in-memory refresh token table for device session tests
+type deviceTokenStore struct {
+	tokens []*auth.RefreshToken
+}
+
+func (s *deviceTokenStore) store(rt *auth.RefreshToken) error {
+	rt.ID = uuid.New()
+	s.tokens = append(s.tokens, rt)
+	return nil
+}
+
+func (s *deviceTokenStore) revokeDevice(userID uuid.UUID, deviceID string, revokedAt time.Time) (int, error) {
+	count := 0
+	for _, rt := range s.tokens {
+		if rt.UserID == userID && rt.DeviceID == deviceID && rt.RevokedAt == nil {
+			at := revokedAt
+			rt.RevokedAt = &at
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *deviceTokenStore) active(deviceID string) int {
+	count := 0
+	for _, rt := range s.tokens {
+		if rt.DeviceID == deviceID && rt.RevokedAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// loginFromDevice performs a login with the given X-Device-ID header and fails the test on a non-200 response
+func loginFromDevice(t *testing.T, handler http.HandlerFunc, email, password, deviceID string) {
+	t.Helper()
+
+	bodyBytes, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	if deviceID != "" {
+		req.Header.Set(DeviceIDHeader, deviceID)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("login from device %q: response code = %d, want %d", deviceID, w.Code, http.StatusOK)
+	}
+}
+
+func newDeviceLoginHandler(t *testing.T, store *deviceTokenStore) (http.HandlerFunc, *auth.User, string) {
+	t.Helper()
+
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	password := "SecurePass123!"
+	passwordHash, err := authService.HashPassword(password)
+	if err != nil {
+		t.Fatalf("setup failed: HashPassword() error = %v", err)
+	}
+	user := &auth.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: passwordHash, Role: "viewer", IsActive: true}
+
+	userByEmail := func(email string) (*auth.User, error) {
+		if email == user.Email {
+			return user, nil
+		}
+		return nil, nil
+	}
+
+	return NewLoginHandler(authService, userByEmail, store.store, store.revokeDevice, nil, nil), user, password
+}
+
+func TestLoginHandler_SameDeviceKeepsOneActiveToken(t *testing.T) {
+	store := &deviceTokenStore{}
+	handler, user, password := newDeviceLoginHandler(t, store)
+
+	for i := 0; i < 3; i++ {
+		loginFromDevice(t, handler, user.Email, password, "laptop-1")
+	}
+
+	if len(store.tokens) != 3 {
+		t.Fatalf("stored %d tokens, want 3", len(store.tokens))
+	}
+	if got := store.active("laptop-1"); got != 1 {
+		t.Errorf("active tokens for device = %d, want 1", got)
+	}
+	if store.tokens[2].RevokedAt != nil {
+		t.Error("the newest token was revoked")
+	}
+	if store.tokens[2].DeviceID != "laptop-1" {
+		t.Errorf("stored device ID = %q, want %q", store.tokens[2].DeviceID, "laptop-1")
+	}
+}
+
+func TestLoginHandler_DifferentDevicesKeepSeparateTokens(t *testing.T) {
+	store := &deviceTokenStore{}
+	handler, user, password := newDeviceLoginHandler(t, store)
+
+	loginFromDevice(t, handler, user.Email, password, "laptop-1")
+	loginFromDevice(t, handler, user.Email, password, "desktop-2")
+	loginFromDevice(t, handler, user.Email, password, "laptop-1")
+
+	if got := store.active("laptop-1"); got != 1 {
+		t.Errorf("active tokens for laptop-1 = %d, want 1", got)
+	}
+	if got := store.active("desktop-2"); got != 1 {
+		t.Errorf("active tokens for desktop-2 = %d, want 1", got)
+	}
+}
+
+func TestLoginHandler_NoDeviceIDDoesNotRevoke(t *testing.T) {
+	store := &deviceTokenStore{}
+	handler, user, password := newDeviceLoginHandler(t, store)
+
+	loginFromDevice(t, handler, user.Email, password, "")
+	loginFromDevice(t, handler, user.Email, password, "")
+
+	if got := store.active(""); got != 2 {
+		t.Errorf("active tokens without a device ID = %d, want 2", got)
+	}
+}
+
+func TestLoginHandler_RejectsOverlongDeviceID(t *testing.T) {
+	store := &deviceTokenStore{}
+	handler, user, password := newDeviceLoginHandler(t, store)
+
+	bodyBytes, _ := json.Marshal(map[string]string{"email": user.Email, "password": password})
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewReader(bodyBytes))
+	req.Header.Set(DeviceIDHeader, strings.Repeat("x", maxDeviceIDLength+1))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if len(store.tokens) != 0 {
+		t.Errorf("stored %d tokens, want 0", len(store.tokens))
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+)
+
+// DefaultReadinessTimeout bounds how long a readiness request waits for all checks
+const DefaultReadinessTimeout = 2 * time.Second
+
+// Subsystem names reported by the readiness handler
+const (
+	ReadinessAuditLog     = "audit_log"
+	ReadinessProfileStore = "profile_store"
+)
+
+// ReadinessCheckFunc probes a subsystem and returns an error if it is unhealthy.
+// Implementations should be cheap and return when ctx is done.
+type ReadinessCheckFunc func(ctx context.Context) error
+
+// ReadinessCheck is a named subsystem probe run on each readiness request
+type ReadinessCheck struct {
+	Name  string
+	Check ReadinessCheckFunc
+}
+
+// ProbeProfileStoreFunc performs a tiny write against the profile store
+// (writing and removing a probe record) to confirm it accepts writes
+type ProbeProfileStoreFunc func(ctx context.Context) error
+
+// AuditLogReadinessCheck checks that the audit sink accepts writes. Loggers
+// that do not implement auth.WritableChecker (stdout, none) are always ready.
+func AuditLogReadinessCheck(logger auth.AuditLogger) ReadinessCheck {
+	return ReadinessCheck{
+		Name: ReadinessAuditLog,
+		Check: func(ctx context.Context) error {
+			checker, ok := logger.(auth.WritableChecker)
+			if !ok {
+				return nil
+			}
+			return checker.CheckWritable()
+		},
+	}
+}
+
+// ProfileStoreReadinessCheck checks that the profile store accepts writes
+func ProfileStoreReadinessCheck(probe ProbeProfileStoreFunc) ReadinessCheck {
+	return ReadinessCheck{
+		Name:  ReadinessProfileStore,
+		Check: ReadinessCheckFunc(probe),
+	}
+}
+
+// NewReadyHandler creates a handler reporting whether the server can serve
+// traffic. All checks run concurrently and must finish within timeout; a
+// check that fails or times out makes the handler respond 503 with the
+// unhealthy subsystem names. Failure details are logged, not returned.
+func NewReadyHandler(timeout time.Duration, checks ...ReadinessCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		results := make([]chan error, len(checks))
+		for i, check := range checks {
+			results[i] = make(chan error, 1)
+			go func(check ReadinessCheck, result chan<- error) {
+				result <- check.Check(ctx)
+			}(check, results[i])
+		}
+
+		unhealthy := make([]string, 0)
+		for i, check := range checks {
+			var err error
+			select {
+			case err = <-results[i]:
+			case <-ctx.Done():
+				// Prefer a result that raced with the deadline
+				select {
+				case err = <-results[i]:
+				default:
+					err = ctx.Err()
+				}
+			}
+			if err != nil {
+				log.Printf("Readiness check %s failed: %v", check.Name, err)
+				unhealthy = append(unhealthy, check.Name)
+			}
+		}
+
+		if len(unhealthy) > 0 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"status":    "unavailable",
+				"unhealthy": unhealthy,
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status": "ready",
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+)
+
+// unwritableAuditLogger is an audit sink whose writability check always fails
+type unwritableAuditLogger struct {
+	auth.NoopAuditLogger
+}
+
+func (unwritableAuditLogger) CheckWritable() error {
+	return errors.New("read-only file system")
+}
+
+type readyResponse struct {
+	Status    string   `json:"status"`
+	Unhealthy []string `json:"unhealthy"`
+}
+
+func serveReady(t *testing.T, handler http.HandlerFunc) (int, readyResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/ready", nil))
+
+	var resp readyResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return w.Code, resp
+}
+
+func healthyStore(ctx context.Context) error {
+	return nil
+}
+
+func TestReadyHandler_AllHealthy(t *testing.T) {
+	handler := NewReadyHandler(time.Second,
+		AuditLogReadinessCheck(auth.NewInMemoryAuditLogger()),
+		ProfileStoreReadinessCheck(healthyStore),
+	)
+
+	code, resp := serveReady(t, handler)
+	if code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", code)
+	}
+	if resp.Status != "ready" {
+		t.Errorf("expected status 'ready', got %q", resp.Status)
+	}
+}
+
+func TestReadyHandler_FailingAuditSink(t *testing.T) {
+	handler := NewReadyHandler(time.Second,
+		AuditLogReadinessCheck(unwritableAuditLogger{}),
+		ProfileStoreReadinessCheck(healthyStore),
+	)
+
+	code, resp := serveReady(t, handler)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", code)
+	}
+	if !reflect.DeepEqual(resp.Unhealthy, []string{ReadinessAuditLog}) {
+		t.Errorf("expected unhealthy [%s], got %v", ReadinessAuditLog, resp.Unhealthy)
+	}
+}
+
+func TestReadyHandler_FailingProfileStore(t *testing.T) {
+	handler := NewReadyHandler(time.Second,
+		AuditLogReadinessCheck(auth.NoopAuditLogger{}),
+		ProfileStoreReadinessCheck(func(ctx context.Context) error {
+			return errors.New("disk full")
+		}),
+	)
+
+	code, resp := serveReady(t, handler)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", code)
+	}
+	if resp.Status != "unavailable" {
+		t.Errorf("expected status 'unavailable', got %q", resp.Status)
+	}
+	if !reflect.DeepEqual(resp.Unhealthy, []string{ReadinessProfileStore}) {
+		t.Errorf("expected unhealthy [%s], got %v", ReadinessProfileStore, resp.Unhealthy)
+	}
+}
+
+func TestReadyHandler_SlowCheckTimesOut(t *testing.T) {
+	handler := NewReadyHandler(20*time.Millisecond,
+		ProfileStoreReadinessCheck(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	)
+
+	start := time.Now()
+	code, resp := serveReady(t, handler)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected readiness check to be time-bounded, took %s", elapsed)
+	}
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", code)
+	}
+	if !reflect.DeepEqual(resp.Unhealthy, []string{ReadinessProfileStore}) {
+		t.Errorf("expected unhealthy [%s], got %v", ReadinessProfileStore, resp.Unhealthy)
+	}
+}
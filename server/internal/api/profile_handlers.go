@@ -0,0 +1,781 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+	"github.com/mark-chris/devtools-sync/server/internal/profiles"
+)
+
+// GetProfileByNameFunc is a function that retrieves a user's profile by name.
+// Matching is case-insensitive (see profiles.NameKey); returns nil if none exists.
+// Trashed profiles are returned too, so handlers can tell them apart.
+type GetProfileByNameFunc func(userID uuid.UUID, name string) (*profiles.Profile, error)
+
+// SaveProfileFunc is a function that creates or updates a profile.
+// Implementations must enforce per-user uniqueness of profiles.NameKey and
+// return profiles.ErrNameConflict on collision.
+type SaveProfileFunc func(p *profiles.Profile) error
+
+// SaveProfileVersionFunc is a function that records a profile version snapshot
+type SaveProfileVersionFunc func(v *profiles.Version) error
+
+// ListProfileVersionsFunc is a function that retrieves every recorded version
+// of a profile
+type ListProfileVersionsFunc func(profileID uuid.UUID) ([]profiles.Version, error)
+
+// NewUploadProfileHandler creates a handler that stores a profile for the
+// authenticated user. Names are normalized before storage; uploading a name
+// that differs from an existing profile only by case is rejected with 409, and
+// a profile listing the same extension ID more than once with 422. Uploading
+// over a trashed profile replaces it and takes it out of the trash.
+//
+// Every upload increments the profile's version and records a snapshot of it;
+// the optional tag query parameter labels that version (see profiles.Version).
+func NewUploadProfileHandler(
+	authService *auth.AuthService,
+	getProfileByName GetProfileByNameFunc,
+	saveProfile SaveProfileFunc,
+	saveVersion SaveProfileVersionFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		// Parse request
+		var req profiles.Profile
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid request body",
+			})
+			return
+		}
+
+		// Normalize and validate name
+		name := profiles.NormalizeName(req.Name)
+		if err := profiles.ValidateName(name); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		tag := profiles.NormalizeTag(r.URL.Query().Get("tag"))
+		if r.URL.Query().Has("tag") {
+			if err := profiles.ValidateTag(tag); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+				return
+			}
+		}
+
+		// Reject duplicate extension entries, which would install twice on pull
+		if duplicates := profiles.DuplicateExtensionIDs(req.Extensions); len(duplicates) > 0 {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":      "Profile contains duplicate extension IDs: " + strings.Join(duplicates, ", "),
+				"duplicates": duplicates,
+			})
+			return
+		}
+
+		// Look up an existing profile with the same normalized name
+		existing, err := getProfileByName(user.ID, name)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to look up profile",
+			})
+			return
+		}
+
+		if existing != nil && existing.Name != name {
+			writeJSON(w, http.StatusConflict, map[string]string{
+				"error": "A profile named '" + existing.Name + "' already exists",
+			})
+			return
+		}
+
+		now := authService.Now()
+		profile := &profiles.Profile{
+			ID:          uuid.New(),
+			UserID:      user.ID,
+			Name:        name,
+			CreatedAt:   now,
+			UpdatedAt:   req.UpdatedAt,
+			Extensions:  req.Extensions,
+			Description: req.Description,
+			Settings:    req.Settings,
+			Keybindings: req.Keybindings,
+			Version:     1,
+			ModifiedAt:  now,
+		}
+		if existing != nil {
+			profile.ID = existing.ID
+			profile.CreatedAt = existing.CreatedAt
+			profile.Version = existing.Version + 1
+		}
+		if profile.UpdatedAt.IsZero() {
+			profile.UpdatedAt = now
+		}
+		if profile.Extensions == nil {
+			profile.Extensions = []profiles.Extension{}
+		}
+
+		// Store profile
+		if err := saveProfile(profile); err != nil {
+			if errors.Is(err, profiles.ErrNameConflict) {
+				writeJSON(w, http.StatusConflict, map[string]string{
+					"error": "A profile with this name already exists",
+				})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to store profile",
+			})
+			return
+		}
+
+		if err := saveVersion(&profiles.Version{
+			ProfileID: profile.ID,
+			Number:    profile.Version,
+			CreatedAt: now,
+			Tag:       tag,
+			Profile:   *profile,
+		}); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to store profile version",
+			})
+			return
+		}
+
+		status := http.StatusOK
+		if existing == nil || existing.Trashed() {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, profile)
+	}
+}
+
+// PatchProfileRequest holds the metadata fields a PATCH may change. Nil
+// fields are left as stored; extensions cannot be changed this way.
+type PatchProfileRequest struct {
+	Description *string    `json:"description,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// NewPatchProfileHandler creates a handler for PATCH /api/v1/profiles/{name}
+// that updates only the metadata fields present in the request. UpdatedAt is
+// set to the given time or, if omitted, to now, so an empty body "touches"
+// the profile. ModifiedAt is always set to now.
+func NewPatchProfileHandler(
+	authService *auth.AuthService,
+	getProfileByName GetProfileByNameFunc,
+	saveProfile SaveProfileFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		name := profiles.NormalizeName(r.PathValue("name"))
+		if err := profiles.ValidateName(name); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		// Parse request
+		var req PatchProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid request body",
+			})
+			return
+		}
+
+		existing, err := getProfileByName(user.ID, name)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to look up profile",
+			})
+			return
+		}
+		if existing == nil || existing.Trashed() {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": "Profile not found",
+			})
+			return
+		}
+
+		// Copy so a failed save leaves the stored profile untouched
+		profile := *existing
+		if req.Description != nil {
+			profile.Description = *req.Description
+		}
+		profile.ModifiedAt = authService.Now()
+		profile.UpdatedAt = profile.ModifiedAt
+		if req.UpdatedAt != nil {
+			profile.UpdatedAt = *req.UpdatedAt
+		}
+
+		if err := saveProfile(&profile); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to store profile",
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, profile)
+	}
+}
+
+// ListUserProfilesFunc is a function that retrieves every profile of a user
+type ListUserProfilesFunc func(userID uuid.UUID) ([]profiles.Profile, error)
+
+// ProfileSummary describes a profile in the detailed profile list
+type ProfileSummary struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	Extensions  int       `json:"extensions"`
+}
+
+// NewListProfilesHandler creates a handler for GET /api/v1/profiles that lists
+// the authenticated user's profiles sorted by name, leaving out trashed ones.
+// By default each profile is a ProfileSummary; with names_only=true the
+// response is the legacy list of names. With modified_since=<RFC 3339 time>
+// only profiles the server modified strictly after that time are listed, so
+// clients can fetch just what changed since the newest ModifiedAt they saw.
+func NewListProfilesHandler(listProfiles ListUserProfilesFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		namesOnly := false
+		if param := r.URL.Query().Get("names_only"); param != "" {
+			value, err := strconv.ParseBool(param)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": "Invalid names_only value, use true or false",
+				})
+				return
+			}
+			namesOnly = value
+		}
+
+		var modifiedSince time.Time
+		if param := r.URL.Query().Get("modified_since"); param != "" {
+			value, err := time.Parse(time.RFC3339, param)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": "Invalid modified_since value, use an RFC 3339 timestamp",
+				})
+				return
+			}
+			modifiedSince = value
+		}
+
+		list, err := listProfiles(user.ID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to list profiles",
+			})
+			return
+		}
+		list = profiles.FilterActive(list)
+		if !modifiedSince.IsZero() {
+			list = profiles.FilterModifiedSince(list, modifiedSince)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+		if namesOnly {
+			names := make([]string, len(list))
+			for i, p := range list {
+				names[i] = p.Name
+			}
+			writeJSON(w, http.StatusOK, names)
+			return
+		}
+
+		summaries := make([]ProfileSummary, len(list))
+		for i, p := range list {
+			summaries[i] = ProfileSummary{
+				Name:        p.Name,
+				Description: p.Description,
+				CreatedAt:   p.CreatedAt,
+				UpdatedAt:   p.UpdatedAt,
+				ModifiedAt:  p.ModifiedAt,
+				Extensions:  len(p.Extensions),
+			}
+		}
+		writeJSON(w, http.StatusOK, summaries)
+	}
+}
+
+// ListAllProfilesFunc is a function that retrieves every stored profile across all users
+type ListAllProfilesFunc func() ([]profiles.Profile, error)
+
+// StaleProfile describes a profile in the stale profile report
+type StaleProfile struct {
+	Name       string    `json:"name"`
+	UserID     uuid.UUID `json:"user_id"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Extensions int       `json:"extensions"`
+}
+
+// StaleProfilesResponse is the response body for the stale profile report
+type StaleProfilesResponse struct {
+	OlderThan string         `json:"older_than"`
+	Profiles  []StaleProfile `json:"profiles"`
+}
+
+// NewStaleProfilesHandler creates a handler for GET /api/v1/profiles/stale that
+// reports profiles not updated within the older_than query parameter (e.g. "90d",
+// default 90 days). It is an admin report and must be wrapped with RequireRole("admin").
+func NewStaleProfilesHandler(
+	authService *auth.AuthService,
+	listAllProfiles ListAllProfilesFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		olderThanParam := r.URL.Query().Get("older_than")
+		olderThan := profiles.DefaultStaleAge
+		if olderThanParam == "" {
+			olderThanParam = "90d"
+		} else {
+			age, err := profiles.ParseAge(olderThanParam)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": "Invalid older_than value, use a duration such as 90d or 12h",
+				})
+				return
+			}
+			olderThan = age
+		}
+
+		all, err := listAllProfiles()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to list profiles",
+			})
+			return
+		}
+
+		// Trashed profiles are purged on their own schedule
+		stale := profiles.FilterStale(profiles.FilterActive(all), authService.Now(), olderThan)
+
+		resp := StaleProfilesResponse{
+			OlderThan: olderThanParam,
+			Profiles:  make([]StaleProfile, len(stale)),
+		}
+		for i, p := range stale {
+			resp.Profiles[i] = StaleProfile{
+				Name:       p.Name,
+				UserID:     p.UserID,
+				UpdatedAt:  p.UpdatedAt,
+				Extensions: len(p.Extensions),
+			}
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// NewDeleteProfileHandler creates a handler for DELETE /api/v1/profiles/{name}
+// that moves the profile to the trash instead of deleting it. Trashed profiles
+// are hidden from the profile list and can be restored until the trash
+// janitor purges them. Responds 204, or 404 if there is no such profile or it
+// is already in the trash.
+func NewDeleteProfileHandler(
+	authService *auth.AuthService,
+	getProfileByName GetProfileByNameFunc,
+	saveProfile SaveProfileFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		name := profiles.NormalizeName(r.PathValue("name"))
+		if err := profiles.ValidateName(name); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		existing, err := getProfileByName(user.ID, name)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to look up profile",
+			})
+			return
+		}
+		if existing == nil || existing.Trashed() {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": "Profile not found",
+			})
+			return
+		}
+
+		// Copy so a failed save leaves the stored profile untouched
+		profile := *existing
+		deletedAt := authService.Now()
+		profile.DeletedAt = &deletedAt
+
+		if err := saveProfile(&profile); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to delete profile",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// TrashedProfile describes a profile in the trash
+type TrashedProfile struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	PurgeAt     time.Time `json:"purge_at"`
+	Extensions  int       `json:"extensions"`
+}
+
+// NewListTrashHandler creates a handler for GET /api/v1/profiles/trash that
+// lists the authenticated user's trashed profiles, most recently deleted
+// first, with the time each will be purged after retention
+func NewListTrashHandler(listProfiles ListUserProfilesFunc, retention time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		list, err := listProfiles(user.ID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to list profiles",
+			})
+			return
+		}
+
+		trashed := profiles.FilterTrashed(list)
+		resp := make([]TrashedProfile, len(trashed))
+		for i, p := range trashed {
+			resp[i] = TrashedProfile{
+				Name:        p.Name,
+				Description: p.Description,
+				DeletedAt:   *p.DeletedAt,
+				PurgeAt:     p.DeletedAt.Add(retention),
+				Extensions:  len(p.Extensions),
+			}
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// NewRestoreProfileHandler creates a handler for
+// POST /api/v1/profiles/{name}/restore that takes a profile out of the trash
+// and returns it. Responds 404 if no trashed profile has that name.
+func NewRestoreProfileHandler(
+	authService *auth.AuthService,
+	getProfileByName GetProfileByNameFunc,
+	saveProfile SaveProfileFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		name := profiles.NormalizeName(r.PathValue("name"))
+		if err := profiles.ValidateName(name); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		existing, err := getProfileByName(user.ID, name)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to look up profile",
+			})
+			return
+		}
+		if existing == nil || !existing.Trashed() {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": "Profile not found in trash",
+			})
+			return
+		}
+
+		// Copy so a failed save leaves the stored profile untouched
+		profile := *existing
+		profile.DeletedAt = nil
+		profile.ModifiedAt = authService.Now()
+
+		if err := saveProfile(&profile); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to restore profile",
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, profile)
+	}
+}
+
+// RenameProfileRequest represents the rename profile request body
+type RenameProfileRequest struct {
+	Name string `json:"name"`
+}
+
+// NewRenameProfileHandler creates a handler for
+// POST /api/v1/profiles/{name}/rename that gives a profile the name in the
+// request body, keeping its ID, extensions and version history. Responds 404
+// if there is no such profile or it is in the trash, and 409 if another
+// profile, trashed or not, already has the new name after normalization.
+// Renaming to a name that differs only by case is allowed.
+func NewRenameProfileHandler(
+	authService *auth.AuthService,
+	getProfileByName GetProfileByNameFunc,
+	saveProfile SaveProfileFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		name := profiles.NormalizeName(r.PathValue("name"))
+		if err := profiles.ValidateName(name); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		var req RenameProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid request body",
+			})
+			return
+		}
+		newName := profiles.NormalizeName(req.Name)
+		if err := profiles.ValidateName(newName); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		existing, err := getProfileByName(user.ID, name)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to look up profile",
+			})
+			return
+		}
+		if existing == nil || existing.Trashed() {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": "Profile not found",
+			})
+			return
+		}
+
+		target, err := getProfileByName(user.ID, newName)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to look up profile",
+			})
+			return
+		}
+		if target != nil && target.ID != existing.ID {
+			writeJSON(w, http.StatusConflict, map[string]string{
+				"error": "A profile named '" + target.Name + "' already exists",
+			})
+			return
+		}
+
+		// Copy so a failed save leaves the stored profile untouched
+		profile := *existing
+		profile.Name = newName
+		profile.ModifiedAt = authService.Now()
+
+		if err := saveProfile(&profile); err != nil {
+			if errors.Is(err, profiles.ErrNameConflict) {
+				writeJSON(w, http.StatusConflict, map[string]string{
+					"error": "A profile with this name already exists",
+				})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to rename profile",
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, profile)
+	}
+}
+
+// ProfileTag describes a tag in the tag list of a profile
+type ProfileTag struct {
+	Tag       string    `json:"tag"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewListProfileTagsHandler creates a handler for
+// GET /api/v1/profiles/{name}/tags that lists the profile's tags with the
+// version each names, newest version first. Responds 404 if there is no such
+// profile or it is in the trash.
+func NewListProfileTagsHandler(
+	getProfileByName GetProfileByNameFunc,
+	listVersions ListProfileVersionsFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		versions, ok := profileVersions(w, r, getProfileByName, listVersions)
+		if !ok {
+			return
+		}
+
+		tags := make([]ProfileTag, 0)
+		for _, v := range profiles.Tagged(versions) {
+			tags = append(tags, ProfileTag{Tag: v.Tag, Version: v.Number, CreatedAt: v.CreatedAt})
+		}
+
+		writeJSON(w, http.StatusOK, tags)
+	}
+}
+
+// NewGetProfileByTagHandler creates a handler for
+// GET /api/v1/profiles/{name}/tags/{tag} that returns the profile as it was
+// at the version the tag names. Responds 404 if there is no such profile, it
+// is in the trash, or no version carries the tag.
+func NewGetProfileByTagHandler(
+	getProfileByName GetProfileByNameFunc,
+	listVersions ListProfileVersionsFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tag := profiles.NormalizeTag(r.PathValue("tag"))
+		if err := profiles.ValidateTag(tag); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		versions, ok := profileVersions(w, r, getProfileByName, listVersions)
+		if !ok {
+			return
+		}
+
+		version := profiles.LatestTagged(versions, tag)
+		if version == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": "Tag not found",
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, version.Profile)
+	}
+}
+
+// profileVersions looks up the versions of the authenticated user's profile
+// named in the path, writing an error response and returning false if it
+// cannot be found
+func profileVersions(
+	w http.ResponseWriter,
+	r *http.Request,
+	getProfileByName GetProfileByNameFunc,
+	listVersions ListProfileVersionsFunc,
+) ([]profiles.Version, bool) {
+	// Get user from context (set by RequireAuth middleware)
+	user, ok := r.Context().Value(userContextKey).(*auth.User)
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{
+			"error": "User not found in context",
+		})
+		return nil, false
+	}
+
+	name := profiles.NormalizeName(r.PathValue("name"))
+	if err := profiles.ValidateName(name); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+
+	existing, err := getProfileByName(user.ID, name)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to look up profile",
+		})
+		return nil, false
+	}
+	if existing == nil || existing.Trashed() {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "Profile not found",
+		})
+		return nil, false
+	}
+
+	versions, err := listVersions(existing.ID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list profile versions",
+		})
+		return nil, false
+	}
+	return versions, true
+}
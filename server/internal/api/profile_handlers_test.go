@@ -0,0 +1,1044 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+	"github.com/mark-chris/devtools-sync/server/internal/middleware"
+	"github.com/mark-chris/devtools-sync/server/internal/profiles"
+)
+
+// fakeProfileStore is an in-memory profile store keyed by user and normalized name
+type fakeProfileStore struct {
+	profiles map[string]*profiles.Profile
+	versions map[uuid.UUID][]profiles.Version
+}
+
+func newFakeProfileStore() *fakeProfileStore {
+	return &fakeProfileStore{
+		profiles: make(map[string]*profiles.Profile),
+		versions: make(map[uuid.UUID][]profiles.Version),
+	}
+}
+
+func (s *fakeProfileStore) key(userID uuid.UUID, name string) string {
+	return userID.String() + "/" + profiles.NameKey(name)
+}
+
+func (s *fakeProfileStore) get(userID uuid.UUID, name string) (*profiles.Profile, error) {
+	return s.profiles[s.key(userID, name)], nil
+}
+
+func (s *fakeProfileStore) save(p *profiles.Profile) error {
+	k := s.key(p.UserID, p.Name)
+	if existing, ok := s.profiles[k]; ok && existing.ID != p.ID {
+		return profiles.ErrNameConflict
+	}
+	// Profiles are saved by ID, so a rename drops the old name
+	for other, existing := range s.profiles {
+		if existing.ID == p.ID && other != k {
+			delete(s.profiles, other)
+		}
+	}
+	s.profiles[k] = p
+	return nil
+}
+
+func (s *fakeProfileStore) list(userID uuid.UUID) ([]profiles.Profile, error) {
+	var list []profiles.Profile
+	for _, p := range s.profiles {
+		if p.UserID == userID {
+			list = append(list, *p)
+		}
+	}
+	return list, nil
+}
+
+func (s *fakeProfileStore) saveVersion(v *profiles.Version) error {
+	s.versions[v.ProfileID] = append(s.versions[v.ProfileID], *v)
+	return nil
+}
+
+func (s *fakeProfileStore) listVersions(profileID uuid.UUID) ([]profiles.Version, error) {
+	return s.versions[profileID], nil
+}
+
+func uploadProfile(t *testing.T, handler http.Handler, user *auth.User, body map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/api/v1/profiles", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestUploadProfileHandler_CreatesProfile(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{
+		"name":       "  work ",
+		"extensions": []map[string]interface{}{{"id": "golang.go", "version": "0.40.0", "enabled": true}},
+	})
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	stored, _ := store.get(user.ID, "work")
+	if stored == nil {
+		t.Fatal("profile was not stored")
+	}
+	if stored.Name != "work" {
+		t.Errorf("stored name = %q, want %q (trimmed)", stored.Name, "work")
+	}
+	if len(stored.Extensions) != 1 {
+		t.Errorf("stored %d extensions, want 1", len(stored.Extensions))
+	}
+}
+
+func TestUploadProfileHandler_ServerAssignsModifiedAt(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	// A client with a slow clock sends old times for both fields
+	w := uploadProfile(t, handler, user, map[string]interface{}{
+		"name":        "work",
+		"updated_at":  "2020-01-01T00:00:00Z",
+		"modified_at": "2020-01-01T00:00:00Z",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	stored, _ := store.get(user.ID, "work")
+	if want := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC); !stored.UpdatedAt.Equal(want) {
+		t.Errorf("updated_at = %v, want the client's %v", stored.UpdatedAt, want)
+	}
+	if !stored.ModifiedAt.Equal(now) {
+		t.Errorf("modified_at = %v, want server time %v", stored.ModifiedAt, now)
+	}
+}
+
+func TestUploadProfileHandler_StoresSettingsAndKeybindings(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{
+		"name":        "work",
+		"settings":    map[string]interface{}{"editor.fontSize": 14},
+		"keybindings": []map[string]string{{"key": "ctrl+k", "command": "noop"}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	stored, _ := store.get(user.ID, "work")
+	if string(stored.Settings) != `{"editor.fontSize":14}` {
+		t.Errorf("stored settings = %s", stored.Settings)
+	}
+	if string(stored.Keybindings) != `[{"command":"noop","key":"ctrl+k"}]` {
+		t.Errorf("stored keybindings = %s", stored.Keybindings)
+	}
+
+	var returned profiles.Profile
+	if err := json.NewDecoder(w.Body).Decode(&returned); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if string(returned.Settings) != string(stored.Settings) || string(returned.Keybindings) != string(stored.Keybindings) {
+		t.Errorf("response settings/keybindings = %s / %s, want the stored ones", returned.Settings, returned.Keybindings)
+	}
+}
+
+func TestUploadProfileHandler_StoresExtensionMetadata(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{
+		"name": "work",
+		"extensions": []map[string]interface{}{{
+			"id": "golang.go", "version": "0.40.0", "enabled": true,
+			"display_name": "Go", "publisher": "golang", "description": "Rich Go language support",
+		}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	stored, _ := store.get(user.ID, "work")
+	ext := stored.Extensions[0]
+	if ext.DisplayName != "Go" || ext.Publisher != "golang" || ext.Description != "Rich Go language support" {
+		t.Errorf("stored extension = %+v, want the manifest metadata kept", ext)
+	}
+}
+
+func TestUploadProfileHandler_CaseOnlyDifferenceConflicts(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	if w := uploadProfile(t, handler, user, map[string]interface{}{"name": "Work"}); w.Code != http.StatusCreated {
+		t.Fatalf("first upload code = %d, want %d", w.Code, http.StatusCreated)
+	}
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{"name": "work "})
+	if w.Code != http.StatusConflict {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	if len(store.profiles) != 1 {
+		t.Errorf("store has %d profiles, want 1", len(store.profiles))
+	}
+}
+
+func TestUploadProfileHandler_WhitespaceVariantUpdatesExisting(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	if w := uploadProfile(t, handler, user, map[string]interface{}{"name": "Work"}); w.Code != http.StatusCreated {
+		t.Fatalf("first upload code = %d, want %d", w.Code, http.StatusCreated)
+	}
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{
+		"name":       " Work ",
+		"extensions": []map[string]interface{}{{"id": "golang.go", "version": "0.40.0", "enabled": true}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	stored, _ := store.get(user.ID, "Work")
+	if len(stored.Extensions) != 1 {
+		t.Errorf("expected existing profile to be updated, got %d extensions", len(stored.Extensions))
+	}
+}
+
+func TestUploadProfileHandler_SameNameDifferentUsers(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	alice := &auth.User{ID: uuid.New(), Email: "alice@example.com", Role: "viewer"}
+	bob := &auth.User{ID: uuid.New(), Email: "bob@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	if w := uploadProfile(t, handler, alice, map[string]interface{}{"name": "Work"}); w.Code != http.StatusCreated {
+		t.Fatalf("alice upload code = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if w := uploadProfile(t, handler, bob, map[string]interface{}{"name": "work"}); w.Code != http.StatusCreated {
+		t.Errorf("bob upload code = %d, want %d", w.Code, http.StatusCreated)
+	}
+}
+
+func TestUploadProfileHandler_InvalidName(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{"name": "   "})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUploadProfileHandler_StoreConflictReturns409(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	// Simulate a concurrent insert caught by the unique index
+	getProfileByName := func(userID uuid.UUID, name string) (*profiles.Profile, error) {
+		return nil, nil
+	}
+	saveProfile := func(p *profiles.Profile) error {
+		return profiles.ErrNameConflict
+	}
+
+	handler := NewUploadProfileHandler(authService, getProfileByName, saveProfile, newFakeProfileStore().saveVersion)
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{"name": "work"})
+	if w.Code != http.StatusConflict {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+func TestUploadProfileHandler_DuplicateExtensionsReturns422(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{
+		"name": "work",
+		"extensions": []map[string]interface{}{
+			{"id": "golang.go", "version": "0.40.0", "enabled": true},
+			{"id": "ms-python.python", "version": "1.0.0", "enabled": true},
+			{"id": "Golang.Go", "version": "0.41.0", "enabled": true},
+		},
+	})
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusUnprocessableEntity, w.Body.String())
+	}
+
+	var response struct {
+		Error      string   `json:"error"`
+		Duplicates []string `json:"duplicates"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Duplicates) != 1 || response.Duplicates[0] != "Golang.Go" {
+		t.Errorf("duplicates = %v, want [Golang.Go]", response.Duplicates)
+	}
+	if stored, _ := store.get(user.ID, "work"); stored != nil {
+		t.Error("profile with duplicate extensions should not be stored")
+	}
+}
+
+func TestUploadProfileHandler_UniqueExtensionsAccepted(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	w := uploadProfile(t, handler, user, map[string]interface{}{
+		"name": "work",
+		"extensions": []map[string]interface{}{
+			{"id": "golang.go", "version": "0.40.0", "enabled": true},
+			{"id": "ms-python.python", "version": "1.0.0", "enabled": true},
+		},
+	})
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("response code = %d, want %d (body: %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+}
+
+func patchProfile(t *testing.T, handler http.Handler, user *auth.User, name, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("PATCH", "/api/v1/profiles/"+name, bytes.NewReader([]byte(body)))
+	req.SetPathValue("name", name)
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func seedProfile(store *fakeProfileStore, userID uuid.UUID, updatedAt time.Time) *profiles.Profile {
+	p := &profiles.Profile{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        "work",
+		CreatedAt:   updatedAt,
+		UpdatedAt:   updatedAt,
+		ModifiedAt:  updatedAt,
+		Description: "old description",
+		Extensions: []profiles.Extension{
+			{ID: "golang.go", Version: "0.40.0", Enabled: true},
+			{ID: "ms-python.python", Version: "1.0.0", Enabled: false},
+		},
+	}
+	_ = store.save(p)
+	return p
+}
+
+func TestPatchProfileHandler_UpdatesDescriptionOnly(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	created := now.Add(-48 * time.Hour)
+	seeded := seedProfile(store, user.ID, created)
+
+	handler := NewPatchProfileHandler(authService, store.get, store.save)
+	w := patchProfile(t, handler, user, "work", `{"description":"laptop setup"}`)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	stored, _ := store.get(user.ID, "work")
+	if stored.Description != "laptop setup" {
+		t.Errorf("description = %q, want %q", stored.Description, "laptop setup")
+	}
+	if !stored.UpdatedAt.Equal(now) {
+		t.Errorf("updated_at = %v, want %v", stored.UpdatedAt, now)
+	}
+	if stored.ID != seeded.ID || !stored.CreatedAt.Equal(created) {
+		t.Errorf("identity changed: id %v created_at %v", stored.ID, stored.CreatedAt)
+	}
+	if len(stored.Extensions) != 2 || stored.Extensions[0].ID != "golang.go" || stored.Extensions[1].Enabled {
+		t.Errorf("extensions changed: %+v", stored.Extensions)
+	}
+}
+
+func TestPatchProfileHandler_UpdatedAtOnlyKeepsDescription(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	seedProfile(store, user.ID, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	handler := NewPatchProfileHandler(authService, store.get, store.save)
+	w := patchProfile(t, handler, user, "work", `{"updated_at":"2024-03-01T00:00:00Z"}`)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	stored, _ := store.get(user.ID, "work")
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !stored.UpdatedAt.Equal(want) {
+		t.Errorf("updated_at = %v, want %v", stored.UpdatedAt, want)
+	}
+	// A backdated UpdatedAt is still a modification
+	if !stored.ModifiedAt.Equal(now) {
+		t.Errorf("modified_at = %v, want %v", stored.ModifiedAt, now)
+	}
+	if stored.Description != "old description" {
+		t.Errorf("description = %q, want it unchanged", stored.Description)
+	}
+	if len(stored.Extensions) != 2 {
+		t.Errorf("stored %d extensions, want 2", len(stored.Extensions))
+	}
+}
+
+func TestPatchProfileHandler_NotFound(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	handler := NewPatchProfileHandler(authService, store.get, store.save)
+	w := patchProfile(t, handler, user, "missing", `{"description":"x"}`)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestPatchProfileHandler_InvalidBody(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	seedProfile(store, user.ID, time.Now())
+
+	handler := NewPatchProfileHandler(authService, store.get, store.save)
+	w := patchProfile(t, handler, user, "work", `{"description":`)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+// listProfilesFor returns a ListUserProfilesFunc serving two profiles for userID
+func listProfilesFor(userID uuid.UUID) ListUserProfilesFunc {
+	updated := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	return func(id uuid.UUID) ([]profiles.Profile, error) {
+		if id != userID {
+			return nil, nil
+		}
+		return []profiles.Profile{
+			{Name: "work", Description: "office laptop", UpdatedAt: updated, Extensions: []profiles.Extension{{ID: "golang.go"}, {ID: "ms-python.python"}}},
+			{Name: "home", UpdatedAt: updated, Extensions: []profiles.Extension{}},
+		}, nil
+	}
+}
+
+func listProfilesRequest(t *testing.T, handler http.Handler, user *auth.User, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/v1/profiles"+query, nil)
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestListProfilesHandler_DefaultReturnsMetadata(t *testing.T) {
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	handler := NewListProfilesHandler(listProfilesFor(user.ID))
+
+	for _, query := range []string{"", "?names_only=false"} {
+		w := listProfilesRequest(t, handler, user, query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: response code = %d, want %d", query, w.Code, http.StatusOK)
+		}
+
+		var resp []ProfileSummary
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("%q: failed to decode detailed list: %v", query, err)
+		}
+		if len(resp) != 2 || resp[0].Name != "home" || resp[1].Name != "work" {
+			t.Fatalf("%q: got %+v, want home and work sorted by name", query, resp)
+		}
+		if resp[1].Extensions != 2 || resp[1].Description != "office laptop" || resp[1].UpdatedAt.IsZero() {
+			t.Errorf("%q: work summary = %+v, want 2 extensions, description and updated_at", query, resp[1])
+		}
+	}
+}
+
+func TestListProfilesHandler_NamesOnly(t *testing.T) {
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	handler := NewListProfilesHandler(listProfilesFor(user.ID))
+
+	w := listProfilesRequest(t, handler, user, "?names_only=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var names []string
+	if err := json.NewDecoder(w.Body).Decode(&names); err != nil {
+		t.Fatalf("failed to decode names: %v", err)
+	}
+	if len(names) != 2 || names[0] != "home" || names[1] != "work" {
+		t.Errorf("names = %v, want [home work]", names)
+	}
+}
+
+func TestListProfilesHandler_InvalidNamesOnly(t *testing.T) {
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	handler := NewListProfilesHandler(listProfilesFor(user.ID))
+
+	w := listProfilesRequest(t, handler, user, "?names_only=maybe")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestListProfilesHandler_ModifiedSinceBoundary(t *testing.T) {
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	since := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	list := []profiles.Profile{
+		{UserID: user.ID, Name: "at-since", ModifiedAt: since},
+		{UserID: user.ID, Name: "just-after", ModifiedAt: since.Add(time.Millisecond)},
+		{UserID: user.ID, Name: "before", ModifiedAt: since.Add(-time.Hour)},
+		// The client-supplied UpdatedAt does not count, only the server's ModifiedAt
+		{UserID: user.ID, Name: "backdated", UpdatedAt: since.Add(-time.Hour), ModifiedAt: since.Add(24 * time.Hour)},
+		{UserID: user.ID, Name: "future-clock", UpdatedAt: since.Add(24 * time.Hour), ModifiedAt: since.Add(-time.Hour)},
+	}
+	handler := NewListProfilesHandler(func(userID uuid.UUID) ([]profiles.Profile, error) { return list, nil })
+
+	// The same instant in another zone is the same boundary
+	for _, value := range []string{"2026-06-01T12:00:00Z", "2026-06-01T14:00:00%2B02:00"} {
+		w := listProfilesRequest(t, handler, user, "?names_only=true&modified_since="+value)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: response code = %d, want %d (body: %s)", value, w.Code, http.StatusOK, w.Body.String())
+		}
+		var names []string
+		if err := json.NewDecoder(w.Body).Decode(&names); err != nil {
+			t.Fatalf("%s: failed to decode names: %v", value, err)
+		}
+		if len(names) != 2 || names[0] != "backdated" || names[1] != "just-after" {
+			t.Errorf("%s: names = %v, want [backdated just-after]", value, names)
+		}
+	}
+
+	w := listProfilesRequest(t, handler, user, "?modified_since=yesterday")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid modified_since: response code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestStaleProfilesHandler(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+	day := 24 * time.Hour
+
+	listAllProfiles := func() ([]profiles.Profile, error) {
+		return []profiles.Profile{
+			{Name: "recent", UserID: uuid.New(), UpdatedAt: now.Add(-10 * day)},
+			{Name: "idle", UserID: uuid.New(), UpdatedAt: now.Add(-120 * day)},
+			{Name: "boundary", UserID: uuid.New(), UpdatedAt: now.Add(-30 * day)},
+		}, nil
+	}
+
+	handler := NewStaleProfilesHandler(authService, listAllProfiles)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"idle"}},
+		{"?older_than=90d", []string{"idle"}},
+		{"?older_than=30d", []string{"idle"}},
+		{"?older_than=29d", []string{"idle", "boundary"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/profiles/stale"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("response code = %d, want %d", w.Code, http.StatusOK)
+			}
+
+			var resp StaleProfilesResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			var got []string
+			for _, p := range resp.Profiles {
+				got = append(got, p.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("stale profiles = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("stale profiles = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestStaleProfilesHandler_InvalidOlderThan(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	listAllProfiles := func() ([]profiles.Profile, error) {
+		t.Error("profiles should not be listed for an invalid request")
+		return nil, nil
+	}
+
+	handler := NewStaleProfilesHandler(authService, listAllProfiles)
+
+	req := httptest.NewRequest("GET", "/api/v1/profiles/stale?older_than=soon", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("response code = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+// profileRequest sends a body-less request for the profile name to handler
+func profileRequest(t *testing.T, handler http.Handler, user *auth.User, method, path, name string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if name != "" {
+		req.SetPathValue("name", name)
+	}
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestDeleteProfileHandler_TrashAndRestore(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	seeded := seedProfile(store, user.ID, now.Add(-time.Hour))
+
+	deleteHandler := NewDeleteProfileHandler(authService, store.get, store.save)
+	listHandler := NewListProfilesHandler(store.list)
+	trashHandler := NewListTrashHandler(store.list, profiles.DefaultTrashRetention)
+	restoreHandler := NewRestoreProfileHandler(authService, store.get, store.save)
+
+	w := profileRequest(t, deleteHandler, user, "DELETE", "/api/v1/profiles/work", "work")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete response code = %d, want %d (body: %s)", w.Code, http.StatusNoContent, w.Body.String())
+	}
+
+	// Trashed profiles are kept but hidden from the normal list
+	stored, _ := store.get(user.ID, "work")
+	if stored == nil || !stored.Trashed() || !stored.DeletedAt.Equal(now) {
+		t.Fatalf("expected profile to be kept in the trash, got %+v", stored)
+	}
+	w = listProfilesRequest(t, listHandler, user, "?names_only=true")
+	var names []string
+	if err := json.NewDecoder(w.Body).Decode(&names); err != nil {
+		t.Fatalf("failed to decode names: %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("names = %v, want trashed profile excluded", names)
+	}
+
+	w = profileRequest(t, trashHandler, user, "GET", "/api/v1/profiles/trash", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("trash response code = %d, want %d", w.Code, http.StatusOK)
+	}
+	var trash []TrashedProfile
+	if err := json.NewDecoder(w.Body).Decode(&trash); err != nil {
+		t.Fatalf("failed to decode trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].Name != "work" || trash[0].Extensions != 2 {
+		t.Fatalf("trash = %+v, want the deleted profile", trash)
+	}
+	if !trash[0].DeletedAt.Equal(now) || !trash[0].PurgeAt.Equal(now.Add(profiles.DefaultTrashRetention)) {
+		t.Errorf("trash times = %v / %v, want deletion now and purge after retention", trash[0].DeletedAt, trash[0].PurgeAt)
+	}
+
+	// Deleting again finds nothing to delete
+	if w := profileRequest(t, deleteHandler, user, "DELETE", "/api/v1/profiles/work", "work"); w.Code != http.StatusNotFound {
+		t.Errorf("second delete response code = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	w = profileRequest(t, restoreHandler, user, "POST", "/api/v1/profiles/work/restore", "work")
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore response code = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	stored, _ = store.get(user.ID, "work")
+	if stored.Trashed() || stored.ID != seeded.ID || len(stored.Extensions) != 2 {
+		t.Errorf("expected the original profile restored, got %+v", stored)
+	}
+	if !stored.ModifiedAt.Equal(now) {
+		t.Errorf("modified_at = %v, want restore time %v", stored.ModifiedAt, now)
+	}
+	w = listProfilesRequest(t, listHandler, user, "?names_only=true")
+	names = nil
+	if err := json.NewDecoder(w.Body).Decode(&names); err != nil {
+		t.Fatalf("failed to decode names: %v", err)
+	}
+	if len(names) != 1 || names[0] != "work" {
+		t.Errorf("names = %v, want restored profile listed", names)
+	}
+}
+
+func TestRestoreProfileHandler_NotInTrash(t *testing.T) {
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	seedProfile(store, user.ID, time.Now())
+
+	handler := NewRestoreProfileHandler(auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!")), store.get, store.save)
+	for _, name := range []string{"work", "missing"} {
+		w := profileRequest(t, handler, user, "POST", "/api/v1/profiles/"+name+"/restore", name)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: response code = %d, want %d", name, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestTrashedProfileHiddenFromPatchAndReplacedByUpload(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	seeded := seedProfile(store, user.ID, time.Now())
+	deletedAt := time.Now()
+	seeded.DeletedAt = &deletedAt
+
+	patchHandler := NewPatchProfileHandler(authService, store.get, store.save)
+	if w := patchProfile(t, patchHandler, user, "work", `{"description":"x"}`); w.Code != http.StatusNotFound {
+		t.Errorf("patch response code = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	uploadHandler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+	w := uploadProfile(t, uploadHandler, user, map[string]interface{}{
+		"name":       "work",
+		"extensions": []map[string]interface{}{{"id": "golang.go", "version": "0.41.0", "enabled": true}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("upload response code = %d, want %d (body: %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+	stored, _ := store.get(user.ID, "work")
+	if stored.Trashed() || len(stored.Extensions) != 1 {
+		t.Errorf("expected upload to replace the trashed profile, got %+v", stored)
+	}
+}
+
+// uploadTaggedProfile uploads a profile named work with one extension at
+// version, tagged with tag ("" sends no tag)
+func uploadTaggedProfile(t *testing.T, handler http.Handler, user *auth.User, version, tag string) *httptest.ResponseRecorder {
+	t.Helper()
+	path := "/api/v1/profiles"
+	if tag != "" {
+		path += "?tag=" + url.QueryEscape(tag)
+	}
+	bodyBytes, _ := json.Marshal(map[string]interface{}{
+		"name":       "work",
+		"extensions": []map[string]interface{}{{"id": "golang.go", "version": version, "enabled": true}},
+	})
+	req := httptest.NewRequest("POST", path, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestUploadProfileHandler_TagsCreatedVersion(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	uploadTaggedProfile(t, handler, user, "0.40.0", "")
+	w := uploadTaggedProfile(t, handler, user, "0.41.0", "v2025.1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp profiles.Profile
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Version != 2 {
+		t.Errorf("response version = %d, want 2", resp.Version)
+	}
+
+	stored, _ := store.get(user.ID, "work")
+	versions, _ := store.listVersions(stored.ID)
+	if len(versions) != 2 {
+		t.Fatalf("recorded %d versions, want 2", len(versions))
+	}
+	if versions[0].Number != 1 || versions[0].Tag != "" {
+		t.Errorf("first version = %d tagged %q, want 1 untagged", versions[0].Number, versions[0].Tag)
+	}
+	if versions[1].Number != 2 || versions[1].Tag != "v2025.1" || versions[1].Profile.Extensions[0].Version != "0.41.0" {
+		t.Errorf("second version = %+v, want version 2 tagged v2025.1 with golang.go 0.41.0", versions[1])
+	}
+}
+
+func TestUploadProfileHandler_InvalidTag(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	handler := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	for _, tag := range []string{"has space", "bad/tag", " "} {
+		if w := uploadTaggedProfile(t, handler, user, "0.40.0", tag); w.Code != http.StatusBadRequest {
+			t.Errorf("tag %q: response code = %d, want %d", tag, w.Code, http.StatusBadRequest)
+		}
+	}
+	if stored, _ := store.get(user.ID, "work"); stored != nil {
+		t.Error("profile stored despite an invalid tag")
+	}
+}
+
+// tagRequest fetches the work profile at tag from handler
+func tagRequest(t *testing.T, handler http.Handler, user *auth.User, tag string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/v1/profiles/work/tags/"+tag, nil)
+	req.SetPathValue("name", "work")
+	req.SetPathValue("tag", tag)
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetProfileByTagHandler(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	upload := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+
+	uploadTaggedProfile(t, upload, user, "0.40.0", "stable")
+	uploadTaggedProfile(t, upload, user, "0.41.0", "v2025.1")
+	uploadTaggedProfile(t, upload, user, "0.42.0", "")
+
+	handler := NewGetProfileByTagHandler(store.get, store.listVersions)
+	w := tagRequest(t, handler, user, "stable")
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var got profiles.Profile
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Version != 1 || got.Extensions[0].Version != "0.40.0" {
+		t.Errorf("stable = version %d with golang.go %s, want version 1 with 0.40.0", got.Version, got.Extensions[0].Version)
+	}
+
+	// Tagging a newer upload moves the tag forward
+	uploadTaggedProfile(t, upload, user, "0.43.0", "stable")
+	w = tagRequest(t, handler, user, "stable")
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Version != 4 || got.Extensions[0].Version != "0.43.0" {
+		t.Errorf("stable = version %d with golang.go %s, want version 4 with 0.43.0", got.Version, got.Extensions[0].Version)
+	}
+
+	if w := tagRequest(t, handler, user, "missing"); w.Code != http.StatusNotFound {
+		t.Errorf("missing tag: response code = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// Tags are listed with the version they name, newest first
+	w = profileRequest(t, NewListProfileTagsHandler(store.get, store.listVersions), user, "GET", "/api/v1/profiles/work/tags", "work")
+	var tags []ProfileTag
+	if err := json.Unmarshal(w.Body.Bytes(), &tags); err != nil {
+		t.Fatalf("failed to decode tag list: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Tag != "stable" || tags[0].Version != 4 || tags[1].Tag != "v2025.1" || tags[1].Version != 2 {
+		t.Errorf("tags = %+v, want stable@4 then v2025.1@2", tags)
+	}
+}
+
+func TestUploadProfileHandler_GzipUploadOverHTTP(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+
+	upload := NewUploadProfileHandler(authService, store.get, store.save, store.saveVersion)
+	// The same request body middleware order as cmd/main.go
+	chain := middleware.DecompressRequest(middleware.MaxBodySize(10 * 1024 * 1024)(upload))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chain.ServeHTTP(w, r.WithContext(contextWithUser(r.Context(), user)))
+	}))
+	defer srv.Close()
+
+	// Large enough to pass the agent's compression threshold
+	extensions := make([]map[string]interface{}, 200)
+	for i := range extensions {
+		extensions[i] = map[string]interface{}{"id": fmt.Sprintf("publisher.extension-%03d", i), "version": "1.0.0", "enabled": true}
+	}
+	raw, _ := json.Marshal(map[string]interface{}{"name": "work", "extensions": extensions})
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, _ = zw.Write(raw)
+	_ = zw.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v1/profiles", bytes.NewReader(compressed.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("response code = %d, want %d (body: %s)", resp.StatusCode, http.StatusCreated, body)
+	}
+	stored, _ := store.get(user.ID, "work")
+	if stored == nil || len(stored.Extensions) != len(extensions) {
+		t.Fatalf("stored profile = %+v, want %d extensions", stored, len(extensions))
+	}
+}
+
+func renameProfile(t *testing.T, handler http.Handler, user *auth.User, name, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/v1/profiles/"+name+"/rename", bytes.NewReader([]byte(body)))
+	req.SetPathValue("name", name)
+	req = req.WithContext(contextWithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestRenameProfileHandler_Renames(t *testing.T) {
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	authService.SetClock(auth.ClockFunc(func() time.Time { return now }))
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	seeded := seedProfile(store, user.ID, now.Add(-time.Hour))
+
+	handler := NewRenameProfileHandler(authService, store.get, store.save)
+	w := renameProfile(t, handler, user, "work", `{"name": " laptop "}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if old, _ := store.get(user.ID, "work"); old != nil {
+		t.Errorf("expected the old name to be gone, got %+v", old)
+	}
+	renamed, _ := store.get(user.ID, "laptop")
+	if renamed == nil || renamed.Name != "laptop" || renamed.ID != seeded.ID || len(renamed.Extensions) != 2 {
+		t.Fatalf("expected the profile under its new trimmed name, got %+v", renamed)
+	}
+	if !renamed.ModifiedAt.Equal(now) || !renamed.UpdatedAt.Equal(seeded.UpdatedAt) {
+		t.Errorf("times = modified %v / updated %v, want modified now and updated unchanged", renamed.ModifiedAt, renamed.UpdatedAt)
+	}
+}
+
+func TestRenameProfileHandler_CaseOnlyChange(t *testing.T) {
+	store := newFakeProfileStore()
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	seedProfile(store, user.ID, time.Now())
+
+	handler := NewRenameProfileHandler(auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!")), store.get, store.save)
+	w := renameProfile(t, handler, user, "work", `{"name": "Work"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if renamed, _ := store.get(user.ID, "work"); renamed == nil || renamed.Name != "Work" {
+		t.Errorf("expected the profile to be renamed to Work, got %+v", renamed)
+	}
+}
+
+func TestRenameProfileHandler_Errors(t *testing.T) {
+	user := &auth.User{ID: uuid.New(), Email: "user@example.com", Role: "viewer"}
+	deletedAt := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		from string
+		body string
+		want int
+	}{
+		{"taken by another profile", "work", `{"name": "HOME"}`, http.StatusConflict},
+		{"taken by a trashed profile", "work", `{"name": "old"}`, http.StatusConflict},
+		{"missing profile", "missing", `{"name": "other"}`, http.StatusNotFound},
+		{"trashed profile", "old", `{"name": "other"}`, http.StatusNotFound},
+		{"invalid new name", "work", `{"name": "a/b"}`, http.StatusBadRequest},
+		{"empty new name", "work", `{"name": "  "}`, http.StatusBadRequest},
+		{"invalid body", "work", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeProfileStore()
+			seedProfile(store, user.ID, time.Now())
+			_ = store.save(&profiles.Profile{ID: uuid.New(), UserID: user.ID, Name: "home"})
+			_ = store.save(&profiles.Profile{ID: uuid.New(), UserID: user.ID, Name: "old", DeletedAt: &deletedAt})
+
+			handler := NewRenameProfileHandler(auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!")), store.get, store.save)
+			w := renameProfile(t, handler, user, tt.from, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("response code = %d, want %d (body: %s)", w.Code, tt.want, w.Body.String())
+			}
+			if work, _ := store.get(user.ID, "work"); work == nil {
+				t.Error("the original profile should be unchanged")
+			}
+		})
+	}
+}
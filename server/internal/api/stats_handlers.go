@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+)
+
+// CountFunc is a function that returns the number of stored records of one
+// kind. Implementations should count rather than load the records.
+type CountFunc func() (int, error)
+
+// ServerStatsCounters are the counts aggregated by the server stats handler
+type ServerStatsCounters struct {
+	// Users counts every user account that is not deleted
+	Users CountFunc
+	// ActiveUsers counts user accounts that are active
+	ActiveUsers CountFunc
+	// Profiles counts stored profiles, excluding the trash
+	Profiles CountFunc
+	// RefreshTokens counts stored refresh tokens
+	RefreshTokens CountFunc
+	// PendingInvites counts invites that are neither accepted nor expired
+	PendingInvites CountFunc
+}
+
+// ServerStatsResponse is the response body of the server stats handler
+type ServerStatsResponse struct {
+	Users          int `json:"users"`
+	ActiveUsers    int `json:"active_users"`
+	Profiles       int `json:"profiles"`
+	RefreshTokens  int `json:"refresh_tokens"`
+	PendingInvites int `json:"pending_invites"`
+}
+
+// NewServerStatsHandler creates a handler for GET /api/v1/admin/stats that
+// reports an overview of the server's users, profiles, refresh tokens and
+// pending invites. Only admins may call it.
+func NewServerStatsHandler(counters ServerStatsCounters) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		user, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		if user.Role != "admin" {
+			writeJSON(w, http.StatusForbidden, map[string]string{
+				"error": "Insufficient permissions",
+			})
+			return
+		}
+
+		var resp ServerStatsResponse
+		for _, c := range []struct {
+			count CountFunc
+			into  *int
+		}{
+			{counters.Users, &resp.Users},
+			{counters.ActiveUsers, &resp.ActiveUsers},
+			{counters.Profiles, &resp.Profiles},
+			{counters.RefreshTokens, &resp.RefreshTokens},
+			{counters.PendingInvites, &resp.PendingInvites},
+		} {
+			n, err := c.count()
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{
+					"error": "Failed to collect server stats",
+				})
+				return
+			}
+			*c.into = n
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+)
+
+// count returns a CountFunc reporting n
+func count(n int) CountFunc {
+	return func() (int, error) { return n, nil }
+}
+
+func testStatsCounters() ServerStatsCounters {
+	return ServerStatsCounters{
+		Users:          count(12),
+		ActiveUsers:    count(9),
+		Profiles:       count(40),
+		RefreshTokens:  count(17),
+		PendingInvites: count(3),
+	}
+}
+
+func serveStats(t *testing.T, user *auth.User, counters ServerStatsCounters) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/stats", nil)
+	if user != nil {
+		req = req.WithContext(contextWithUser(req.Context(), user))
+	}
+	w := httptest.NewRecorder()
+	NewServerStatsHandler(counters)(w, req)
+	return w
+}
+
+func TestServerStatsHandler_ReturnsCounts(t *testing.T) {
+	w := serveStats(t, &auth.User{ID: uuid.New(), Role: "admin"}, testStatsCounters())
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ServerStatsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := ServerStatsResponse{Users: 12, ActiveUsers: 9, Profiles: 40, RefreshTokens: 17, PendingInvites: 3}
+	if resp != want {
+		t.Errorf("got %+v, want %+v", resp, want)
+	}
+}
+
+func TestServerStatsHandler_RequiresAdmin(t *testing.T) {
+	for _, role := range []string{"viewer", "manager"} {
+		t.Run(role, func(t *testing.T) {
+			w := serveStats(t, &auth.User{ID: uuid.New(), Role: role}, testStatsCounters())
+			if w.Code != http.StatusForbidden {
+				t.Errorf("expected status 403, got %d", w.Code)
+			}
+		})
+	}
+
+	if w := serveStats(t, nil, testStatsCounters()); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without a user, got %d", w.Code)
+	}
+}
+
+func TestServerStatsHandler_CountFailure(t *testing.T) {
+	counters := testStatsCounters()
+	counters.RefreshTokens = func() (int, error) { return 0, errors.New("database unavailable") }
+
+	w := serveStats(t, &auth.User{ID: uuid.New(), Role: "admin"}, counters)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"regexp"
 	"time"
@@ -126,8 +127,8 @@ func NewInviteHandler(
 			TokenHash: authService.HashToken(inviteToken),
 			Role:      req.Role,
 			InvitedBy: user.ID,
-			ExpiresAt: time.Now().Add(48 * time.Hour),
-			CreatedAt: time.Now(),
+			ExpiresAt: authService.Now().Add(48 * time.Hour),
+			CreatedAt: authService.Now(),
 		}
 
 		// Store invite
@@ -158,7 +159,8 @@ func NewInviteHandler(
 // GetInviteByTokenFunc is a function that retrieves an invite by token hash
 type GetInviteByTokenFunc func(tokenHash string) (*auth.UserInvite, error)
 
-// CreateUserFunc is a function that creates a new user
+// CreateUserFunc is a function that creates a new user.
+// Implementations must return auth.ErrEmailExists if the email is taken.
 type CreateUserFunc func(user *auth.User) error
 
 // MarkInviteAcceptedFunc is a function that marks an invite as accepted
@@ -216,7 +218,7 @@ func NewAcceptInviteHandler(
 		}
 
 		// Check if expired
-		if time.Now().After(invite.ExpiresAt) {
+		if authService.Now().After(invite.ExpiresAt) {
 			writeJSON(w, http.StatusBadRequest, map[string]string{
 				"error": "Invalid or expired invite token",
 			})
@@ -241,7 +243,7 @@ func NewAcceptInviteHandler(
 		}
 
 		// Create user
-		now := time.Now()
+		now := authService.Now()
 		user := &auth.User{
 			ID:           uuid.New(),
 			Email:        invite.Email,
@@ -285,3 +287,144 @@ func NewAcceptInviteHandler(
 		})
 	}
 }
+
+// CreateUserRequest represents the create user request body
+type CreateUserRequest struct {
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	DisplayName string `json:"display_name"`
+	Password    string `json:"password"`
+}
+
+// CreateUserResponse represents the create user response body
+type CreateUserResponse struct {
+	ID          string `json:"id"`
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	DisplayName string `json:"display_name"`
+}
+
+// NewCreateUserHandler creates a handler that creates an active user directly,
+// bypassing the invite flow, for scripted provisioning. Only admins and
+// managers may call it, and they can only grant their own role or below.
+// If auditLogger is non-nil, user creation events are audit-logged.
+func NewCreateUserHandler(
+	authService *auth.AuthService,
+	createUser CreateUserFunc,
+	auditLogger auth.AuditLogger,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user from context (set by RequireAuth middleware)
+		creator, ok := r.Context().Value(userContextKey).(*auth.User)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error": "User not found in context",
+			})
+			return
+		}
+
+		if creator.Role != "admin" && creator.Role != "manager" {
+			writeJSON(w, http.StatusForbidden, map[string]string{
+				"error": "Insufficient permissions to create users",
+			})
+			return
+		}
+
+		// Parse request
+		var req CreateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid request body",
+			})
+			return
+		}
+
+		// Validate email
+		if !emailRegex.MatchString(req.Email) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid email address",
+			})
+			return
+		}
+
+		// Validate role
+		validRoles := map[string]bool{
+			"viewer":  true,
+			"manager": true,
+			"admin":   true,
+		}
+
+		if !validRoles[req.Role] {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid role. Must be viewer, manager, or admin",
+			})
+			return
+		}
+
+		// Check role hierarchy — creator can only grant same level or below
+		if !canInviteRole(creator.Role, req.Role) {
+			writeJSON(w, http.StatusForbidden, map[string]string{
+				"error": "Insufficient permissions to create a user with this role",
+			})
+			return
+		}
+
+		// Validate password
+		if err := auth.ValidatePassword(req.Password); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		// Hash password
+		passwordHash, err := authService.HashPassword(req.Password)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to hash password",
+			})
+			return
+		}
+
+		// Create user
+		now := authService.Now()
+		user := &auth.User{
+			ID:           uuid.New(),
+			Email:        req.Email,
+			PasswordHash: passwordHash,
+			DisplayName:  req.DisplayName,
+			Role:         req.Role,
+			IsActive:     true,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+
+		if err := createUser(user); err != nil {
+			if errors.Is(err, auth.ErrEmailExists) {
+				writeJSON(w, http.StatusConflict, map[string]string{
+					"error": "A user with this email already exists",
+				})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create user",
+			})
+			return
+		}
+
+		// Audit log
+		if auditLogger != nil {
+			logEntry := auth.CreateUserCreatedAuditLog(creator.ID, user.ID, user.Email, user.Role)
+			logEntry.ClientIP = middleware.GetClientIP(r)
+			logEntry.UserAgent = r.UserAgent()
+			_ = auditLogger.Log(logEntry)
+		}
+
+		writeJSON(w, http.StatusCreated, CreateUserResponse{
+			ID:          user.ID.String(),
+			Email:       user.Email,
+			Role:        user.Role,
+			DisplayName: user.DisplayName,
+		})
+	}
+}
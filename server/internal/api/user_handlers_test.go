@@ -702,3 +702,158 @@ func TestAcceptInviteHandler_AuditLogsAcceptance(t *testing.T) {
 		t.Errorf("user agent = %v, want TestAgent/1.0", entry.UserAgent)
 	}
 }
+
+// serveCreateUser posts body to a create user handler as creator
+func serveCreateUser(t *testing.T, creator *auth.User, body map[string]string, createUser CreateUserFunc, auditLogger auth.AuditLogger) *httptest.ResponseRecorder {
+	t.Helper()
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	handler := NewCreateUserHandler(authService, createUser, auditLogger)
+
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/users", bytes.NewReader(bodyBytes))
+	req = req.WithContext(contextWithUser(req.Context(), creator))
+	req.RemoteAddr = "10.0.0.1:12345"
+	req.Header.Set("User-Agent", "TestAgent/1.0")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateUserHandler_CreatesActiveUser(t *testing.T) {
+	adminUser := &auth.User{ID: uuid.New(), Email: "admin@example.com", Role: "admin"}
+
+	var created *auth.User
+	createUser := func(user *auth.User) error {
+		created = user
+		return nil
+	}
+	auditLogger := auth.NewInMemoryAuditLogger()
+
+	w := serveCreateUser(t, adminUser, map[string]string{
+		"email":        "ci-bot@example.com",
+		"role":         "viewer",
+		"display_name": "CI Bot",
+		"password":     "SecurePass123!",
+	}, createUser, auditLogger)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if created == nil {
+		t.Fatal("user was not created")
+	}
+	if !created.IsActive || created.Email != "ci-bot@example.com" || created.Role != "viewer" || created.DisplayName != "CI Bot" {
+		t.Errorf("unexpected created user: %+v", created)
+	}
+	if created.PasswordHash == "" || created.PasswordHash == "SecurePass123!" {
+		t.Error("expected password to be hashed")
+	}
+
+	var response CreateUserResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.ID != created.ID.String() || response.Email != created.Email {
+		t.Errorf("response = %+v, want created user %s", response, created.ID)
+	}
+
+	logs := auditLogger.GetLogs()
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 audit log, got %d", len(logs))
+	}
+	entry := logs[0]
+	if entry.EventType != auth.AuditUserCreated {
+		t.Errorf("event type = %v, want %v", entry.EventType, auth.AuditUserCreated)
+	}
+	if entry.ActorID == nil || *entry.ActorID != adminUser.ID {
+		t.Errorf("actor ID = %v, want %v", entry.ActorID, adminUser.ID)
+	}
+	if entry.TargetID == nil || *entry.TargetID != created.ID {
+		t.Errorf("target ID = %v, want %v", entry.TargetID, created.ID)
+	}
+	if entry.ClientIP != "10.0.0.1" {
+		t.Errorf("client IP = %v, want 10.0.0.1", entry.ClientIP)
+	}
+}
+
+func TestCreateUserHandler_RoleHierarchy(t *testing.T) {
+	tests := []struct {
+		creatorRole string
+		targetRole  string
+		wantStatus  int
+	}{
+		{"admin", "admin", http.StatusCreated},
+		{"admin", "manager", http.StatusCreated},
+		{"admin", "viewer", http.StatusCreated},
+		{"manager", "manager", http.StatusCreated},
+		{"manager", "viewer", http.StatusCreated},
+		{"manager", "admin", http.StatusForbidden},
+		{"viewer", "viewer", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.creatorRole+" creates "+tt.targetRole, func(t *testing.T) {
+			creator := &auth.User{ID: uuid.New(), Email: "creator@example.com", Role: tt.creatorRole}
+			createCalled := false
+			createUser := func(user *auth.User) error {
+				createCalled = true
+				return nil
+			}
+
+			w := serveCreateUser(t, creator, map[string]string{
+				"email":    "new@example.com",
+				"role":     tt.targetRole,
+				"password": "SecurePass123!",
+			}, createUser, nil)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if createCalled != (tt.wantStatus == http.StatusCreated) {
+				t.Errorf("createUser called = %v, want %v", createCalled, tt.wantStatus == http.StatusCreated)
+			}
+		})
+	}
+}
+
+func TestCreateUserHandler_DuplicateEmail(t *testing.T) {
+	adminUser := &auth.User{ID: uuid.New(), Email: "admin@example.com", Role: "admin"}
+	createUser := func(user *auth.User) error {
+		return auth.ErrEmailExists
+	}
+	auditLogger := auth.NewInMemoryAuditLogger()
+
+	w := serveCreateUser(t, adminUser, map[string]string{
+		"email":    "taken@example.com",
+		"role":     "viewer",
+		"password": "SecurePass123!",
+	}, createUser, auditLogger)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+	if len(auditLogger.GetLogs()) != 0 {
+		t.Error("expected no audit log for a rejected creation")
+	}
+}
+
+func TestCreateUserHandler_InvalidPassword(t *testing.T) {
+	adminUser := &auth.User{ID: uuid.New(), Email: "admin@example.com", Role: "admin"}
+	createUser := func(user *auth.User) error {
+		t.Error("createUser should not be called with an invalid password")
+		return nil
+	}
+
+	for _, password := range []string{"short", "alllowercase123!", "NoSpecialChars123"} {
+		w := serveCreateUser(t, adminUser, map[string]string{
+			"email":    "new@example.com",
+			"role":     "viewer",
+			"password": password,
+		}, createUser, nil)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("password %q: expected 400, got %d", password, w.Code)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, so the auth middleware can tell keys
+// apart from JWT access tokens sent as the same bearer credential
+const APIKeyPrefix = "dts_"
+
+// GenerateAPIKey generates a new random API key. Store only its HashToken hash.
+func (s *AuthService) GenerateAPIKey() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// APIKeyDisplayPrefixLength is how much of a key is stored in the clear as
+// KeyPrefix, so users can tell their keys apart
+const APIKeyDisplayPrefixLength = 8
+
+// IsAPIKey reports whether a bearer credential is an API key rather than a JWT
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// Usable reports whether the key is neither revoked nor expired at now
+func (k *APIKey) Usable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
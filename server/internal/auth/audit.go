@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"fmt"
+	"io"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,11 +19,15 @@ const (
 	AuditRefreshSuccess     AuditEvent = "auth.refresh.success"
 	AuditRefreshFailure     AuditEvent = "auth.refresh.failure"
 	AuditLogout             AuditEvent = "auth.logout"
+	AuditLogoutAll          AuditEvent = "auth.logout.all"
 	AuditSessionRevoked     AuditEvent = "auth.session.revoked"
+	AuditAPIKeyCreated      AuditEvent = "auth.apikey.created"
+	AuditAPIKeyRevoked      AuditEvent = "auth.apikey.revoked"
 
 	// User management events
 	AuditInviteCreated      AuditEvent = "user.invite.created"
 	AuditInviteAccepted     AuditEvent = "user.invite.accepted"
+	AuditUserCreated        AuditEvent = "user.created"
 )
 
 // AuditActorType represents the type of actor performing the action
@@ -52,6 +59,13 @@ type AuditLogger interface {
 	Log(entry *AuditLog) error
 }
 
+// WritableChecker is implemented by audit loggers whose sink can stop
+// accepting writes at runtime, such as a file on a full or read-only disk.
+// CheckWritable must be cheap and must not add entries to the log.
+type WritableChecker interface {
+	CheckWritable() error
+}
+
 // InMemoryAuditLogger is a simple in-memory audit logger for development
 type InMemoryAuditLogger struct {
 	logs []AuditLog
@@ -81,6 +95,41 @@ func (l *InMemoryAuditLogger) GetLogs() []AuditLog {
 	return l.logs
 }
 
+// NoopAuditLogger discards all audit log entries
+type NoopAuditLogger struct{}
+
+// Log discards the entry
+func (NoopAuditLogger) Log(entry *AuditLog) error {
+	return nil
+}
+
+// WriterAuditLogger writes audit log entries as JSON lines to an io.Writer
+// such as os.Stdout. Safe for concurrent use.
+type WriterAuditLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterAuditLogger creates an audit logger that writes to w
+func NewWriterAuditLogger(w io.Writer) *WriterAuditLogger {
+	return &WriterAuditLogger{w: w}
+}
+
+// Log writes an audit log entry as a single JSON line
+func (l *WriterAuditLogger) Log(entry *AuditLog) error {
+	line, err := encodeAuditLogLine(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
 // CreateLoginAuditLog creates an audit log for login attempts
 func CreateLoginAuditLog(success bool, userID *uuid.UUID, email, clientIP, userAgent string) *AuditLog {
 	eventType := AuditLoginFailure
@@ -122,3 +171,19 @@ func CreateInviteAuditLog(inviterID, inviteID uuid.UUID, email, role string) *Au
 		},
 	}
 }
+
+// CreateUserCreatedAuditLog creates an audit log for a user created directly
+// by an administrator, bypassing the invite flow
+func CreateUserCreatedAuditLog(creatorID, userID uuid.UUID, email, role string) *AuditLog {
+	return &AuditLog{
+		EventType:  AuditUserCreated,
+		ActorType:  ActorTypeUser,
+		ActorID:    &creatorID,
+		TargetType: "user",
+		TargetID:   &userID,
+		Details: map[string]interface{}{
+			"email": email,
+			"role":  role,
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package auth
+
+import "time"
+
+// Clock provides the current time. Expiry checks go through a Clock so tests
+// can move time across boundaries instead of crafting past/future timestamps.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to the Clock interface
+type ClockFunc func() time.Time
+
+// Now returns f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the default Clock backed by time.Now
+var SystemClock Clock = ClockFunc(time.Now)
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeClock is a manually advanced Clock for expiry tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestAuthService_DefaultClockIsSystemTime(t *testing.T) {
+	service := NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+
+	before := time.Now()
+	got := service.Now()
+	after := time.Now()
+
+	if got.Before(before) || got.After(after) {
+		t.Errorf("Now() = %v, want between %v and %v", got, before, after)
+	}
+}
+
+func TestValidateAccessToken_ClockAdvancesPastExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	service := NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	service.SetClock(clock)
+
+	user := &User{ID: uuid.New(), Email: "test@example.com", Role: "admin"}
+	token, err := service.GenerateAccessToken(user)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+
+	// Just before the 15 minute expiry the token is still valid
+	clock.Advance(15*time.Minute - time.Second)
+	if _, err := service.ValidateAccessToken(token); err != nil {
+		t.Errorf("ValidateAccessToken() before expiry error = %v, want nil", err)
+	}
+
+	// Just after expiry it is rejected
+	clock.Advance(2 * time.Second)
+	if _, err := service.ValidateAccessToken(token); err == nil {
+		t.Error("ValidateAccessToken() after expiry error = nil, want error")
+	}
+}
+
+func TestValidateInviteToken_ClockAdvancesPastExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	service := NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	service.SetClock(clock)
+
+	inviteData, token, err := CreateInviteData(service, "new@example.com", "viewer", uuid.New().String())
+	if err != nil {
+		t.Fatalf("CreateInviteData() error = %v", err)
+	}
+
+	clock.Advance(48 * time.Hour)
+	if !ValidateInviteToken(service, inviteData, token) {
+		t.Error("ValidateInviteToken() at exact expiry = false, want true")
+	}
+
+	clock.Advance(time.Nanosecond)
+	if ValidateInviteToken(service, inviteData, token) {
+		t.Error("ValidateInviteToken() after expiry = true, want false")
+	}
+}
@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultAuditLogMaxSize is the size in bytes at which the audit log is rotated
+	DefaultAuditLogMaxSize = 100 * 1024 * 1024
+
+	// DefaultAuditLogMaxFiles is the number of rotated audit log files kept
+	DefaultAuditLogMaxFiles = 5
+)
+
+// FileAuditLoggerOptions configures a FileAuditLogger
+type FileAuditLoggerOptions struct {
+	// MaxSize is the size in bytes at which the log is rotated.
+	// Zero uses DefaultAuditLogMaxSize.
+	MaxSize int64
+
+	// MaxFiles is the number of rotated files kept (path.1 ... path.N).
+	// Zero uses DefaultAuditLogMaxFiles.
+	MaxFiles int
+
+	// FlushInterval buffers writes and flushes them on this interval.
+	// Zero flushes after every entry.
+	FlushInterval time.Duration
+}
+
+// auditLogRecord is the JSON line written for each audit log entry
+type auditLogRecord struct {
+	ID         uuid.UUID              `json:"id"`
+	EventType  AuditEvent             `json:"event_type"`
+	ActorType  AuditActorType         `json:"actor_type"`
+	ActorID    *uuid.UUID             `json:"actor_id,omitempty"`
+	GroupID    *uuid.UUID             `json:"group_id,omitempty"`
+	TargetType string                 `json:"target_type,omitempty"`
+	TargetID   *uuid.UUID             `json:"target_id,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	ClientIP   string                 `json:"client_ip,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// encodeAuditLogLine fills in a missing ID and timestamp and encodes the
+// entry as a newline-terminated JSON line
+func encodeAuditLogLine(entry *AuditLog) ([]byte, error) {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	line, err := json.Marshal(auditLogRecord{
+		ID:         entry.ID,
+		EventType:  entry.EventType,
+		ActorType:  entry.ActorType,
+		ActorID:    entry.ActorID,
+		GroupID:    entry.GroupID,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Details:    entry.Details,
+		ClientIP:   entry.ClientIP,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt.UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit log entry: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+// FileAuditLogger writes audit log entries as JSON lines to a file, rotating
+// it once it reaches a size threshold. Safe for concurrent use.
+type FileAuditLogger struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	buffered bool
+	file     *os.File
+	writer   *bufio.Writer
+	size     int64
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopped  sync.Once
+}
+
+// NewFileAuditLogger opens (or creates) the audit log at path for appending.
+// Call Close on shutdown to flush buffered entries.
+func NewFileAuditLogger(path string, opts FileAuditLoggerOptions) (*FileAuditLogger, error) {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultAuditLogMaxSize
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultAuditLogMaxFiles
+	}
+
+	l := &FileAuditLogger{
+		path:     path,
+		maxSize:  opts.MaxSize,
+		maxFiles: opts.MaxFiles,
+		buffered: opts.FlushInterval > 0,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	if l.buffered {
+		go l.flushLoop(opts.FlushInterval)
+	} else {
+		close(l.doneCh)
+	}
+
+	return l, nil
+}
+
+func (l *FileAuditLogger) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+
+	l.file = file
+	l.writer = bufio.NewWriter(file)
+	l.size = info.Size()
+	return nil
+}
+
+func (l *FileAuditLogger) flushLoop(interval time.Duration) {
+	defer close(l.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.Flush(); err != nil {
+				log.Printf("WARNING: failed to flush audit log: %v", err)
+			}
+		case <-l.stopCh:
+			return
+		}
+	}
+}
+
+// Log writes an audit log entry as a single JSON line
+func (l *FileAuditLogger) Log(entry *AuditLog) error {
+	line, err := encodeAuditLogLine(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.writer.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	if !l.buffered {
+		if err := l.writer.Flush(); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rotate shifts path -> path.1 -> ... -> path.N, dropping the oldest file,
+// and reopens an empty log. Caller must hold l.mu.
+func (l *FileAuditLogger) rotate() error {
+	if err := l.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	l.file = nil
+
+	oldest := rotatedAuditLogPath(l.path, l.maxFiles)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old audit log: %w", err)
+	}
+	for i := l.maxFiles - 1; i >= 1; i-- {
+		src := rotatedAuditLogPath(l.path, i)
+		if err := os.Rename(src, rotatedAuditLogPath(l.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+	if err := os.Rename(l.path, rotatedAuditLogPath(l.path, 1)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	return l.open()
+}
+
+func rotatedAuditLogPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// CheckWritable verifies the log is open and its directory accepts writes
+// (needed for rotation) by creating and removing a small probe file next to it
+func (l *FileAuditLogger) CheckWritable() error {
+	l.mu.Lock()
+	closed := l.file == nil
+	l.mu.Unlock()
+	if closed {
+		return fmt.Errorf("audit log is closed")
+	}
+
+	probe, err := os.CreateTemp(filepath.Dir(l.path), "."+filepath.Base(l.path)+".probe-*")
+	if err != nil {
+		return fmt.Errorf("audit log directory is not writable: %w", err)
+	}
+	defer func() { _ = os.Remove(probe.Name()) }()
+
+	_, writeErr := probe.Write([]byte{'\n'})
+	closeErr := probe.Close()
+	if writeErr != nil {
+		return fmt.Errorf("audit log directory is not writable: %w", writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("audit log directory is not writable: %w", closeErr)
+	}
+	return nil
+}
+
+// Flush writes any buffered entries to the file
+func (l *FileAuditLogger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	if err := l.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Close stops the background flush loop, flushes buffered entries and
+// closes the file. Safe to call multiple times.
+func (l *FileAuditLogger) Close() error {
+	l.stopped.Do(func() {
+		close(l.stopCh)
+	})
+	<-l.doneCh
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	flushErr := l.writer.Flush()
+	closeErr := l.file.Close()
+	l.file = nil
+	if flushErr != nil {
+		return fmt.Errorf("failed to write audit log: %w", flushErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to close audit log: %w", closeErr)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func readAuditLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("audit log line is not valid JSON: %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	return lines
+}
+
+func TestFileAuditLogger_WritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewFileAuditLogger(path, FileAuditLoggerOptions{})
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+	defer func() { _ = logger.Close() }()
+
+	userID := uuid.New()
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := CreateLoginAuditLog(true, &userID, "user@example.com", "10.0.0.1", "agent/1.0")
+	entry.CreatedAt = createdAt
+
+	if err := logger.Log(entry); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	// Unbuffered: entry is on disk before Close
+	lines := readAuditLines(t, path)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+
+	line := lines[0]
+	if line["id"] != entry.ID.String() {
+		t.Errorf("expected id %s, got %v", entry.ID, line["id"])
+	}
+	if line["event_type"] != string(AuditLoginSuccess) {
+		t.Errorf("expected event_type %s, got %v", AuditLoginSuccess, line["event_type"])
+	}
+	if line["actor_id"] != userID.String() {
+		t.Errorf("expected actor_id %s, got %v", userID, line["actor_id"])
+	}
+	if line["client_ip"] != "10.0.0.1" {
+		t.Errorf("expected client_ip 10.0.0.1, got %v", line["client_ip"])
+	}
+	if line["created_at"] != "2026-01-02T03:04:05Z" {
+		t.Errorf("expected created_at 2026-01-02T03:04:05Z, got %v", line["created_at"])
+	}
+	details, ok := line["details"].(map[string]interface{})
+	if !ok || details["email"] != "user@example.com" {
+		t.Errorf("expected details.email user@example.com, got %v", line["details"])
+	}
+	if _, ok := line["group_id"]; ok {
+		t.Error("expected empty group_id to be omitted")
+	}
+}
+
+func TestFileAuditLogger_RotatesAtSizeThreshold(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewFileAuditLogger(path, FileAuditLoggerOptions{MaxSize: 400, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+	defer func() { _ = logger.Close() }()
+
+	for i := 0; i < 10; i++ {
+		if err := logger.Log(&AuditLog{EventType: AuditLogout, ActorType: ActorTypeUser}); err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", filepath.Base(p), err)
+		}
+		if info.Size() > 400 {
+			t.Errorf("expected %s to be at most 400 bytes, got %d", filepath.Base(p), info.Size())
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("expected only 2 rotated files to be kept")
+	}
+}
+
+func TestFileAuditLogger_FlushInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewFileAuditLogger(path, FileAuditLoggerOptions{FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+
+	if err := logger.Log(&AuditLog{EventType: AuditLogout, ActorType: ActorTypeUser}); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	if lines := readAuditLines(t, path); len(lines) != 0 {
+		t.Errorf("expected entry to be buffered, got %d lines", len(lines))
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+
+	if lines := readAuditLines(t, path); len(lines) != 1 {
+		t.Errorf("expected 1 line after Close, got %d", len(lines))
+	}
+}
+
+func TestFileAuditLogger_ConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewFileAuditLogger(path, FileAuditLoggerOptions{FlushInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+
+	const writers = 20
+	const perWriter = 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				entry := &AuditLog{
+					EventType: AuditRefreshSuccess,
+					ActorType: ActorTypeAgent,
+					Details:   map[string]interface{}{"writer": i, "seq": j},
+				}
+				if err := logger.Log(entry); err != nil {
+					t.Errorf("Log failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// readAuditLines fails on any interleaved (invalid JSON) line
+	if lines := readAuditLines(t, path); len(lines) != writers*perWriter {
+		t.Errorf("expected %d lines, got %d", writers*perWriter, len(lines))
+	}
+}
+
+func TestFileAuditLogger_LogAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewFileAuditLogger(path, FileAuditLoggerOptions{})
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if err := logger.Log(&AuditLog{EventType: AuditLogout}); err == nil {
+		t.Error("expected error logging to a closed audit log")
+	}
+}
+
+func TestFileAuditLogger_CheckWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatalf("failed to create log dir: %v", err)
+	}
+	path := filepath.Join(dir, "audit.log")
+	logger, err := NewFileAuditLogger(path, FileAuditLoggerOptions{})
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+	defer func() { _ = logger.Close() }()
+
+	if err := logger.CheckWritable(); err != nil {
+		t.Fatalf("expected writable audit log, got: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read log dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected probe file to be removed, found %d entries", len(entries))
+	}
+
+	// The open file handle survives, but new files (and rotation) cannot be created
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("failed to remove log dir: %v", err)
+	}
+	if err := logger.CheckWritable(); err == nil {
+		t.Error("expected error when the audit log directory is gone")
+	}
+}
+
+func TestFileAuditLogger_CheckWritableAfterClose(t *testing.T) {
+	logger, err := NewFileAuditLogger(filepath.Join(t.TempDir(), "audit.log"), FileAuditLoggerOptions{})
+	if err != nil {
+		t.Fatalf("NewFileAuditLogger failed: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if err := logger.CheckWritable(); err == nil {
+		t.Error("expected error checking a closed audit log")
+	}
+}
+
+func TestWriterAuditLogger_WritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWriterAuditLogger(&buf)
+
+	for i := 0; i < 2; i++ {
+		if err := logger.Log(&AuditLog{EventType: AuditLogout, ActorType: ActorTypeUser}); err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("line is not valid JSON: %v", err)
+	}
+	if record["event_type"] != string(AuditLogout) {
+		t.Errorf("expected event_type %s, got %v", AuditLogout, record["event_type"])
+	}
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// BuiltinPasswordDenylist selects the embedded list of common passwords
+const BuiltinPasswordDenylist = "builtin"
+
+//go:embed common_passwords.txt
+var builtinPasswords string
+
+// PasswordDenylist is a set of passwords that are rejected regardless of
+// whether they meet the composition rules. Matching is case-insensitive.
+type PasswordDenylist struct {
+	entries map[string]struct{}
+}
+
+var (
+	passwordDenylistMu sync.RWMutex
+	passwordDenylist   *PasswordDenylist
+)
+
+// SetPasswordDenylist configures the denylist used by ValidatePassword.
+// A nil denylist disables the check.
+func SetPasswordDenylist(d *PasswordDenylist) {
+	passwordDenylistMu.Lock()
+	defer passwordDenylistMu.Unlock()
+	passwordDenylist = d
+}
+
+func currentPasswordDenylist() *PasswordDenylist {
+	passwordDenylistMu.RLock()
+	defer passwordDenylistMu.RUnlock()
+	return passwordDenylist
+}
+
+// LoadPasswordDenylist loads a denylist from source: "" disables the check
+// (returns nil), "builtin" uses the embedded list, and anything else is read
+// as a file path with one password per line.
+func LoadPasswordDenylist(source string) (*PasswordDenylist, error) {
+	switch source {
+	case "":
+		return nil, nil
+	case BuiltinPasswordDenylist:
+		return ParsePasswordDenylist(strings.NewReader(builtinPasswords))
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open password denylist: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		return ParsePasswordDenylist(f)
+	}
+}
+
+// ParsePasswordDenylist reads one password per line. Blank lines and lines
+// starting with # are ignored.
+func ParsePasswordDenylist(r io.Reader) (*PasswordDenylist, error) {
+	d := &PasswordDenylist{entries: make(map[string]struct{})}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		d.entries[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read password denylist: %w", err)
+	}
+
+	return d, nil
+}
+
+// Contains reports whether password is on the denylist
+func (d *PasswordDenylist) Contains(password string) bool {
+	_, ok := d.entries[strings.ToLower(password)]
+	return ok
+}
+
+// Len returns the number of passwords on the denylist
+func (d *PasswordDenylist) Len() int {
+	return len(d.entries)
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useDenylist installs d for the duration of the test
+func useDenylist(t *testing.T, d *PasswordDenylist) {
+	t.Helper()
+	SetPasswordDenylist(d)
+	t.Cleanup(func() { SetPasswordDenylist(nil) })
+}
+
+func TestValidatePassword_DenylistedPasswordRejected(t *testing.T) {
+	d, err := LoadPasswordDenylist(BuiltinPasswordDenylist)
+	if err != nil {
+		t.Fatalf("LoadPasswordDenylist() error = %v", err)
+	}
+	useDenylist(t, d)
+
+	// Each meets every composition rule but is on the list, in any case
+	for _, password := range []string{"Password1234!", "pASSWORD1234!", "Welcome1234!"} {
+		err := ValidatePassword(password)
+		if err == nil || !strings.Contains(err.Error(), "too common") {
+			t.Errorf("ValidatePassword(%q) error = %v, want denylist rejection", password, err)
+		}
+	}
+}
+
+func TestValidatePassword_StrongPasswordPassesDenylist(t *testing.T) {
+	d, err := LoadPasswordDenylist(BuiltinPasswordDenylist)
+	if err != nil {
+		t.Fatalf("LoadPasswordDenylist() error = %v", err)
+	}
+	useDenylist(t, d)
+
+	if err := ValidatePassword("Tangerine#Orbit42Lamp"); err != nil {
+		t.Errorf("ValidatePassword() error = %v, want nil for strong unique password", err)
+	}
+}
+
+func TestValidatePassword_NoDenylistConfigured(t *testing.T) {
+	d, err := LoadPasswordDenylist("")
+	if err != nil {
+		t.Fatalf("LoadPasswordDenylist(\"\") error = %v", err)
+	}
+	if d != nil {
+		t.Fatal("expected no denylist for empty source")
+	}
+	useDenylist(t, d)
+
+	if err := ValidatePassword("Password1234!"); err != nil {
+		t.Errorf("ValidatePassword() error = %v, want nil when no denylist is configured", err)
+	}
+}
+
+func TestLoadPasswordDenylist_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	content := "# company-specific\nAcmeCorp2026!\n\n  Devtools#Sync1  \n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write denylist: %v", err)
+	}
+
+	d, err := LoadPasswordDenylist(path)
+	if err != nil {
+		t.Fatalf("LoadPasswordDenylist() error = %v", err)
+	}
+	if d.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", d.Len())
+	}
+	useDenylist(t, d)
+
+	if err := ValidatePassword("ACMECORP2026!a"); err != nil {
+		t.Errorf("ValidatePassword() error = %v, want nil for password not on list", err)
+	}
+	if err := ValidatePassword("AcmeCorp2026!"); err == nil || !strings.Contains(err.Error(), "too common") {
+		t.Errorf("ValidatePassword(AcmeCorp2026!) error = %v, want denylist rejection", err)
+	}
+}
+
+func TestLoadPasswordDenylist_MissingFile(t *testing.T) {
+	if _, err := LoadPasswordDenylist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected error for missing denylist file")
+	}
+}
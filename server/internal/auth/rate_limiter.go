@@ -6,6 +6,13 @@ import (
 	"time"
 )
 
+// Defaults for the rate limiter guarding the authentication endpoints
+const (
+	DefaultRateLimitCleanupInterval = 5 * time.Minute
+	DefaultRateLimitMaxAge          = time.Hour
+	DefaultRateLimitMaxEntries      = 10000
+)
+
 // RateLimiter implements rate limiting for authentication endpoints.
 // It automatically cleans up stale entries and enforces a maximum map size
 // to prevent memory exhaustion under sustained attack.
@@ -13,6 +20,7 @@ type RateLimiter struct {
 	mu         sync.Mutex
 	attempts   map[string][]time.Time
 	maxEntries int
+	clock      Clock
 	stopCh     chan struct{}
 	stopped    sync.Once
 }
@@ -21,24 +29,35 @@ type RateLimiter struct {
 // stale entries. cleanupInterval controls how often cleanup runs, maxAge
 // controls how long entries are kept, and maxEntries caps the map size.
 func NewRateLimiter(cleanupInterval, maxAge time.Duration, maxEntries int) *RateLimiter {
-	rl := &RateLimiter{
-		attempts:   make(map[string][]time.Time),
-		maxEntries: maxEntries,
-		stopCh:     make(chan struct{}),
-	}
+	rl := newRateLimiter(maxEntries)
 
 	go rl.cleanupLoop(cleanupInterval, maxAge)
 
 	return rl
 }
 
+// newRateLimiter creates a rate limiter without starting its cleanup loop
+func newRateLimiter(maxEntries int) *RateLimiter {
+	return &RateLimiter{
+		attempts:   make(map[string][]time.Time),
+		maxEntries: maxEntries,
+		clock:      SystemClock,
+		stopCh:     make(chan struct{}),
+	}
+}
+
 func (rl *RateLimiter) cleanupLoop(interval, maxAge time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	rl.runCleanup(ticker.C, maxAge)
+}
+
+// runCleanup removes entries older than maxAge on every tick until Stop is called
+func (rl *RateLimiter) runCleanup(tick <-chan time.Time, maxAge time.Duration) {
 	for {
 		select {
-		case <-ticker.C:
+		case <-tick:
 			rl.Cleanup(maxAge)
 		case <-rl.stopCh:
 			return
@@ -46,6 +65,12 @@ func (rl *RateLimiter) cleanupLoop(interval, maxAge time.Duration) {
 	}
 }
 
+// SetClock replaces the clock used to timestamp attempts and age them out.
+// Call it before the limiter is in use.
+func (rl *RateLimiter) SetClock(clock Clock) {
+	rl.clock = clock
+}
+
 // Stop halts the background cleanup goroutine. Safe to call multiple times.
 func (rl *RateLimiter) Stop() {
 	rl.stopped.Do(func() {
@@ -68,7 +93,7 @@ func (rl *RateLimiter) CheckLimit(key string, maxAttempts int, window time.Durat
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	now := time.Now()
+	now := rl.clock.Now()
 	attempts := rl.attempts[key]
 
 	// Filter to attempts within window
@@ -127,7 +152,7 @@ func (rl *RateLimiter) Cleanup(maxAge time.Duration) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	now := time.Now()
+	now := rl.clock.Now()
 	for key, attempts := range rl.attempts {
 		var recent []time.Time
 		for _, t := range attempts {
@@ -41,6 +41,45 @@ func TestCleanupRemovesStaleEntries(t *testing.T) {
 	}
 }
 
+func TestCleanupTickRemovesExpiredEntries(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rl := newRateLimiter(1000)
+	rl.SetClock(ClockFunc(func() time.Time { return now }))
+
+	tick := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		rl.runCleanup(tick, time.Hour)
+		close(done)
+	}()
+
+	if err := rl.CheckLimit("stale-ip", 100, time.Hour); err != nil {
+		t.Fatalf("CheckLimit failed: %v", err)
+	}
+	now = now.Add(50 * time.Minute)
+	if err := rl.CheckLimit("active-ip", 100, time.Hour); err != nil {
+		t.Fatalf("CheckLimit failed: %v", err)
+	}
+
+	// stale-ip's only attempt is now past maxAge; active-ip's is 20 minutes old
+	now = now.Add(20 * time.Minute)
+	tick <- now
+
+	// The loop can only exit once the tick's cleanup has finished
+	rl.Stop()
+	<-done
+
+	if rl.Len() != 1 {
+		t.Fatalf("expected 1 entry after cleanup tick, got %d", rl.Len())
+	}
+	if _, ok := rl.attempts["active-ip"]; !ok {
+		t.Error("active entry was removed by cleanup")
+	}
+	if _, ok := rl.attempts["stale-ip"]; ok {
+		t.Error("expired entry was not removed by cleanup")
+	}
+}
+
 func TestMaxEntriesEviction(t *testing.T) {
 	maxEntries := 5
 	rl := NewRateLimiter(time.Hour, time.Hour, maxEntries)
@@ -14,23 +14,36 @@ import (
 // AuthService handles authentication operations
 type AuthService struct {
 	secretKey []byte
+	clock     Clock
 }
 
 // NewAuthService creates a new AuthService
 func NewAuthService(secretKey []byte) *AuthService {
 	return &AuthService{
 		secretKey: secretKey,
+		clock:     SystemClock,
 	}
 }
 
+// SetClock replaces the clock used for token timestamps and expiry checks
+func (s *AuthService) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// Now returns the current time according to the service's clock
+func (s *AuthService) Now() time.Time {
+	return s.clock.Now()
+}
+
 // GenerateAccessToken generates a JWT access token for a user
 func (s *AuthService) GenerateAccessToken(user *User) (string, error) {
+	now := s.Now()
 	claims := jwt.MapClaims{
 		"sub":   user.ID.String(),
 		"email": user.Email,
 		"role":  user.Role,
-		"iat":   time.Now().Unix(),
-		"exp":   time.Now().Add(15 * time.Minute).Unix(),
+		"iat":   now.Unix(),
+		"exp":   now.Add(15 * time.Minute).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -45,7 +58,7 @@ func (s *AuthService) ValidateAccessToken(tokenString string) (*Claims, error) {
 			return nil, jwt.ErrSignatureInvalid
 		}
 		return s.secretKey, nil
-	})
+	}, jwt.WithTimeFunc(s.Now))
 
 	if err != nil {
 		return nil, err
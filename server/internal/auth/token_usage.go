@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTokenUsageFlushInterval is how often batched refresh token usage is written
+const DefaultTokenUsageFlushInterval = 30 * time.Second
+
+// BatchUpdateLastUsedFunc persists last_used_at for many refresh tokens at once,
+// keyed by refresh token ID
+type BatchUpdateLastUsedFunc func(lastUsed map[uuid.UUID]time.Time) error
+
+// TokenUsageTracker accumulates refresh token last-used timestamps in memory
+// and writes them in batches, so a token refresh does not cost a database write.
+// Timestamps may lag by up to one flush interval.
+type TokenUsageTracker struct {
+	mu          sync.Mutex
+	pending     map[uuid.UUID]time.Time
+	updateBatch BatchUpdateLastUsedFunc
+	stopCh      chan struct{}
+	doneCh      chan struct{}
+	stopped     sync.Once
+}
+
+// NewTokenUsageTracker creates a tracker that flushes pending usage through
+// updateBatch every flushInterval. Call Stop on shutdown to flush the rest.
+func NewTokenUsageTracker(updateBatch BatchUpdateLastUsedFunc, flushInterval time.Duration) *TokenUsageTracker {
+	t := &TokenUsageTracker{
+		pending:     make(map[uuid.UUID]time.Time),
+		updateBatch: updateBatch,
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+
+	go t.flushLoop(flushInterval)
+
+	return t
+}
+
+func (t *TokenUsageTracker) flushLoop(interval time.Duration) {
+	defer close(t.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.Flush(); err != nil {
+				log.Printf("WARNING: failed to flush refresh token usage: %v", err)
+			}
+		case <-t.stopCh:
+			return
+		}
+	}
+}
+
+// Record notes that a refresh token was used. Its signature matches the
+// refresh handler's token updater, so it can be passed in place of a
+// write-through update. Only the latest timestamp per token is kept.
+func (t *TokenUsageTracker) Record(rt *RefreshToken) error {
+	if rt.LastUsedAt == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if existing, ok := t.pending[rt.ID]; !ok || rt.LastUsedAt.After(existing) {
+		t.pending[rt.ID] = *rt.LastUsedAt
+	}
+	return nil
+}
+
+// Pending returns the number of tokens waiting to be flushed.
+func (t *TokenUsageTracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// Flush writes all pending usage in a single batch. On failure the entries
+// are kept so the next flush retries them.
+func (t *TokenUsageTracker) Flush() error {
+	t.mu.Lock()
+	if len(t.pending) == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	batch := t.pending
+	t.pending = make(map[uuid.UUID]time.Time)
+	t.mu.Unlock()
+
+	if err := t.updateBatch(batch); err != nil {
+		t.mu.Lock()
+		for id, usedAt := range batch {
+			if existing, ok := t.pending[id]; !ok || usedAt.After(existing) {
+				t.pending[id] = usedAt
+			}
+		}
+		t.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Stop halts the background flush loop and writes any pending usage.
+// Safe to call multiple times.
+func (t *TokenUsageTracker) Stop() error {
+	t.stopped.Do(func() {
+		close(t.stopCh)
+	})
+	<-t.doneCh
+	return t.Flush()
+}
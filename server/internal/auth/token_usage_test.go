@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// batchRecorder captures batches passed to a BatchUpdateLastUsedFunc
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches []map[uuid.UUID]time.Time
+	err     error
+}
+
+func (b *batchRecorder) update(lastUsed map[uuid.UUID]time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	b.batches = append(b.batches, lastUsed)
+	return nil
+}
+
+func (b *batchRecorder) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.batches)
+}
+
+func usedAt(id uuid.UUID, at time.Time) *RefreshToken {
+	return &RefreshToken{ID: id, LastUsedAt: &at}
+}
+
+func TestTokenUsageTracker_CoalescesUpdates(t *testing.T) {
+	recorder := &batchRecorder{}
+	tracker := NewTokenUsageTracker(recorder.update, time.Hour)
+	defer func() { _ = tracker.Stop() }()
+
+	tokenA := uuid.New()
+	tokenB := uuid.New()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_ = tracker.Record(usedAt(tokenA, base))
+	_ = tracker.Record(usedAt(tokenA, base.Add(2*time.Minute)))
+	_ = tracker.Record(usedAt(tokenA, base.Add(time.Minute)))
+	_ = tracker.Record(usedAt(tokenB, base))
+
+	if recorder.count() != 0 {
+		t.Fatalf("expected no writes before flush, got %d", recorder.count())
+	}
+
+	if err := tracker.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if recorder.count() != 1 {
+		t.Fatalf("expected 1 batched write, got %d", recorder.count())
+	}
+	batch := recorder.batches[0]
+	if len(batch) != 2 {
+		t.Errorf("expected 2 tokens in batch, got %d", len(batch))
+	}
+	if !batch[tokenA].Equal(base.Add(2 * time.Minute)) {
+		t.Errorf("token A last used = %v, want latest %v", batch[tokenA], base.Add(2*time.Minute))
+	}
+
+	// Nothing pending means no further writes
+	if err := tracker.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if recorder.count() != 1 {
+		t.Errorf("expected empty flush to skip the write, got %d writes", recorder.count())
+	}
+}
+
+func TestTokenUsageTracker_StopFlushesPending(t *testing.T) {
+	recorder := &batchRecorder{}
+	tracker := NewTokenUsageTracker(recorder.update, time.Hour)
+
+	_ = tracker.Record(usedAt(uuid.New(), time.Now()))
+
+	if err := tracker.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	if recorder.count() != 1 {
+		t.Errorf("expected pending usage to be flushed on stop, got %d writes", recorder.count())
+	}
+	if tracker.Pending() != 0 {
+		t.Errorf("expected nothing pending after stop, got %d", tracker.Pending())
+	}
+
+	// Stopping twice is safe
+	if err := tracker.Stop(); err != nil {
+		t.Errorf("second Stop failed: %v", err)
+	}
+}
+
+func TestTokenUsageTracker_FlushesPeriodically(t *testing.T) {
+	recorder := &batchRecorder{}
+	tracker := NewTokenUsageTracker(recorder.update, 20*time.Millisecond)
+	defer func() { _ = tracker.Stop() }()
+
+	_ = tracker.Record(usedAt(uuid.New(), time.Now()))
+
+	deadline := time.Now().Add(time.Second)
+	for recorder.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if recorder.count() != 1 {
+		t.Errorf("expected background flush, got %d writes", recorder.count())
+	}
+}
+
+func TestTokenUsageTracker_FailedFlushKeepsPending(t *testing.T) {
+	recorder := &batchRecorder{err: errors.New("database unavailable")}
+	tracker := NewTokenUsageTracker(recorder.update, time.Hour)
+	defer func() { _ = tracker.Stop() }()
+
+	_ = tracker.Record(usedAt(uuid.New(), time.Now()))
+
+	if err := tracker.Flush(); err == nil {
+		t.Fatal("expected flush error")
+	}
+	if tracker.Pending() != 1 {
+		t.Fatalf("expected failed batch to be retained, got %d pending", tracker.Pending())
+	}
+
+	recorder.mu.Lock()
+	recorder.err = nil
+	recorder.mu.Unlock()
+
+	if err := tracker.Flush(); err != nil {
+		t.Fatalf("retry flush failed: %v", err)
+	}
+	if recorder.count() != 1 {
+		t.Errorf("expected retried batch to be written, got %d writes", recorder.count())
+	}
+}
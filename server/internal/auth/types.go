@@ -32,6 +32,7 @@ type RefreshToken struct {
 	ID          uuid.UUID
 	UserID      uuid.UUID
 	TokenHash   string
+	DeviceID    string
 	DeviceName  string
 	UserAgent   string
 	ClientIP    string
@@ -41,6 +42,21 @@ type RefreshToken struct {
 	CreatedAt   time.Time
 }
 
+// APIKey represents a database API key record. Only the key's hash is
+// stored; requests authenticated with it act as UserID with at most Role.
+type APIKey struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	KeyHash    string
+	KeyPrefix  string
+	Role       string
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
 // UserInvite represents a database user invite record
 type UserInvite struct {
 	ID         uuid.UUID
@@ -6,7 +6,12 @@ import (
 	"unicode"
 )
 
-// ValidatePassword enforces password complexity requirements
+// ErrEmailExists is returned by user stores when creating a user whose email
+// is already registered
+var ErrEmailExists = errors.New("a user with this email already exists")
+
+// ValidatePassword enforces password complexity requirements and, when a
+// denylist is configured (see SetPasswordDenylist), rejects common passwords
 func ValidatePassword(password string) error {
 	if len(password) < 12 {
 		return errors.New("password must be at least 12 characters")
@@ -30,6 +35,10 @@ func ValidatePassword(password string) error {
 		return errors.New("password must contain uppercase, lowercase, number, and special character")
 	}
 
+	if denylist := currentPasswordDenylist(); denylist != nil && denylist.Contains(password) {
+		return errors.New("password is too common, choose a different password")
+	}
+
 	return nil
 }
 
@@ -50,7 +59,7 @@ func CreateInviteData(authService *AuthService, email, role, invitedBy string) (
 		TokenHash:  tokenHash,
 		Role:       role,
 		InvitedBy:  invitedBy,
-		ExpiresAt:  time.Now().Add(48 * time.Hour),
+		ExpiresAt:  authService.Now().Add(48 * time.Hour),
 		AcceptedAt: nil,
 	}
 
@@ -65,7 +74,7 @@ func ValidateInviteToken(authService *AuthService, inviteData *InviteData, token
 	}
 
 	// Check if expired
-	if time.Now().After(inviteData.ExpiresAt) {
+	if authService.Now().After(inviteData.ExpiresAt) {
 		return false
 	}
 
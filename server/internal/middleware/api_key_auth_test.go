@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+)
+
+// apiKeyFixture is an admin user holding one API key
+type apiKeyFixture struct {
+	authService *auth.AuthService
+	user        *auth.User
+	key         *auth.APIKey
+	plaintext   string
+}
+
+func newAPIKeyFixture(t *testing.T, role string) *apiKeyFixture {
+	t.Helper()
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+
+	plaintext, err := authService.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() error = %v", err)
+	}
+
+	user := &auth.User{ID: uuid.New(), Email: "ci@example.com", Role: "admin", IsActive: true}
+	return &apiKeyFixture{
+		authService: authService,
+		user:        user,
+		key: &auth.APIKey{
+			ID:      uuid.New(),
+			UserID:  user.ID,
+			Name:    "ci",
+			KeyHash: authService.HashToken(plaintext),
+			Role:    role,
+		},
+		plaintext: plaintext,
+	}
+}
+
+// serve sends credential through RequireAuthWithAPIKeys and returns the
+// response and the user the handler saw, if it was called
+func (f *apiKeyFixture) serve(credential string) (*httptest.ResponseRecorder, *auth.User) {
+	userGetter := func(userID string) (*auth.User, error) {
+		if userID == f.user.ID.String() {
+			return f.user, nil
+		}
+		return nil, nil
+	}
+	apiKeyByHash := func(keyHash string) (*auth.APIKey, error) {
+		if keyHash == f.key.KeyHash {
+			return f.key, nil
+		}
+		return nil, nil
+	}
+
+	var seen *auth.User
+	handler := RequireAuthWithAPIKeys(f.authService, userGetter, apiKeyByHash, ProxyAuthConfig{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen, _ = r.Context().Value(userContextKey).(*auth.User)
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+credential)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w, seen
+}
+
+func TestRequireAuthWithAPIKeys_ValidKey(t *testing.T) {
+	f := newAPIKeyFixture(t, "viewer")
+
+	w, user := f.serve(f.plaintext)
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d", w.Code, http.StatusOK)
+	}
+	if user == nil || user.ID != f.user.ID {
+		t.Fatalf("context user = %v, want %v", user, f.user.ID)
+	}
+	// The key's role caps the admin user's
+	if user.Role != "viewer" {
+		t.Errorf("context role = %q, want viewer", user.Role)
+	}
+	if f.user.Role != "admin" {
+		t.Error("capping the role must not modify the stored user")
+	}
+}
+
+func TestRequireAuthWithAPIKeys_StillAcceptsJWT(t *testing.T) {
+	f := newAPIKeyFixture(t, "viewer")
+	token, err := f.authService.GenerateAccessToken(f.user)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+
+	w, user := f.serve(token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("response code = %d, want %d", w.Code, http.StatusOK)
+	}
+	if user == nil || user.Role != "admin" {
+		t.Errorf("context user = %v, want the admin user", user)
+	}
+}
+
+func TestRequireAuthWithAPIKeys_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		credential func(f *apiKeyFixture) string
+		setup      func(f *apiKeyFixture)
+	}{
+		{
+			name:       "unknown key",
+			credential: func(f *apiKeyFixture) string { return auth.APIKeyPrefix + "unknown" },
+		},
+		{
+			name: "revoked key",
+			setup: func(f *apiKeyFixture) {
+				revokedAt := f.authService.Now().Add(-time.Minute)
+				f.key.RevokedAt = &revokedAt
+			},
+		},
+		{
+			name: "expired key",
+			setup: func(f *apiKeyFixture) {
+				expiresAt := f.authService.Now().Add(-time.Minute)
+				f.key.ExpiresAt = &expiresAt
+			},
+		},
+		{
+			name:  "inactive user",
+			setup: func(f *apiKeyFixture) { f.user.IsActive = false },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAPIKeyFixture(t, "admin")
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			credential := f.plaintext
+			if tt.credential != nil {
+				credential = tt.credential(f)
+			}
+
+			w, user := f.serve(credential)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("response code = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+			if user != nil {
+				t.Error("handler should not be called")
+			}
+		})
+	}
+}
+
+func TestRequireAuth_RejectsAPIKeysWithoutGetter(t *testing.T) {
+	f := newAPIKeyFixture(t, "admin")
+	userGetter := func(userID string) (*auth.User, error) { return f.user, nil }
+
+	called := false
+	handler := RequireAuth(f.authService, userGetter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+f.plaintext)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized || called {
+		t.Errorf("response code = %d (handler called: %v), want 401", w.Code, called)
+	}
+}
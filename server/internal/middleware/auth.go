@@ -17,10 +17,56 @@ const userContextKey contextKey = "user"
 // UserGetter is a function that retrieves a user by ID
 type UserGetter func(userID string) (*auth.User, error)
 
+// APIKeyGetter is a function that retrieves an API key by the HashToken hash
+// of the key; returns nil if none exists
+type APIKeyGetter func(keyHash string) (*auth.APIKey, error)
+
+// roleHierarchy orders roles from least to most privileged
+var roleHierarchy = map[string]int{
+	"viewer":  1,
+	"manager": 2,
+	"admin":   3,
+}
+
 // RequireAuth is middleware that validates JWT tokens and attaches user to context
 func RequireAuth(authService *auth.AuthService, userGetter UserGetter) func(http.Handler) http.Handler {
+	return RequireAuthWithProxy(authService, userGetter, ProxyAuthConfig{})
+}
+
+// RequireAuthWithAPIKeys is RequireAuthWithProxy that also accepts API keys
+// (see auth.APIKeyPrefix) as the bearer credential. A key is looked up by its
+// hash through apiKeyByHash and must be neither revoked nor expired; the
+// request then acts as the key's user, which must be active, with the role
+// capped at the key's role. A nil apiKeyByHash rejects API keys.
+func RequireAuthWithAPIKeys(authService *auth.AuthService, userGetter UserGetter, apiKeyByHash APIKeyGetter, proxy ProxyAuthConfig) func(http.Handler) http.Handler {
+	return requireAuth(authService, userGetter, apiKeyByHash, proxy)
+}
+
+// RequireAuthWithProxy is RequireAuth that also accepts the identity header
+// described by proxy on requests from a trusted proxy. Such requests skip JWT
+// validation and act as the existing, active user the header names, with the
+// role capped at proxy.MaxRole. A zero ProxyAuthConfig disables the header.
+func RequireAuthWithProxy(authService *auth.AuthService, userGetter UserGetter, proxy ProxyAuthConfig) func(http.Handler) http.Handler {
+	return requireAuth(authService, userGetter, nil, proxy)
+}
+
+func requireAuth(authService *auth.AuthService, userGetter UserGetter, apiKeyByHash APIKeyGetter, proxy ProxyAuthConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if email := proxy.proxyIdentity(r); email != "" {
+				user, err := proxy.UserByEmail(email)
+				if err != nil || user == nil || !user.IsActive {
+					writeJSON(w, http.StatusUnauthorized, map[string]string{
+						"error": "User not found or inactive",
+					})
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), userContextKey, proxy.capRole(user))
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// Extract Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
@@ -33,6 +79,20 @@ func RequireAuth(authService *auth.AuthService, userGetter UserGetter) func(http
 			// Extract token
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 
+			if auth.IsAPIKey(token) {
+				user, ok := apiKeyUser(authService, userGetter, apiKeyByHash, token)
+				if !ok {
+					writeJSON(w, http.StatusUnauthorized, map[string]string{
+						"error": "Invalid, expired or revoked API key",
+					})
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), userContextKey, user)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// Validate token
 			claims, err := authService.ValidateAccessToken(token)
 			if err != nil {
@@ -66,14 +126,40 @@ func RequireAuth(authService *auth.AuthService, userGetter UserGetter) func(http
 	}
 }
 
-// RequireRole is middleware that checks if the user has the required role
-func RequireRole(minRole string) func(http.Handler) http.Handler {
-	roleHierarchy := map[string]int{
-		"viewer":  1,
-		"manager": 2,
-		"admin":   3,
+// apiKeyUser resolves an API key to the active user it acts as, with the
+// role capped at the key's role
+func apiKeyUser(authService *auth.AuthService, userGetter UserGetter, apiKeyByHash APIKeyGetter, key string) (*auth.User, bool) {
+	if apiKeyByHash == nil {
+		return nil, false
+	}
+
+	apiKey, err := apiKeyByHash(authService.HashToken(key))
+	if err != nil || apiKey == nil || !apiKey.Usable(authService.Now()) {
+		return nil, false
 	}
 
+	user, err := userGetter(apiKey.UserID.String())
+	if err != nil || user == nil || !user.IsActive {
+		return nil, false
+	}
+
+	return capRole(user, apiKey.Role), true
+}
+
+// capRole returns user with its role lowered to maxRole if it is more
+// privileged. An unknown maxRole leaves the user unchanged.
+func capRole(user *auth.User, maxRole string) *auth.User {
+	maxLevel, ok := roleHierarchy[maxRole]
+	if !ok || roleHierarchy[user.Role] <= maxLevel {
+		return user
+	}
+	capped := *user
+	capped.Role = maxRole
+	return &capped
+}
+
+// RequireRole is middleware that checks if the user has the required role
+func RequireRole(minRole string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get user from context (set by RequireAuth)
@@ -6,10 +6,18 @@ import (
 )
 
 // MaxBodySize returns middleware that limits request body size
-// maxBytes is the maximum allowed body size in bytes
+// maxBytes is the maximum allowed body size in bytes.
+// Requests whose Content-Length already exceeds the limit are rejected with
+// 413 before the body is read, so a client that sent "Expect: 100-continue"
+// is never told to continue and does not upload the body at all.
 func MaxBodySize(maxBytes int64) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				writeBodyTooLarge(w, maxBytes)
+				return
+			}
+
 			// Limit request body size
 			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
 
@@ -31,14 +39,19 @@ func HandleMaxBytesError(w http.ResponseWriter, err error, maxBytes int64) bool
 
 	// Check if error is from MaxBytesReader
 	if err.Error() == "http: request body too large" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusRequestEntityTooLarge)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"error":          "Request body too large",
-			"max_size_bytes": maxBytes,
-		})
+		writeBodyTooLarge(w, maxBytes)
 		return true
 	}
 
 	return false
 }
+
+// writeBodyTooLarge writes the 413 response for an oversized request body
+func writeBodyTooLarge(w http.ResponseWriter, maxBytes int64) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":          "Request body too large",
+		"max_size_bytes": maxBytes,
+	})
+}
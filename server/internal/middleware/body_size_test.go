@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestMaxBodySize_WithinLimit(t *testing.T) {
@@ -206,3 +207,57 @@ func TestMaxBodySize_MultipleReads(t *testing.T) {
 		t.Error("Expected non-200 status for oversized body")
 	}
 }
+
+// countingReader counts the bytes read from it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestMaxBodySize_RejectsDeclaredLengthBeforeContinue(t *testing.T) {
+	handlerCalled := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerCalled = true
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	srv := httptest.NewServer(MaxBodySize(1024)(handler))
+	defer srv.Close()
+
+	// A long continue timeout makes the client wait for the server's answer
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ExpectContinueTimeout = 5 * time.Second
+	client := &http.Client{Transport: transport}
+
+	const size = 1 << 20
+	body := &countingReader{r: bytes.NewReader(bytes.Repeat([]byte("a"), size))}
+	req, err := http.NewRequest("POST", srv.URL, body)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Expect", "100-continue")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", resp.StatusCode)
+	}
+	if handlerCalled {
+		t.Error("Expected handler not to be called for an oversized Content-Length")
+	}
+	if body.n != 0 {
+		t.Errorf("Expected the body not to be sent, but %d bytes were read", body.n)
+	}
+}
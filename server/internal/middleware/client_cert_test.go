@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCertificate_AttachesCommonName(t *testing.T) {
+	var gotCN string
+	var gotOK bool
+	handler := ClientCertificate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCN, gotOK = ClientCommonName(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{
+			{Subject: pkix.Name{CommonName: "build-agent-01"}},
+		}},
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !gotOK || gotCN != "build-agent-01" {
+		t.Errorf("expected client CN 'build-agent-01', got %q (%v)", gotCN, gotOK)
+	}
+}
+
+func TestClientCertificate_NoVerifiedCertificate(t *testing.T) {
+	tests := map[string]*tls.ConnectionState{
+		"plain HTTP":      nil,
+		"no client cert":  {},
+		"unverified cert": {PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "spoofed"}}}},
+	}
+
+	for name, state := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotOK bool
+			handler := ClientCertificate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, gotOK = ClientCommonName(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.TLS = state
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if gotOK {
+				t.Error("expected no client CN in context")
+			}
+		})
+	}
+}
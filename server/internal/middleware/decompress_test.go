@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipBody(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// echoBody responds with the request body it read and its Content-Encoding.
+// A body it cannot read is rejected with 400, as the API handlers do.
+func echoBody(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if !HandleMaxBytesError(w, err, 0) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	w.Header().Set("X-Seen-Encoding", r.Header.Get("Content-Encoding"))
+	_, _ = w.Write(body)
+}
+
+func TestDecompressRequest_Gzip(t *testing.T) {
+	payload := []byte(`{"name":"work"}`)
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(gzipBody(t, payload)))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	DecompressRequest(http.HandlerFunc(echoBody)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != string(payload) {
+		t.Errorf("Expected decompressed body %q, got %q", payload, w.Body.String())
+	}
+	if enc := w.Header().Get("X-Seen-Encoding"); enc != "" {
+		t.Errorf("Expected Content-Encoding to be removed, handler saw %q", enc)
+	}
+}
+
+func TestDecompressRequest_PlainBodyUntouched(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte("plain")))
+	w := httptest.NewRecorder()
+
+	DecompressRequest(http.HandlerFunc(echoBody)).ServeHTTP(w, req)
+
+	if w.Body.String() != "plain" {
+		t.Errorf("Expected plain body, got %q", w.Body.String())
+	}
+}
+
+func TestDecompressRequest_InvalidGzip(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte("not gzip")))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	DecompressRequest(http.HandlerFunc(echoBody)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), ErrInvalidGzipBody.Error()) {
+		t.Errorf("Expected an invalid gzip error, got %q", w.Body.String())
+	}
+}
+
+func TestDecompressRequest_UnsupportedEncoding(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte("data")))
+	req.Header.Set("Content-Encoding", "br")
+	w := httptest.NewRecorder()
+
+	DecompressRequest(http.HandlerFunc(echoBody)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status 415, got %d", w.Code)
+	}
+}
+
+func TestDecompressRequest_LimitAppliesToDecompressedSize(t *testing.T) {
+	// 64KB of zeros compresses to well under the 1KB limit
+	compressed := gzipBody(t, bytes.Repeat([]byte{0}, 64*1024))
+	if len(compressed) >= 1024 {
+		t.Fatalf("setup: compressed body is %d bytes, want under 1024", len(compressed))
+	}
+
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(compressed))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	DecompressRequest(MaxBodySize(1024)(http.HandlerFunc(echoBody))).ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for an oversized decompressed body, got %d", w.Code)
+	}
+}
+
+func TestDecompressRequest_RejectedBeforeContinue(t *testing.T) {
+	// The inner handler rejects the request without reading the body
+	handler := DecompressRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	body := gzipBody(t, []byte(`{"name":"work"}`))
+	_, err = fmt.Fprintf(conn, "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Encoding: gzip\r\nContent-Length: %d\r\nExpect: 100-continue\r\n\r\n", len(body))
+	if err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without 100 Continue, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+)
+
+// UserByEmailGetter is a function that retrieves a user by email
+type UserByEmailGetter func(email string) (*auth.User, error)
+
+// ProxyAuthConfig lets RequireAuthWithProxy authenticate requests by an
+// identity header set by an auth proxy in front of the server. The header is
+// only honored on connections coming directly from a trusted proxy; on any
+// other request it is ignored and a JWT is required as usual.
+type ProxyAuthConfig struct {
+	// Header carries the email of the user the proxy authenticated,
+	// e.g. X-Auth-Email
+	Header string
+
+	// TrustedProxies are the networks of the proxies allowed to set Header.
+	// They are matched against the connection's peer address, never against
+	// X-Forwarded-For or X-Real-IP, which clients can forge.
+	TrustedProxies []*net.IPNet
+
+	// UserByEmail maps the header to an existing user; proxy authentication
+	// never creates users
+	UserByEmail UserByEmailGetter
+
+	// MaxRole is the highest role granted to proxy-authenticated requests.
+	// Users with a higher stored role act with MaxRole. Empty grants each
+	// user their stored role.
+	MaxRole string
+}
+
+// enabled reports whether the config is complete enough to honor the header
+func (c ProxyAuthConfig) enabled() bool {
+	return c.Header != "" && len(c.TrustedProxies) > 0 && c.UserByEmail != nil
+}
+
+// trustedPeer reports whether the request's connection comes from a trusted proxy
+func (c ProxyAuthConfig) trustedPeer(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range c.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyIdentity returns the email asserted by a trusted proxy, or "" if the
+// request does not carry one that may be honored
+func (c ProxyAuthConfig) proxyIdentity(r *http.Request) string {
+	if !c.enabled() || !c.trustedPeer(r) {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(r.Header.Get(c.Header)))
+}
+
+// capRole returns user with its role lowered to MaxRole if it is higher
+func (c ProxyAuthConfig) capRole(user *auth.User) *auth.User {
+	return capRole(user, c.MaxRole)
+}
+
+// ParseTrustedProxies parses a comma-separated list of IP addresses and CIDR
+// networks, such as "10.0.0.5, 192.168.1.0/24"
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: not an IP address or CIDR network", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
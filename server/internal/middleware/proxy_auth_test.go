@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mark-chris/devtools-sync/server/internal/auth"
+)
+
+// serveProxyAuth sends a request from remoteAddr with the given headers
+// through RequireAuthWithProxy and returns the response and the user the
+// next handler saw, if it was reached
+func serveProxyAuth(t *testing.T, proxy ProxyAuthConfig, remoteAddr string, headers map[string]string) (*httptest.ResponseRecorder, *auth.User) {
+	t.Helper()
+	authService := auth.NewAuthService([]byte("test-secret-key-min-32-bytes-long!"))
+	userGetter := func(userID string) (*auth.User, error) { return nil, nil }
+
+	var seen *auth.User
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = r.Context().Value(userContextKey).(*auth.User)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profiles", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	RequireAuthWithProxy(authService, userGetter, proxy)(next).ServeHTTP(w, req)
+	return w, seen
+}
+
+func testProxyConfig(t *testing.T, users ...*auth.User) ProxyAuthConfig {
+	t.Helper()
+	trusted, err := ParseTrustedProxies("10.0.0.5, 192.168.1.0/24")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	return ProxyAuthConfig{
+		Header:         "X-Auth-Email",
+		TrustedProxies: trusted,
+		UserByEmail: func(email string) (*auth.User, error) {
+			for _, u := range users {
+				if u.Email == email {
+					return u, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestRequireAuthWithProxy_HonorsHeaderFromTrustedProxy(t *testing.T) {
+	user := &auth.User{ID: uuid.New(), Email: "dev@example.com", Role: "manager", IsActive: true}
+	proxy := testProxyConfig(t, user)
+
+	for _, remoteAddr := range []string{"10.0.0.5:51234", "192.168.1.77:443"} {
+		w, seen := serveProxyAuth(t, proxy, remoteAddr, map[string]string{"X-Auth-Email": " Dev@Example.com "})
+		if w.Code != http.StatusOK {
+			t.Fatalf("from %s: status = %d, want 200: %s", remoteAddr, w.Code, w.Body.String())
+		}
+		if seen == nil || seen.ID != user.ID {
+			t.Errorf("from %s: context user = %+v, want %s", remoteAddr, seen, user.ID)
+		}
+	}
+}
+
+func TestRequireAuthWithProxy_IgnoresHeaderFromUntrustedPeer(t *testing.T) {
+	user := &auth.User{ID: uuid.New(), Email: "dev@example.com", Role: "admin", IsActive: true}
+	proxy := testProxyConfig(t, user)
+
+	// A forged X-Forwarded-For must not make the peer look trusted
+	w, seen := serveProxyAuth(t, proxy, "203.0.113.9:40000", map[string]string{
+		"X-Auth-Email":    "dev@example.com",
+		"X-Forwarded-For": "10.0.0.5",
+	})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+	if seen != nil {
+		t.Errorf("expected the request to be rejected, reached handler as %+v", seen)
+	}
+}
+
+func TestRequireAuthWithProxy_DisabledWithoutConfig(t *testing.T) {
+	user := &auth.User{ID: uuid.New(), Email: "dev@example.com", Role: "viewer", IsActive: true}
+	proxy := testProxyConfig(t, user)
+	proxy.TrustedProxies = nil
+
+	w, _ := serveProxyAuth(t, proxy, "10.0.0.5:51234", map[string]string{"X-Auth-Email": "dev@example.com"})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401 without trusted proxies", w.Code)
+	}
+}
+
+func TestRequireAuthWithProxy_UnknownOrInactiveUser(t *testing.T) {
+	inactive := &auth.User{ID: uuid.New(), Email: "gone@example.com", Role: "viewer", IsActive: false}
+	proxy := testProxyConfig(t, inactive)
+
+	for _, email := range []string{"nobody@example.com", "gone@example.com"} {
+		w, seen := serveProxyAuth(t, proxy, "10.0.0.5:51234", map[string]string{"X-Auth-Email": email})
+		if w.Code != http.StatusUnauthorized || seen != nil {
+			t.Errorf("%s: status = %d, want 401 without reaching the handler", email, w.Code)
+		}
+	}
+}
+
+func TestRequireAuthWithProxy_CapsRole(t *testing.T) {
+	admin := &auth.User{ID: uuid.New(), Email: "root@example.com", Role: "admin", IsActive: true}
+	viewer := &auth.User{ID: uuid.New(), Email: "ro@example.com", Role: "viewer", IsActive: true}
+	proxy := testProxyConfig(t, admin, viewer)
+	proxy.MaxRole = "manager"
+
+	_, seen := serveProxyAuth(t, proxy, "10.0.0.5:51234", map[string]string{"X-Auth-Email": "root@example.com"})
+	if seen == nil || seen.Role != "manager" {
+		t.Errorf("admin through proxy has role %+v, want manager", seen)
+	}
+	if admin.Role != "admin" {
+		t.Errorf("capping must not modify the stored user, role is now %s", admin.Role)
+	}
+
+	_, seen = serveProxyAuth(t, proxy, "10.0.0.5:51234", map[string]string{"X-Auth-Email": "ro@example.com"})
+	if seen == nil || seen.Role != "viewer" {
+		t.Errorf("viewer through proxy has role %+v, want viewer", seen)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks, err := ParseTrustedProxies("10.0.0.5, 192.168.1.0/24,,::1")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	want := []string{"10.0.0.5/32", "192.168.1.0/24", "::1/128"}
+	if len(networks) != len(want) {
+		t.Fatalf("got %v, want %v", networks, want)
+	}
+	for i, n := range networks {
+		if n.String() != want[i] {
+			t.Errorf("network %d = %s, want %s", i, n, want[i])
+		}
+	}
+
+	for _, bad := range []string{"proxy.internal", "10.0.0.0/33"} {
+		if _, err := ParseTrustedProxies(bad); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) should fail", bad)
+		}
+	}
+}
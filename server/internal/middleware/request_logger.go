@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// statusRecorder captures the status code written by the wrapped handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// RequestLogger returns middleware that logs each request with its method,
+// path, status, and duration. Requests taking longer than slowThreshold are
+// additionally logged at warn level so outliers stand out.
+// A slowThreshold of zero disables slow-request warnings.
+func RequestLogger(slowThreshold time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rec, r)
+
+			duration := time.Since(start)
+			log.Printf("%s %s %d %s", r.Method, r.URL.Path, rec.status, duration)
+
+			if slowThreshold > 0 && duration > slowThreshold {
+				log.Printf("WARNING: slow request: %s %s took %s (threshold %s)", r.Method, r.URL.Path, duration, slowThreshold)
+			}
+		})
+	}
+}
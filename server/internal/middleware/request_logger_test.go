@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureLog redirects the standard logger to a buffer for the duration of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	origOutput := log.Writer()
+	log.SetOutput(buf)
+	t.Cleanup(func() {
+		log.SetOutput(origOutput)
+	})
+	return buf
+}
+
+func TestRequestLogger_SlowRequestLogsWarning(t *testing.T) {
+	buf := captureLog(t)
+
+	handler := RequestLogger(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/profiles", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	got := buf.String()
+	if !strings.Contains(got, "WARNING: slow request") {
+		t.Fatalf("expected slow request warning, got: %s", got)
+	}
+	if !strings.Contains(got, "GET /api/v1/profiles took") {
+		t.Errorf("expected method and path in slow request warning, got: %s", got)
+	}
+}
+
+func TestRequestLogger_FastRequestNoWarning(t *testing.T) {
+	buf := captureLog(t)
+
+	handler := RequestLogger(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	got := buf.String()
+	if strings.Contains(got, "WARNING") {
+		t.Errorf("expected no warning for fast request, got: %s", got)
+	}
+	if !strings.Contains(got, "GET /health 200") {
+		t.Errorf("expected access log line, got: %s", got)
+	}
+}
+
+func TestRequestLogger_ZeroThresholdDisablesWarning(t *testing.T) {
+	buf := captureLog(t)
+
+	handler := RequestLogger(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if strings.Contains(buf.String(), "WARNING") {
+		t.Errorf("expected no warning when threshold is zero, got: %s", buf.String())
+	}
+}
+
+func TestRequestLogger_RecordsStatus(t *testing.T) {
+	buf := captureLog(t)
+
+	handler := RequestLogger(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/missing", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if !strings.Contains(buf.String(), "POST /missing 404") {
+		t.Errorf("expected status 404 in access log, got: %s", buf.String())
+	}
+}
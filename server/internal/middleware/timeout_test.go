@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout_HandlerExceedsDeadline(t *testing.T) {
+	handlerDone := make(chan error, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Sleep past the deadline without watching the context
+		time.Sleep(200 * time.Millisecond)
+		_, err := w.Write([]byte("too late"))
+		handlerDone <- err
+	})
+
+	req := httptest.NewRequest("GET", "/slow", nil)
+	w := httptest.NewRecorder()
+
+	Timeout(20*time.Millisecond)(handler).ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %q", ct)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q: %v", w.Body.String(), err)
+	}
+	if body["error"] != "Request timed out" {
+		t.Errorf("Expected timeout error message, got %q", body["error"])
+	}
+
+	if err := <-handlerDone; err != http.ErrHandlerTimeout {
+		t.Errorf("Expected late write to fail with ErrHandlerTimeout, got %v", err)
+	}
+}
+
+func TestTimeout_HandlerFinishesInTime(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("Expected request context to have a deadline")
+		}
+		w.Header().Set("X-Test", "ok")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	})
+
+	req := httptest.NewRequest("POST", "/fast", nil)
+	w := httptest.NewRecorder()
+
+	Timeout(time.Second)(handler).ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", w.Code)
+	}
+	if w.Header().Get("X-Test") != "ok" {
+		t.Error("Expected handler headers to be passed through")
+	}
+	if w.Body.String() != "created" {
+		t.Errorf("Expected body 'created', got %q", w.Body.String())
+	}
+}
+
+func TestTimeout_ZeroDisables(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			t.Error("Expected no deadline when timeout is disabled")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	Timeout(0)(handler).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestTimeout_WriterDoesNotFlush(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Output is buffered until the handler returns, so flushing is not offered
+		if _, ok := w.(http.Flusher); ok {
+			t.Error("Expected the buffered writer not to implement http.Flusher")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	Timeout(time.Second)(handler).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
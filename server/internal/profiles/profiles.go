@@ -0,0 +1,108 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNameConflict is returned when a profile name collides with an existing
+// profile of the same user after normalization
+var ErrNameConflict = errors.New("a profile with this name already exists")
+
+// Extension represents a VS Code extension entry in a profile
+type Extension struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Enabled bool   `json:"enabled"`
+
+	// Required is stored as sent by the agent; unset means required
+	Required *bool `json:"required,omitempty"`
+
+	// VersionConstraint is stored as sent by the agent, which resolves it
+	VersionConstraint string `json:"version_constraint,omitempty"`
+
+	// DisplayName, Publisher and Description are informational, copied by
+	// the agent from the extension manifest
+	DisplayName string `json:"display_name,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Profile represents an extension profile stored for a user
+type Profile struct {
+	ID          uuid.UUID   `json:"-"`
+	UserID      uuid.UUID   `json:"-"`
+	Name        string      `json:"name"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Extensions  []Extension `json:"extensions"`
+	Description string      `json:"description,omitempty"`
+
+	// Settings and Keybindings are the profile's VS Code settings.json and
+	// keybindings.json, stored as sent by the agent
+	Settings    json.RawMessage `json:"settings,omitempty"`
+	Keybindings json.RawMessage `json:"keybindings,omitempty"`
+
+	// ModifiedAt is set from the server clock on every upload, patch and
+	// restore. UpdatedAt is the client's edit time and may be set to any
+	// value, so change tracking (modified_since) uses ModifiedAt instead.
+	ModifiedAt time.Time `json:"modified_at"`
+
+	// Version is the Number of the newest Version snapshot of the profile
+	Version int `json:"version,omitempty"`
+
+	// DeletedAt is set when the profile is moved to the trash; trashed
+	// profiles are hidden until restored or purged
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Trashed reports whether the profile has been moved to the trash
+func (p *Profile) Trashed() bool {
+	return p.DeletedAt != nil
+}
+
+// NormalizeName trims surrounding whitespace from a profile name.
+// The agent applies the same normalization when saving locally.
+func NormalizeName(name string) string {
+	return strings.TrimSpace(name)
+}
+
+// NameKey returns the key used to enforce per-user name uniqueness.
+// Names that differ only by case or surrounding whitespace share a key.
+func NameKey(name string) string {
+	return strings.ToLower(NormalizeName(name))
+}
+
+// ValidateName checks that a normalized profile name is usable
+func ValidateName(name string) error {
+	if name == "" {
+		return errors.New("profile name cannot be empty")
+	}
+
+	if strings.ContainsAny(name, "/\\:*?\"<>|") {
+		return errors.New("profile name contains invalid characters")
+	}
+
+	return nil
+}
+
+// DuplicateExtensionIDs returns the IDs that appear more than once in exts,
+// in order of first appearance. IDs are compared case-insensitively, as VS
+// Code does. The agent deduplicates extensions before uploading, keeping the
+// highest version of each, so this only rejects other clients' uploads.
+func DuplicateExtensionIDs(exts []Extension) []string {
+	seen := make(map[string]int, len(exts))
+	var duplicates []string
+	for _, ext := range exts {
+		key := strings.ToLower(ext.ID)
+		seen[key]++
+		if seen[key] == 2 {
+			duplicates = append(duplicates, ext.ID)
+		}
+	}
+	return duplicates
+}
@@ -0,0 +1,62 @@
+package profiles
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"work", "work"},
+		{"  work  ", "work"},
+		{"Work\t", "Work"},
+		{"my profile", "my profile"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.input); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNameKey_CollidesOnCaseAndWhitespace(t *testing.T) {
+	if NameKey("Work") != NameKey("work ") {
+		t.Errorf("expected %q and %q to share a key", "Work", "work ")
+	}
+	if NameKey("work") == NameKey("personal") {
+		t.Error("expected different names to have different keys")
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	if err := ValidateName("work"); err != nil {
+		t.Errorf("ValidateName(work) error = %v, want nil", err)
+	}
+	if err := ValidateName(""); err == nil {
+		t.Error("ValidateName(\"\") error = nil, want error")
+	}
+	if err := ValidateName("../etc"); err == nil {
+		t.Error("ValidateName(../etc) error = nil, want error")
+	}
+}
+
+func TestDuplicateExtensionIDs(t *testing.T) {
+	exts := []Extension{
+		{ID: "golang.go"},
+		{ID: "ms-python.python"},
+		{ID: "Golang.Go"},
+		{ID: "golang.go"},
+		{ID: "ms-python.python"},
+	}
+
+	got := DuplicateExtensionIDs(exts)
+	want := []string{"Golang.Go", "ms-python.python"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("DuplicateExtensionIDs = %v, want %v", got, want)
+	}
+
+	if got := DuplicateExtensionIDs([]Extension{{ID: "a.b"}, {ID: "c.d"}}); len(got) != 0 {
+		t.Errorf("DuplicateExtensionIDs of unique IDs = %v, want none", got)
+	}
+}
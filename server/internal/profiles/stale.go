@@ -0,0 +1,71 @@
+package profiles
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStaleAge is the idle period after which a profile is reported as stale
+const DefaultStaleAge = 90 * 24 * time.Hour
+
+// ParseAge parses an age such as "90d", "12h" or "30m". A "d" suffix means
+// whole days; anything else is parsed as a Go duration. Ages must be positive.
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	var age time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		age = d
+	}
+
+	if age <= 0 {
+		return 0, fmt.Errorf("age must be positive, got %q", value)
+	}
+
+	return age, nil
+}
+
+// FilterStale returns the profiles last updated more than olderThan before now,
+// oldest first. A profile updated exactly olderThan ago is not stale.
+func FilterStale(all []Profile, now time.Time, olderThan time.Duration) []Profile {
+	cutoff := now.Add(-olderThan)
+
+	stale := make([]Profile, 0)
+	for _, p := range all {
+		if p.UpdatedAt.Before(cutoff) {
+			stale = append(stale, p)
+		}
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].UpdatedAt.Before(stale[j].UpdatedAt)
+	})
+
+	return stale
+}
+
+// FilterModifiedSince returns the profiles the server modified strictly after
+// since (see Profile.ModifiedAt), in order. A profile modified exactly at
+// since is left out.
+func FilterModifiedSince(all []Profile, since time.Time) []Profile {
+	modified := make([]Profile, 0, len(all))
+	for _, p := range all {
+		if p.ModifiedAt.After(since) {
+			modified = append(modified, p)
+		}
+	}
+	return modified
+}
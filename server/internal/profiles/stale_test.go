@@ -0,0 +1,97 @@
+package profiles
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"1d", 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"30m", 30 * time.Minute, false},
+		{"0d", 0, true},
+		{"-5d", 0, true},
+		{"d", 0, true},
+		{"ninety", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAge(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFilterStale_ThresholdBoundary(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	olderThan := 90 * 24 * time.Hour
+	cutoff := now.Add(-olderThan)
+
+	all := []Profile{
+		{Name: "at-cutoff", UpdatedAt: cutoff},
+		{Name: "just-past", UpdatedAt: cutoff.Add(-time.Second)},
+		{Name: "just-inside", UpdatedAt: cutoff.Add(time.Second)},
+	}
+
+	stale := FilterStale(all, now, olderThan)
+
+	if len(stale) != 1 || stale[0].Name != "just-past" {
+		t.Errorf("FilterStale() = %v, want only 'just-past'", names(stale))
+	}
+}
+
+func TestFilterModifiedSince(t *testing.T) {
+	since := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	all := []Profile{
+		{Name: "later", ModifiedAt: since.Add(time.Hour)},
+		{Name: "at-since", ModifiedAt: since},
+		{Name: "just-after", ModifiedAt: since.Add(time.Nanosecond)},
+		{Name: "before", ModifiedAt: since.Add(-time.Second)},
+		// UpdatedAt is the client's and is ignored
+		{Name: "client-clock", UpdatedAt: since.Add(time.Hour), ModifiedAt: since.Add(-time.Hour)},
+	}
+
+	modified := FilterModifiedSince(all, since)
+	if got := names(modified); len(got) != 2 || got[0] != "later" || got[1] != "just-after" {
+		t.Errorf("FilterModifiedSince() = %v, want [later just-after]", got)
+	}
+}
+
+func TestFilterStale_ExcludesRecentAndSortsOldestFirst(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	all := []Profile{
+		{Name: "recent", UpdatedAt: now.Add(-day)},
+		{Name: "old", UpdatedAt: now.Add(-100 * day)},
+		{Name: "ancient", UpdatedAt: now.Add(-400 * day)},
+		{Name: "today", UpdatedAt: now},
+	}
+
+	stale := FilterStale(all, now, 90*day)
+
+	got := names(stale)
+	if len(got) != 2 || got[0] != "ancient" || got[1] != "old" {
+		t.Errorf("FilterStale() = %v, want [ancient old]", got)
+	}
+}
+
+func names(ps []Profile) []string {
+	out := make([]string, len(ps))
+	for i, p := range ps {
+		out[i] = p.Name
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package profiles
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTrashRetention is how long a trashed profile can be restored before
+// the janitor purges it
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// FilterActive returns the profiles that are not in the trash, in order
+func FilterActive(all []Profile) []Profile {
+	active := make([]Profile, 0, len(all))
+	for _, p := range all {
+		if !p.Trashed() {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// FilterTrashed returns the profiles in the trash, most recently trashed first
+func FilterTrashed(all []Profile) []Profile {
+	trashed := make([]Profile, 0)
+	for _, p := range all {
+		if p.Trashed() {
+			trashed = append(trashed, p)
+		}
+	}
+	sortByDeletedAt(trashed, false)
+	return trashed
+}
+
+// ExpiredTrash returns the trashed profiles deleted more than retention before
+// now, oldest first. A profile trashed exactly retention ago is kept.
+func ExpiredTrash(all []Profile, now time.Time, retention time.Duration) []Profile {
+	cutoff := now.Add(-retention)
+
+	expired := make([]Profile, 0)
+	for _, p := range all {
+		if p.Trashed() && p.DeletedAt.Before(cutoff) {
+			expired = append(expired, p)
+		}
+	}
+	sortByDeletedAt(expired, true)
+	return expired
+}
+
+// sortByDeletedAt orders trashed profiles by deletion time
+func sortByDeletedAt(list []Profile, oldestFirst bool) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if oldestFirst {
+			return list[i].DeletedAt.Before(*list[j].DeletedAt)
+		}
+		return list[i].DeletedAt.After(*list[j].DeletedAt)
+	})
+}
+
+// ListAllFunc retrieves every stored profile across all users, including
+// trashed ones
+type ListAllFunc func() ([]Profile, error)
+
+// PurgeFunc permanently deletes the profile with the given ID
+type PurgeFunc func(id uuid.UUID) error
+
+// TrashJanitor periodically purges profiles that have been in the trash for
+// longer than the retention window
+type TrashJanitor struct {
+	listAll   ListAllFunc
+	purge     PurgeFunc
+	now       func() time.Time
+	retention time.Duration
+	stopCh    chan struct{}
+	stopped   sync.Once
+}
+
+// NewTrashJanitor creates a janitor that runs PurgeExpired every interval in
+// the background until Stop is called. now supplies the current time.
+func NewTrashJanitor(listAll ListAllFunc, purge PurgeFunc, now func() time.Time, interval, retention time.Duration) *TrashJanitor {
+	j := &TrashJanitor{
+		listAll:   listAll,
+		purge:     purge,
+		now:       now,
+		retention: retention,
+		stopCh:    make(chan struct{}),
+	}
+
+	go j.purgeLoop(interval)
+
+	return j
+}
+
+func (j *TrashJanitor) purgeLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if n, err := j.PurgeExpired(); err != nil {
+				log.Printf("trash janitor: purged %d profile(s) before failing: %v", n, err)
+			}
+		case <-j.stopCh:
+			return
+		}
+	}
+}
+
+// Stop halts the background purge goroutine. Safe to call multiple times.
+func (j *TrashJanitor) Stop() {
+	j.stopped.Do(func() {
+		close(j.stopCh)
+	})
+}
+
+// PurgeExpired permanently deletes trashed profiles past the retention window
+// and returns how many were purged. It stops at the first failure.
+func (j *TrashJanitor) PurgeExpired() (int, error) {
+	all, err := j.listAll()
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, p := range ExpiredTrash(all, j.now(), j.retention) {
+		if err := j.purge(p.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
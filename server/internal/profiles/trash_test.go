@@ -0,0 +1,95 @@
+package profiles
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// trashedAt returns a profile named name trashed at the given time
+func trashedAt(name string, at time.Time) Profile {
+	return Profile{ID: uuid.New(), Name: name, DeletedAt: &at}
+}
+
+func TestFilterActiveAndTrashed(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	all := []Profile{
+		{Name: "work"},
+		trashedAt("old", now.Add(-48*time.Hour)),
+		{Name: "home"},
+		trashedAt("recent", now.Add(-time.Hour)),
+	}
+
+	active := FilterActive(all)
+	if len(active) != 2 || active[0].Name != "work" || active[1].Name != "home" {
+		t.Errorf("FilterActive = %+v, want work and home in order", active)
+	}
+
+	trashed := FilterTrashed(all)
+	if len(trashed) != 2 || trashed[0].Name != "recent" || trashed[1].Name != "old" {
+		t.Errorf("FilterTrashed = %+v, want recent then old", trashed)
+	}
+}
+
+func TestExpiredTrash_RetentionBoundary(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	retention := DefaultTrashRetention
+	all := []Profile{
+		trashedAt("at-boundary", now.Add(-retention)),
+		trashedAt("just-expired", now.Add(-retention-time.Second)),
+		trashedAt("long-gone", now.Add(-2*retention)),
+		{Name: "active"},
+	}
+
+	expired := ExpiredTrash(all, now, retention)
+	if len(expired) != 2 || expired[0].Name != "long-gone" || expired[1].Name != "just-expired" {
+		t.Errorf("ExpiredTrash = %+v, want long-gone then just-expired", expired)
+	}
+}
+
+func TestTrashJanitor_PurgeExpired(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	expired := trashedAt("expired", now.Add(-DefaultTrashRetention-time.Hour))
+	kept := trashedAt("kept", now.Add(-time.Hour))
+	all := []Profile{{ID: uuid.New(), Name: "active"}, expired, kept}
+
+	var purged []uuid.UUID
+	j := NewTrashJanitor(
+		func() ([]Profile, error) { return all, nil },
+		func(id uuid.UUID) error { purged = append(purged, id); return nil },
+		func() time.Time { return now },
+		time.Hour, DefaultTrashRetention,
+	)
+	defer j.Stop()
+
+	n, err := j.PurgeExpired()
+	if err != nil {
+		t.Fatalf("PurgeExpired failed: %v", err)
+	}
+	if n != 1 || len(purged) != 1 || purged[0] != expired.ID {
+		t.Errorf("purged %d profile(s) %v, want only %v", n, purged, expired.ID)
+	}
+}
+
+func TestTrashJanitor_PurgeStopsOnError(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	all := []Profile{
+		trashedAt("a", now.Add(-DefaultTrashRetention-2*time.Hour)),
+		trashedAt("b", now.Add(-DefaultTrashRetention-time.Hour)),
+	}
+
+	j := NewTrashJanitor(
+		func() ([]Profile, error) { return all, nil },
+		func(id uuid.UUID) error { return errors.New("store unavailable") },
+		func() time.Time { return now },
+		time.Hour, DefaultTrashRetention,
+	)
+	j.Stop()
+	j.Stop() // safe to call twice
+
+	if n, err := j.PurgeExpired(); err == nil || n != 0 {
+		t.Errorf("PurgeExpired = %d, %v; want 0 and an error", n, err)
+	}
+}
@@ -0,0 +1,84 @@
+package profiles
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxTagLength bounds the length of a version tag
+const maxTagLength = 64
+
+// Version is an immutable snapshot of a profile recorded on every upload.
+// Number counts uploads of the profile starting at 1 and matches the
+// Profile.Version of the snapshot.
+type Version struct {
+	ProfileID uuid.UUID `json:"-"`
+	Number    int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Tag is an optional release label such as "stable" or "v2025.1". A tag
+	// names the newest version carrying it, so tagging a new upload with an
+	// existing tag moves the tag forward.
+	Tag string `json:"tag,omitempty"`
+
+	Profile Profile `json:"profile"`
+}
+
+// NormalizeTag trims surrounding whitespace from a version tag
+func NormalizeTag(tag string) string {
+	return strings.TrimSpace(tag)
+}
+
+// ValidateTag checks that a normalized tag is non-empty, at most 64
+// characters and made only of letters, digits, '.', '_' and '-'
+func ValidateTag(tag string) error {
+	if tag == "" {
+		return errors.New("tag cannot be empty")
+	}
+	if len(tag) > maxTagLength {
+		return errors.New("tag must be at most 64 characters")
+	}
+	for _, r := range tag {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return errors.New("tag may only contain letters, digits, '.', '_' and '-'")
+		}
+	}
+	return nil
+}
+
+// LatestTagged returns the newest version carrying tag, or nil if none does
+func LatestTagged(versions []Version, tag string) *Version {
+	var latest *Version
+	for i := range versions {
+		if versions[i].Tag == tag && (latest == nil || versions[i].Number > latest.Number) {
+			latest = &versions[i]
+		}
+	}
+	return latest
+}
+
+// Tagged returns the version each tag currently names, newest version first
+func Tagged(versions []Version) []Version {
+	byTag := make(map[string]Version)
+	for _, v := range versions {
+		if v.Tag == "" {
+			continue
+		}
+		if current, ok := byTag[v.Tag]; !ok || v.Number > current.Number {
+			byTag[v.Tag] = v
+		}
+	}
+
+	tagged := make([]Version, 0, len(byTag))
+	for _, v := range byTag {
+		tagged = append(tagged, v)
+	}
+	sort.Slice(tagged, func(i, j int) bool {
+		return tagged[i].Number > tagged[j].Number
+	})
+	return tagged
+}
@@ -0,0 +1,40 @@
+package profiles
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTag(t *testing.T) {
+	for _, valid := range []string{"stable", "v2025.1", "release_2-rc.1"} {
+		if err := ValidateTag(valid); err != nil {
+			t.Errorf("ValidateTag(%q) failed: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "has space", "a/b", strings.Repeat("x", 65)} {
+		if err := ValidateTag(invalid); err == nil {
+			t.Errorf("ValidateTag(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestTaggedAndLatestTagged(t *testing.T) {
+	versions := []Version{
+		{Number: 1, Tag: "stable"},
+		{Number: 2, Tag: "v2025.1"},
+		{Number: 3},
+		{Number: 4, Tag: "stable"},
+	}
+
+	if v := LatestTagged(versions, "stable"); v == nil || v.Number != 4 {
+		t.Errorf("LatestTagged(stable) = %+v, want version 4", v)
+	}
+	if v := LatestTagged(versions, "missing"); v != nil {
+		t.Errorf("LatestTagged(missing) = %+v, want nil", v)
+	}
+
+	tagged := Tagged(versions)
+	if len(tagged) != 2 || tagged[0].Tag != "stable" || tagged[0].Number != 4 || tagged[1].Tag != "v2025.1" {
+		t.Errorf("Tagged = %+v, want stable@4 then v2025.1@2", tagged)
+	}
+}
@@ -0,0 +1,2 @@
+-- 000013_create_profiles_table.down.sql
+DROP TABLE IF EXISTS profiles;
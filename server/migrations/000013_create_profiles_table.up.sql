@@ -0,0 +1,13 @@
+-- 000013_create_profiles_table.up.sql
+CREATE TABLE profiles (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  extensions JSONB NOT NULL DEFAULT '[]',
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Names are unique per user, ignoring case ("Work" and "work" collide)
+CREATE UNIQUE INDEX idx_profiles_user_id_name ON profiles(user_id, LOWER(name));
+CREATE INDEX idx_profiles_updated_at ON profiles(updated_at);
@@ -0,0 +1,4 @@
+ALTER TABLE refresh_tokens ADD COLUMN device_id VARCHAR(128);
+
+-- Login revokes the previous unrevoked token for the same (user, device)
+CREATE INDEX idx_refresh_tokens_user_id_device_id ON refresh_tokens(user_id, device_id) WHERE revoked_at IS NULL;
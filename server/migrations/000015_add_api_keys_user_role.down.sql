@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_api_keys_user_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS role;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS user_id;
+DELETE FROM api_keys WHERE group_id IS NULL;
+ALTER TABLE api_keys ALTER COLUMN group_id SET NOT NULL;
@@ -0,0 +1,7 @@
+-- User API keys act as their creator: group_id becomes optional and the key
+-- carries the user it authenticates and the highest role it grants
+ALTER TABLE api_keys ALTER COLUMN group_id DROP NOT NULL;
+ALTER TABLE api_keys ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE api_keys ADD COLUMN role VARCHAR(50);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_profiles_user_id_modified_at;
+ALTER TABLE profiles DROP COLUMN IF EXISTS modified_at;
@@ -0,0 +1,5 @@
+-- Server-assigned modification time; updated_at is the client's edit time
+ALTER TABLE profiles ADD COLUMN modified_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- List requests filter on modified_at (modified_since)
+CREATE INDEX idx_profiles_user_id_modified_at ON profiles(user_id, modified_at);
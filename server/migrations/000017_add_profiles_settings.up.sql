@@ -0,0 +1,3 @@
+-- VS Code settings.json and keybindings.json carried by a profile
+ALTER TABLE profiles ADD COLUMN settings JSONB;
+ALTER TABLE profiles ADD COLUMN keybindings JSONB;